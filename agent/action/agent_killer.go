@@ -0,0 +1,26 @@
+package action
+
+import (
+	"os"
+	"time"
+)
+
+type Killer interface {
+	KillAgent(waitToKillAgentInterval time.Duration)
+}
+
+type AgentKiller struct{}
+
+func NewAgentKiller() AgentKiller {
+	return AgentKiller{}
+}
+
+// KillAgent exits the agent process once the interval has passed so that its
+// process manager starts it again
+func (a AgentKiller) KillAgent(waitToKillAgentInterval time.Duration) {
+	time.Sleep(waitToKillAgentInterval)
+
+	syncFilesystems()
+
+	os.Exit(0)
+}
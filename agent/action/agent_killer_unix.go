@@ -0,0 +1,11 @@
+// +build !windows
+
+package action
+
+import (
+	"syscall"
+)
+
+func syncFilesystems() {
+	syscall.Sync()
+}
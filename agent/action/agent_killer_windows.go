@@ -0,0 +1,4 @@
+package action
+
+// Windows has no system wide equivalent of sync(2); files are flushed as they are closed
+func syncFilesystems() {}
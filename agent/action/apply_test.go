@@ -11,11 +11,11 @@ import (
 	boshas "github.com/cloudfoundry/bosh-agent/agent/applier/applyspec"
 	fakeas "github.com/cloudfoundry/bosh-agent/agent/applier/applyspec/fakes"
 	fakeappl "github.com/cloudfoundry/bosh-agent/agent/applier/fakes"
+	fakefs "github.com/cloudfoundry/bosh-agent/platform/fakefs"
 	boshsettings "github.com/cloudfoundry/bosh-agent/settings"
 	boshdir "github.com/cloudfoundry/bosh-agent/settings/directories"
 	fakesettings "github.com/cloudfoundry/bosh-agent/settings/fakes"
 	boshsys "github.com/cloudfoundry/bosh-utils/system"
-	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
 )
 
 var _ = Describe("ApplyAction", func() {
@@ -33,7 +33,7 @@ var _ = Describe("ApplyAction", func() {
 		specService = fakeas.NewFakeV1Service()
 		settingsService = &fakesettings.FakeSettingsService{}
 		dirProvider = boshdir.NewProvider("/var/vcap")
-		fs = fakesys.NewFakeFileSystem()
+		fs = fakefs.NewFakeFileSystem()
 		action = NewApply(applier, specService, settingsService, dirProvider, fs)
 	})
 
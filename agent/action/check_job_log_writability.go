@@ -0,0 +1,171 @@
+package action
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	boshas "github.com/cloudfoundry/bosh-agent/agent/applier/applyspec"
+	boshplatform "github.com/cloudfoundry/bosh-agent/platform"
+	boshsettings "github.com/cloudfoundry/bosh-agent/settings"
+	boshdirs "github.com/cloudfoundry/bosh-agent/settings/directories"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+)
+
+type JobLogWritability struct {
+	Job    string `json:"job"`
+	LogDir string `json:"log_dir"`
+	User   string `json:"user"`
+	Exists bool   `json:"exists"`
+
+	// Ownership and permissions are only reported for existing directories
+	Owner string `json:"owner,omitempty"`
+	Group string `json:"group,omitempty"`
+	Mode  string `json:"mode,omitempty"`
+
+	Writable bool `json:"writable"`
+}
+
+type CheckJobLogWritabilityResponse struct {
+	Jobs []JobLogWritability `json:"jobs"`
+}
+
+type CheckJobLogWritabilityAction struct {
+	specService boshas.V1Service
+	platform    boshplatform.Platform
+	dirProvider boshdirs.Provider
+}
+
+func NewCheckJobLogWritability(
+	specService boshas.V1Service,
+	platform boshplatform.Platform,
+	dirProvider boshdirs.Provider,
+) CheckJobLogWritabilityAction {
+	return CheckJobLogWritabilityAction{
+		specService: specService,
+		platform:    platform,
+		dirProvider: dirProvider,
+	}
+}
+
+func (a CheckJobLogWritabilityAction) IsAsynchronous(_ ProtocolVersion) bool {
+	return false
+}
+
+func (a CheckJobLogWritabilityAction) IsPersistent() bool {
+	return false
+}
+
+func (a CheckJobLogWritabilityAction) IsLoggable() bool {
+	return true
+}
+
+// Run checks the log directory of every job in the current spec against the
+// user job processes run as; jobs do not configure their own user
+func (a CheckJobLogWritabilityAction) Run() (CheckJobLogWritabilityResponse, error) {
+	response := CheckJobLogWritabilityResponse{Jobs: []JobLogWritability{}}
+
+	currentSpec, err := a.specService.Get()
+	if err != nil {
+		return response, bosherr.WrapError(err, "Getting current spec")
+	}
+
+	jobs := currentSpec.Jobs()
+	if len(jobs) == 0 {
+		return response, nil
+	}
+
+	jobUser := boshsettings.VCAPUsername
+
+	stdout, _, _, err := a.platform.GetRunner().RunCommand("id", "-Gn", jobUser)
+	if err != nil {
+		return response, bosherr.WrapErrorf(err, "Looking up groups of user '%s'", jobUser)
+	}
+
+	userGroups := strings.Fields(stdout)
+
+	for _, job := range jobs {
+		status, err := a.check(job.Name, jobUser, userGroups)
+		if err != nil {
+			return response, err
+		}
+
+		response.Jobs = append(response.Jobs, status)
+	}
+
+	return response, nil
+}
+
+func (a CheckJobLogWritabilityAction) Resume() (interface{}, error) {
+	return nil, errors.New("not supported")
+}
+
+func (a CheckJobLogWritabilityAction) Cancel() error {
+	return errors.New("not supported")
+}
+
+func (a CheckJobLogWritabilityAction) check(jobName, user string, userGroups []string) (JobLogWritability, error) {
+	status := JobLogWritability{
+		Job:    jobName,
+		LogDir: a.dirProvider.JobLogDir(jobName),
+		User:   user,
+	}
+
+	fs := a.platform.GetFs()
+
+	if !fs.FileExists(status.LogDir) {
+		return status, nil
+	}
+
+	fileInfo, err := fs.Stat(status.LogDir)
+	if err != nil {
+		return status, bosherr.WrapErrorf(err, "Checking log directory of job '%s'", jobName)
+	}
+
+	if !fileInfo.IsDir() {
+		return status, nil
+	}
+
+	status.Exists = true
+
+	stdout, _, _, err := a.platform.GetRunner().RunCommand("stat", "-c", "%U:%G", status.LogDir)
+	if err != nil {
+		return status, bosherr.WrapErrorf(err, "Getting owner of log directory of job '%s'", jobName)
+	}
+
+	ownership := strings.SplitN(strings.TrimSpace(stdout), ":", 2)
+	if len(ownership) != 2 {
+		return status, bosherr.Errorf("Parsing owner of log directory of job '%s' from '%s'", jobName, strings.TrimSpace(stdout))
+	}
+
+	status.Owner = ownership[0]
+	status.Group = ownership[1]
+
+	mode := fileInfo.Mode().Perm()
+	status.Mode = fmt.Sprintf("%#o", mode)
+
+	status.Writable = isWritableBy(mode, status.Owner == user, containsString(userGroups, status.Group))
+
+	return status, nil
+}
+
+func isWritableBy(mode os.FileMode, isOwner, isInGroup bool) bool {
+	switch {
+	case isOwner:
+		return mode&0200 != 0
+	case isInGroup:
+		return mode&0020 != 0
+	default:
+		return mode&0002 != 0
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
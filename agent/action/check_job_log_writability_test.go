@@ -0,0 +1,122 @@
+package action_test
+
+import (
+	"errors"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-agent/agent/action"
+	"github.com/cloudfoundry/bosh-agent/agent/applier/applyspec"
+	fakeapplyspec "github.com/cloudfoundry/bosh-agent/agent/applier/applyspec/fakes"
+	fakefs "github.com/cloudfoundry/bosh-agent/platform/fakefs"
+	"github.com/cloudfoundry/bosh-agent/platform/platformfakes"
+	boshdirs "github.com/cloudfoundry/bosh-agent/settings/directories"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+)
+
+var _ = Describe("CheckJobLogWritabilityAction", func() {
+	var (
+		specService *fakeapplyspec.FakeV1Service
+		platform    *platformfakes.FakePlatform
+		fs          *fakefs.FakeFileSystem
+		cmdRunner   *fakesys.FakeCmdRunner
+		action      CheckJobLogWritabilityAction
+	)
+
+	BeforeEach(func() {
+		specService = fakeapplyspec.NewFakeV1Service()
+		specService.Spec.RenderedTemplatesArchiveSpec = &applyspec.RenderedTemplatesArchiveSpec{}
+		specService.Spec.JobSpec.JobTemplateSpecs = []applyspec.JobTemplateSpec{{Name: "fake-job"}}
+
+		platform = &platformfakes.FakePlatform{}
+		fs = fakefs.NewFakeFileSystem()
+		cmdRunner = fakesys.NewFakeCmdRunner()
+		platform.GetFsReturns(fs)
+		platform.GetRunnerReturns(cmdRunner)
+
+		cmdRunner.AddCmdResult("id -Gn vcap", fakesys.FakeCmdResult{Stdout: "vcap admin\n"})
+
+		action = NewCheckJobLogWritability(specService, platform, boshdirs.NewProvider("/var/vcap"))
+	})
+
+	AssertActionIsNotAsynchronous(action)
+	AssertActionIsNotPersistent(action)
+	AssertActionIsLoggable(action)
+
+	AssertActionIsNotResumable(action)
+	AssertActionIsNotCancelable(action)
+
+	Describe("Run", func() {
+		createLogDir := func(mode os.FileMode) {
+			Expect(fs.MkdirAll("/var/vcap/data/sys/log/fake-job", mode)).To(Succeed())
+			Expect(fs.Chmod("/var/vcap/data/sys/log/fake-job", mode)).To(Succeed())
+		}
+
+		It("reports a log directory writable through the job user's group", func() {
+			createLogDir(0770)
+			cmdRunner.AddCmdResult("stat -c %U:%G /var/vcap/data/sys/log/fake-job", fakesys.FakeCmdResult{Stdout: "root:vcap\n"})
+
+			response, err := action.Run()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response).To(Equal(CheckJobLogWritabilityResponse{
+				Jobs: []JobLogWritability{
+					{
+						Job:      "fake-job",
+						LogDir:   "/var/vcap/data/sys/log/fake-job",
+						User:     "vcap",
+						Exists:   true,
+						Owner:    "root",
+						Group:    "vcap",
+						Mode:     "0770",
+						Writable: true,
+					},
+				},
+			}))
+		})
+
+		It("reports a log directory owned by another user and group as not writable", func() {
+			createLogDir(0755)
+			cmdRunner.AddCmdResult("stat -c %U:%G /var/vcap/data/sys/log/fake-job", fakesys.FakeCmdResult{Stdout: "root:root\n"})
+
+			response, err := action.Run()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Jobs).To(HaveLen(1))
+			Expect(response.Jobs[0].Exists).To(BeTrue())
+			Expect(response.Jobs[0].Owner).To(Equal("root"))
+			Expect(response.Jobs[0].Group).To(Equal("root"))
+			Expect(response.Jobs[0].Mode).To(Equal("0755"))
+			Expect(response.Jobs[0].Writable).To(BeFalse())
+		})
+
+		It("reports a missing log directory as not writable", func() {
+			response, err := action.Run()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Jobs).To(Equal([]JobLogWritability{
+				{
+					Job:    "fake-job",
+					LogDir: "/var/vcap/data/sys/log/fake-job",
+					User:   "vcap",
+				},
+			}))
+		})
+
+		It("returns an error when the owner of the log directory cannot be determined", func() {
+			createLogDir(0770)
+			cmdRunner.AddCmdResult("stat -c %U:%G /var/vcap/data/sys/log/fake-job", fakesys.FakeCmdResult{Error: errors.New("fake-stat-error")})
+
+			_, err := action.Run()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Getting owner of log directory of job 'fake-job'"))
+		})
+
+		It("returns an error when the current spec cannot be retrieved", func() {
+			specService.GetErr = errors.New("fake-spec-get-error")
+
+			_, err := action.Run()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-spec-get-error"))
+		})
+	})
+})
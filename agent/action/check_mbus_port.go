@@ -0,0 +1,102 @@
+package action
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"strconv"
+
+	boshsettings "github.com/cloudfoundry/bosh-agent/settings"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+)
+
+type CheckMbusPortResponse struct {
+	Address   string `json:"address"`
+	Available bool   `json:"available"`
+	Process   string `json:"process,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+type CheckMbusPortAction struct {
+	settingsService boshsettings.Service
+	prober          PortProber
+
+	logTag string
+	logger boshlog.Logger
+}
+
+func NewCheckMbusPort(settingsService boshsettings.Service, prober PortProber, logger boshlog.Logger) CheckMbusPortAction {
+	return CheckMbusPortAction{
+		settingsService: settingsService,
+		prober:          prober,
+
+		logTag: "Check Mbus Port Action",
+		logger: logger,
+	}
+}
+
+func (a CheckMbusPortAction) IsAsynchronous(_ ProtocolVersion) bool {
+	return false
+}
+
+func (a CheckMbusPortAction) IsPersistent() bool {
+	return false
+}
+
+func (a CheckMbusPortAction) IsLoggable() bool {
+	return true
+}
+
+func (a CheckMbusPortAction) Run() (CheckMbusPortResponse, error) {
+	mbusURL, err := url.Parse(a.settingsService.GetSettings().GetMbusURL())
+	if err != nil {
+		return CheckMbusPortResponse{}, bosherr.WrapError(err, "Parsing mbus URL")
+	}
+
+	port, err := strconv.Atoi(mbusURL.Port())
+	if err != nil {
+		return CheckMbusPortResponse{}, bosherr.Errorf("Mbus URL does not specify a valid port")
+	}
+
+	host := mbusURL.Hostname()
+	address := net.JoinHostPort(host, mbusURL.Port())
+	response := CheckMbusPortResponse{Address: address}
+
+	err = a.prober.Probe(probeNetwork(host), address)
+	if err == nil {
+		response.Available = true
+		return response, nil
+	}
+
+	response.Error = err.Error()
+
+	response.Process, err = a.prober.Owner(port)
+	if err != nil {
+		a.logger.Warn(a.logTag, "Failed to determine process listening on port %d: %s", port, err.Error())
+	}
+
+	return response, nil
+}
+
+// Literal addresses are probed on their own address family so that an
+// IPv6 mbus address is not satisfied by an IPv4 listener and vice versa
+func probeNetwork(host string) string {
+	ip := net.ParseIP(host)
+	switch {
+	case ip == nil:
+		return "tcp"
+	case ip.To4() != nil:
+		return "tcp4"
+	default:
+		return "tcp6"
+	}
+}
+
+func (a CheckMbusPortAction) Resume() (interface{}, error) {
+	return nil, errors.New("not supported")
+}
+
+func (a CheckMbusPortAction) Cancel() error {
+	return errors.New("not supported")
+}
@@ -0,0 +1,96 @@
+package action
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+
+	boshdirs "github.com/cloudfoundry/bosh-agent/settings/directories"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+type ReadOnlyMount struct {
+	PartitionPath string `json:"partition_path"`
+	MountPoint    string `json:"mount_point"`
+}
+
+type CheckReadOnlyMountsResponse struct {
+	ReadOnlyMounts []ReadOnlyMount `json:"read_only_mounts"`
+}
+
+type CheckReadOnlyMountsAction struct {
+	fs          boshsys.FileSystem
+	dirProvider boshdirs.Provider
+}
+
+func NewCheckReadOnlyMounts(fs boshsys.FileSystem, dirProvider boshdirs.Provider) CheckReadOnlyMountsAction {
+	return CheckReadOnlyMountsAction{
+		fs:          fs,
+		dirProvider: dirProvider,
+	}
+}
+
+func (a CheckReadOnlyMountsAction) IsAsynchronous(_ ProtocolVersion) bool {
+	return false
+}
+
+func (a CheckReadOnlyMountsAction) IsPersistent() bool {
+	return false
+}
+
+func (a CheckReadOnlyMountsAction) IsLoggable() bool {
+	return true
+}
+
+func (a CheckReadOnlyMountsAction) Run() (CheckReadOnlyMountsResponse, error) {
+	response := CheckReadOnlyMountsResponse{ReadOnlyMounts: []ReadOnlyMount{}}
+
+	mountInfo, err := a.fs.ReadFileString("/proc/mounts")
+	if err != nil {
+		return response, bosherr.WrapError(err, "Reading /proc/mounts")
+	}
+
+	for _, mountEntry := range strings.Split(mountInfo, "\n") {
+		mountFields := strings.Fields(mountEntry)
+		if len(mountFields) < 4 {
+			continue
+		}
+
+		mountPoint := mountFields[1]
+		if !a.isBoshMount(mountPoint) {
+			continue
+		}
+
+		for _, option := range strings.Split(mountFields[3], ",") {
+			if option == "ro" {
+				response.ReadOnlyMounts = append(response.ReadOnlyMounts, ReadOnlyMount{
+					PartitionPath: mountFields[0],
+					MountPoint:    mountPoint,
+				})
+				break
+			}
+		}
+	}
+
+	return response, nil
+}
+
+// Only the root filesystem and mounts under the BOSH base directory
+// are expected to always be writable
+func (a CheckReadOnlyMountsAction) isBoshMount(mountPoint string) bool {
+	if mountPoint == "/" {
+		return true
+	}
+
+	baseDir := filepath.Clean(a.dirProvider.BaseDir())
+	return mountPoint == baseDir || strings.HasPrefix(mountPoint, baseDir+"/")
+}
+
+func (a CheckReadOnlyMountsAction) Resume() (interface{}, error) {
+	return nil, errors.New("not supported")
+}
+
+func (a CheckReadOnlyMountsAction) Cancel() error {
+	return errors.New("not supported")
+}
@@ -0,0 +1,80 @@
+package action_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-agent/agent/action"
+	fakefs "github.com/cloudfoundry/bosh-agent/platform/fakefs"
+	boshdirs "github.com/cloudfoundry/bosh-agent/settings/directories"
+)
+
+var _ = Describe("CheckReadOnlyMountsAction", func() {
+	var (
+		fs     *fakefs.FakeFileSystem
+		action CheckReadOnlyMountsAction
+	)
+
+	BeforeEach(func() {
+		fs = fakefs.NewFakeFileSystem()
+		action = NewCheckReadOnlyMounts(fs, boshdirs.NewProvider("/var/vcap"))
+	})
+
+	AssertActionIsNotAsynchronous(action)
+	AssertActionIsNotPersistent(action)
+	AssertActionIsLoggable(action)
+
+	AssertActionIsNotResumable(action)
+	AssertActionIsNotCancelable(action)
+
+	Describe("Run", func() {
+		It("reports BOSH mounts that are read-only", func() {
+			err := fs.WriteFileString("/proc/mounts", `/dev/sda1 / ext4 rw,relatime 0 0
+proc /proc proc rw,nosuid,nodev,noexec,relatime 0 0
+/dev/sdb2 /var/vcap/data ext4 ro,relatime 0 0
+/dev/sdc1 /var/vcap/store ext4 rw,relatime 0 0
+/dev/sr0 /media/cdrom iso9660 ro,relatime 0 0
+`)
+			Expect(err).ToNot(HaveOccurred())
+
+			response, err := action.Run()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.ReadOnlyMounts).To(Equal([]ReadOnlyMount{
+				{PartitionPath: "/dev/sdb2", MountPoint: "/var/vcap/data"},
+			}))
+		})
+
+		It("reports nothing when all BOSH mounts are read-write", func() {
+			err := fs.WriteFileString("/proc/mounts", `/dev/sda1 / ext4 rw,relatime 0 0
+/dev/sdb2 /var/vcap/data ext4 rw,relatime 0 0
+/dev/sdc1 /var/vcap/store ext4 rw,relatime,errors=remount-ro 0 0
+`)
+			Expect(err).ToNot(HaveOccurred())
+
+			response, err := action.Run()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.ReadOnlyMounts).To(BeEmpty())
+		})
+
+		It("does not consider mounts that only share a prefix with the base directory", func() {
+			err := fs.WriteFileString("/proc/mounts", "/dev/sdd1 /var/vcapture ext4 ro 0 0\n")
+			Expect(err).ToNot(HaveOccurred())
+
+			response, err := action.Run()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.ReadOnlyMounts).To(BeEmpty())
+		})
+
+		It("returns an error when /proc/mounts cannot be read", func() {
+			err := fs.WriteFileString("/proc/mounts", "")
+			Expect(err).ToNot(HaveOccurred())
+			fs.RegisterReadFileError("/proc/mounts", errors.New("fake-read-error"))
+
+			_, err = action.Run()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-read-error"))
+		})
+	})
+})
@@ -51,7 +51,7 @@ func (a CompilePackageAction) Run(blobID string, multiDigest boshcrypto.Multiple
 		})
 	}
 
-	uploadedBlobID, uploadedDigest, err := a.compiler.Compile(pkg, modelsDeps)
+	uploadedBlobID, uploadedDigest, _, err := a.compiler.Compile(pkg, modelsDeps)
 	if err != nil {
 		err = bosherr.WrapErrorf(err, "Compiling package %s", pkg.Name)
 		return
@@ -2,11 +2,15 @@ package action
 
 import (
 	"errors"
+	"sort"
+	"time"
 
 	boshmodels "github.com/cloudfoundry/bosh-agent/agent/applier/models"
 	boshcomp "github.com/cloudfoundry/bosh-agent/agent/compiler"
+	blobdelegator "github.com/cloudfoundry/bosh-agent/agent/httpblobprovider/blobstore_delegator"
 	boshcrypto "github.com/cloudfoundry/bosh-utils/crypto"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
 )
 
 type CompilePackageWithSignedURLRequest struct {
@@ -18,19 +22,72 @@ type CompilePackageWithSignedURLRequest struct {
 	Name    string                    `json:"name"`
 	Version string                    `json:"version"`
 	Deps    boshcomp.Dependencies     `json:"deps"`
+
+	// DryRun only fetches and verifies the package and its dependencies
+	DryRun bool `json:"dry_run"`
+
+	// UploadDigestAlgorithm, e.g. "sha256", is used for the returned digest of
+	// the uploaded compiled package instead of the blobstore's default
+	UploadDigestAlgorithm string `json:"upload_digest_algorithm"`
+
+	// Signed URL for checking the blob already stored at UploadSignedURL, needed
+	// unless the blobstore is configured to overwrite duplicate uploads
+	UploadExistingBlobSignedURL string `json:"upload_existing_blob_signed_url"`
 }
 
+const (
+	DefaultCompilePackageFetchRetries                = 3
+	DefaultCompilePackageFetchRetryDelay             = 2 * time.Second
+	DefaultCompilePackageParallelDependencyDownloads = 5
+)
+
 type CompilePackageWithSignedURL struct {
-	compiler boshcomp.Compiler
+	compiler                    boshcomp.Compiler
+	blobDelegator               blobdelegator.BlobstoreDelegator
+	fs                          boshsys.FileSystem
+	retries                     int
+	retryDelay                  time.Duration
+	parallelDependencyDownloads int
 }
 
-func NewCompilePackageWithSignedURL(compiler boshcomp.Compiler) (compilePackage CompilePackageWithSignedURL) {
+// NewCompilePackageWithSignedURL creates the action; the compiler retries
+// fetching the package up to retries times after transient failures, waiting
+// retryDelay before the first retry and doubling the wait for each following one.
+// Up to parallelDependencyDownloads dependencies are downloaded at once.
+// Dry runs fetch the blobs through blobDelegator without involving compiler.
+func NewCompilePackageWithSignedURL(
+	compiler boshcomp.Compiler,
+	blobDelegator blobdelegator.BlobstoreDelegator,
+	fs boshsys.FileSystem,
+	retries int,
+	retryDelay time.Duration,
+	parallelDependencyDownloads int,
+) (compilePackage CompilePackageWithSignedURL) {
 	return CompilePackageWithSignedURL{
-		compiler: compiler,
+		compiler:                    compiler,
+		blobDelegator:               blobDelegator,
+		fs:                          fs,
+		retries:                     retries,
+		retryDelay:                  retryDelay,
+		parallelDependencyDownloads: parallelDependencyDownloads,
 	}
 }
 
 func (a CompilePackageWithSignedURL) Run(request CompilePackageWithSignedURLRequest) (map[string]interface{}, error) {
+	err := validateDependencies(request.Deps)
+	if err != nil {
+		return map[string]interface{}{}, bosherr.WrapErrorf(err, "Compiling package %s", request.Name)
+	}
+
+	var uploadDigestAlgorithm boshcrypto.Algorithm
+
+	if request.UploadDigestAlgorithm != "" {
+		uploadDigestAlgorithm, err = digestAlgorithmFor(request.UploadDigestAlgorithm)
+		if err != nil {
+			return map[string]interface{}{}, bosherr.WrapErrorf(err, "Compiling package %s", request.Name)
+		}
+	}
+
 	pkg := boshcomp.Package{
 		Name:                request.Name,
 		Sha1:                request.Digest,
@@ -38,6 +95,21 @@ func (a CompilePackageWithSignedURL) Run(request CompilePackageWithSignedURLRequ
 		PackageGetSignedURL: request.PackageGetSignedURL,
 		UploadSignedURL:     request.UploadSignedURL,
 		BlobstoreHeaders:    request.BlobstoreHeaders,
+
+		UploadExistingBlobSignedURL: request.UploadExistingBlobSignedURL,
+	}
+
+	if request.DryRun {
+		err = a.verifyInputs(pkg, request.Deps)
+		if err != nil {
+			return map[string]interface{}{}, bosherr.WrapErrorf(err, "Validating package %s", pkg.Name)
+		}
+
+		return map[string]interface{}{
+			"result": map[string]interface{}{
+				"dry_run": true,
+			},
+		}, nil
 	}
 
 	modelsDeps := []boshmodels.Package{}
@@ -55,13 +127,19 @@ func (a CompilePackageWithSignedURL) Run(request CompilePackageWithSignedURLRequ
 		})
 	}
 
-	_, uploadedDigest, err := a.compiler.Compile(pkg, modelsDeps)
+	_, uploadedDigest, uploadedSize, err := a.compiler.Compile(pkg, modelsDeps, boshcomp.CompileOptions{
+		MaxParallelDependencyDownloads: a.parallelDependencyDownloads,
+		UploadDigestAlgorithm:          uploadDigestAlgorithm,
+		FetchRetries:                   a.retries,
+		FetchRetryDelay:                a.retryDelay,
+	})
 	if err != nil {
 		return map[string]interface{}{}, bosherr.WrapErrorf(err, "Compiling package %s", pkg.Name)
 	}
 
-	result := map[string]string{
+	result := map[string]interface{}{
 		"sha1": uploadedDigest.String(),
+		"size": uploadedSize,
 	}
 
 	return map[string]interface{}{
@@ -69,6 +147,65 @@ func (a CompilePackageWithSignedURL) Run(request CompilePackageWithSignedURLRequ
 	}, nil
 }
 
+// validateDependencies catches dependencies the compiler would only fail on
+// after downloading the package and possibly other dependencies
+func validateDependencies(deps boshcomp.Dependencies) error {
+	for _, name := range sortedDependencyNames(deps) {
+		dep := deps[name]
+
+		if dep.PackageGetSignedURL == "" && dep.BlobstoreID == "" {
+			return bosherr.Errorf("Dependency '%s' has neither a package_get_signed_url nor a blobstore_id", name)
+		}
+
+		if dep.Sha1.String() == "" {
+			return bosherr.Errorf("Dependency '%s' has no sha1 digest", name)
+		}
+	}
+
+	return nil
+}
+
+// sortedDependencyNames makes sure the same dependency is reported first on every run
+func sortedDependencyNames(deps boshcomp.Dependencies) []string {
+	names := make([]string, 0, len(deps))
+	for name := range deps {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// verifyInputs fetches the package and its dependencies one after another,
+// relying on the delegator to verify each of them against its digest
+func (a CompilePackageWithSignedURL) verifyInputs(pkg boshcomp.Package, deps boshcomp.Dependencies) error {
+	err := a.fetchAndDiscard(pkg)
+	if err != nil {
+		return bosherr.WrapError(err, "Fetching package blob")
+	}
+
+	for _, name := range sortedDependencyNames(deps) {
+		err = a.fetchAndDiscard(deps[name])
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Fetching dependent package: '%s'", name)
+		}
+	}
+
+	return nil
+}
+
+func (a CompilePackageWithSignedURL) fetchAndDiscard(pkg boshcomp.Package) error {
+	fileName, err := a.blobDelegator.Get(pkg.Sha1, pkg.PackageGetSignedURL, pkg.BlobstoreID, pkg.BlobstoreHeaders)
+	if err != nil {
+		return err
+	}
+
+	_ = a.fs.RemoveAll(fileName)
+
+	return nil
+}
+
 func (a CompilePackageWithSignedURL) Resume() (interface{}, error) {
 	return nil, errors.New("not supported")
 }
@@ -3,6 +3,8 @@ package action_test
 import (
 	"encoding/json"
 	"errors"
+	"path/filepath"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -11,6 +13,8 @@ import (
 	boshmodels "github.com/cloudfoundry/bosh-agent/agent/applier/models"
 	boshcomp "github.com/cloudfoundry/bosh-agent/agent/compiler"
 	fakecomp "github.com/cloudfoundry/bosh-agent/agent/compiler/fakes"
+	fakeblobdelegator "github.com/cloudfoundry/bosh-agent/agent/httpblobprovider/blobstore_delegator/blobstore_delegatorfakes"
+	fakefs "github.com/cloudfoundry/bosh-agent/platform/fakefs"
 	boshcrypto "github.com/cloudfoundry/bosh-utils/crypto"
 )
 
@@ -45,13 +49,17 @@ func getCompileWithSignedURLActionArguments() CompilePackageWithSignedURLRequest
 
 var _ = Describe("CompilePackageWithSignedURL", func() {
 	var (
-		compiler *fakecomp.FakeCompiler
-		action   CompilePackageWithSignedURL
+		compiler      *fakecomp.FakeCompiler
+		blobDelegator *fakeblobdelegator.FakeBlobstoreDelegator
+		fs            *fakefs.FakeFileSystem
+		action        CompilePackageWithSignedURL
 	)
 
 	BeforeEach(func() {
 		compiler = fakecomp.NewFakeCompiler()
-		action = NewCompilePackageWithSignedURL(compiler)
+		blobDelegator = &fakeblobdelegator.FakeBlobstoreDelegator{}
+		fs = fakefs.NewFakeFileSystem()
+		action = NewCompilePackageWithSignedURL(compiler, blobDelegator, fs, 0, 0, 3)
 	})
 
 	AssertActionIsAsynchronous(action)
@@ -88,6 +96,7 @@ var _ = Describe("CompilePackageWithSignedURL", func() {
 		It("compile package compiles the package and returns blob id", func() {
 			compiler.CompileBlobID = "my-blob-id"
 			compiler.CompileDigest = boshcrypto.NewDigest(boshcrypto.DigestAlgorithmSHA1, "some checksum")
+			compiler.CompileSize = 1024
 
 			expectedPkg := boshcomp.Package{
 				BlobstoreID:         "",
@@ -100,8 +109,9 @@ var _ = Describe("CompilePackageWithSignedURL", func() {
 			}
 
 			expectedValue := map[string]interface{}{
-				"result": map[string]string{
+				"result": map[string]interface{}{
 					"sha1": "some checksum",
+					"size": int64(1024),
 				},
 			}
 			expectedDeps := []boshmodels.Package{
@@ -135,6 +145,50 @@ var _ = Describe("CompilePackageWithSignedURL", func() {
 
 			// Using ConsistOf since package dependencies are specified as a hash (no order)
 			Expect(compiler.CompileDeps).To(ConsistOf(expectedDeps))
+			Expect(compiler.CompileOpts).To(Equal([]boshcomp.CompileOptions{{MaxParallelDependencyDownloads: 3}}))
+		})
+
+		It("compiles the package with the requested upload digest algorithm", func() {
+			compiler.CompileDigest = boshcrypto.NewDigest(boshcrypto.DigestAlgorithmSHA256, "some-sha256-checksum")
+
+			request := getCompileWithSignedURLActionArguments()
+			request.UploadDigestAlgorithm = "sha256"
+
+			value, err := action.Run(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(value).To(Equal(map[string]interface{}{
+				"result": map[string]interface{}{
+					"sha1": "sha256:some-sha256-checksum",
+					"size": int64(0),
+				},
+			}))
+
+			Expect(compiler.CompileOpts).To(Equal([]boshcomp.CompileOptions{{
+				MaxParallelDependencyDownloads: 3,
+				UploadDigestAlgorithm:          boshcrypto.DigestAlgorithmSHA256,
+			}}))
+		})
+
+		It("passes the signed URL for checking the existing compiled package blob", func() {
+			compiler.CompileDigest = boshcrypto.NewDigest(boshcrypto.DigestAlgorithmSHA1, "some checksum")
+
+			request := getCompileWithSignedURLActionArguments()
+			request.UploadExistingBlobSignedURL = "fake/head/url"
+
+			_, err := action.Run(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(compiler.CompilePkg.UploadSignedURL).To(Equal("fake/upload/url"))
+			Expect(compiler.CompilePkg.UploadExistingBlobSignedURL).To(Equal("fake/head/url"))
+		})
+
+		It("returns an error without compiling when the upload digest algorithm is not supported", func() {
+			request := getCompileWithSignedURLActionArguments()
+			request.UploadDigestAlgorithm = "md5"
+
+			_, err := action.Run(request)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Compiling package fake-package-name: Unsupported digest algorithm 'md5'"))
+			Expect(compiler.CompileCallCount).To(Equal(0))
 		})
 
 		It("returns error when compile fails", func() {
@@ -144,5 +198,140 @@ var _ = Describe("CompilePackageWithSignedURL", func() {
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring("fake-compile-error"))
 		})
+
+		Context("when a dependency is malformed", func() {
+			unmarshalDeps := func(depsJSON string) boshcomp.Dependencies {
+				var deps boshcomp.Dependencies
+				Expect(json.Unmarshal([]byte(depsJSON), &deps)).To(Succeed())
+				return deps
+			}
+
+			It("returns an error without compiling when a dependency has no source", func() {
+				request := getCompileWithSignedURLActionArguments()
+				request.Deps = unmarshalDeps(`{
+					"good": {"name": "good", "version": "1", "sha1": "a9993e364706816aba3e25717850c26c9cd0d89d", "blobstore_id": "good-blobstore-id"},
+					"no-source": {"name": "no-source", "version": "1", "sha1": "a9993e364706816aba3e25717850c26c9cd0d89d"}
+				}`)
+
+				_, err := action.Run(request)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Compiling package fake-package-name: Dependency 'no-source' has neither a package_get_signed_url nor a blobstore_id"))
+				Expect(compiler.CompileCallCount).To(Equal(0))
+			})
+
+			It("returns an error without compiling when a dependency has no digest", func() {
+				request := getCompileWithSignedURLActionArguments()
+				request.Deps = unmarshalDeps(`{
+					"no-digest": {"name": "no-digest", "version": "1", "package_get_signed_url": "fake/get/url"}
+				}`)
+
+				_, err := action.Run(request)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Compiling package fake-package-name: Dependency 'no-digest' has no sha1 digest"))
+				Expect(compiler.CompileCallCount).To(Equal(0))
+			})
+
+			It("accepts dependencies with only a signed URL or only a blobstore ID", func() {
+				request := getCompileWithSignedURLActionArguments()
+				request.Deps = unmarshalDeps(`{
+					"signed": {"name": "signed", "version": "1", "sha1": "a9993e364706816aba3e25717850c26c9cd0d89d", "package_get_signed_url": "fake/get/url"},
+					"blobstore": {"name": "blobstore", "version": "1", "sha1": "a9993e364706816aba3e25717850c26c9cd0d89d", "blobstore_id": "fake-blobstore-id"}
+				}`)
+				compiler.CompileDigest = boshcrypto.NewDigest(boshcrypto.DigestAlgorithmSHA1, "some checksum")
+
+				_, err := action.Run(request)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(compiler.CompileCallCount).To(Equal(1))
+			})
+		})
+
+		Context("when doing a dry run", func() {
+			var request CompilePackageWithSignedURLRequest
+
+			BeforeEach(func() {
+				request = getCompileWithSignedURLActionArguments()
+				request.DryRun = true
+
+				blobDelegator.GetStub = func(_ boshcrypto.Digest, signedURL, _ string, _ map[string]string) (string, error) {
+					fileName := "/tmp/fetched-" + filepath.Base(signedURL)
+					Expect(fs.WriteFileString(fileName, "fake-contents")).To(Succeed())
+					return fileName, nil
+				}
+			})
+
+			It("fetches and verifies the package and its dependencies without compiling", func() {
+				value, err := action.Run(request)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(value).To(Equal(map[string]interface{}{
+					"result": map[string]interface{}{"dry_run": true},
+				}))
+
+				Expect(blobDelegator.GetCallCount()).To(Equal(3))
+
+				digest, signedURL, blobID, headers := blobDelegator.GetArgsForCall(0)
+				Expect(digest).To(Equal(request.Digest))
+				Expect(signedURL).To(Equal("fake/get/url"))
+				Expect(blobID).To(BeEmpty())
+				Expect(headers).To(Equal(map[string]string{"header": "value"}))
+
+				digest, signedURL, blobID, _ = blobDelegator.GetArgsForCall(1)
+				Expect(digest).To(Equal(request.Deps["first_dep"].Sha1))
+				Expect(signedURL).To(Equal("fake/get/first-dep-url"))
+				Expect(blobID).To(Equal("first_dep_blobstore_id"))
+
+				_, signedURL, _, _ = blobDelegator.GetArgsForCall(2)
+				Expect(signedURL).To(Equal("fake/get/sec-dep-url"))
+
+				Expect(compiler.CompileCallCount).To(Equal(0))
+				Expect(blobDelegator.WriteCallCount()).To(Equal(0))
+			})
+
+			It("removes the fetched blobs", func() {
+				_, err := action.Run(request)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(fs.FileExists("/tmp/fetched-url")).To(BeFalse())
+				Expect(fs.FileExists("/tmp/fetched-first-dep-url")).To(BeFalse())
+				Expect(fs.FileExists("/tmp/fetched-sec-dep-url")).To(BeFalse())
+			})
+
+			It("returns an error when a dependency does not match its digest", func() {
+				blobDelegator.GetStub = nil
+				blobDelegator.GetReturnsOnCall(0, "/tmp/fetched-url", nil)
+				blobDelegator.GetReturnsOnCall(1, "", errors.New("fake-digest-mismatch"))
+
+				_, err := action.Run(request)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Validating package fake-package-name: Fetching dependent package: 'first_dep': fake-digest-mismatch"))
+
+				Expect(blobDelegator.GetCallCount()).To(Equal(2))
+				Expect(compiler.CompileCallCount).To(Equal(0))
+			})
+
+			It("returns an error when the package cannot be fetched", func() {
+				blobDelegator.GetStub = nil
+				blobDelegator.GetReturns("", errors.New("fake-fetch-error"))
+
+				_, err := action.Run(request)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Validating package fake-package-name: Fetching package blob: fake-fetch-error"))
+
+				Expect(blobDelegator.GetCallCount()).To(Equal(1))
+			})
+		})
+
+		It("lets the compiler retry fetching the package", func() {
+			action = NewCompilePackageWithSignedURL(compiler, blobDelegator, fs, 2, 5*time.Second, 3)
+			compiler.CompileDigest = boshcrypto.NewDigest(boshcrypto.DigestAlgorithmSHA1, "some checksum")
+
+			_, err := action.Run(getCompileWithSignedURLActionArguments())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(compiler.CompileCallCount).To(Equal(1))
+			Expect(compiler.CompileOpts).To(Equal([]boshcomp.CompileOptions{{
+				MaxParallelDependencyDownloads: 3,
+				FetchRetries:                   2,
+				FetchRetryDelay:                5 * time.Second,
+			}}))
+		})
 	})
 })
@@ -1,12 +1,18 @@
 package action
 
 import (
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/clock"
+
 	boshappl "github.com/cloudfoundry/bosh-agent/agent/applier"
 	boshas "github.com/cloudfoundry/bosh-agent/agent/applier/applyspec"
 	boshagentblob "github.com/cloudfoundry/bosh-agent/agent/blobstore"
 	boshcomp "github.com/cloudfoundry/bosh-agent/agent/compiler"
 	blobdelegator "github.com/cloudfoundry/bosh-agent/agent/httpblobprovider/blobstore_delegator"
 	boshscript "github.com/cloudfoundry/bosh-agent/agent/script"
+	boshdrain "github.com/cloudfoundry/bosh-agent/agent/script/drain"
 	boshtask "github.com/cloudfoundry/bosh-agent/agent/task"
 	boshjobsuper "github.com/cloudfoundry/bosh-agent/jobsupervisor"
 	boshnotif "github.com/cloudfoundry/bosh-agent/notification"
@@ -27,6 +33,7 @@ func NewFactory(
 	// last argument.
 	sensitiveBlobManager boshagentblob.BlobManagerInterface,
 	taskService boshtask.Service,
+	taskManager boshtask.Manager,
 	notifier boshnotif.Notifier,
 	applier boshappl.Applier,
 	compiler boshcomp.Compiler,
@@ -36,10 +43,11 @@ func NewFactory(
 	logger boshlog.Logger,
 	blobstoreDelegator blobdelegator.BlobstoreDelegator) (factory Factory) {
 	compressor := platform.GetCompressor()
-	copier := platform.GetCopier()
+	logsCopier := NewLogsCopier(platform.GetCopier(), settingsService)
 	dirProvider := platform.GetDirProvider()
 	vitalsService := platform.GetVitalsService()
 	certManager := platform.GetCertManager()
+	drainLock := boshdrain.NewFileLock(platform.GetFs(), filepath.Join(dirProvider.BoshDir(), "drain.lock"), os.Getpid(), boshdrain.IsProcessRunning, clock.NewClock(), logger)
 
 	factory = concreteFactory{
 		availableActions: map[string]Action{
@@ -47,30 +55,43 @@ func NewFactory(
 			"ping": NewPing(),
 			"info": NewInfo(),
 
+			// Agent diagnostics
+			"get_runtime_profile":   NewGetRuntimeProfile(),
+			"get_clock_skew":        NewGetClockSkew(clock.NewClock()),
+			"get_agent_environment": NewGetAgentEnvironment(),
+			"check_mbus_port":       NewCheckMbusPort(settingsService, NewPortProber(platform.GetRunner()), logger),
+
 			// Task management
 			"get_task":    NewGetTask(taskService),
 			"cancel_task": NewCancelTask(taskService),
 
 			// VM admin
 			"ssh":                        NewSSH(settingsService, platform, dirProvider, logger),
-			"fetch_logs":                 NewFetchLogs(compressor, copier, blobstoreDelegator, dirProvider),
-			"fetch_logs_with_signed_url": NewFetchLogsWithSignedURLAction(compressor, copier, dirProvider, blobstoreDelegator),
+			"fetch_logs":                 NewFetchLogs(compressor, logsCopier, blobstoreDelegator, dirProvider, settingsService, platform.GetRunner(), platform.GetFs()),
+			"fetch_logs_with_signed_url": NewFetchLogsWithSignedURLAction(compressor, logsCopier, dirProvider, blobstoreDelegator, settingsService, platform.GetRunner(), platform.GetFs()),
+			"rotate_logs":                NewRotateLogs(platform.GetFs(), dirProvider, clock.NewClock()),
 			"update_settings":            NewUpdateSettings(settingsService, platform, certManager, logger),
 			"shutdown":                   NewShutdown(platform),
+			"restart_agent":              NewRestartAgent(taskManager, NewAgentKiller(), platform.GetFs(), dirProvider, clock.NewClock(), logger),
+			"deploy_blob_to_path":        NewDeployBlobToPath(blobstoreDelegator, platform.GetFs(), logger),
 
 			// Job management
-			"prepare":    NewPrepare(applier),
-			"apply":      NewApply(applier, specService, settingsService, dirProvider, platform.GetFs()),
-			"start":      NewStart(jobSupervisor, applier, specService),
-			"stop":       NewStop(jobSupervisor),
-			"drain":      NewDrain(notifier, specService, jobScriptProvider, jobSupervisor, logger),
-			"get_state":  NewGetState(settingsService, specService, jobSupervisor, vitalsService),
-			"run_errand": NewRunErrand(specService, dirProvider.JobsDir(), platform.GetRunner(), logger),
-			"run_script": NewRunScript(jobScriptProvider, specService, logger),
+			"prepare":                   NewPrepare(applier),
+			"apply":                     NewApply(applier, specService, settingsService, dirProvider, platform.GetFs()),
+			"start":                     NewStart(jobSupervisor, applier, specService),
+			"stop":                      NewStop(jobSupervisor),
+			"drain":                     NewDrain(notifier, specService, jobScriptProvider, jobSupervisor, settingsService, drainLock, logger),
+			"get_state":                 NewGetState(settingsService, specService, jobSupervisor, vitalsService),
+			"run_errand":                NewRunErrand(specService, dirProvider.JobsDir(), platform.GetRunner(), logger),
+			"run_script":                NewRunScript(jobScriptProvider, specService, dirProvider, logger),
+			"verify_job_packages":       NewVerifyJobPackages(applier, specService),
+			"check_job_log_writability": NewCheckJobLogWritability(specService, platform, dirProvider),
 
 			// Compilation
 			"compile_package":                 NewCompilePackage(compiler),
-			"compile_package_with_signed_url": NewCompilePackageWithSignedURL(compiler),
+			"compile_package_with_signed_url": NewCompilePackageWithSignedURL(compiler, blobstoreDelegator, platform.GetFs(), DefaultCompilePackageFetchRetries, DefaultCompilePackageFetchRetryDelay, DefaultCompilePackageParallelDependencyDownloads),
+			"get_blob_info":                   NewGetBlobInfo(sensitiveBlobManager, blobstoreDelegator),
+			"delete_blob":                     NewDeleteBlob(blobstoreDelegator),
 
 			// Rendered Templates
 			"upload_blob": NewUploadBlobAction(sensitiveBlobManager),
@@ -80,9 +101,27 @@ func NewFactory(
 			"migrate_disk":           NewMigrateDisk(platform, dirProvider),
 			"mount_disk":             NewMountDisk(settingsService, platform, dirProvider, logger),
 			"unmount_disk":           NewUnmountDisk(settingsService, platform),
+			"check_read_only_mounts": NewCheckReadOnlyMounts(platform.GetFs(), dirProvider),
+			"drop_caches":            NewDropCaches(platform.GetFs(), settingsService),
+			"verify_ephemeral_disk":  NewVerifyEphemeralDisk(settingsService, platform, platform.GetFs()),
+			"get_scheduler_settings": NewGetSchedulerSettings(platform.GetFs()),
+			"get_disk_io_stats":      NewGetDiskIOStats(platform.GetFs(), clock.NewClock()),
+			"get_disk_usage":         NewGetDiskUsage(platform.GetStatsCollector(), dirProvider),
+			"trim_disks":             NewTrimDisks(platform, dirProvider),
 			"add_persistent_disk":    NewAddPersistentDiskAction(settingsService),
 			"remove_persistent_disk": NewRemovePersistentDiskAction(settingsService),
 
+			// Instance diagnostics
+			"get_memory_breakdown": NewGetMemoryBreakdown(platform.GetFs()),
+			"get_job_connections":  NewGetJobConnections(platform.GetFs(), dirProvider),
+			"get_kernel_cmdline":   NewGetKernelCmdline(platform.GetFs()),
+			"get_locale_timezone":  NewGetLocaleTimezone(platform.GetFs(), platform.GetRunner()),
+			"get_firewall_rules":   NewGetFirewallRules(platform.GetRunner()),
+
+			// Access control
+			"get_access_control_config": NewGetAccessControlConfig(platform.GetFs()),
+			"get_security_modules":      NewGetSecurityModules(platform.GetFs()),
+
 			// ARP cache management
 			"delete_arp_entries": NewDeleteARPEntries(platform),
 
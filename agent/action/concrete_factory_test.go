@@ -1,6 +1,7 @@
 package action_test
 
 import (
+	"code.cloudfoundry.org/clock"
 	. "github.com/cloudfoundry/bosh-agent/agent/action"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -20,8 +21,8 @@ import (
 	faketask "github.com/cloudfoundry/bosh-agent/agent/task/fakes"
 	fakejobsuper "github.com/cloudfoundry/bosh-agent/jobsupervisor/fakes"
 	fakenotif "github.com/cloudfoundry/bosh-agent/notification/fakes"
+	fakefs "github.com/cloudfoundry/bosh-agent/platform/fakefs"
 	fakesettings "github.com/cloudfoundry/bosh-agent/settings/fakes"
-	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
 )
 
 //go:generate counterfeiter -o fakes/fake_clock.go ../../vendor/code.cloudfoundry.org/clock Clock
@@ -32,6 +33,7 @@ var _ = Describe("concreteFactory", func() {
 		platform          *platformfakes.FakePlatform
 		blobManager       *fakeagentblobstore.FakeBlobManagerInterface
 		taskService       *faketask.FakeService
+		taskManager       *faketask.FakeManager
 		notifier          *fakenotif.FakeNotifier
 		applier           *fakeappl.FakeApplier
 		compiler          *fakecomp.FakeCompiler
@@ -40,7 +42,7 @@ var _ = Describe("concreteFactory", func() {
 		jobScriptProvider boshscript.JobScriptProvider
 		factory           Factory
 		logger            boshlog.Logger
-		fileSystem        *fakesys.FakeFileSystem
+		fileSystem        *fakefs.FakeFileSystem
 		blobDelegator     *fakeblobdelegator.FakeBlobstoreDelegator
 	)
 
@@ -48,12 +50,13 @@ var _ = Describe("concreteFactory", func() {
 		settingsService = &fakesettings.FakeSettingsService{}
 
 		platform = &platformfakes.FakePlatform{}
-		fileSystem = fakesys.NewFakeFileSystem()
+		fileSystem = fakefs.NewFakeFileSystem()
 		platform.GetFsReturns(fileSystem)
 		platform.GetDirProviderReturns(boshdir.NewProvider("/var/vcap"))
 
 		blobManager = &fakeagentblobstore.FakeBlobManagerInterface{}
 		taskService = &faketask.FakeService{}
+		taskManager = faketask.NewFakeManager()
 		notifier = fakenotif.NewFakeNotifier()
 		applier = fakeappl.NewFakeApplier()
 		compiler = fakecomp.NewFakeCompiler()
@@ -68,6 +71,7 @@ var _ = Describe("concreteFactory", func() {
 			platform,
 			blobManager,
 			taskService,
+			taskManager,
 			notifier,
 			applier,
 			compiler,
@@ -107,14 +111,26 @@ var _ = Describe("concreteFactory", func() {
 	It("fetch_logs", func() {
 		action, err := factory.Create("fetch_logs")
 		Expect(err).ToNot(HaveOccurred())
-		Expect(action).To(Equal(NewFetchLogs(platform.GetCompressor(), platform.GetCopier(), blobDelegator, platform.GetDirProvider())))
+		Expect(action).To(Equal(NewFetchLogs(platform.GetCompressor(), NewLogsCopier(platform.GetCopier(), settingsService), blobDelegator, platform.GetDirProvider(), settingsService, platform.GetRunner(), fileSystem)))
 	})
 
 	It("fetch_logs_with_signed_url", func() {
 		ac, err := factory.Create("fetch_logs_with_signed_url")
 		Expect(err).ToNot(HaveOccurred())
 
-		Expect(ac).To(Equal(NewFetchLogsWithSignedURLAction(platform.GetCompressor(), platform.GetCopier(), platform.GetDirProvider(), blobDelegator)))
+		Expect(ac).To(Equal(NewFetchLogsWithSignedURLAction(platform.GetCompressor(), NewLogsCopier(platform.GetCopier(), settingsService), platform.GetDirProvider(), blobDelegator, settingsService, platform.GetRunner(), fileSystem)))
+	})
+
+	It("rotate_logs", func() {
+		action, err := factory.Create("rotate_logs")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(action).To(Equal(NewRotateLogs(fileSystem, platform.GetDirProvider(), clock.NewClock())))
+	})
+
+	It("deploy_blob_to_path", func() {
+		action, err := factory.Create("deploy_blob_to_path")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(action).To(Equal(NewDeployBlobToPath(blobDelegator, fileSystem, logger)))
 	})
 
 	It("get_task", func() {
@@ -165,6 +181,12 @@ var _ = Describe("concreteFactory", func() {
 		Expect(action).To(Equal(NewInfo()))
 	})
 
+	It("get_runtime_profile", func() {
+		action, err := factory.Create("get_runtime_profile")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(action).To(Equal(NewGetRuntimeProfile()))
+	})
+
 	It("ssh", func() {
 		action, err := factory.Create("ssh")
 		Expect(err).ToNot(HaveOccurred())
@@ -195,6 +217,114 @@ var _ = Describe("concreteFactory", func() {
 		Expect(action).To(Equal(NewUnmountDisk(settingsService, platform)))
 	})
 
+	It("check_read_only_mounts", func() {
+		action, err := factory.Create("check_read_only_mounts")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(action).To(Equal(NewCheckReadOnlyMounts(fileSystem, platform.GetDirProvider())))
+	})
+
+	It("drop_caches", func() {
+		action, err := factory.Create("drop_caches")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(action).To(Equal(NewDropCaches(fileSystem, settingsService)))
+	})
+
+	It("verify_ephemeral_disk", func() {
+		action, err := factory.Create("verify_ephemeral_disk")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(action).To(Equal(NewVerifyEphemeralDisk(settingsService, platform, fileSystem)))
+	})
+
+	It("get_clock_skew", func() {
+		action, err := factory.Create("get_clock_skew")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(action).To(Equal(NewGetClockSkew(clock.NewClock())))
+	})
+
+	It("get_memory_breakdown", func() {
+		action, err := factory.Create("get_memory_breakdown")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(action).To(Equal(NewGetMemoryBreakdown(fileSystem)))
+	})
+
+	It("get_agent_environment", func() {
+		action, err := factory.Create("get_agent_environment")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(action).To(Equal(NewGetAgentEnvironment()))
+	})
+
+	It("check_mbus_port", func() {
+		action, err := factory.Create("check_mbus_port")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(action).To(Equal(NewCheckMbusPort(settingsService, NewPortProber(platform.GetRunner()), logger)))
+	})
+
+	It("get_scheduler_settings", func() {
+		action, err := factory.Create("get_scheduler_settings")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(action).To(Equal(NewGetSchedulerSettings(fileSystem)))
+	})
+
+	It("get_kernel_cmdline", func() {
+		action, err := factory.Create("get_kernel_cmdline")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(action).To(Equal(NewGetKernelCmdline(fileSystem)))
+	})
+
+	It("get_locale_timezone", func() {
+		action, err := factory.Create("get_locale_timezone")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(action).To(Equal(NewGetLocaleTimezone(fileSystem, platform.GetRunner())))
+	})
+
+	It("get_firewall_rules", func() {
+		action, err := factory.Create("get_firewall_rules")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(action).To(Equal(NewGetFirewallRules(platform.GetRunner())))
+	})
+
+	It("get_access_control_config", func() {
+		action, err := factory.Create("get_access_control_config")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(action).To(Equal(NewGetAccessControlConfig(fileSystem)))
+	})
+
+	It("get_security_modules", func() {
+		action, err := factory.Create("get_security_modules")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(action).To(Equal(NewGetSecurityModules(fileSystem)))
+	})
+
+	It("get_disk_io_stats", func() {
+		action, err := factory.Create("get_disk_io_stats")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(action).To(Equal(NewGetDiskIOStats(fileSystem, clock.NewClock())))
+	})
+
+	It("trim_disks", func() {
+		action, err := factory.Create("trim_disks")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(action).To(Equal(NewTrimDisks(platform, platform.GetDirProvider())))
+	})
+
+	It("get_job_connections", func() {
+		action, err := factory.Create("get_job_connections")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(action).To(Equal(NewGetJobConnections(fileSystem, platform.GetDirProvider())))
+	})
+
+	It("get_disk_usage", func() {
+		action, err := factory.Create("get_disk_usage")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(action).To(Equal(NewGetDiskUsage(platform.GetStatsCollector(), platform.GetDirProvider())))
+	})
+
+	It("restart_agent", func() {
+		action, err := factory.Create("restart_agent")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(action).To(Equal(NewRestartAgent(taskManager, NewAgentKiller(), fileSystem, platform.GetDirProvider(), clock.NewClock(), logger)))
+	})
+
 	It("compile_package", func() {
 		action, err := factory.Create("compile_package")
 		Expect(err).ToNot(HaveOccurred())
@@ -204,7 +334,7 @@ var _ = Describe("concreteFactory", func() {
 	It("compile_package_with_signed_url", func() {
 		action, err := factory.Create("compile_package_with_signed_url")
 		Expect(err).ToNot(HaveOccurred())
-		Expect(action).To(Equal(NewCompilePackageWithSignedURL(compiler)))
+		Expect(action).To(Equal(NewCompilePackageWithSignedURL(compiler, blobDelegator, fileSystem, DefaultCompilePackageFetchRetries, DefaultCompilePackageFetchRetryDelay, DefaultCompilePackageParallelDependencyDownloads)))
 	})
 
 	It("run_errand", func() {
@@ -218,7 +348,19 @@ var _ = Describe("concreteFactory", func() {
 	It("run_script", func() {
 		action, err := factory.Create("run_script")
 		Expect(err).ToNot(HaveOccurred())
-		Expect(action).To(Equal(NewRunScript(jobScriptProvider, specService, logger)))
+		Expect(action).To(Equal(NewRunScript(jobScriptProvider, specService, platform.GetDirProvider(), logger)))
+	})
+
+	It("verify_job_packages", func() {
+		action, err := factory.Create("verify_job_packages")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(action).To(Equal(NewVerifyJobPackages(applier, specService)))
+	})
+
+	It("check_job_log_writability", func() {
+		action, err := factory.Create("check_job_log_writability")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(action).To(Equal(NewCheckJobLogWritability(specService, platform, platform.GetDirProvider())))
 	})
 
 	It("prepare", func() {
@@ -245,6 +387,18 @@ var _ = Describe("concreteFactory", func() {
 		Expect(action).To(Equal(NewSyncDNS(blobDelegator, settingsService, platform, logger)))
 	})
 
+	It("get_blob_info", func() {
+		action, err := factory.Create("get_blob_info")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(action).To(Equal(NewGetBlobInfo(blobManager, blobDelegator)))
+	})
+
+	It("delete_blob", func() {
+		action, err := factory.Create("delete_blob")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(action).To(Equal(NewDeleteBlob(blobDelegator)))
+	})
+
 	It("upload_blob", func() {
 		action, err := factory.Create("upload_blob")
 		Expect(err).ToNot(HaveOccurred())
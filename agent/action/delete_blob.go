@@ -0,0 +1,61 @@
+package action
+
+import (
+	"errors"
+
+	blobdelegator "github.com/cloudfoundry/bosh-agent/agent/httpblobprovider/blobstore_delegator"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+)
+
+type DeleteBlobRequest struct {
+	BlobID string `json:"blob_id"`
+
+	// Takes precedence over the blob ID when given
+	SignedURL string `json:"signed_url"`
+}
+
+type DeleteBlobAction struct {
+	blobDelegator blobdelegator.BlobstoreDelegator
+}
+
+func NewDeleteBlob(blobDelegator blobdelegator.BlobstoreDelegator) DeleteBlobAction {
+	return DeleteBlobAction{blobDelegator: blobDelegator}
+}
+
+func (a DeleteBlobAction) IsAsynchronous(_ ProtocolVersion) bool {
+	return false
+}
+
+func (a DeleteBlobAction) IsPersistent() bool {
+	return false
+}
+
+func (a DeleteBlobAction) IsLoggable() bool {
+	return true
+}
+
+func (a DeleteBlobAction) Run(request DeleteBlobRequest) (string, error) {
+	if request.SignedURL == "" && request.BlobID == "" {
+		return "", bosherr.Error("Either a blob ID or a signed URL must be given")
+	}
+
+	err := a.blobDelegator.Delete(request.SignedURL, request.BlobID)
+	if err != nil {
+		if request.SignedURL != "" {
+			// Signed URLs are left out of errors since they carry credentials
+			return "", bosherr.WrapError(err, "Deleting blob at the signed URL")
+		}
+
+		return "", bosherr.WrapErrorf(err, "Deleting blob '%s'", request.BlobID)
+	}
+
+	return "deleted", nil
+}
+
+func (a DeleteBlobAction) Resume() (interface{}, error) {
+	return nil, errors.New("not supported")
+}
+
+func (a DeleteBlobAction) Cancel() error {
+	return errors.New("not supported")
+}
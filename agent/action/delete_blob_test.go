@@ -0,0 +1,77 @@
+package action_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-agent/agent/action"
+	"github.com/cloudfoundry/bosh-agent/agent/httpblobprovider"
+	fakeblobdelegator "github.com/cloudfoundry/bosh-agent/agent/httpblobprovider/blobstore_delegator/blobstore_delegatorfakes"
+)
+
+var _ = Describe("DeleteBlobAction", func() {
+	var (
+		blobDelegator *fakeblobdelegator.FakeBlobstoreDelegator
+		action        DeleteBlobAction
+	)
+
+	BeforeEach(func() {
+		blobDelegator = &fakeblobdelegator.FakeBlobstoreDelegator{}
+		action = NewDeleteBlob(blobDelegator)
+	})
+
+	AssertActionIsNotAsynchronous(action)
+	AssertActionIsNotPersistent(action)
+	AssertActionIsLoggable(action)
+
+	AssertActionIsNotResumable(action)
+	AssertActionIsNotCancelable(action)
+
+	Describe("Run", func() {
+		It("deletes the blob from the blobstore", func() {
+			value, err := action.Run(DeleteBlobRequest{BlobID: "fake-blob-id"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(value).To(Equal("deleted"))
+
+			Expect(blobDelegator.DeleteCallCount()).To(Equal(1))
+			signedURL, blobID := blobDelegator.DeleteArgsForCall(0)
+			Expect(signedURL).To(BeEmpty())
+			Expect(blobID).To(Equal("fake-blob-id"))
+		})
+
+		It("deletes the blob at the signed URL", func() {
+			value, err := action.Run(DeleteBlobRequest{SignedURL: "fake-signed-url"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(value).To(Equal("deleted"))
+
+			signedURL, _ := blobDelegator.DeleteArgsForCall(0)
+			Expect(signedURL).To(Equal("fake-signed-url"))
+		})
+
+		It("returns an error when the blob is missing from the blobstore", func() {
+			blobDelegator.DeleteReturns(errors.New("fake-not-found-error"))
+
+			_, err := action.Run(DeleteBlobRequest{BlobID: "fake-blob-id"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Deleting blob 'fake-blob-id': fake-not-found-error"))
+		})
+
+		It("returns an error without the signed URL when the blob is missing", func() {
+			blobDelegator.DeleteReturns(httpblobprovider.HTTPStatusError{Method: "DELETE", StatusCode: 404})
+
+			_, err := action.Run(DeleteBlobRequest{SignedURL: "fake-signed-url"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Deleting blob at the signed URL: Error executing DELETE, response was 404"))
+		})
+
+		It("returns an error when neither a blob ID nor a signed URL is given", func() {
+			_, err := action.Run(DeleteBlobRequest{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Either a blob ID or a signed URL must be given"))
+
+			Expect(blobDelegator.DeleteCallCount()).To(Equal(0))
+		})
+	})
+})
@@ -0,0 +1,167 @@
+package action
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	blobdelegator "github.com/cloudfoundry/bosh-agent/agent/httpblobprovider/blobstore_delegator"
+	boshcrypto "github.com/cloudfoundry/bosh-utils/crypto"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+const defaultDeployBlobFileMode = os.FileMode(0644)
+
+type DeployBlobToPathRequest struct {
+	BlobID           string                    `json:"blob_id"`
+	SignedURL        string                    `json:"signed_url"`
+	MultiDigest      boshcrypto.MultipleDigest `json:"multi_digest"`
+	BlobstoreHeaders map[string]string         `json:"blobstore_headers"`
+
+	Path  string `json:"path"`
+	Mode  string `json:"mode"`
+	Owner string `json:"owner"`
+}
+
+type DeployBlobToPathAction struct {
+	blobDelegator blobdelegator.BlobstoreDelegator
+	fs            boshsys.FileSystem
+	logger        boshlog.Logger
+	logTag        string
+}
+
+func NewDeployBlobToPath(
+	blobDelegator blobdelegator.BlobstoreDelegator,
+	fs boshsys.FileSystem,
+	logger boshlog.Logger,
+) DeployBlobToPathAction {
+	return DeployBlobToPathAction{
+		blobDelegator: blobDelegator,
+		fs:            fs,
+		logger:        logger,
+		logTag:        "DeployBlobToPathAction",
+	}
+}
+
+func (a DeployBlobToPathAction) IsAsynchronous(_ ProtocolVersion) bool {
+	return true
+}
+
+func (a DeployBlobToPathAction) IsPersistent() bool {
+	return false
+}
+
+func (a DeployBlobToPathAction) IsLoggable() bool {
+	return true
+}
+
+func (a DeployBlobToPathAction) Run(request DeployBlobToPathRequest) (string, error) {
+	err := a.validatePath(request.Path)
+	if err != nil {
+		return "", err
+	}
+
+	mode, err := a.fileMode(request.Mode)
+	if err != nil {
+		return "", err
+	}
+
+	blobPath, err := a.blobDelegator.Get(request.MultiDigest, request.SignedURL, request.BlobID, request.BlobstoreHeaders)
+	if err != nil {
+		return "", bosherr.WrapError(err, "Fetching blob")
+	}
+
+	defer func() {
+		if removeErr := a.fs.RemoveAll(blobPath); removeErr != nil {
+			a.logger.Error(a.logTag, fmt.Sprintf("Failed to remove blob file at path '%s'", blobPath))
+		}
+	}()
+
+	err = request.MultiDigest.VerifyFilePath(blobPath, a.fs)
+	if err != nil {
+		return "", bosherr.WrapError(err, "Verifying blob digest")
+	}
+
+	err = a.fs.MkdirAll(filepath.Dir(request.Path), os.FileMode(0755))
+	if err != nil {
+		return "", bosherr.WrapError(err, "Creating target directory")
+	}
+
+	// Stage next to the target so the final rename stays on the same filesystem
+	stagingPath := request.Path + ".bosh-deploy-blob"
+
+	err = a.stage(blobPath, stagingPath, mode, request.Owner)
+	if err != nil {
+		_ = a.fs.RemoveAll(stagingPath)
+		return "", err
+	}
+
+	err = a.fs.Rename(stagingPath, request.Path)
+	if err != nil {
+		_ = a.fs.RemoveAll(stagingPath)
+		return "", bosherr.WrapErrorf(err, "Moving blob to '%s'", request.Path)
+	}
+
+	return "deployed", nil
+}
+
+func (a DeployBlobToPathAction) stage(blobPath, stagingPath string, mode os.FileMode, owner string) error {
+	err := a.fs.CopyFile(blobPath, stagingPath)
+	if err != nil {
+		return bosherr.WrapError(err, "Copying blob to staging path")
+	}
+
+	err = a.fs.Chmod(stagingPath, mode)
+	if err != nil {
+		return bosherr.WrapError(err, "Setting blob file mode")
+	}
+
+	if owner != "" {
+		err = a.fs.Chown(stagingPath, owner)
+		if err != nil {
+			return bosherr.WrapError(err, "Setting blob file owner")
+		}
+	}
+
+	return nil
+}
+
+func (a DeployBlobToPathAction) validatePath(path string) error {
+	if !filepath.IsAbs(path) {
+		return bosherr.Errorf("Target path '%s' must be absolute", path)
+	}
+
+	for _, part := range strings.Split(filepath.ToSlash(path), "/") {
+		if part == ".." {
+			return bosherr.Errorf("Target path '%s' must not contain '..'", path)
+		}
+	}
+
+	return nil
+}
+
+func (a DeployBlobToPathAction) fileMode(mode string) (os.FileMode, error) {
+	if mode == "" {
+		return defaultDeployBlobFileMode, nil
+	}
+
+	parsedMode, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil {
+		return 0, bosherr.WrapErrorf(err, "Parsing file mode '%s'", mode)
+	}
+
+	return os.FileMode(parsedMode), nil
+}
+
+func (a DeployBlobToPathAction) Resume() (interface{}, error) {
+	return nil, errors.New("not supported")
+}
+
+func (a DeployBlobToPathAction) Cancel() error {
+	return errors.New("not supported")
+}
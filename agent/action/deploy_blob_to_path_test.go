@@ -0,0 +1,123 @@
+package action_test
+
+import (
+	"errors"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-agent/agent/action"
+
+	fakeblobdelegator "github.com/cloudfoundry/bosh-agent/agent/httpblobprovider/blobstore_delegator/blobstore_delegatorfakes"
+	fakefs "github.com/cloudfoundry/bosh-agent/platform/fakefs"
+	boshcrypto "github.com/cloudfoundry/bosh-utils/crypto"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+)
+
+var _ = Describe("DeployBlobToPathAction", func() {
+	var (
+		blobDelegator *fakeblobdelegator.FakeBlobstoreDelegator
+		fs            *fakefs.FakeFileSystem
+		action        DeployBlobToPathAction
+		request       DeployBlobToPathRequest
+	)
+
+	BeforeEach(func() {
+		blobDelegator = &fakeblobdelegator.FakeBlobstoreDelegator{}
+		fs = fakefs.NewFakeFileSystem()
+		logger := boshlog.NewLogger(boshlog.LevelNone)
+		action = NewDeployBlobToPath(blobDelegator, fs, logger)
+
+		err := fs.WriteFileString("/fake-tmp/blob", "fake-contents")
+		Expect(err).ToNot(HaveOccurred())
+		blobDelegator.GetReturns("/fake-tmp/blob", nil)
+
+		request = DeployBlobToPathRequest{
+			BlobID: "fake-blob-id",
+			MultiDigest: boshcrypto.MustNewMultipleDigest(
+				boshcrypto.NewDigest(boshcrypto.DigestAlgorithmSHA1, "978ad524a02039f261773fe93d94973ae7de6470"),
+			),
+			Path:  "/var/vcap/jobs/fake-job/config/fake.conf",
+			Mode:  "0600",
+			Owner: "vcap",
+		}
+	})
+
+	AssertActionIsAsynchronous(action)
+	AssertActionIsNotPersistent(action)
+	AssertActionIsLoggable(action)
+
+	AssertActionIsNotResumable(action)
+	AssertActionIsNotCancelable(action)
+
+	Describe("Run", func() {
+		It("writes the blob to the target path with the requested mode and owner", func() {
+			result, err := action.Run(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal("deployed"))
+
+			digest, _, blobID, _ := blobDelegator.GetArgsForCall(0)
+			Expect(digest).To(Equal(request.MultiDigest))
+			Expect(blobID).To(Equal("fake-blob-id"))
+
+			contents, err := fs.ReadFileString("/var/vcap/jobs/fake-job/config/fake.conf")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(contents).To(Equal("fake-contents"))
+
+			stat := fs.GetFileTestStat("/var/vcap/jobs/fake-job/config/fake.conf")
+			Expect(stat.FileMode).To(Equal(os.FileMode(0600)))
+			Expect(stat.Username).To(Equal("vcap"))
+
+			Expect(fs.FileExists("/var/vcap/jobs/fake-job/config/fake.conf.bosh-deploy-blob")).To(BeFalse())
+			Expect(fs.FileExists("/fake-tmp/blob")).To(BeFalse())
+		})
+
+		It("returns an error and leaves the target untouched when the digest does not match", func() {
+			request.MultiDigest = boshcrypto.MustNewMultipleDigest(
+				boshcrypto.NewDigest(boshcrypto.DigestAlgorithmSHA1, "wrong-digest"),
+			)
+
+			_, err := action.Run(request)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Verifying blob digest"))
+
+			Expect(fs.FileExists("/var/vcap/jobs/fake-job/config/fake.conf")).To(BeFalse())
+			Expect(fs.FileExists("/fake-tmp/blob")).To(BeFalse())
+		})
+
+		It("rejects target paths that traverse directories", func() {
+			request.Path = "/var/vcap/jobs/../../etc/passwd"
+
+			_, err := action.Run(request)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("must not contain '..'"))
+
+			Expect(blobDelegator.GetCallCount()).To(Equal(0))
+		})
+
+		It("rejects relative target paths", func() {
+			request.Path = "etc/passwd"
+
+			_, err := action.Run(request)
+			Expect(err).To(HaveOccurred())
+			Expect(blobDelegator.GetCallCount()).To(Equal(0))
+		})
+
+		It("returns an error when fetching the blob fails", func() {
+			blobDelegator.GetReturns("", errors.New("fake-get-error"))
+
+			_, err := action.Run(request)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-get-error"))
+		})
+
+		It("returns an error when the mode is invalid", func() {
+			request.Mode = "rwx"
+
+			_, err := action.Run(request)
+			Expect(err).To(HaveOccurred())
+			Expect(blobDelegator.GetCallCount()).To(Equal(0))
+		})
+	})
+})
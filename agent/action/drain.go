@@ -2,12 +2,14 @@ package action
 
 import (
 	"errors"
+	"time"
 
 	boshas "github.com/cloudfoundry/bosh-agent/agent/applier/applyspec"
 	boshscript "github.com/cloudfoundry/bosh-agent/agent/script"
 	boshdrain "github.com/cloudfoundry/bosh-agent/agent/script/drain"
 	boshjobsuper "github.com/cloudfoundry/bosh-agent/jobsupervisor"
 	boshnotif "github.com/cloudfoundry/bosh-agent/notification"
+	boshsettings "github.com/cloudfoundry/bosh-agent/settings"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 )
@@ -17,6 +19,8 @@ type DrainAction struct {
 	notifier          boshnotif.Notifier
 	specService       boshas.V1Service
 	jobSupervisor     boshjobsuper.JobSupervisor
+	settingsService   boshsettings.Service
+	lock              boshdrain.FileLock
 
 	logTag   string
 	logger   boshlog.Logger
@@ -36,6 +40,8 @@ func NewDrain(
 	specService boshas.V1Service,
 	jobScriptProvider boshscript.JobScriptProvider,
 	jobSupervisor boshjobsuper.JobSupervisor,
+	settingsService boshsettings.Service,
+	lock boshdrain.FileLock,
 	logger boshlog.Logger,
 ) DrainAction {
 	return DrainAction{
@@ -43,6 +49,8 @@ func NewDrain(
 		specService:       specService,
 		jobScriptProvider: jobScriptProvider,
 		jobSupervisor:     jobSupervisor,
+		settingsService:   settingsService,
+		lock:              lock,
 
 		logTag:   "Drain Action",
 		logger:   logger,
@@ -68,11 +76,31 @@ func (a DrainAction) Run(drainType DrainType, newSpecs ...boshas.V1ApplySpec) (i
 		return 0, bosherr.WrapError(err, "Getting current spec")
 	}
 
-	params, err := a.determineParams(drainType, currentSpec, newSpecs)
+	env := a.settingsService.GetSettings().Env
+
+	pollIntervalRange := boshdrain.PollIntervalRange{
+		Min: time.Duration(env.Bosh.Drain.MinPollInterval) * time.Second,
+		Max: time.Duration(env.Bosh.Drain.MaxPollInterval) * time.Second,
+	}
+
+	params, err := a.determineParams(drainType, currentSpec, newSpecs, pollIntervalRange)
 	if err != nil {
 		return 0, err
 	}
 
+	var scripts []boshscript.Script
+
+	for _, job := range currentSpec.Jobs() {
+		script := a.jobScriptProvider.NewDrainScript(job.BundleName(), params)
+
+		// Missing drain scripts are otherwise skipped and count as an immediate success
+		if !script.Exists() && env.IsDrainScriptRequired(job.Name) {
+			return 0, bosherr.Errorf("Drain script for job '%s' is missing", job.Name)
+		}
+
+		scripts = append(scripts, script)
+	}
+
 	a.logger.Debug(a.logTag, "Unmonitoring")
 
 	err = a.jobSupervisor.Unmonitor()
@@ -81,14 +109,27 @@ func (a DrainAction) Run(drainType DrainType, newSpecs ...boshas.V1ApplySpec) (i
 	}
 	//TODO write health.json
 
-	var scripts []boshscript.Script
+	maxConcurrency := 0
 
-	for _, job := range currentSpec.Jobs() {
-		script := a.jobScriptProvider.NewDrainScript(job.BundleName(), params)
-		scripts = append(scripts, script)
+	if env.Bosh.Drain.Serialize {
+		// Drain scripts of co-located jobs must not overlap either
+		maxConcurrency = 1
+
+		a.logger.Debug(a.logTag, "Acquiring drain lock")
+
+		err = a.lock.Lock(env.GetDrainLockTimeout(), a.cancelCh)
+		if err != nil {
+			return 0, bosherr.WrapError(err, "Acquiring drain lock")
+		}
+
+		defer func() {
+			if unlockErr := a.lock.Unlock(); unlockErr != nil {
+				a.logger.Error(a.logTag, "Failed to release drain lock: %s", unlockErr.Error())
+			}
+		}()
 	}
 
-	script := a.jobScriptProvider.NewParallelScript("drain", scripts)
+	script := a.jobScriptProvider.NewParallelScript("drain", scripts, maxConcurrency, nil)
 
 	resultsCh := make(chan error, 1)
 	go func() { resultsCh <- script.Run() }()
@@ -102,7 +143,12 @@ func (a DrainAction) Run(drainType DrainType, newSpecs ...boshas.V1ApplySpec) (i
 	}
 }
 
-func (a DrainAction) determineParams(drainType DrainType, currentSpec boshas.V1ApplySpec, newSpecs []boshas.V1ApplySpec) (boshdrain.ScriptParams, error) {
+func (a DrainAction) determineParams(
+	drainType DrainType,
+	currentSpec boshas.V1ApplySpec,
+	newSpecs []boshas.V1ApplySpec,
+	pollIntervalRange boshdrain.PollIntervalRange,
+) (boshdrain.ScriptParams, error) {
 	var newSpec *boshas.V1ApplySpec
 	var params boshdrain.ScriptParams
 
@@ -121,7 +167,7 @@ func (a DrainAction) determineParams(drainType DrainType, currentSpec boshas.V1A
 			return params, bosherr.Error("Drain update requires new spec")
 		}
 
-		params = boshdrain.NewUpdateParams(currentSpec, *newSpec)
+		params = boshdrain.NewUpdateParams(currentSpec, *newSpec, pollIntervalRange)
 
 	case DrainTypeShutdown:
 		err := a.notifier.NotifyShutdown()
@@ -129,7 +175,7 @@ func (a DrainAction) determineParams(drainType DrainType, currentSpec boshas.V1A
 			return params, bosherr.WrapError(err, "Notifying shutdown")
 		}
 
-		params = boshdrain.NewShutdownParams(currentSpec, newSpec)
+		params = boshdrain.NewShutdownParams(currentSpec, newSpec, pollIntervalRange)
 	}
 
 	return params, nil
@@ -2,6 +2,9 @@ package action_test
 
 import (
 	"errors"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -15,10 +18,15 @@ import (
 	"github.com/cloudfoundry/bosh-agent/agent/script/scriptfakes"
 	fakejobsuper "github.com/cloudfoundry/bosh-agent/jobsupervisor/fakes"
 	fakenotif "github.com/cloudfoundry/bosh-agent/notification/fakes"
+	fakefs "github.com/cloudfoundry/bosh-agent/platform/fakefs"
+	fakesettings "github.com/cloudfoundry/bosh-agent/settings/fakes"
 	"github.com/cloudfoundry/bosh-utils/crypto"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 )
 
+// noProcessRunning makes drain locks held by other drains reclaimable once stale
+func noProcessRunning(int) bool { return false }
+
 var _ = Describe("DrainAction", func() {
 	var (
 		notifier          *fakenotif.FakeNotifier
@@ -26,6 +34,9 @@ var _ = Describe("DrainAction", func() {
 		jobScriptProvider *scriptfakes.FakeJobScriptProvider
 		fakeScripts       map[string]*scriptfakes.FakeCancellableScript
 		jobSupervisor     *fakejobsuper.FakeJobSupervisor
+		settingsService   *fakesettings.FakeSettingsService
+		fs                *fakefs.FakeFileSystem
+		fakeClock         *fakeclock.FakeClock
 		action            DrainAction
 		logger            boshlog.Logger
 	)
@@ -37,7 +48,12 @@ var _ = Describe("DrainAction", func() {
 		specService = fakeas.NewFakeV1Service()
 		jobScriptProvider = &scriptfakes.FakeJobScriptProvider{}
 		jobSupervisor = fakejobsuper.NewFakeJobSupervisor()
-		action = NewDrain(notifier, specService, jobScriptProvider, jobSupervisor, logger)
+		settingsService = &fakesettings.FakeSettingsService{}
+		fs = fakefs.NewFakeFileSystem()
+		Expect(fs.MkdirAll("/fake", 0755)).To(Succeed())
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		lock := boshdrain.NewFileLock(fs, "/fake/drain.lock", 2001, noProcessRunning, fakeClock, logger)
+		action = NewDrain(notifier, specService, jobScriptProvider, jobSupervisor, settingsService, lock, logger)
 	})
 
 	BeforeEach(func() {
@@ -130,7 +146,7 @@ var _ = Describe("DrainAction", func() {
 							barScript.TagReturns("bar")
 
 							jobScriptProvider.NewDrainScriptStub = func(jobName string, params boshdrain.ScriptParams) boshscript.CancellableScript {
-								Expect(params).To(Equal(boshdrain.NewUpdateParams(currentSpec, newSpec)))
+								Expect(params).To(Equal(boshdrain.NewUpdateParams(currentSpec, newSpec, boshdrain.PollIntervalRange{})))
 
 								if jobName == "foo" {
 									return fooScript
@@ -150,11 +166,181 @@ var _ = Describe("DrainAction", func() {
 							Expect(parallelScript.RunCallCount()).To(Equal(1))
 							Expect(jobScriptProvider.NewParallelScriptCallCount()).To(Equal(1))
 
-							scriptName, scripts := jobScriptProvider.NewParallelScriptArgsForCall(0)
+							scriptName, scripts, _, _ := jobScriptProvider.NewParallelScriptArgsForCall(0)
 							Expect(scriptName).To(Equal("drain"))
 							Expect(scripts).To(Equal([]boshscript.Script{fooScript, barScript}))
 						})
 
+						It("runs drain scripts with the configured status poll interval range", func() {
+							settingsService.Settings.Env.Bosh.Drain.MinPollInterval = 5
+							settingsService.Settings.Env.Bosh.Drain.MaxPollInterval = 60
+
+							_, err := act()
+							Expect(err).ToNot(HaveOccurred())
+
+							Expect(jobScriptProvider.NewDrainScriptCallCount()).To(Equal(2))
+							_, params := jobScriptProvider.NewDrainScriptArgsForCall(0)
+							Expect(params.PollIntervalRange()).To(Equal(boshdrain.PollIntervalRange{
+								Min: 5 * time.Second,
+								Max: 60 * time.Second,
+							}))
+						})
+
+						Context("when a job has no drain script", func() {
+							var fooScript, barScript *scriptfakes.FakeCancellableScript
+
+							BeforeEach(func() {
+								fooScript = &scriptfakes.FakeCancellableScript{}
+								fooScript.ExistsReturns(true)
+
+								barScript = &scriptfakes.FakeCancellableScript{}
+								barScript.ExistsReturns(false)
+
+								jobScriptProvider.NewDrainScriptStub = func(jobName string, params boshdrain.ScriptParams) boshscript.CancellableScript {
+									if jobName == "foo" {
+										return fooScript
+									}
+									return barScript
+								}
+							})
+
+							It("treats the missing script as an immediate success by default", func() {
+								value, err := act()
+								Expect(err).ToNot(HaveOccurred())
+								Expect(value).To(Equal(0))
+
+								Expect(parallelScript.RunCallCount()).To(Equal(1))
+							})
+
+							It("returns an error without unmonitoring when drain scripts are required globally", func() {
+								settingsService.Settings.Env.Bosh.Drain.RequireScript = true
+
+								_, err := act()
+								Expect(err).To(HaveOccurred())
+								Expect(err.Error()).To(Equal("Drain script for job 'bar' is missing"))
+
+								Expect(jobSupervisor.Unmonitored).To(BeFalse())
+								Expect(parallelScript.RunCallCount()).To(Equal(0))
+							})
+
+							It("returns an error when the job requires a drain script", func() {
+								settingsService.Settings.Env.Bosh.Drain.RequireScriptJobs = []string{"bar"}
+
+								_, err := act()
+								Expect(err).To(HaveOccurred())
+								Expect(err.Error()).To(Equal("Drain script for job 'bar' is missing"))
+							})
+
+							It("succeeds when only jobs with drain scripts require them", func() {
+								settingsService.Settings.Env.Bosh.Drain.RequireScriptJobs = []string{"foo"}
+
+								_, err := act()
+								Expect(err).ToNot(HaveOccurred())
+								Expect(parallelScript.RunCallCount()).To(Equal(1))
+							})
+						})
+
+						Context("when drains are serialized", func() {
+							BeforeEach(func() {
+								settingsService.Settings.Env.Bosh.Drain.Serialize = true
+								settingsService.Settings.Env.Bosh.Drain.LockTimeout = 60
+							})
+
+							It("holds the drain lock while running drain scripts", func() {
+								parallelScript.RunStub = func() error {
+									Expect(fs.FileExists("/fake/drain.lock")).To(BeTrue())
+									return nil
+								}
+
+								_, err := act()
+								Expect(err).ToNot(HaveOccurred())
+
+								Expect(parallelScript.RunCallCount()).To(Equal(1))
+								Expect(fs.FileExists("/fake/drain.lock")).To(BeFalse())
+							})
+
+							It("runs the drain scripts of the jobs one at a time", func() {
+								_, err := act()
+								Expect(err).ToNot(HaveOccurred())
+
+								_, _, maxConcurrency, _ := jobScriptProvider.NewParallelScriptArgsForCall(0)
+								Expect(maxConcurrency).To(Equal(1))
+							})
+
+							It("waits for another drain holding the lock", func() {
+								otherLock := boshdrain.NewFileLock(fs, "/fake/drain.lock", 2002, noProcessRunning, fakeClock, logger)
+								err := otherLock.Lock(time.Minute, nil)
+								Expect(err).ToNot(HaveOccurred())
+
+								doneCh := make(chan error, 1)
+								go func() {
+									_, err := act()
+									doneCh <- err
+								}()
+
+								Eventually(fakeClock.WatcherCount).Should(Equal(1))
+								Consistently(doneCh).ShouldNot(Receive())
+								Expect(parallelScript.RunCallCount()).To(Equal(0))
+
+								err = otherLock.Unlock()
+								Expect(err).ToNot(HaveOccurred())
+
+								fakeClock.Increment(time.Second)
+								Eventually(doneCh).Should(Receive(BeNil()))
+								Expect(parallelScript.RunCallCount()).To(Equal(1))
+							})
+
+							It("stops waiting for the lock when cancelled", func() {
+								otherLock := boshdrain.NewFileLock(fs, "/fake/drain.lock", 2002, noProcessRunning, fakeClock, logger)
+								err := otherLock.Lock(time.Minute, nil)
+								Expect(err).ToNot(HaveOccurred())
+
+								doneCh := make(chan error, 1)
+								go func() {
+									_, err := act()
+									doneCh <- err
+								}()
+
+								Eventually(fakeClock.WatcherCount).Should(Equal(1))
+
+								err = action.Cancel()
+								Expect(err).ToNot(HaveOccurred())
+
+								var drainErr error
+								Eventually(doneCh).Should(Receive(&drainErr))
+								Expect(drainErr).To(HaveOccurred())
+								Expect(drainErr.Error()).To(ContainSubstring("Waiting for lock '/fake/drain.lock' was cancelled"))
+								Expect(parallelScript.RunCallCount()).To(Equal(0))
+								Expect(fs.FileExists("/fake/drain.lock")).To(BeTrue())
+							})
+
+							It("reclaims a stale lock left behind by another drain", func() {
+								otherLock := boshdrain.NewFileLock(fs, "/fake/drain.lock", 2002, noProcessRunning, fakeClock, logger)
+								err := otherLock.Lock(time.Minute, nil)
+								Expect(err).ToNot(HaveOccurred())
+
+								fakeClock.Increment(time.Minute)
+
+								_, err = act()
+								Expect(err).ToNot(HaveOccurred())
+								Expect(parallelScript.RunCallCount()).To(Equal(1))
+							})
+						})
+
+						It("does not take the drain lock by default", func() {
+							parallelScript.RunStub = func() error {
+								Expect(fs.FileExists("/fake/drain.lock")).To(BeFalse())
+								return nil
+							}
+
+							_, err := act()
+							Expect(err).ToNot(HaveOccurred())
+							Expect(parallelScript.RunCallCount()).To(Equal(1))
+
+							_, _, maxConcurrency, _ := jobScriptProvider.NewParallelScriptArgsForCall(0)
+							Expect(maxConcurrency).To(Equal(0))
+						})
+
 						It("returns an error when parallel script fails", func() {
 							parallelScript.RunReturns(errors.New("fake-error"))
 
@@ -243,7 +429,7 @@ var _ = Describe("DrainAction", func() {
 							barScript.TagReturns("bar")
 
 							jobScriptProvider.NewDrainScriptStub = func(jobName string, params boshdrain.ScriptParams) boshscript.CancellableScript {
-								Expect(params).To(Equal(boshdrain.NewShutdownParams(currentSpec, nil)))
+								Expect(params).To(Equal(boshdrain.NewShutdownParams(currentSpec, nil, boshdrain.PollIntervalRange{})))
 
 								if jobName == "foo" {
 									return fooScript
@@ -263,11 +449,77 @@ var _ = Describe("DrainAction", func() {
 							Expect(parallelScript.RunCallCount()).To(Equal(1))
 							Expect(jobScriptProvider.NewParallelScriptCallCount()).To(Equal(1))
 
-							scriptName, scripts := jobScriptProvider.NewParallelScriptArgsForCall(0)
+							scriptName, scripts, _, _ := jobScriptProvider.NewParallelScriptArgsForCall(0)
 							Expect(scriptName).To(Equal("drain"))
 							Expect(scripts).To(Equal([]boshscript.Script{fooScript, barScript}))
 						})
 
+						Context("when drains are serialized", func() {
+							BeforeEach(func() {
+								settingsService.Settings.Env.Bosh.Drain.Serialize = true
+								settingsService.Settings.Env.Bosh.Drain.LockTimeout = 60
+							})
+
+							It("holds the drain lock while running drain scripts", func() {
+								parallelScript.RunStub = func() error {
+									Expect(fs.FileExists("/fake/drain.lock")).To(BeTrue())
+									return nil
+								}
+
+								_, err := act()
+								Expect(err).ToNot(HaveOccurred())
+
+								Expect(parallelScript.RunCallCount()).To(Equal(1))
+								Expect(fs.FileExists("/fake/drain.lock")).To(BeFalse())
+							})
+
+							It("waits for another drain holding the lock", func() {
+								otherLock := boshdrain.NewFileLock(fs, "/fake/drain.lock", 2002, noProcessRunning, fakeClock, logger)
+								err := otherLock.Lock(time.Minute, nil)
+								Expect(err).ToNot(HaveOccurred())
+
+								doneCh := make(chan error, 1)
+								go func() {
+									_, err := act()
+									doneCh <- err
+								}()
+
+								Eventually(fakeClock.WatcherCount).Should(Equal(1))
+								Consistently(doneCh).ShouldNot(Receive())
+								Expect(parallelScript.RunCallCount()).To(Equal(0))
+
+								err = otherLock.Unlock()
+								Expect(err).ToNot(HaveOccurred())
+
+								fakeClock.Increment(time.Second)
+								Eventually(doneCh).Should(Receive(BeNil()))
+								Expect(parallelScript.RunCallCount()).To(Equal(1))
+							})
+
+							It("reclaims a stale lock left behind by another drain", func() {
+								otherLock := boshdrain.NewFileLock(fs, "/fake/drain.lock", 2002, noProcessRunning, fakeClock, logger)
+								err := otherLock.Lock(time.Minute, nil)
+								Expect(err).ToNot(HaveOccurred())
+
+								fakeClock.Increment(time.Minute)
+
+								_, err = act()
+								Expect(err).ToNot(HaveOccurred())
+								Expect(parallelScript.RunCallCount()).To(Equal(1))
+							})
+						})
+
+						It("does not take the drain lock by default", func() {
+							parallelScript.RunStub = func() error {
+								Expect(fs.FileExists("/fake/drain.lock")).To(BeFalse())
+								return nil
+							}
+
+							_, err := act()
+							Expect(err).ToNot(HaveOccurred())
+							Expect(parallelScript.RunCallCount()).To(Equal(1))
+						})
+
 						It("returns an error when parallel script fails", func() {
 							parallelScript.RunReturns(errors.New("fake-error"))
 
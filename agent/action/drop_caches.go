@@ -0,0 +1,72 @@
+package action
+
+import (
+	"errors"
+	"os"
+
+	boshsettings "github.com/cloudfoundry/bosh-agent/settings"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+const (
+	dropCachesPath = "/proc/sys/vm/drop_caches"
+
+	// Frees the page cache as well as dentries and inodes
+	dropCachesAll = "3"
+)
+
+type DropCachesAction struct {
+	fs              boshsys.FileSystem
+	settingsService boshsettings.Service
+}
+
+func NewDropCaches(fs boshsys.FileSystem, settingsService boshsettings.Service) DropCachesAction {
+	return DropCachesAction{
+		fs:              fs,
+		settingsService: settingsService,
+	}
+}
+
+func (a DropCachesAction) IsAsynchronous(_ ProtocolVersion) bool {
+	return false
+}
+
+func (a DropCachesAction) IsPersistent() bool {
+	return false
+}
+
+func (a DropCachesAction) IsLoggable() bool {
+	return true
+}
+
+func (a DropCachesAction) Run() (string, error) {
+	if !a.settingsService.GetSettings().Env.Bosh.EnableDropCaches {
+		return "", bosherr.Error("Dropping caches is not enabled")
+	}
+
+	file, err := a.fs.OpenFile(dropCachesPath, os.O_WRONLY, 0)
+	if err != nil {
+		if os.IsPermission(err) {
+			return "", bosherr.WrapErrorf(err, "Insufficient permissions to write to '%s'", dropCachesPath)
+		}
+		return "", bosherr.WrapErrorf(err, "Opening '%s'", dropCachesPath)
+	}
+
+	defer file.Close()
+
+	_, err = file.Write([]byte(dropCachesAll))
+	if err != nil {
+		return "", bosherr.WrapErrorf(err, "Writing to '%s'", dropCachesPath)
+	}
+
+	return "dropped", nil
+}
+
+func (a DropCachesAction) Resume() (interface{}, error) {
+	return nil, errors.New("not supported")
+}
+
+func (a DropCachesAction) Cancel() error {
+	return errors.New("not supported")
+}
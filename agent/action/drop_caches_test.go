@@ -0,0 +1,82 @@
+package action_test
+
+import (
+	"errors"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-agent/agent/action"
+	fakefs "github.com/cloudfoundry/bosh-agent/platform/fakefs"
+	fakesettings "github.com/cloudfoundry/bosh-agent/settings/fakes"
+)
+
+var _ = Describe("DropCachesAction", func() {
+	var (
+		fs              *fakefs.FakeFileSystem
+		settingsService *fakesettings.FakeSettingsService
+		action          DropCachesAction
+	)
+
+	BeforeEach(func() {
+		fs = fakefs.NewFakeFileSystem()
+		settingsService = &fakesettings.FakeSettingsService{}
+		settingsService.Settings.Env.Bosh.EnableDropCaches = true
+		action = NewDropCaches(fs, settingsService)
+	})
+
+	AssertActionIsNotAsynchronous(action)
+	AssertActionIsNotPersistent(action)
+	AssertActionIsLoggable(action)
+
+	AssertActionIsNotResumable(action)
+	AssertActionIsNotCancelable(action)
+
+	Describe("Run", func() {
+		It("writes 3 to drop_caches to free page cache, dentries and inodes", func() {
+			result, err := action.Run()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal("dropped"))
+
+			contents, err := fs.ReadFileString("/proc/sys/vm/drop_caches")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(contents).To(Equal("3"))
+		})
+
+		It("returns an error without writing when dropping caches is not enabled", func() {
+			settingsService.Settings.Env.Bosh.EnableDropCaches = false
+
+			_, err := action.Run()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Dropping caches is not enabled"))
+			Expect(fs.FileExists("/proc/sys/vm/drop_caches")).To(BeFalse())
+		})
+
+		It("returns a descriptive error when the agent lacks permission", func() {
+			fs.OpenFileErr = &os.PathError{Op: "open", Path: "/proc/sys/vm/drop_caches", Err: os.ErrPermission}
+
+			_, err := action.Run()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Insufficient permissions to write to '/proc/sys/vm/drop_caches'"))
+		})
+
+		It("returns an error when opening drop_caches fails", func() {
+			fs.OpenFileErr = errors.New("fake-open-err")
+
+			_, err := action.Run()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-open-err"))
+		})
+
+		It("returns an error when writing to drop_caches fails", func() {
+			file := fakefs.NewFakeFile("/proc/sys/vm/drop_caches", fs)
+			file.WriteErr = errors.New("fake-write-err")
+			fs.RegisterOpenFile("/proc/sys/vm/drop_caches", file)
+
+			_, err := action.Run()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-write-err"))
+		})
+	})
+})
@@ -1,16 +1,29 @@
 package fakes
 
 import (
+	"sync"
 	"time"
 )
 
 type FakeAgentKiller struct {
+	killAgentIntervals []time.Duration
+	killAgentMutex     sync.Mutex
 }
 
-func NewFakeAgentKiller() FakeAgentKiller {
-	return FakeAgentKiller{}
+func NewFakeAgentKiller() *FakeAgentKiller {
+	return &FakeAgentKiller{}
 }
 
-func (a FakeAgentKiller) KillAgent(waitToKillAgentInterval time.Duration) {
-	return
+func (a *FakeAgentKiller) KillAgent(waitToKillAgentInterval time.Duration) {
+	a.killAgentMutex.Lock()
+	defer a.killAgentMutex.Unlock()
+
+	a.killAgentIntervals = append(a.killAgentIntervals, waitToKillAgentInterval)
+}
+
+func (a *FakeAgentKiller) KillAgentIntervals() []time.Duration {
+	a.killAgentMutex.Lock()
+	defer a.killAgentMutex.Unlock()
+
+	return append([]time.Duration{}, a.killAgentIntervals...)
 }
@@ -0,0 +1,22 @@
+package fakes
+
+type FakePortProber struct {
+	ProbeNetworks  []string
+	ProbeAddresses []string
+	ProbeErr       error
+
+	OwnerPorts   []int
+	OwnerProcess string
+	OwnerErr     error
+}
+
+func (p *FakePortProber) Probe(network, address string) error {
+	p.ProbeNetworks = append(p.ProbeNetworks, network)
+	p.ProbeAddresses = append(p.ProbeAddresses, address)
+	return p.ProbeErr
+}
+
+func (p *FakePortProber) Owner(port int) (string, error) {
+	p.OwnerPorts = append(p.OwnerPorts, port)
+	return p.OwnerProcess, p.OwnerErr
+}
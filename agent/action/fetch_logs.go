@@ -2,18 +2,25 @@ package action
 
 import (
 	"errors"
+	"os"
 
 	"github.com/cloudfoundry/bosh-agent/agent/httpblobprovider/blobstore_delegator"
+	boshsettings "github.com/cloudfoundry/bosh-agent/settings"
 	boshdirs "github.com/cloudfoundry/bosh-agent/settings/directories"
+	boshcrypto "github.com/cloudfoundry/bosh-utils/crypto"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshcmd "github.com/cloudfoundry/bosh-utils/fileutil"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
 )
 
 type FetchLogsAction struct {
-	compressor  boshcmd.Compressor
-	copier      boshcmd.Copier
-	blobstore   blobstore_delegator.BlobstoreDelegator
-	settingsDir boshdirs.Provider
+	compressor      boshcmd.Compressor
+	copier          boshcmd.Copier
+	blobstore       blobstore_delegator.BlobstoreDelegator
+	settingsDir     boshdirs.Provider
+	settingsService boshsettings.Service
+	runner          boshsys.CmdRunner
+	fs              boshsys.FileSystem
 }
 
 func NewFetchLogs(
@@ -21,11 +28,17 @@ func NewFetchLogs(
 	copier boshcmd.Copier,
 	blobstore blobstore_delegator.BlobstoreDelegator,
 	settingsDir boshdirs.Provider,
+	settingsService boshsettings.Service,
+	runner boshsys.CmdRunner,
+	fs boshsys.FileSystem,
 ) (action FetchLogsAction) {
 	action.compressor = compressor
 	action.copier = copier
 	action.blobstore = blobstore
 	action.settingsDir = settingsDir
+	action.settingsService = settingsService
+	action.runner = runner
+	action.fs = fs
 	return
 }
 
@@ -41,26 +54,22 @@ func (a FetchLogsAction) IsLoggable() bool {
 	return true
 }
 
-func (a FetchLogsAction) Run(logType string, filters []string) (value map[string]string, err error) {
-	var logsDir string
+// Run uploads the filtered logs; an optional maxBytes limits the total
+// size of the copied log files before compression (0 means unlimited)
+func (a FetchLogsAction) Run(logType string, filters []string, maxBytes ...uint64) (value map[string]string, err error) {
+	digestAlgorithm := a.settingsService.GetSettings().Env.Bosh.DigestAlgorithm
 
-	switch logType {
-	case "job":
-		if len(filters) == 0 {
-			filters = []string{"**/*"}
-		}
-		logsDir = a.settingsDir.LogsDir()
-	case "agent":
-		if len(filters) == 0 {
-			filters = []string{"**/*"}
-		}
-		logsDir = a.settingsDir.AgentLogsDir()
-	default:
-		err = bosherr.Error("Invalid log type")
+	_, err = digestAlgorithmFor(digestAlgorithm)
+	if err != nil {
+		return
+	}
+
+	logsDir, err := logsDirFor(a.settingsDir, logType)
+	if err != nil {
 		return
 	}
 
-	tmpDir, err := a.copier.FilteredCopyToTemp(logsDir, filters)
+	tmpDir, err := a.copier.FilteredCopyToTemp(logsDir, logsFilters(filters))
 	if err != nil {
 		err = bosherr.WrapError(err, "Copying filtered files to temp directory")
 		return
@@ -68,9 +77,15 @@ func (a FetchLogsAction) Run(logType string, filters []string) (value map[string
 
 	defer a.copier.CleanUp(tmpDir)
 
-	tarball, err := a.compressor.CompressFilesInDir(tmpDir)
+	if len(maxBytes) > 0 && maxBytes[0] > 0 {
+		err = a.checkLogsSize(tmpDir, maxBytes[0])
+		if err != nil {
+			return
+		}
+	}
+
+	tarball, err := logsTarball(a.compressor, a.runner, a.fs, tmpDir, a.settingsService.GetSettings().Env.Bosh.Logs.MaxTarballSize)
 	if err != nil {
-		err = bosherr.WrapError(err, "Making logs tarball")
 		return
 	}
 
@@ -84,10 +99,42 @@ func (a FetchLogsAction) Run(logType string, filters []string) (value map[string
 		return
 	}
 
-	value = map[string]string{"blobstore_id": blobID, "sha1": multidigestSha.String()}
+	digest, algorithm, err := uploadedDigest(a.fs, tarball, multidigestSha, digestAlgorithm)
+	if err != nil {
+		err = bosherr.WrapError(err, "Calculating logs tarball digest")
+		return
+	}
+
+	value = map[string]string{"blobstore_id": blobID, "digest": digest, "algorithm": algorithm}
+	if algorithm == boshcrypto.DigestAlgorithmSHA1.Name() {
+		value["sha1"] = digest
+	}
 	return
 }
 
+func (a FetchLogsAction) checkLogsSize(logsDir string, maxBytes uint64) error {
+	var totalBytes uint64
+
+	err := a.fs.Walk(logsDir, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			totalBytes += uint64(info.Size())
+		}
+		return nil
+	})
+	if err != nil {
+		return bosherr.WrapError(err, "Calculating logs size")
+	}
+
+	if totalBytes > maxBytes {
+		return bosherr.Errorf("Logs total %d bytes which exceeds the limit of %d bytes", totalBytes, maxBytes)
+	}
+
+	return nil
+}
+
 func (a FetchLogsAction) Resume() (interface{}, error) {
 	return nil, errors.New("not supported")
 }
@@ -1,26 +1,35 @@
 package action_test
 
 import (
+	"errors"
 	"path/filepath"
+	"strings"
 
 	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 
 	. "github.com/cloudfoundry/bosh-agent/agent/action"
 	fakeblobdelegator "github.com/cloudfoundry/bosh-agent/agent/httpblobprovider/blobstore_delegator/blobstore_delegatorfakes"
+	fakefs "github.com/cloudfoundry/bosh-agent/platform/fakefs"
 	boshdirs "github.com/cloudfoundry/bosh-agent/settings/directories"
+	fakesettings "github.com/cloudfoundry/bosh-agent/settings/fakes"
 	boshassert "github.com/cloudfoundry/bosh-utils/assert"
 	boshcrypto "github.com/cloudfoundry/bosh-utils/crypto"
 	fakecmd "github.com/cloudfoundry/bosh-utils/fileutil/fakes"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
 )
 
 var _ = Describe("FetchLogsAction", func() {
 	var (
-		compressor  *fakecmd.FakeCompressor
-		copier      *fakecmd.FakeCopier
-		blobstore   *fakeblobdelegator.FakeBlobstoreDelegator
-		dirProvider boshdirs.Provider
-		action      FetchLogsAction
+		compressor      *fakecmd.FakeCompressor
+		copier          *fakecmd.FakeCopier
+		blobstore       *fakeblobdelegator.FakeBlobstoreDelegator
+		dirProvider     boshdirs.Provider
+		settingsService *fakesettings.FakeSettingsService
+		runner          *fakesys.FakeCmdRunner
+		fs              *fakefs.FakeFileSystem
+		action          FetchLogsAction
 	)
 
 	BeforeEach(func() {
@@ -28,7 +37,10 @@ var _ = Describe("FetchLogsAction", func() {
 		blobstore = &fakeblobdelegator.FakeBlobstoreDelegator{}
 		dirProvider = boshdirs.NewProvider("/fake/dir")
 		copier = fakecmd.NewFakeCopier()
-		action = NewFetchLogs(compressor, copier, blobstore, dirProvider)
+		settingsService = &fakesettings.FakeSettingsService{}
+		runner = fakesys.NewFakeCmdRunner()
+		fs = fakefs.NewFakeFileSystem()
+		action = NewFetchLogs(compressor, copier, blobstore, dirProvider, settingsService, runner, fs)
 	})
 
 	AssertActionIsAsynchronous(action)
@@ -57,6 +69,8 @@ var _ = Describe("FetchLogsAction", func() {
 				expectedPath = filepath.Join("/fake", "dir", "sys", "log")
 			case "agent":
 				expectedPath = filepath.Join("/fake", "dir", "bosh", "log")
+			case "system":
+				expectedPath = filepath.Join("/fake", "dir", "sys", "log")
 			}
 
 			Expect(copier.FilteredCopyToTempDir).To(boshassert.MatchPath(expectedPath))
@@ -68,12 +82,13 @@ var _ = Describe("FetchLogsAction", func() {
 			_, compressFilesInTarballPath, _ := blobstore.WriteArgsForCall(0)
 			Expect(compressFilesInTarballPath).To(Equal(compressor.CompressFilesInDirTarballPath))
 
-			boshassert.MatchesJSONString(GinkgoT(), logs, `{"blobstore_id":"my-blob-id","sha1":"`+sha1+`"}`)
+			boshassert.MatchesJSONString(GinkgoT(), logs, `{"algorithm":"sha1","blobstore_id":"my-blob-id","digest":"`+sha1+`","sha1":"`+sha1+`"}`)
 		}
 
 		It("logs errs if given invalid log type", func() {
 			_, err := action.Run("other-logs", []string{})
 			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Invalid log type"))
 		})
 
 		It("agent logs with filters", func() {
@@ -100,6 +115,30 @@ var _ = Describe("FetchLogsAction", func() {
 			testLogs("job", filters, expectedFilters)
 		})
 
+		It("job logs with include and exclude filters", func() {
+			filters := []string{"**/*.log*", "!**/*.1.gz"}
+			expectedFilters := []string{"**/*.log*", "!**/*.1.gz"}
+			testLogs("job", filters, expectedFilters)
+		})
+
+		It("job logs with only exclude filters include all other logs", func() {
+			filters := []string{"!**/*.1.gz"}
+			expectedFilters := []string{"**/*", "!**/*.1.gz"}
+			testLogs("job", filters, expectedFilters)
+		})
+
+		It("system logs without filters", func() {
+			filters := []string{}
+			expectedFilters := []string{"**/*"}
+			testLogs("system", filters, expectedFilters)
+		})
+
+		It("system logs with filters", func() {
+			filters := []string{"**/syslog*", "!**/*.gz"}
+			expectedFilters := []string{"**/syslog*", "!**/*.gz"}
+			testLogs("system", filters, expectedFilters)
+		})
+
 		It("cleans up compressed package after uploading it to blobstore", func() {
 			var beforeCleanUpTarballPath, afterCleanUpTarballPath string
 
@@ -121,5 +160,159 @@ var _ = Describe("FetchLogsAction", func() {
 			afterCleanUpTarballPath = compressor.CleanUpTarballPath
 			Expect(afterCleanUpTarballPath).To(Equal("/fake-compressed-logs.tar"))
 		})
+
+		Context("when a digest algorithm is configured", func() {
+			BeforeEach(func() {
+				compressor.CompressFilesInDirTarballPath = "/fake-compressed-logs.tar"
+				err := fs.WriteFileString("/fake-compressed-logs.tar", "fake-logs")
+				Expect(err).ToNot(HaveOccurred())
+
+				blobstore.WriteReturns("my-blob-id", boshcrypto.MultipleDigest{}, nil)
+			})
+
+			DescribeTable("returns the digest of the tarball for the configured algorithm",
+				func(algorithm boshcrypto.Algorithm) {
+					settingsService.Settings.Env.Bosh.DigestAlgorithm = algorithm.Name()
+
+					expectedDigest, err := algorithm.CreateDigest(strings.NewReader("fake-logs"))
+					Expect(err).ToNot(HaveOccurred())
+
+					logs, err := action.Run("job", []string{})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(logs["digest"]).To(Equal(expectedDigest.String()))
+					Expect(logs["algorithm"]).To(Equal(algorithm.Name()))
+				},
+				Entry("sha1", boshcrypto.DigestAlgorithmSHA1),
+				Entry("sha256", boshcrypto.DigestAlgorithmSHA256),
+				Entry("sha512", boshcrypto.DigestAlgorithmSHA512),
+			)
+
+			It("reuses the digest reported by the blobstore when it uses the configured algorithm", func() {
+				settingsService.Settings.Env.Bosh.DigestAlgorithm = "sha512"
+				blobstore.WriteReturns("my-blob-id", boshcrypto.MustNewMultipleDigest(
+					boshcrypto.NewDigest(boshcrypto.DigestAlgorithmSHA1, "fake-sha1"),
+					boshcrypto.NewDigest(boshcrypto.DigestAlgorithmSHA512, "fake-sha512"),
+				), nil)
+
+				logs, err := action.Run("job", []string{})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(logs["digest"]).To(Equal("sha512:fake-sha512"))
+			})
+
+			It("only returns the sha1 key for sha1 digests", func() {
+				settingsService.Settings.Env.Bosh.DigestAlgorithm = "sha256"
+
+				logs, err := action.Run("job", []string{})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(logs).ToNot(HaveKey("sha1"))
+
+				settingsService.Settings.Env.Bosh.DigestAlgorithm = "sha1"
+
+				logs, err = action.Run("job", []string{})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(logs["sha1"]).To(Equal(logs["digest"]))
+			})
+
+			It("returns an error for an unsupported algorithm", func() {
+				settingsService.Settings.Env.Bosh.DigestAlgorithm = "md5"
+
+				_, err := action.Run("job", []string{})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Unsupported digest algorithm 'md5'"))
+				Expect(copier.FilteredCopyToTempDir).To(BeEmpty())
+			})
+		})
+
+		Context("when a maximum size is requested", func() {
+			BeforeEach(func() {
+				copier.FilteredCopyToTempTempDir = "/fake-temp-dir"
+				compressor.CompressFilesInDirTarballPath = "/fake-compressed-logs.tar"
+
+				err := fs.WriteFileString("/fake-temp-dir/job/job.stdout.log", "0123456789")
+				Expect(err).ToNot(HaveOccurred())
+
+				err = fs.WriteFileString("/fake-temp-dir/job/job.stderr.log", "01234")
+				Expect(err).ToNot(HaveOccurred())
+
+				blobstore.WriteReturns("my-blob-id", boshcrypto.MultipleDigest{}, nil)
+			})
+
+			It("returns an error without compressing when the copied logs exceed the limit", func() {
+				_, err := action.Run("job", []string{}, 14)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Logs total 15 bytes which exceeds the limit of 14 bytes"))
+
+				Expect(compressor.CompressFilesInDirDir).To(BeEmpty())
+				Expect(blobstore.WriteCallCount()).To(Equal(0))
+				Expect(copier.CleanUpTempDir).To(Equal("/fake-temp-dir"))
+			})
+
+			It("uploads the logs when they are within the limit", func() {
+				logs, err := action.Run("job", []string{}, 15)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(logs["blobstore_id"]).To(Equal("my-blob-id"))
+			})
+
+			It("does not limit the logs when the limit is 0", func() {
+				_, err := action.Run("job", []string{}, 0)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(blobstore.WriteCallCount()).To(Equal(1))
+			})
+
+			It("returns an error when the copied logs cannot be walked", func() {
+				fs.WalkErr = errors.New("fake-walk-err")
+
+				_, err := action.Run("job", []string{}, 14)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-walk-err"))
+			})
+		})
+
+		Context("when a maximum tarball size is configured", func() {
+			BeforeEach(func() {
+				settingsService.Settings.Env.Bosh.Logs.MaxTarballSize = 10
+				copier.FilteredCopyToTempTempDir = "/fake-temp-dir"
+				fs.ReturnTempFile = fakefs.NewFakeFile("/fake-logs-tarball.tgz", fs)
+
+				blobstore.WriteReturns("my-blob-id", boshcrypto.MultipleDigest{}, nil)
+			})
+
+			It("streams the tarball from tar and uploads it when it is within the limit", func() {
+				runner.AddCmdResult("tar czf - -C /fake-temp-dir .", fakesys.FakeCmdResult{Stdout: "0123456789"})
+
+				_, err := action.Run("job", []string{})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(compressor.CompressFilesInDirDir).To(BeEmpty())
+				Expect(blobstore.WriteCallCount()).To(Equal(1))
+
+				_, tarballPath, _ := blobstore.WriteArgsForCall(0)
+				Expect(tarballPath).To(Equal("/fake-logs-tarball.tgz"))
+				Expect(fs.ReadFileString("/fake-logs-tarball.tgz")).To(Equal("0123456789"))
+			})
+
+			It("aborts compression and removes the partial tarball when it exceeds the limit", func() {
+				runner.AddCmdResult("tar czf - -C /fake-temp-dir .", fakesys.FakeCmdResult{Stdout: "0123456789a"})
+
+				_, err := action.Run("job", []string{})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Logs exceed maximum size of 10 bytes"))
+
+				Expect(blobstore.WriteCallCount()).To(Equal(0))
+				Expect(fs.FileExists("/fake-logs-tarball.tgz")).To(BeFalse())
+				Expect(copier.CleanUpTempDir).To(Equal("/fake-temp-dir"))
+			})
+
+			It("removes the partial tarball when tar fails", func() {
+				runner.AddCmdResult("tar czf - -C /fake-temp-dir .", fakesys.FakeCmdResult{Error: errors.New("fake-tar-err")})
+
+				_, err := action.Run("job", []string{})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-tar-err"))
+
+				Expect(blobstore.WriteCallCount()).To(Equal(0))
+				Expect(fs.FileExists("/fake-logs-tarball.tgz")).To(BeFalse())
+			})
+		})
 	})
 })
@@ -4,9 +4,12 @@ import (
 	"errors"
 
 	blobdelegator "github.com/cloudfoundry/bosh-agent/agent/httpblobprovider/blobstore_delegator"
+	boshsettings "github.com/cloudfoundry/bosh-agent/settings"
 	boshdirs "github.com/cloudfoundry/bosh-agent/settings/directories"
+	boshcrypto "github.com/cloudfoundry/bosh-utils/crypto"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshcmd "github.com/cloudfoundry/bosh-utils/fileutil"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
 )
 
 type FetchLogsWithSignedURLRequest struct {
@@ -14,28 +17,51 @@ type FetchLogsWithSignedURLRequest struct {
 	LogType          string            `json:"log_type"`
 	Filters          []string          `json:"filters"`
 	BlobstoreHeaders map[string]string `json:"blobstore_headers"`
+
+	// Overrides the configured maximum logs tarball size when set
+	MaxTarballSize uint64 `json:"max_tarball_size"`
+
+	// Overrides the configured digest algorithm when set
+	DigestAlgorithm string `json:"digest_algorithm"`
+
+	// Signed URL for checking the blob already stored at SignedURL, needed
+	// unless the blobstore is configured to overwrite duplicate uploads
+	ExistingBlobSignedURL string `json:"existing_blob_signed_url"`
 }
 
 type FetchLogsWithSignedURLResponse struct {
-	SHA1Digest string `json:"sha1"`
+	Digest    string `json:"digest"`
+	Algorithm string `json:"algorithm"`
+
+	// Only set for sha1 digests, kept for directors that predate the digest key
+	SHA1Digest string `json:"sha1,omitempty"`
 }
 
 type FetchLogsWithSignedURLAction struct {
-	compressor    boshcmd.Compressor
-	copier        boshcmd.Copier
-	settingsDir   boshdirs.Provider
-	blobDelegator blobdelegator.BlobstoreDelegator
+	compressor      boshcmd.Compressor
+	copier          boshcmd.Copier
+	settingsDir     boshdirs.Provider
+	blobDelegator   blobdelegator.BlobstoreDelegator
+	settingsService boshsettings.Service
+	runner          boshsys.CmdRunner
+	fs              boshsys.FileSystem
 }
 
 func NewFetchLogsWithSignedURLAction(
 	compressor boshcmd.Compressor,
 	copier boshcmd.Copier,
 	settingsDir boshdirs.Provider,
-	blobDelegator blobdelegator.BlobstoreDelegator) (action FetchLogsWithSignedURLAction) {
+	blobDelegator blobdelegator.BlobstoreDelegator,
+	settingsService boshsettings.Service,
+	runner boshsys.CmdRunner,
+	fs boshsys.FileSystem) (action FetchLogsWithSignedURLAction) {
 	action.compressor = compressor
 	action.copier = copier
 	action.settingsDir = settingsDir
 	action.blobDelegator = blobDelegator
+	action.settingsService = settingsService
+	action.runner = runner
+	action.fs = fs
 	return
 }
 
@@ -52,48 +78,68 @@ func (a FetchLogsWithSignedURLAction) IsLoggable() bool {
 }
 
 func (a FetchLogsWithSignedURLAction) Run(request FetchLogsWithSignedURLRequest) (FetchLogsWithSignedURLResponse, error) {
-	var logsDir string
 	filters := request.Filters
 
-	switch request.LogType {
-	case "job":
-		if len(request.Filters) == 0 {
-			filters = []string{"**/*"}
-		}
-		logsDir = a.settingsDir.LogsDir()
-	case "agent":
-		if len(request.Filters) == 0 {
-			filters = []string{"**/*"}
-		}
-		logsDir = a.settingsDir.AgentLogsDir()
-	default:
-		return FetchLogsWithSignedURLResponse{}, bosherr.Error("Invalid log type")
+	digestAlgorithm := request.DigestAlgorithm
+	if digestAlgorithm == "" {
+		digestAlgorithm = a.settingsService.GetSettings().Env.Bosh.DigestAlgorithm
+	}
+
+	requestedAlgorithm, err := digestAlgorithmFor(digestAlgorithm)
+	if err != nil {
+		return FetchLogsWithSignedURLResponse{}, err
+	}
+
+	logsDir, err := logsDirFor(a.settingsDir, request.LogType)
+	if err != nil {
+		return FetchLogsWithSignedURLResponse{}, err
 	}
 
-	tmpDir, err := a.copier.FilteredCopyToTemp(logsDir, filters)
+	tmpDir, err := a.copier.FilteredCopyToTemp(logsDir, logsFilters(filters))
 	if err != nil {
 		return FetchLogsWithSignedURLResponse{}, bosherr.WrapError(err, "Copying filtered files to temp directory")
 	}
 
 	defer a.copier.CleanUp(tmpDir)
 
-	tarball, err := a.compressor.CompressFilesInDir(tmpDir)
+	maxTarballSize := request.MaxTarballSize
+	if maxTarballSize == 0 {
+		maxTarballSize = a.settingsService.GetSettings().Env.Bosh.Logs.MaxTarballSize
+	}
+
+	tarball, err := logsTarball(a.compressor, a.runner, a.fs, tmpDir, maxTarballSize)
 	if err != nil {
-		return FetchLogsWithSignedURLResponse{}, bosherr.WrapError(err, "Making logs tarball")
+		return FetchLogsWithSignedURLResponse{}, err
 	}
 
 	defer func() {
 		_ = a.compressor.CleanUp(tarball)
 	}()
 
-	_, digest, err := a.blobDelegator.Write(request.SignedURL, tarball, request.BlobstoreHeaders)
+	var digest boshcrypto.MultipleDigest
+	if request.ExistingBlobSignedURL == "" {
+		_, digest, err = a.blobDelegator.Write(request.SignedURL, tarball, request.BlobstoreHeaders)
+	} else {
+		digest, err = a.blobDelegator.WriteWithExistingBlobCheck(request.SignedURL, request.ExistingBlobSignedURL, tarball, request.BlobstoreHeaders, []boshcrypto.Algorithm{requestedAlgorithm})
+	}
 	if err != nil {
 		return FetchLogsWithSignedURLResponse{}, bosherr.WrapError(err, "Create file on blobstore")
 	}
 
-	return FetchLogsWithSignedURLResponse{
-		SHA1Digest: digest.String(),
-	}, nil
+	digestString, algorithm, err := uploadedDigest(a.fs, tarball, digest, digestAlgorithm)
+	if err != nil {
+		return FetchLogsWithSignedURLResponse{}, bosherr.WrapError(err, "Calculating logs tarball digest")
+	}
+
+	response := FetchLogsWithSignedURLResponse{
+		Digest:    digestString,
+		Algorithm: algorithm,
+	}
+	if algorithm == boshcrypto.DigestAlgorithmSHA1.Name() {
+		response.SHA1Digest = digestString
+	}
+
+	return response, nil
 }
 
 func (a FetchLogsWithSignedURLAction) Resume() (interface{}, error) {
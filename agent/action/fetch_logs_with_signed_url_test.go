@@ -2,6 +2,7 @@ package action_test
 
 import (
 	"path/filepath"
+	"strings"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -11,18 +12,24 @@ import (
 	fakeblobdelegator "github.com/cloudfoundry/bosh-agent/agent/httpblobprovider/blobstore_delegator/blobstore_delegatorfakes"
 	fakecmd "github.com/cloudfoundry/bosh-utils/fileutil/fakes"
 
+	fakefs "github.com/cloudfoundry/bosh-agent/platform/fakefs"
 	boshdirs "github.com/cloudfoundry/bosh-agent/settings/directories"
+	fakesettings "github.com/cloudfoundry/bosh-agent/settings/fakes"
 	boshassert "github.com/cloudfoundry/bosh-utils/assert"
 	boshcrypto "github.com/cloudfoundry/bosh-utils/crypto"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
 )
 
 var _ = Describe("FetchLogsWithSignedURLAction", func() {
 	var (
-		compressor    *fakecmd.FakeCompressor
-		copier        *fakecmd.FakeCopier
-		dirProvider   boshdirs.Provider
-		action        FetchLogsWithSignedURLAction
-		blobDelegator *fakeblobdelegator.FakeBlobstoreDelegator
+		compressor      *fakecmd.FakeCompressor
+		copier          *fakecmd.FakeCopier
+		dirProvider     boshdirs.Provider
+		action          FetchLogsWithSignedURLAction
+		blobDelegator   *fakeblobdelegator.FakeBlobstoreDelegator
+		settingsService *fakesettings.FakeSettingsService
+		runner          *fakesys.FakeCmdRunner
+		fs              *fakefs.FakeFileSystem
 	)
 
 	BeforeEach(func() {
@@ -30,8 +37,11 @@ var _ = Describe("FetchLogsWithSignedURLAction", func() {
 		dirProvider = boshdirs.NewProvider("/fake/dir")
 		copier = fakecmd.NewFakeCopier()
 		blobDelegator = &fakeblobdelegator.FakeBlobstoreDelegator{}
+		settingsService = &fakesettings.FakeSettingsService{}
+		runner = fakesys.NewFakeCmdRunner()
+		fs = fakefs.NewFakeFileSystem()
 
-		action = NewFetchLogsWithSignedURLAction(compressor, copier, dirProvider, blobDelegator)
+		action = NewFetchLogsWithSignedURLAction(compressor, copier, dirProvider, blobDelegator, settingsService, runner, fs)
 	})
 
 	AssertActionIsAsynchronous(action)
@@ -59,6 +69,8 @@ var _ = Describe("FetchLogsWithSignedURLAction", func() {
 				expectedPath = filepath.Join("/fake", "dir", "sys", "log")
 			case "agent":
 				expectedPath = filepath.Join("/fake", "dir", "bosh", "log")
+			case "system":
+				expectedPath = filepath.Join("/fake", "dir", "sys", "log")
 			}
 
 			Expect(copier.FilteredCopyToTempDir).To(boshassert.MatchPath(expectedPath))
@@ -72,12 +84,13 @@ var _ = Describe("FetchLogsWithSignedURLAction", func() {
 			Expect(headers).To(Equal(map[string]string{"key": "value"}))
 			Expect(actualTarballPath).To(Equal(compressor.CompressFilesInDirTarballPath))
 
-			boshassert.MatchesJSONString(GinkgoT(), logs, `{"sha1":"`+sha1+`"}`)
+			boshassert.MatchesJSONString(GinkgoT(), logs, `{"digest":"`+sha1+`","algorithm":"sha1","sha1":"`+sha1+`"}`)
 		}
 
 		It("logs errs if given invalid log type", func() {
 			_, err := action.Run(FetchLogsWithSignedURLRequest{LogType: "other-logs", Filters: []string{}})
 			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Invalid log type"))
 		})
 
 		It("agent logs with filters", func() {
@@ -104,6 +117,105 @@ var _ = Describe("FetchLogsWithSignedURLAction", func() {
 			testLogs("job", filters, expectedFilters)
 		})
 
+		It("job logs with include and exclude filters", func() {
+			filters := []string{"**/*.log*", "!**/*.1.gz"}
+			expectedFilters := []string{"**/*.log*", "!**/*.1.gz"}
+			testLogs("job", filters, expectedFilters)
+		})
+
+		It("job logs with only exclude filters include all other logs", func() {
+			filters := []string{"!**/*.1.gz"}
+			expectedFilters := []string{"**/*", "!**/*.1.gz"}
+			testLogs("job", filters, expectedFilters)
+		})
+
+		It("system logs without filters", func() {
+			filters := []string{}
+			expectedFilters := []string{"**/*"}
+			testLogs("system", filters, expectedFilters)
+		})
+
+		It("system logs with filters", func() {
+			filters := []string{"**/syslog*", "!**/*.gz"}
+			expectedFilters := []string{"**/syslog*", "!**/*.gz"}
+			testLogs("system", filters, expectedFilters)
+		})
+
+		It("returns the digest for the configured algorithm", func() {
+			settingsService.Settings.Env.Bosh.DigestAlgorithm = "sha256"
+			compressor.CompressFilesInDirTarballPath = "/fake-compressed-logs.tar"
+			err := fs.WriteFileString("/fake-compressed-logs.tar", "fake-logs")
+			Expect(err).ToNot(HaveOccurred())
+
+			expectedDigest, err := boshcrypto.DigestAlgorithmSHA256.CreateDigest(strings.NewReader("fake-logs"))
+			Expect(err).ToNot(HaveOccurred())
+
+			logs, err := action.Run(FetchLogsWithSignedURLRequest{SignedURL: "foobar", LogType: "job"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(logs).To(Equal(FetchLogsWithSignedURLResponse{
+				Digest:    expectedDigest.String(),
+				Algorithm: "sha256",
+			}))
+		})
+
+		It("prefers the digest algorithm given in the request", func() {
+			settingsService.Settings.Env.Bosh.DigestAlgorithm = "sha256"
+			compressor.CompressFilesInDirTarballPath = "/fake-compressed-logs.tar"
+			err := fs.WriteFileString("/fake-compressed-logs.tar", "fake-logs")
+			Expect(err).ToNot(HaveOccurred())
+
+			expectedDigest, err := boshcrypto.DigestAlgorithmSHA512.CreateDigest(strings.NewReader("fake-logs"))
+			Expect(err).ToNot(HaveOccurred())
+
+			logs, err := action.Run(FetchLogsWithSignedURLRequest{SignedURL: "foobar", LogType: "job", DigestAlgorithm: "sha512"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(logs.Digest).To(Equal(expectedDigest.String()))
+			Expect(logs.Algorithm).To(Equal("sha512"))
+		})
+
+		It("returns an error for an unsupported requested digest algorithm", func() {
+			_, err := action.Run(FetchLogsWithSignedURLRequest{SignedURL: "foobar", LogType: "job", DigestAlgorithm: "md5"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Unsupported digest algorithm 'md5'"))
+			Expect(blobDelegator.WriteCallCount()).To(Equal(0))
+		})
+
+		It("passes the content type given in the blobstore headers", func() {
+			_, err := action.Run(FetchLogsWithSignedURLRequest{SignedURL: "foobar", LogType: "job", BlobstoreHeaders: map[string]string{"content-type": "application/x-tar"}})
+			Expect(err).ToNot(HaveOccurred())
+
+			_, _, headers := blobDelegator.WriteArgsForCall(0)
+			Expect(headers).To(Equal(map[string]string{"content-type": "application/x-tar"}))
+		})
+
+		It("lets the blobstore check the existing blob when given a URL for it", func() {
+			settingsService.Settings.Env.Bosh.DigestAlgorithm = "sha256"
+			compressor.CompressFilesInDirTarballPath = "/fake-compressed-logs.tar"
+			err := fs.WriteFileString("/fake-compressed-logs.tar", "fake-logs")
+			Expect(err).ToNot(HaveOccurred())
+
+			expectedDigest, err := boshcrypto.DigestAlgorithmSHA256.CreateDigest(strings.NewReader("fake-logs"))
+			Expect(err).ToNot(HaveOccurred())
+			blobDelegator.WriteWithExistingBlobCheckReturns(boshcrypto.MustNewMultipleDigest(expectedDigest), nil)
+
+			logs, err := action.Run(FetchLogsWithSignedURLRequest{
+				SignedURL:             "foobar",
+				ExistingBlobSignedURL: "foobar-head",
+				LogType:               "job",
+				BlobstoreHeaders:      map[string]string{"key": "value"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(logs.Digest).To(Equal(expectedDigest.String()))
+
+			Expect(blobDelegator.WriteCallCount()).To(Equal(0))
+			signedURL, existingBlobSignedURL, tarballPath, headers, algorithms := blobDelegator.WriteWithExistingBlobCheckArgsForCall(0)
+			Expect(signedURL).To(Equal("foobar"))
+			Expect(existingBlobSignedURL).To(Equal("foobar-head"))
+			Expect(tarballPath).To(Equal("/fake-compressed-logs.tar"))
+			Expect(headers).To(Equal(map[string]string{"key": "value"}))
+			Expect(algorithms).To(Equal([]boshcrypto.Algorithm{boshcrypto.DigestAlgorithmSHA256}))
+		})
+
 		It("cleans up compressed package after uploading it to blobstore", func() {
 			var beforeCleanUpTarballPath, afterCleanUpTarballPath string
 
@@ -119,5 +231,42 @@ var _ = Describe("FetchLogsWithSignedURLAction", func() {
 			afterCleanUpTarballPath = compressor.CleanUpTarballPath
 			Expect(afterCleanUpTarballPath).To(Equal("/fake-compressed-logs.tar"))
 		})
+
+		Context("when a maximum tarball size is configured", func() {
+			BeforeEach(func() {
+				settingsService.Settings.Env.Bosh.Logs.MaxTarballSize = 10
+				copier.FilteredCopyToTempTempDir = "/fake-temp-dir"
+				fs.ReturnTempFile = fakefs.NewFakeFile("/fake-logs-tarball.tgz", fs)
+			})
+
+			It("uploads the streamed tarball when it is within the limit", func() {
+				runner.AddCmdResult("tar czf - -C /fake-temp-dir .", fakesys.FakeCmdResult{Stdout: "0123456789"})
+
+				_, err := action.Run(FetchLogsWithSignedURLRequest{SignedURL: "foobar", LogType: "job"})
+				Expect(err).ToNot(HaveOccurred())
+
+				_, tarballPath, _ := blobDelegator.WriteArgsForCall(0)
+				Expect(tarballPath).To(Equal("/fake-logs-tarball.tgz"))
+			})
+
+			It("aborts compression and removes the partial tarball when it exceeds the limit", func() {
+				runner.AddCmdResult("tar czf - -C /fake-temp-dir .", fakesys.FakeCmdResult{Stdout: "0123456789a"})
+
+				_, err := action.Run(FetchLogsWithSignedURLRequest{SignedURL: "foobar", LogType: "job"})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Logs exceed maximum size of 10 bytes"))
+
+				Expect(blobDelegator.WriteCallCount()).To(Equal(0))
+				Expect(fs.FileExists("/fake-logs-tarball.tgz")).To(BeFalse())
+			})
+
+			It("prefers the maximum tarball size given in the request", func() {
+				runner.AddCmdResult("tar czf - -C /fake-temp-dir .", fakesys.FakeCmdResult{Stdout: "0123456789"})
+
+				_, err := action.Run(FetchLogsWithSignedURLRequest{SignedURL: "foobar", LogType: "job", MaxTarballSize: 9})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Logs exceed maximum size of 9 bytes"))
+			})
+		})
 	})
 })
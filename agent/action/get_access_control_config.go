@@ -0,0 +1,142 @@
+package action
+
+import (
+	"errors"
+	"strings"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+// Keeps responses well below the message size accepted by the mbus
+const maxAccessControlConfigBytes = 64 * 1024
+
+// sshd directives that decide who may log in and how, compared case-insensitively
+var accessControlSSHDKeywords = map[string]bool{
+	"allowgroups":                     true,
+	"allowusers":                      true,
+	"authenticationmethods":           true,
+	"authorizedkeysfile":              true,
+	"challengeresponseauthentication": true,
+	"denygroups":                      true,
+	"denyusers":                       true,
+	"kbdinteractiveauthentication":    true,
+	"listenaddress":                   true,
+	"match":                           true,
+	"maxauthtries":                    true,
+	"passwordauthentication":          true,
+	"permitemptypasswords":            true,
+	"permitrootlogin":                 true,
+	"port":                            true,
+	"pubkeyauthentication":            true,
+	"usepam":                          true,
+}
+
+type AccessControlFile struct {
+	Path    string `json:"path"`
+	Exists  bool   `json:"exists"`
+	Content string `json:"content"`
+
+	// Set when content was cut off at the size limit
+	Truncated bool `json:"truncated"`
+}
+
+type GetAccessControlConfigResponse struct {
+	HostsAllow AccessControlFile `json:"hosts_allow"`
+	HostsDeny  AccessControlFile `json:"hosts_deny"`
+
+	// Only the lines of directives controlling access
+	SSHDConfig AccessControlFile `json:"sshd_config"`
+
+	PAMSSHD AccessControlFile `json:"pam_sshd"`
+}
+
+type GetAccessControlConfigAction struct {
+	fs boshsys.FileSystem
+}
+
+func NewGetAccessControlConfig(fs boshsys.FileSystem) GetAccessControlConfigAction {
+	return GetAccessControlConfigAction{fs: fs}
+}
+
+func (a GetAccessControlConfigAction) IsAsynchronous(_ ProtocolVersion) bool {
+	return false
+}
+
+func (a GetAccessControlConfigAction) IsPersistent() bool {
+	return false
+}
+
+func (a GetAccessControlConfigAction) IsLoggable() bool {
+	return true
+}
+
+func (a GetAccessControlConfigAction) Run() (GetAccessControlConfigResponse, error) {
+	var response GetAccessControlConfigResponse
+
+	files := []struct {
+		path   string
+		file   *AccessControlFile
+		filter func(string) string
+	}{
+		{"/etc/hosts.allow", &response.HostsAllow, nil},
+		{"/etc/hosts.deny", &response.HostsDeny, nil},
+		{"/etc/ssh/sshd_config", &response.SSHDConfig, filterSSHDAccessControlLines},
+		{"/etc/pam.d/sshd", &response.PAMSSHD, nil},
+	}
+
+	for _, f := range files {
+		*f.file = AccessControlFile{Path: f.path}
+
+		if !a.fs.FileExists(f.path) {
+			continue
+		}
+
+		content, err := a.fs.ReadFileString(f.path)
+		if err != nil {
+			return response, bosherr.WrapErrorf(err, "Reading '%s'", f.path)
+		}
+
+		if f.filter != nil {
+			content = f.filter(content)
+		}
+
+		f.file.Exists = true
+		f.file.Content = content
+
+		if len(content) > maxAccessControlConfigBytes {
+			f.file.Content = content[:maxAccessControlConfigBytes]
+			f.file.Truncated = true
+		}
+	}
+
+	return response, nil
+}
+
+func (a GetAccessControlConfigAction) Resume() (interface{}, error) {
+	return nil, errors.New("not supported")
+}
+
+func (a GetAccessControlConfigAction) Cancel() error {
+	return errors.New("not supported")
+}
+
+func filterSSHDAccessControlLines(sshdConfig string) string {
+	var lines []string
+
+	for _, line := range strings.Split(sshdConfig, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		// Directives may also be written as Keyword=value
+		keyword := strings.ToLower(strings.SplitN(fields[0], "=", 2)[0])
+
+		if accessControlSSHDKeywords[keyword] {
+			lines = append(lines, strings.TrimSpace(line))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
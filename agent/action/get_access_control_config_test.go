@@ -0,0 +1,107 @@
+package action_test
+
+import (
+	"errors"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-agent/agent/action"
+	fakefs "github.com/cloudfoundry/bosh-agent/platform/fakefs"
+)
+
+var _ = Describe("GetAccessControlConfigAction", func() {
+	var (
+		fs     *fakefs.FakeFileSystem
+		action GetAccessControlConfigAction
+	)
+
+	BeforeEach(func() {
+		fs = fakefs.NewFakeFileSystem()
+		action = NewGetAccessControlConfig(fs)
+	})
+
+	AssertActionIsNotAsynchronous(action)
+	AssertActionIsNotPersistent(action)
+	AssertActionIsLoggable(action)
+
+	AssertActionIsNotResumable(action)
+	AssertActionIsNotCancelable(action)
+
+	Describe("Run", func() {
+		It("returns the hosts access files, the access control lines of sshd_config and the sshd PAM config", func() {
+			err := fs.WriteFileString("/etc/hosts.allow", "sshd: 10.0.0.0/8\n")
+			Expect(err).ToNot(HaveOccurred())
+			err = fs.WriteFileString("/etc/hosts.deny", "ALL: ALL\n")
+			Expect(err).ToNot(HaveOccurred())
+			err = fs.WriteFileString("/etc/ssh/sshd_config", `# Managed by bosh
+Port 22
+Protocol 2
+HostKey /etc/ssh/ssh_host_rsa_key
+PermitRootLogin no
+#PasswordAuthentication yes
+PasswordAuthentication no
+UsePAM yes
+X11Forwarding no
+AllowGroups bosh_sshers
+ClientAliveInterval 300
+MaxAuthTries=3
+`)
+			Expect(err).ToNot(HaveOccurred())
+			err = fs.WriteFileString("/etc/pam.d/sshd", "@include common-auth\n")
+			Expect(err).ToNot(HaveOccurred())
+
+			response, err := action.Run()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response).To(Equal(GetAccessControlConfigResponse{
+				HostsAllow: AccessControlFile{Path: "/etc/hosts.allow", Exists: true, Content: "sshd: 10.0.0.0/8\n"},
+				HostsDeny:  AccessControlFile{Path: "/etc/hosts.deny", Exists: true, Content: "ALL: ALL\n"},
+				SSHDConfig: AccessControlFile{
+					Path:   "/etc/ssh/sshd_config",
+					Exists: true,
+					Content: `Port 22
+PermitRootLogin no
+PasswordAuthentication no
+UsePAM yes
+AllowGroups bosh_sshers
+MaxAuthTries=3`,
+				},
+				PAMSSHD: AccessControlFile{Path: "/etc/pam.d/sshd", Exists: true, Content: "@include common-auth\n"},
+			}))
+		})
+
+		It("reports missing files without failing", func() {
+			err := fs.WriteFileString("/etc/hosts.deny", "ALL: ALL\n")
+			Expect(err).ToNot(HaveOccurred())
+
+			response, err := action.Run()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.HostsAllow).To(Equal(AccessControlFile{Path: "/etc/hosts.allow"}))
+			Expect(response.HostsDeny.Exists).To(BeTrue())
+			Expect(response.SSHDConfig).To(Equal(AccessControlFile{Path: "/etc/ssh/sshd_config"}))
+			Expect(response.PAMSSHD).To(Equal(AccessControlFile{Path: "/etc/pam.d/sshd"}))
+		})
+
+		It("truncates files larger than 64KB", func() {
+			err := fs.WriteFileString("/etc/hosts.allow", strings.Repeat("sshd: 10.0.0.1\n", 10000))
+			Expect(err).ToNot(HaveOccurred())
+
+			response, err := action.Run()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.HostsAllow.Content).To(HaveLen(64 * 1024))
+			Expect(response.HostsAllow.Truncated).To(BeTrue())
+		})
+
+		It("returns an error when an existing file cannot be read", func() {
+			err := fs.WriteFileString("/etc/hosts.allow", "sshd: ALL\n")
+			Expect(err).ToNot(HaveOccurred())
+			fs.RegisterReadFileError("/etc/hosts.allow", errors.New("fake-read-error"))
+
+			_, err = action.Run()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Reading '/etc/hosts.allow'"))
+			Expect(err.Error()).To(ContainSubstring("fake-read-error"))
+		})
+	})
+})
@@ -0,0 +1,60 @@
+package action
+
+import (
+	"errors"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const redactedEnvironmentValue = "<redacted>"
+
+// Variable names that commonly hold credentials
+var secretEnvironmentNamePattern = regexp.MustCompile(`(?i)(password|passwd|secret|token|key|credential|auth)`)
+
+type GetAgentEnvironmentAction struct{}
+
+func NewGetAgentEnvironment() GetAgentEnvironmentAction {
+	return GetAgentEnvironmentAction{}
+}
+
+func (a GetAgentEnvironmentAction) IsAsynchronous(_ ProtocolVersion) bool {
+	return false
+}
+
+func (a GetAgentEnvironmentAction) IsPersistent() bool {
+	return false
+}
+
+func (a GetAgentEnvironmentAction) IsLoggable() bool {
+	return false
+}
+
+func (a GetAgentEnvironmentAction) Run() (map[string]string, error) {
+	environment := map[string]string{}
+
+	for _, variable := range os.Environ() {
+		parts := strings.SplitN(variable, "=", 2)
+
+		value := ""
+		if len(parts) == 2 {
+			value = parts[1]
+		}
+
+		if secretEnvironmentNamePattern.MatchString(parts[0]) {
+			value = redactedEnvironmentValue
+		}
+
+		environment[parts[0]] = value
+	}
+
+	return environment, nil
+}
+
+func (a GetAgentEnvironmentAction) Resume() (interface{}, error) {
+	return nil, errors.New("not supported")
+}
+
+func (a GetAgentEnvironmentAction) Cancel() error {
+	return errors.New("not supported")
+}
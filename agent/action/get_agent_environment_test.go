@@ -0,0 +1,69 @@
+package action_test
+
+import (
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-agent/agent/action"
+)
+
+var _ = Describe("GetAgentEnvironmentAction", func() {
+	var (
+		action GetAgentEnvironmentAction
+	)
+
+	BeforeEach(func() {
+		action = NewGetAgentEnvironment()
+
+		for name, value := range map[string]string{
+			"AGENT_ENV_TEST_PATH":          "/var/vcap/bosh/bin",
+			"AGENT_ENV_TEST_EMPTY":         "",
+			"AGENT_ENV_TEST_PASSWORD":      "fake-password",
+			"agent_env_test_client_secret": "fake-secret",
+			"AGENT_ENV_TEST_API_TOKEN":     "fake-token",
+			"AGENT_ENV_TEST_PRIVATE_KEY":   "fake-key",
+		} {
+			Expect(os.Setenv(name, value)).To(Succeed())
+		}
+	})
+
+	AfterEach(func() {
+		for _, name := range []string{
+			"AGENT_ENV_TEST_PATH",
+			"AGENT_ENV_TEST_EMPTY",
+			"AGENT_ENV_TEST_PASSWORD",
+			"agent_env_test_client_secret",
+			"AGENT_ENV_TEST_API_TOKEN",
+			"AGENT_ENV_TEST_PRIVATE_KEY",
+		} {
+			Expect(os.Unsetenv(name)).To(Succeed())
+		}
+	})
+
+	AssertActionIsNotAsynchronous(action)
+	AssertActionIsNotPersistent(action)
+	AssertActionIsNotLoggable(action)
+
+	AssertActionIsNotResumable(action)
+	AssertActionIsNotCancelable(action)
+
+	Describe("Run", func() {
+		It("returns non-secret variables as is", func() {
+			environment, err := action.Run()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(environment).To(HaveKeyWithValue("AGENT_ENV_TEST_PATH", "/var/vcap/bosh/bin"))
+			Expect(environment).To(HaveKeyWithValue("AGENT_ENV_TEST_EMPTY", ""))
+		})
+
+		It("redacts values of secret-named variables", func() {
+			environment, err := action.Run()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(environment).To(HaveKeyWithValue("AGENT_ENV_TEST_PASSWORD", "<redacted>"))
+			Expect(environment).To(HaveKeyWithValue("agent_env_test_client_secret", "<redacted>"))
+			Expect(environment).To(HaveKeyWithValue("AGENT_ENV_TEST_API_TOKEN", "<redacted>"))
+			Expect(environment).To(HaveKeyWithValue("AGENT_ENV_TEST_PRIVATE_KEY", "<redacted>"))
+		})
+	})
+})
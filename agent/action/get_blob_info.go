@@ -0,0 +1,111 @@
+package action
+
+import (
+	"errors"
+	"net/http"
+
+	boshagentblobstore "github.com/cloudfoundry/bosh-agent/agent/blobstore"
+	"github.com/cloudfoundry/bosh-agent/agent/httpblobprovider"
+	blobdelegator "github.com/cloudfoundry/bosh-agent/agent/httpblobprovider/blobstore_delegator"
+	boshcrypto "github.com/cloudfoundry/bosh-utils/crypto"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+)
+
+type GetBlobInfoRequest struct {
+	BlobID           string            `json:"blob_id"`
+	SignedURL        string            `json:"signed_url"`
+	BlobstoreHeaders map[string]string `json:"blobstore_headers"`
+}
+
+type GetBlobInfoResponse struct {
+	Size int64 `json:"size"`
+
+	// Only known for blobs stored by the agent, object stores do not report
+	// a SHA1 without downloading the blob
+	SHA1 string `json:"sha1,omitempty"`
+}
+
+type GetBlobInfoAction struct {
+	blobManager   boshagentblobstore.BlobManagerInterface
+	blobDelegator blobdelegator.BlobstoreDelegator
+}
+
+func NewGetBlobInfo(
+	blobManager boshagentblobstore.BlobManagerInterface,
+	blobDelegator blobdelegator.BlobstoreDelegator,
+) GetBlobInfoAction {
+	return GetBlobInfoAction{
+		blobManager:   blobManager,
+		blobDelegator: blobDelegator,
+	}
+}
+
+func (a GetBlobInfoAction) IsAsynchronous(_ ProtocolVersion) bool {
+	return false
+}
+
+func (a GetBlobInfoAction) IsPersistent() bool {
+	return false
+}
+
+func (a GetBlobInfoAction) IsLoggable() bool {
+	return true
+}
+
+func (a GetBlobInfoAction) Run(request GetBlobInfoRequest) (GetBlobInfoResponse, error) {
+	if request.SignedURL != "" {
+		return a.signedURLBlobInfo(request.SignedURL, request.BlobstoreHeaders)
+	}
+
+	if request.BlobID == "" {
+		return GetBlobInfoResponse{}, bosherr.Error("Either a blob ID or a signed URL must be given")
+	}
+
+	return a.localBlobInfo(request.BlobID)
+}
+
+func (a GetBlobInfoAction) Resume() (interface{}, error) {
+	return nil, errors.New("not supported")
+}
+
+func (a GetBlobInfoAction) Cancel() error {
+	return errors.New("not supported")
+}
+
+func (a GetBlobInfoAction) signedURLBlobInfo(signedURL string, headers map[string]string) (GetBlobInfoResponse, error) {
+	size, err := a.blobDelegator.Size(signedURL, headers)
+	if err != nil {
+		if statusErr, ok := err.(httpblobprovider.HTTPStatusError); ok && statusErr.StatusCode == http.StatusNotFound {
+			return GetBlobInfoResponse{}, bosherr.Error("Blob not found at the signed URL")
+		}
+
+		// Signed URLs are left out of errors since they carry credentials
+		return GetBlobInfoResponse{}, bosherr.WrapError(err, "Getting blob size")
+	}
+
+	return GetBlobInfoResponse{Size: size}, nil
+}
+
+func (a GetBlobInfoAction) localBlobInfo(blobID string) (GetBlobInfoResponse, error) {
+	if !a.blobManager.BlobExists(blobID) {
+		return GetBlobInfoResponse{}, bosherr.Errorf("Blob '%s' not found", blobID)
+	}
+
+	file, _, err := a.blobManager.Fetch(blobID)
+	if err != nil {
+		return GetBlobInfoResponse{}, bosherr.WrapErrorf(err, "Opening blob '%s'", blobID)
+	}
+	defer file.Close()
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return GetBlobInfoResponse{}, bosherr.WrapErrorf(err, "Checking size of blob '%s'", blobID)
+	}
+
+	digest, err := boshcrypto.DigestAlgorithmSHA1.CreateDigest(file)
+	if err != nil {
+		return GetBlobInfoResponse{}, bosherr.WrapErrorf(err, "Calculating SHA1 of blob '%s'", blobID)
+	}
+
+	return GetBlobInfoResponse{Size: fileInfo.Size(), SHA1: digest.String()}, nil
+}
@@ -0,0 +1,121 @@
+package action_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-agent/agent/action"
+	fakeblobmanager "github.com/cloudfoundry/bosh-agent/agent/blobstore/blobstorefakes"
+	"github.com/cloudfoundry/bosh-agent/agent/httpblobprovider"
+	fakeblobdelegator "github.com/cloudfoundry/bosh-agent/agent/httpblobprovider/blobstore_delegator/blobstore_delegatorfakes"
+	fakefs "github.com/cloudfoundry/bosh-agent/platform/fakefs"
+)
+
+var _ = Describe("GetBlobInfoAction", func() {
+	var (
+		blobManager   *fakeblobmanager.FakeBlobManagerInterface
+		blobDelegator *fakeblobdelegator.FakeBlobstoreDelegator
+		action        GetBlobInfoAction
+	)
+
+	BeforeEach(func() {
+		blobManager = &fakeblobmanager.FakeBlobManagerInterface{}
+		blobDelegator = &fakeblobdelegator.FakeBlobstoreDelegator{}
+		action = NewGetBlobInfo(blobManager, blobDelegator)
+	})
+
+	AssertActionIsNotAsynchronous(action)
+	AssertActionIsNotPersistent(action)
+	AssertActionIsLoggable(action)
+
+	AssertActionIsNotResumable(action)
+	AssertActionIsNotCancelable(action)
+
+	Describe("Run", func() {
+		Context("when a blob ID is given", func() {
+			var fs *fakefs.FakeFileSystem
+
+			BeforeEach(func() {
+				fs = fakefs.NewFakeFileSystem()
+				Expect(fs.WriteFileString("/fake-blobs/fake-blob-id", "abc")).To(Succeed())
+
+				blobManager.BlobExistsReturns(true)
+				blobManager.FetchReturns(fakefs.NewFakeFile("/fake-blobs/fake-blob-id", fs), 200, nil)
+			})
+
+			It("returns the size and sha1 of the locally stored blob", func() {
+				response, err := action.Run(GetBlobInfoRequest{BlobID: "fake-blob-id"})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(response).To(Equal(GetBlobInfoResponse{
+					Size: 3,
+					SHA1: "a9993e364706816aba3e25717850c26c9cd0d89d",
+				}))
+
+				Expect(blobManager.BlobExistsArgsForCall(0)).To(Equal("fake-blob-id"))
+				Expect(blobManager.FetchArgsForCall(0)).To(Equal("fake-blob-id"))
+				Expect(blobDelegator.SizeCallCount()).To(Equal(0))
+			})
+
+			It("returns an error when the blob does not exist", func() {
+				blobManager.BlobExistsReturns(false)
+
+				_, err := action.Run(GetBlobInfoRequest{BlobID: "fake-blob-id"})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Blob 'fake-blob-id' not found"))
+
+				Expect(blobManager.FetchCallCount()).To(Equal(0))
+			})
+
+			It("returns an error when the blob cannot be opened", func() {
+				blobManager.FetchReturns(nil, 500, errors.New("fake-fetch-error"))
+
+				_, err := action.Run(GetBlobInfoRequest{BlobID: "fake-blob-id"})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Opening blob 'fake-blob-id': fake-fetch-error"))
+			})
+		})
+
+		Context("when a signed URL is given", func() {
+			It("returns the size reported by the object store", func() {
+				blobDelegator.SizeReturns(1024, nil)
+
+				response, err := action.Run(GetBlobInfoRequest{
+					SignedURL:        "fake-signed-url",
+					BlobstoreHeaders: map[string]string{"key": "value"},
+				})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(response).To(Equal(GetBlobInfoResponse{Size: 1024}))
+
+				signedURL, headers := blobDelegator.SizeArgsForCall(0)
+				Expect(signedURL).To(Equal("fake-signed-url"))
+				Expect(headers).To(Equal(map[string]string{"key": "value"}))
+
+				Expect(blobManager.FetchCallCount()).To(Equal(0))
+			})
+
+			It("returns an error when the blob does not exist", func() {
+				blobDelegator.SizeReturns(0, httpblobprovider.HTTPStatusError{Method: "HEAD", StatusCode: 404})
+
+				_, err := action.Run(GetBlobInfoRequest{SignedURL: "fake-signed-url"})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Blob not found at the signed URL"))
+			})
+
+			It("returns an error when the size cannot be read", func() {
+				blobDelegator.SizeReturns(0, errors.New("fake-size-error"))
+
+				_, err := action.Run(GetBlobInfoRequest{SignedURL: "fake-signed-url"})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Getting blob size: fake-size-error"))
+			})
+		})
+
+		It("returns an error when neither a blob ID nor a signed URL is given", func() {
+			_, err := action.Run(GetBlobInfoRequest{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Either a blob ID or a signed URL must be given"))
+		})
+	})
+})
@@ -0,0 +1,79 @@
+package action
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+)
+
+const defaultClockSkewThresholdInSeconds = 60
+
+type GetClockSkewArgs struct {
+	DirectorTime time.Time `json:"director_time"`
+
+	// Skew above which the response is flagged; defaults to 60 seconds
+	ThresholdInSeconds float64 `json:"threshold_in_seconds"`
+}
+
+type GetClockSkewResponse struct {
+	AgentTime          time.Time `json:"agent_time"`
+	DirectorTime       time.Time `json:"director_time"`
+	SkewInSeconds      float64   `json:"skew_in_seconds"`
+	ThresholdInSeconds float64   `json:"threshold_in_seconds"`
+	ExceedsThreshold   bool      `json:"exceeds_threshold"`
+}
+
+type GetClockSkewAction struct {
+	clock clock.Clock
+}
+
+func NewGetClockSkew(clock clock.Clock) GetClockSkewAction {
+	return GetClockSkewAction{clock: clock}
+}
+
+func (a GetClockSkewAction) IsAsynchronous(_ ProtocolVersion) bool {
+	return false
+}
+
+func (a GetClockSkewAction) IsPersistent() bool {
+	return false
+}
+
+func (a GetClockSkewAction) IsLoggable() bool {
+	return true
+}
+
+// Run reports how far the agent clock is ahead of (positive skew) or
+// behind (negative skew) the supplied director time
+func (a GetClockSkewAction) Run(args GetClockSkewArgs) (GetClockSkewResponse, error) {
+	if args.DirectorTime.IsZero() {
+		return GetClockSkewResponse{}, bosherr.Error("Director time must be provided")
+	}
+
+	threshold := args.ThresholdInSeconds
+	if threshold <= 0 {
+		threshold = defaultClockSkewThresholdInSeconds
+	}
+
+	agentTime := a.clock.Now()
+	skew := agentTime.Sub(args.DirectorTime).Seconds()
+
+	return GetClockSkewResponse{
+		AgentTime:          agentTime,
+		DirectorTime:       args.DirectorTime,
+		SkewInSeconds:      skew,
+		ThresholdInSeconds: threshold,
+		ExceedsThreshold:   math.Abs(skew) > threshold,
+	}, nil
+}
+
+func (a GetClockSkewAction) Resume() (interface{}, error) {
+	return nil, errors.New("not supported")
+}
+
+func (a GetClockSkewAction) Cancel() error {
+	return errors.New("not supported")
+}
@@ -0,0 +1,69 @@
+package action_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-agent/agent/action"
+)
+
+var _ = Describe("GetClockSkewAction", func() {
+	var (
+		agentTime time.Time
+		action    GetClockSkewAction
+	)
+
+	BeforeEach(func() {
+		agentTime = time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+		action = NewGetClockSkew(fakeclock.NewFakeClock(agentTime))
+	})
+
+	AssertActionIsNotAsynchronous(action)
+	AssertActionIsNotPersistent(action)
+	AssertActionIsLoggable(action)
+
+	AssertActionIsNotResumable(action)
+	AssertActionIsNotCancelable(action)
+
+	Describe("Run", func() {
+		It("reports skew within the default tolerance", func() {
+			directorTime := agentTime.Add(-30 * time.Second)
+
+			response, err := action.Run(GetClockSkewArgs{DirectorTime: directorTime})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response).To(Equal(GetClockSkewResponse{
+				AgentTime:          agentTime,
+				DirectorTime:       directorTime,
+				SkewInSeconds:      30,
+				ThresholdInSeconds: 60,
+				ExceedsThreshold:   false,
+			}))
+		})
+
+		It("flags skew beyond the default tolerance when the agent is behind", func() {
+			response, err := action.Run(GetClockSkewArgs{DirectorTime: agentTime.Add(90 * time.Second)})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.SkewInSeconds).To(Equal(float64(-90)))
+			Expect(response.ExceedsThreshold).To(BeTrue())
+		})
+
+		It("uses the requested threshold", func() {
+			response, err := action.Run(GetClockSkewArgs{
+				DirectorTime:       agentTime.Add(-10 * time.Second),
+				ThresholdInSeconds: 5,
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.ThresholdInSeconds).To(Equal(float64(5)))
+			Expect(response.ExceedsThreshold).To(BeTrue())
+		})
+
+		It("returns an error when the director time is missing", func() {
+			_, err := action.Run(GetClockSkewArgs{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Director time must be provided"))
+		})
+	})
+})
@@ -0,0 +1,188 @@
+package action
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+const (
+	diskStatsPath = "/proc/diskstats"
+
+	// /proc/diskstats always counts in 512 byte sectors regardless of the device
+	diskStatsSectorSize = 512
+
+	maxDiskIOStatsSampleInterval = 60
+)
+
+type GetDiskIOStatsOptions struct {
+	// Seconds between two samples used to compute rates; zero only reports counters
+	SampleInterval int `json:"sample_interval"`
+}
+
+type GetDiskIOStatsResponse struct {
+	Devices []DiskIOStats `json:"devices"`
+}
+
+// Counters are cumulative since boot as found in /proc/diskstats
+type DiskIOStats struct {
+	Device string `json:"device"`
+
+	Reads       uint64 `json:"reads"`
+	ReadSectors uint64 `json:"read_sectors"`
+
+	Writes       uint64 `json:"writes"`
+	WriteSectors uint64 `json:"write_sectors"`
+
+	IOTimeMS uint64 `json:"io_time_ms"`
+
+	// Only present when a sample interval was given
+	Rates *DiskIORates `json:"rates,omitempty"`
+}
+
+type DiskIORates struct {
+	ReadsPerSecond      float64 `json:"reads_per_second"`
+	WritesPerSecond     float64 `json:"writes_per_second"`
+	ReadBytesPerSecond  float64 `json:"read_bytes_per_second"`
+	WriteBytesPerSecond float64 `json:"write_bytes_per_second"`
+
+	// Percentage of the interval during which the device was busy
+	UtilizationPercent float64 `json:"utilization_percent"`
+}
+
+type GetDiskIOStatsAction struct {
+	fs          boshsys.FileSystem
+	timeService clock.Clock
+}
+
+func NewGetDiskIOStats(fs boshsys.FileSystem, timeService clock.Clock) GetDiskIOStatsAction {
+	return GetDiskIOStatsAction{fs: fs, timeService: timeService}
+}
+
+// Sampling may take up to a minute so the action runs as a task
+func (a GetDiskIOStatsAction) IsAsynchronous(_ ProtocolVersion) bool {
+	return true
+}
+
+func (a GetDiskIOStatsAction) IsPersistent() bool {
+	return false
+}
+
+func (a GetDiskIOStatsAction) IsLoggable() bool {
+	return true
+}
+
+func (a GetDiskIOStatsAction) Run(options GetDiskIOStatsOptions) (GetDiskIOStatsResponse, error) {
+	if options.SampleInterval < 0 || options.SampleInterval > maxDiskIOStatsSampleInterval {
+		return GetDiskIOStatsResponse{}, bosherr.Errorf(
+			"Invalid sample interval %d, must be between 0 and %d seconds", options.SampleInterval, maxDiskIOStatsSampleInterval)
+	}
+
+	devices, err := a.readDiskStats()
+	if err != nil {
+		return GetDiskIOStatsResponse{}, err
+	}
+
+	if options.SampleInterval == 0 {
+		return GetDiskIOStatsResponse{Devices: devices}, nil
+	}
+
+	interval := time.Duration(options.SampleInterval) * time.Second
+
+	a.timeService.Sleep(interval)
+
+	laterDevices, err := a.readDiskStats()
+	if err != nil {
+		return GetDiskIOStatsResponse{}, err
+	}
+
+	earlierByName := map[string]DiskIOStats{}
+	for _, device := range devices {
+		earlierByName[device.Device] = device
+	}
+
+	for i, device := range laterDevices {
+		// Devices attached between the two samples have nothing to compare against
+		if earlier, found := earlierByName[device.Device]; found {
+			laterDevices[i].Rates = diskIORates(earlier, device, interval)
+		}
+	}
+
+	return GetDiskIOStatsResponse{Devices: laterDevices}, nil
+}
+
+func (a GetDiskIOStatsAction) Resume() (interface{}, error) {
+	return nil, errors.New("not supported")
+}
+
+func (a GetDiskIOStatsAction) Cancel() error {
+	return errors.New("not supported")
+}
+
+func (a GetDiskIOStatsAction) readDiskStats() ([]DiskIOStats, error) {
+	diskStats, err := a.fs.ReadFileString(diskStatsPath)
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Reading '%s'", diskStatsPath)
+	}
+
+	devices := []DiskIOStats{}
+
+	// Lines look like "   8       0 sda 4123 12 301234 2311 9876 54 812345 10234 0 8123 12545"
+	for _, line := range strings.Split(diskStats, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if len(fields) < 14 {
+			return nil, bosherr.Errorf("Unexpected line '%s' in %s", strings.TrimSpace(line), diskStatsPath)
+		}
+
+		device := DiskIOStats{Device: fields[2]}
+
+		counters := map[int]*uint64{
+			3:  &device.Reads,
+			5:  &device.ReadSectors,
+			7:  &device.Writes,
+			9:  &device.WriteSectors,
+			12: &device.IOTimeMS,
+		}
+
+		for i, counter := range counters {
+			*counter, err = strconv.ParseUint(fields[i], 10, 64)
+			if err != nil {
+				return nil, bosherr.WrapErrorf(err, "Parsing counters of '%s' in %s", device.Device, diskStatsPath)
+			}
+		}
+
+		devices = append(devices, device)
+	}
+
+	return devices, nil
+}
+
+func diskIORates(earlier, later DiskIOStats, interval time.Duration) *DiskIORates {
+	seconds := interval.Seconds()
+
+	return &DiskIORates{
+		ReadsPerSecond:      float64(counterDelta(earlier.Reads, later.Reads)) / seconds,
+		WritesPerSecond:     float64(counterDelta(earlier.Writes, later.Writes)) / seconds,
+		ReadBytesPerSecond:  float64(counterDelta(earlier.ReadSectors, later.ReadSectors)*diskStatsSectorSize) / seconds,
+		WriteBytesPerSecond: float64(counterDelta(earlier.WriteSectors, later.WriteSectors)*diskStatsSectorSize) / seconds,
+		UtilizationPercent:  float64(counterDelta(earlier.IOTimeMS, later.IOTimeMS)) / (seconds * 1000) * 100,
+	}
+}
+
+// counterDelta treats a counter that went backwards (e.g. a re-attached device) as idle
+func counterDelta(earlier, later uint64) uint64 {
+	if later < earlier {
+		return 0
+	}
+	return later - earlier
+}
@@ -0,0 +1,132 @@
+package action_test
+
+import (
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-agent/agent/action"
+	fakefs "github.com/cloudfoundry/bosh-agent/platform/fakefs"
+)
+
+var _ = Describe("GetDiskIOStatsAction", func() {
+	var (
+		fs          *fakefs.FakeFileSystem
+		timeService *fakeclock.FakeClock
+		action      GetDiskIOStatsAction
+	)
+
+	BeforeEach(func() {
+		fs = fakefs.NewFakeFileSystem()
+		timeService = fakeclock.NewFakeClock(time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC))
+		action = NewGetDiskIOStats(fs, timeService)
+	})
+
+	AssertActionIsAsynchronous(action)
+	AssertActionIsNotPersistent(action)
+	AssertActionIsLoggable(action)
+
+	AssertActionIsNotResumable(action)
+	AssertActionIsNotCancelable(action)
+
+	Describe("Run", func() {
+		BeforeEach(func() {
+			err := fs.WriteFileString("/proc/diskstats", `   8       0 sda 4123 12 301234 2311 9876 54 812345 10234 0 8123 12545
+   8       1 sda1 4000 12 300000 2300 9800 54 810000 10200 0 8100 12500
+   8      16 sdb 100 0 2000 50 200 0 4000 80 0 120 130 0 0 0 0
+`)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("reports the cumulative counters of each device from /proc/diskstats", func() {
+			response, err := action.Run(GetDiskIOStatsOptions{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response).To(Equal(GetDiskIOStatsResponse{
+				Devices: []DiskIOStats{
+					{Device: "sda", Reads: 4123, ReadSectors: 301234, Writes: 9876, WriteSectors: 812345, IOTimeMS: 8123},
+					{Device: "sda1", Reads: 4000, ReadSectors: 300000, Writes: 9800, WriteSectors: 810000, IOTimeMS: 8100},
+					{Device: "sdb", Reads: 100, ReadSectors: 2000, Writes: 200, WriteSectors: 4000, IOTimeMS: 120},
+				},
+			}))
+		})
+
+		It("computes rates across two samples taken the sample interval apart", func() {
+			done := make(chan struct{})
+
+			var (
+				response GetDiskIOStatsResponse
+				err      error
+			)
+
+			go func() {
+				defer close(done)
+				response, err = action.Run(GetDiskIOStatsOptions{SampleInterval: 2})
+			}()
+
+			Eventually(timeService.WatcherCount).Should(Equal(1))
+
+			writeErr := fs.WriteFileString("/proc/diskstats", `   8       0 sda 4323 12 305234 2411 10276 54 820345 10434 0 9123 13545
+   8      16 sdb 100 0 2000 50 200 0 4000 80 0 120 130 0 0 0 0
+   8      32 sdc 10 0 80 5 0 0 0 0 0 5 5
+`)
+			Expect(writeErr).ToNot(HaveOccurred())
+
+			timeService.Increment(2 * time.Second)
+			Eventually(done).Should(BeClosed())
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Devices).To(HaveLen(3))
+
+			Expect(response.Devices[0].Device).To(Equal("sda"))
+			Expect(response.Devices[0].Reads).To(Equal(uint64(4323)))
+			Expect(response.Devices[0].Rates).To(Equal(&DiskIORates{
+				ReadsPerSecond:      100,
+				WritesPerSecond:     200,
+				ReadBytesPerSecond:  1024000,
+				WriteBytesPerSecond: 2048000,
+				UtilizationPercent:  50,
+			}))
+
+			Expect(response.Devices[1].Device).To(Equal("sdb"))
+			Expect(response.Devices[1].Rates).To(Equal(&DiskIORates{}))
+
+			Expect(response.Devices[2].Device).To(Equal("sdc"))
+			Expect(response.Devices[2].Rates).To(BeNil())
+		})
+
+		It("rejects a sample interval above a minute", func() {
+			_, err := action.Run(GetDiskIOStatsOptions{SampleInterval: 61})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Invalid sample interval 61, must be between 0 and 60 seconds"))
+		})
+
+		It("returns an error when a line has too few fields", func() {
+			err := fs.WriteFileString("/proc/diskstats", "   8       0 sda 4123 12\n")
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = action.Run(GetDiskIOStatsOptions{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Unexpected line '8       0 sda 4123 12' in /proc/diskstats"))
+		})
+
+		It("returns an error when a counter cannot be parsed", func() {
+			err := fs.WriteFileString("/proc/diskstats", "   8       0 sda 4123 12 301234 2311 nope 54 812345 10234 0 8123 12545\n")
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = action.Run(GetDiskIOStatsOptions{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Parsing counters of 'sda' in /proc/diskstats"))
+		})
+
+		It("returns an error when /proc/diskstats cannot be read", func() {
+			fs.RegisterReadFileError("/proc/diskstats", errors.New("fake-read-error"))
+
+			_, err := action.Run(GetDiskIOStatsOptions{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-read-error"))
+		})
+	})
+})
@@ -0,0 +1,76 @@
+package action
+
+import (
+	"errors"
+
+	boshstats "github.com/cloudfoundry/bosh-agent/platform/stats"
+	boshdirs "github.com/cloudfoundry/bosh-agent/settings/directories"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+)
+
+type DiskUsage struct {
+	UsedBytes  uint64 `json:"used_bytes"`
+	TotalBytes uint64 `json:"total_bytes"`
+
+	UsedInodes  uint64 `json:"used_inodes"`
+	TotalInodes uint64 `json:"total_inodes"`
+}
+
+type GetDiskUsageAction struct {
+	statsCollector boshstats.Collector
+	dirProvider    boshdirs.Provider
+}
+
+func NewGetDiskUsage(statsCollector boshstats.Collector, dirProvider boshdirs.Provider) GetDiskUsageAction {
+	return GetDiskUsageAction{
+		statsCollector: statsCollector,
+		dirProvider:    dirProvider,
+	}
+}
+
+func (a GetDiskUsageAction) IsAsynchronous(_ ProtocolVersion) bool {
+	return false
+}
+
+func (a GetDiskUsageAction) IsPersistent() bool {
+	return false
+}
+
+func (a GetDiskUsageAction) IsLoggable() bool {
+	return true
+}
+
+// Run reports the usage of the root, ephemeral and persistent disk keyed by
+// mount path. Like the vitals, the ephemeral and persistent disk are left out
+// when they are not mounted while the root filesystem must always be found.
+func (a GetDiskUsageAction) Run() (map[string]DiskUsage, error) {
+	usages := map[string]DiskUsage{}
+
+	for _, path := range []string{"/", a.dirProvider.DataDir(), a.dirProvider.StoreDir()} {
+		stats, err := a.statsCollector.GetDiskStats(path)
+		if err != nil {
+			if path == "/" {
+				return nil, bosherr.WrapError(err, "Getting disk usage for /")
+			}
+			continue
+		}
+
+		// The stats collector reports disk usage in kilobytes
+		usages[path] = DiskUsage{
+			UsedBytes:   stats.DiskUsage.Used * 1024,
+			TotalBytes:  stats.DiskUsage.Total * 1024,
+			UsedInodes:  stats.InodeUsage.Used,
+			TotalInodes: stats.InodeUsage.Total,
+		}
+	}
+
+	return usages, nil
+}
+
+func (a GetDiskUsageAction) Resume() (interface{}, error) {
+	return nil, errors.New("not supported")
+}
+
+func (a GetDiskUsageAction) Cancel() error {
+	return errors.New("not supported")
+}
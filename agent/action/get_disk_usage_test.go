@@ -0,0 +1,89 @@
+package action_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-agent/agent/action"
+	boshstats "github.com/cloudfoundry/bosh-agent/platform/stats"
+	fakestats "github.com/cloudfoundry/bosh-agent/platform/stats/fakes"
+	boshdirs "github.com/cloudfoundry/bosh-agent/settings/directories"
+)
+
+var _ = Describe("GetDiskUsageAction", func() {
+	var (
+		statsCollector *fakestats.FakeCollector
+		action         GetDiskUsageAction
+	)
+
+	BeforeEach(func() {
+		statsCollector = &fakestats.FakeCollector{
+			DiskStats: map[string]boshstats.DiskStats{
+				"/": {
+					DiskUsage:  boshstats.Usage{Used: 100, Total: 200},
+					InodeUsage: boshstats.Usage{Used: 10, Total: 50},
+				},
+				"/var/vcap/data": {
+					DiskUsage:  boshstats.Usage{Used: 15, Total: 60},
+					InodeUsage: boshstats.Usage{Used: 20, Total: 80},
+				},
+				"/var/vcap/store": {
+					DiskUsage:  boshstats.Usage{Used: 2, Total: 4},
+					InodeUsage: boshstats.Usage{Used: 3, Total: 12},
+				},
+			},
+		}
+		action = NewGetDiskUsage(statsCollector, boshdirs.NewProvider("/var/vcap"))
+	})
+
+	AssertActionIsNotAsynchronous(action)
+	AssertActionIsNotPersistent(action)
+	AssertActionIsLoggable(action)
+
+	AssertActionIsNotResumable(action)
+	AssertActionIsNotCancelable(action)
+
+	Describe("Run", func() {
+		It("returns the usage in bytes and inodes keyed by mount path", func() {
+			usages, err := action.Run()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(usages).To(Equal(map[string]DiskUsage{
+				"/": {
+					UsedBytes:   100 * 1024,
+					TotalBytes:  200 * 1024,
+					UsedInodes:  10,
+					TotalInodes: 50,
+				},
+				"/var/vcap/data": {
+					UsedBytes:   15 * 1024,
+					TotalBytes:  60 * 1024,
+					UsedInodes:  20,
+					TotalInodes: 80,
+				},
+				"/var/vcap/store": {
+					UsedBytes:   2 * 1024,
+					TotalBytes:  4 * 1024,
+					UsedInodes:  3,
+					TotalInodes: 12,
+				},
+			}))
+		})
+
+		It("leaves out disks that are not mounted", func() {
+			delete(statsCollector.DiskStats, "/var/vcap/store")
+
+			usages, err := action.Run()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(usages).To(HaveLen(2))
+			Expect(usages).ToNot(HaveKey("/var/vcap/store"))
+		})
+
+		It("returns an error when the root filesystem usage cannot be read", func() {
+			delete(statsCollector.DiskStats, "/")
+
+			_, err := action.Run()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Getting disk usage for /: Disk not found"))
+		})
+	})
+})
@@ -0,0 +1,78 @@
+package action
+
+import (
+	"errors"
+	"strings"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+const (
+	FirewallBackendNftables = "nftables"
+	FirewallBackendIptables = "iptables"
+)
+
+type GetFirewallRulesResponse struct {
+	Backend string `json:"backend"`
+
+	// Ruleset as printed by nft list ruleset or iptables-save
+	Ruleset string `json:"ruleset"`
+}
+
+type GetFirewallRulesAction struct {
+	runner boshsys.CmdRunner
+}
+
+func NewGetFirewallRules(runner boshsys.CmdRunner) GetFirewallRulesAction {
+	return GetFirewallRulesAction{runner: runner}
+}
+
+func (a GetFirewallRulesAction) IsAsynchronous(_ ProtocolVersion) bool {
+	return false
+}
+
+func (a GetFirewallRulesAction) IsPersistent() bool {
+	return false
+}
+
+func (a GetFirewallRulesAction) IsLoggable() bool {
+	return true
+}
+
+// Run prefers nftables and only falls back to iptables when nft is missing or
+// has no rules, since the legacy iptables tables are not shown by nft
+func (a GetFirewallRulesAction) Run() (GetFirewallRulesResponse, error) {
+	hasNft := a.runner.CommandExists("nft")
+	hasIptables := a.runner.CommandExists("iptables-save")
+
+	if !hasNft && !hasIptables {
+		return GetFirewallRulesResponse{}, bosherr.Error("Neither nft nor iptables-save is available")
+	}
+
+	if hasNft {
+		stdout, _, _, err := a.runner.RunCommand("nft", "list", "ruleset")
+		if err != nil {
+			return GetFirewallRulesResponse{}, bosherr.WrapError(err, "Running nft")
+		}
+
+		if strings.TrimSpace(stdout) != "" || !hasIptables {
+			return GetFirewallRulesResponse{Backend: FirewallBackendNftables, Ruleset: stdout}, nil
+		}
+	}
+
+	stdout, _, _, err := a.runner.RunCommand("iptables-save")
+	if err != nil {
+		return GetFirewallRulesResponse{}, bosherr.WrapError(err, "Running iptables-save")
+	}
+
+	return GetFirewallRulesResponse{Backend: FirewallBackendIptables, Ruleset: stdout}, nil
+}
+
+func (a GetFirewallRulesAction) Resume() (interface{}, error) {
+	return nil, errors.New("not supported")
+}
+
+func (a GetFirewallRulesAction) Cancel() error {
+	return errors.New("not supported")
+}
@@ -0,0 +1,111 @@
+package action_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-agent/agent/action"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+)
+
+var _ = Describe("GetFirewallRulesAction", func() {
+	const (
+		nftRuleset = `table inet filter {
+	chain input {
+		type filter hook input priority 0; policy accept;
+		tcp dport 22 accept
+	}
+}
+`
+		iptablesRuleset = `*filter
+:INPUT ACCEPT [0:0]
+-A INPUT -p tcp -m tcp --dport 22 -j ACCEPT
+COMMIT
+`
+	)
+
+	var (
+		cmdRunner *fakesys.FakeCmdRunner
+		action    GetFirewallRulesAction
+	)
+
+	BeforeEach(func() {
+		cmdRunner = fakesys.NewFakeCmdRunner()
+		action = NewGetFirewallRules(cmdRunner)
+	})
+
+	AssertActionIsNotAsynchronous(action)
+	AssertActionIsNotPersistent(action)
+	AssertActionIsLoggable(action)
+
+	AssertActionIsNotResumable(action)
+	AssertActionIsNotCancelable(action)
+
+	Describe("Run", func() {
+		It("returns the nftables ruleset when nft is available", func() {
+			cmdRunner.AvailableCommands = map[string]bool{"nft": true, "iptables-save": true}
+			cmdRunner.AddCmdResult("nft list ruleset", fakesys.FakeCmdResult{Stdout: nftRuleset})
+
+			response, err := action.Run()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response).To(Equal(GetFirewallRulesResponse{Backend: "nftables", Ruleset: nftRuleset}))
+			Expect(cmdRunner.RunCommands).To(Equal([][]string{{"nft", "list", "ruleset"}}))
+		})
+
+		It("returns the iptables ruleset when nft has no rules", func() {
+			cmdRunner.AvailableCommands = map[string]bool{"nft": true, "iptables-save": true}
+			cmdRunner.AddCmdResult("nft list ruleset", fakesys.FakeCmdResult{Stdout: "\n"})
+			cmdRunner.AddCmdResult("iptables-save", fakesys.FakeCmdResult{Stdout: iptablesRuleset})
+
+			response, err := action.Run()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response).To(Equal(GetFirewallRulesResponse{Backend: "iptables", Ruleset: iptablesRuleset}))
+		})
+
+		It("returns the iptables ruleset when only iptables-save is available", func() {
+			cmdRunner.AvailableCommands = map[string]bool{"iptables-save": true}
+			cmdRunner.AddCmdResult("iptables-save", fakesys.FakeCmdResult{Stdout: iptablesRuleset})
+
+			response, err := action.Run()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response).To(Equal(GetFirewallRulesResponse{Backend: "iptables", Ruleset: iptablesRuleset}))
+			Expect(cmdRunner.RunCommands).To(Equal([][]string{{"iptables-save"}}))
+		})
+
+		It("returns an empty nftables ruleset when only nft is available", func() {
+			cmdRunner.AvailableCommands = map[string]bool{"nft": true}
+			cmdRunner.AddCmdResult("nft list ruleset", fakesys.FakeCmdResult{Stdout: ""})
+
+			response, err := action.Run()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response).To(Equal(GetFirewallRulesResponse{Backend: "nftables", Ruleset: ""}))
+		})
+
+		It("returns an error when neither nft nor iptables-save is available", func() {
+			_, err := action.Run()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Neither nft nor iptables-save is available"))
+			Expect(cmdRunner.RunCommands).To(BeEmpty())
+		})
+
+		It("returns an error when nft fails", func() {
+			cmdRunner.AvailableCommands = map[string]bool{"nft": true}
+			cmdRunner.AddCmdResult("nft list ruleset", fakesys.FakeCmdResult{Error: errors.New("fake-nft-error")})
+
+			_, err := action.Run()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Running nft: fake-nft-error"))
+		})
+
+		It("returns an error when iptables-save fails", func() {
+			cmdRunner.AvailableCommands = map[string]bool{"iptables-save": true}
+			cmdRunner.AddCmdResult("iptables-save", fakesys.FakeCmdResult{Error: errors.New("fake-iptables-error")})
+
+			_, err := action.Run()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Running iptables-save: fake-iptables-error"))
+		})
+	})
+})
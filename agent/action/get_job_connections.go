@@ -0,0 +1,296 @@
+package action
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	boshdirs "github.com/cloudfoundry/bosh-agent/settings/directories"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+// Socket states as found in the st column of /proc/net/tcp
+var reportedTCPStates = map[string]string{
+	"01": "ESTABLISHED",
+	"0A": "LISTEN",
+}
+
+type JobConnection struct {
+	Pid           int    `json:"pid"`
+	Protocol      string `json:"protocol"`
+	LocalAddress  string `json:"local_address"`
+	RemoteAddress string `json:"remote_address"`
+	State         string `json:"state"`
+}
+
+type GetJobConnectionsResponse struct {
+	Connections []JobConnection `json:"connections"`
+}
+
+type GetJobConnectionsAction struct {
+	fs          boshsys.FileSystem
+	dirProvider boshdirs.Provider
+}
+
+func NewGetJobConnections(fs boshsys.FileSystem, dirProvider boshdirs.Provider) GetJobConnectionsAction {
+	return GetJobConnectionsAction{
+		fs:          fs,
+		dirProvider: dirProvider,
+	}
+}
+
+func (a GetJobConnectionsAction) IsAsynchronous(_ ProtocolVersion) bool {
+	return false
+}
+
+func (a GetJobConnectionsAction) IsPersistent() bool {
+	return false
+}
+
+func (a GetJobConnectionsAction) IsLoggable() bool {
+	return true
+}
+
+// Run lists the established and listening TCP connections of the processes
+// started by a job, found through the job's pid files, and their descendants
+func (a GetJobConnectionsAction) Run(jobName string) (GetJobConnectionsResponse, error) {
+	response := GetJobConnectionsResponse{Connections: []JobConnection{}}
+
+	if jobName == "" || strings.Contains(jobName, "/") || strings.Contains(jobName, "..") {
+		return response, bosherr.Errorf("Invalid job name '%s'", jobName)
+	}
+
+	rootPids, err := a.jobPids(jobName)
+	if err != nil {
+		return response, err
+	}
+
+	if len(rootPids) == 0 {
+		return response, bosherr.Errorf("No running processes found for job '%s'", jobName)
+	}
+
+	pids, err := a.processTree(rootPids)
+	if err != nil {
+		return response, err
+	}
+
+	socketPids := map[string]int{}
+	for _, pid := range pids {
+		for _, inode := range a.socketInodes(pid) {
+			socketPids[inode] = pid
+		}
+	}
+
+	// Socket tables are per network namespace so reading them for one process is enough
+	for _, protocol := range []string{"tcp", "tcp6"} {
+		tablePath := fmt.Sprintf("/proc/%d/net/%s", rootPids[0], protocol)
+
+		table, err := a.fs.ReadFileString(tablePath)
+		if err != nil {
+			continue
+		}
+
+		connections, err := parseTCPTable(protocol, table, socketPids)
+		if err != nil {
+			return response, bosherr.WrapErrorf(err, "Parsing '%s'", tablePath)
+		}
+
+		response.Connections = append(response.Connections, connections...)
+	}
+
+	return response, nil
+}
+
+func (a GetJobConnectionsAction) jobPids(jobName string) ([]int, error) {
+	pidFileGlob := filepath.Join(a.dirProvider.JobRunDir(jobName), "*.pid")
+
+	pidFiles, err := a.fs.Glob(pidFileGlob)
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Globbing '%s'", pidFileGlob)
+	}
+
+	pids := []int{}
+	for _, pidFile := range pidFiles {
+		contents, err := a.fs.ReadFileString(pidFile)
+		if err != nil {
+			return nil, bosherr.WrapErrorf(err, "Reading pid file '%s'", pidFile)
+		}
+
+		pid, err := strconv.Atoi(strings.TrimSpace(contents))
+		if err != nil {
+			return nil, bosherr.WrapErrorf(err, "Parsing pid file '%s'", pidFile)
+		}
+
+		if a.fs.FileExists(fmt.Sprintf("/proc/%d", pid)) {
+			pids = append(pids, pid)
+		}
+	}
+	sort.Ints(pids)
+
+	return pids, nil
+}
+
+// processTree returns the given pids together with all their descendants
+func (a GetJobConnectionsAction) processTree(rootPids []int) ([]int, error) {
+	statFiles, err := a.fs.Glob("/proc/[0-9]*/stat")
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Globbing process stat files")
+	}
+
+	children := map[int][]int{}
+	for _, statFile := range statFiles {
+		stat, err := a.fs.ReadFileString(statFile)
+		if err != nil {
+			continue
+		}
+
+		// Format is "pid (comm) state ppid ..."; comm may itself contain spaces and parentheses
+		commEnd := strings.LastIndex(stat, ")")
+		if commEnd == -1 {
+			continue
+		}
+
+		fields := strings.Fields(stat[commEnd+1:])
+		if len(fields) < 2 {
+			continue
+		}
+
+		pid, err := strconv.Atoi(strings.Fields(stat)[0])
+		if err != nil {
+			continue
+		}
+
+		ppid, err := strconv.Atoi(fields[1])
+		if err != nil {
+			continue
+		}
+
+		children[ppid] = append(children[ppid], pid)
+	}
+
+	pids := []int{}
+	seen := map[int]bool{}
+	queue := append([]int{}, rootPids...)
+
+	for len(queue) > 0 {
+		pid := queue[0]
+		queue = queue[1:]
+
+		if seen[pid] {
+			continue
+		}
+		seen[pid] = true
+
+		pids = append(pids, pid)
+		queue = append(queue, children[pid]...)
+	}
+
+	return pids, nil
+}
+
+func (a GetJobConnectionsAction) socketInodes(pid int) []string {
+	fds, err := a.fs.Glob(fmt.Sprintf("/proc/%d/fd/*", pid))
+	if err != nil {
+		return nil
+	}
+
+	inodes := []string{}
+	for _, fd := range fds {
+		// Processes may exit or close descriptors while being inspected
+		target, err := a.fs.Readlink(fd)
+		if err != nil {
+			continue
+		}
+
+		target = path.Base(filepath.ToSlash(target))
+		if strings.HasPrefix(target, "socket:[") && strings.HasSuffix(target, "]") {
+			inodes = append(inodes, strings.TrimSuffix(strings.TrimPrefix(target, "socket:["), "]"))
+		}
+	}
+
+	return inodes
+}
+
+func parseTCPTable(protocol, table string, socketPids map[string]int) ([]JobConnection, error) {
+	connections := []JobConnection{}
+
+	// Columns: sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode ...
+	for _, line := range strings.Split(table, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 10 || fields[0] == "sl" {
+			continue
+		}
+
+		pid, found := socketPids[fields[9]]
+		if !found {
+			continue
+		}
+
+		state, reported := reportedTCPStates[fields[3]]
+		if !reported {
+			continue
+		}
+
+		localAddress, err := parseProcNetAddress(fields[1])
+		if err != nil {
+			return nil, err
+		}
+
+		remoteAddress, err := parseProcNetAddress(fields[2])
+		if err != nil {
+			return nil, err
+		}
+
+		connections = append(connections, JobConnection{
+			Pid:           pid,
+			Protocol:      protocol,
+			LocalAddress:  localAddress,
+			RemoteAddress: remoteAddress,
+			State:         state,
+		})
+	}
+
+	return connections, nil
+}
+
+// parseProcNetAddress converts addresses such as "0100007F:1F90", whose IP
+// is stored as little endian 32-bit words, into "127.0.0.1:8080"
+func parseProcNetAddress(address string) (string, error) {
+	parts := strings.Split(address, ":")
+	if len(parts) != 2 {
+		return "", bosherr.Errorf("Invalid address '%s'", address)
+	}
+
+	ipBytes, err := hex.DecodeString(parts[0])
+	if err != nil || (len(ipBytes) != net.IPv4len && len(ipBytes) != net.IPv6len) {
+		return "", bosherr.Errorf("Invalid address '%s'", address)
+	}
+
+	ip := make(net.IP, len(ipBytes))
+	for i := 0; i < len(ipBytes); i += 4 {
+		binary.BigEndian.PutUint32(ip[i:], binary.LittleEndian.Uint32(ipBytes[i:]))
+	}
+
+	port, err := strconv.ParseUint(parts[1], 16, 16)
+	if err != nil {
+		return "", bosherr.WrapErrorf(err, "Invalid port in address '%s'", address)
+	}
+
+	return net.JoinHostPort(ip.String(), strconv.FormatUint(port, 10)), nil
+}
+
+func (a GetJobConnectionsAction) Resume() (interface{}, error) {
+	return nil, errors.New("not supported")
+}
+
+func (a GetJobConnectionsAction) Cancel() error {
+	return errors.New("not supported")
+}
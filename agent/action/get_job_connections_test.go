@@ -0,0 +1,110 @@
+package action_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-agent/agent/action"
+	fakefs "github.com/cloudfoundry/bosh-agent/platform/fakefs"
+	boshdirs "github.com/cloudfoundry/bosh-agent/settings/directories"
+)
+
+var _ = Describe("GetJobConnectionsAction", func() {
+	var (
+		fs          *fakefs.FakeFileSystem
+		dirProvider boshdirs.Provider
+		action      GetJobConnectionsAction
+	)
+
+	writeFile := func(path, contents string) {
+		err := fs.WriteFileString(path, contents)
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	addProcess := func(pid, ppid string, sockets ...string) {
+		err := fs.MkdirAll("/proc/"+pid+"/fd", 0755)
+		Expect(err).ToNot(HaveOccurred())
+
+		writeFile("/proc/"+pid+"/stat", pid+" (some (odd) name) S "+ppid+" 1 1 0 -1")
+
+		for i, socket := range sockets {
+			err = fs.Symlink("socket:["+socket+"]", "/proc/"+pid+"/fd/"+string(rune('3'+i)))
+			Expect(err).ToNot(HaveOccurred())
+		}
+	}
+
+	BeforeEach(func() {
+		fs = fakefs.NewFakeFileSystem()
+		fs.GlobUsesRealMatching = true
+		dirProvider = boshdirs.NewProvider("/var/vcap")
+		action = NewGetJobConnections(fs, dirProvider)
+	})
+
+	AssertActionIsNotAsynchronous(action)
+	AssertActionIsNotPersistent(action)
+	AssertActionIsLoggable(action)
+
+	AssertActionIsNotResumable(action)
+	AssertActionIsNotCancelable(action)
+
+	Describe("Run", func() {
+		BeforeEach(func() {
+			writeFile("/var/vcap/data/sys/run/fake-job/fake-job.pid", "100\n")
+
+			addProcess("100", "1", "1001")
+			addProcess("101", "100", "1002", "1003")
+			addProcess("200", "1", "2001")
+
+			err := fs.Symlink("/dev/null", "/proc/100/fd/0")
+			Expect(err).ToNot(HaveOccurred())
+
+			writeFile("/proc/100/net/tcp", `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 1001 1 0000000000000000 100 0 0 10 0
+   1: 0100007F:1F90 0100007F:D431 01 00000000:00000000 00:00000000 00000000  1000        0 1002 1 0000000000000000 20 4 30 10 -1
+   2: 0100007F:D432 0100007F:1F90 06 00000000:00000000 00:00000000 00000000  1000        0 1003 1 0000000000000000 20 4 30 10 -1
+   3: 00000000:0016 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 2001 1 0000000000000000 100 0 0 10 0
+`)
+			writeFile("/proc/100/net/tcp6", `  sl  local_address                         remote_address                        st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000000000000000000001000000:1F91 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 1003 1 0000000000000000 100 0 0 10 0
+`)
+		})
+
+		It("reports established and listening connections of the job's processes and their children", func() {
+			response, err := action.Run("fake-job")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Connections).To(Equal([]JobConnection{
+				{Pid: 100, Protocol: "tcp", LocalAddress: "0.0.0.0:8080", RemoteAddress: "0.0.0.0:0", State: "LISTEN"},
+				{Pid: 101, Protocol: "tcp", LocalAddress: "127.0.0.1:8080", RemoteAddress: "127.0.0.1:54321", State: "ESTABLISHED"},
+				{Pid: 101, Protocol: "tcp6", LocalAddress: "[::1]:8081", RemoteAddress: "[::]:0", State: "LISTEN"},
+			}))
+		})
+
+		It("ignores pid files of processes that are no longer running", func() {
+			writeFile("/var/vcap/data/sys/run/fake-job/stale.pid", "300")
+
+			response, err := action.Run("fake-job")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Connections).To(HaveLen(3))
+		})
+
+		It("returns an error when the job has no running processes", func() {
+			_, err := action.Run("other-job")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("No running processes found for job 'other-job'"))
+		})
+
+		It("returns an error when a pid file is malformed", func() {
+			writeFile("/var/vcap/data/sys/run/fake-job/bad.pid", "not-a-pid")
+
+			_, err := action.Run("fake-job")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Parsing pid file '/var/vcap/data/sys/run/fake-job/bad.pid'"))
+		})
+
+		It("rejects job names that would escape the run directory", func() {
+			_, err := action.Run("../fake-job")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Invalid job name '../fake-job'"))
+		})
+	})
+})
@@ -0,0 +1,89 @@
+package action
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+const kernelCmdlinePath = "/proc/cmdline"
+
+type GetKernelCmdlineResponse struct {
+	Cmdline string `json:"cmdline"`
+
+	// Parameters may be repeated, e.g. console=tty1 console=ttyS0
+	Params map[string][]string `json:"params"`
+
+	// Parameters without a value, e.g. quiet
+	Flags []string `json:"flags"`
+}
+
+type GetKernelCmdlineAction struct {
+	fs boshsys.FileSystem
+}
+
+func NewGetKernelCmdline(fs boshsys.FileSystem) GetKernelCmdlineAction {
+	return GetKernelCmdlineAction{fs: fs}
+}
+
+func (a GetKernelCmdlineAction) IsAsynchronous(_ ProtocolVersion) bool {
+	return false
+}
+
+func (a GetKernelCmdlineAction) IsPersistent() bool {
+	return false
+}
+
+func (a GetKernelCmdlineAction) IsLoggable() bool {
+	return true
+}
+
+func (a GetKernelCmdlineAction) Run() (GetKernelCmdlineResponse, error) {
+	cmdline, err := a.fs.ReadFileString(kernelCmdlinePath)
+	if err != nil {
+		return GetKernelCmdlineResponse{}, bosherr.WrapErrorf(err, "Reading '%s'", kernelCmdlinePath)
+	}
+
+	response := GetKernelCmdlineResponse{
+		Cmdline: strings.TrimSpace(cmdline),
+		Params:  map[string][]string{},
+		Flags:   []string{},
+	}
+
+	for _, param := range splitKernelCmdline(response.Cmdline) {
+		i := strings.Index(param, "=")
+		if i == -1 {
+			response.Flags = append(response.Flags, param)
+			continue
+		}
+
+		key := param[:i]
+		response.Params[key] = append(response.Params[key], strings.Trim(param[i+1:], `"`))
+	}
+
+	return response, nil
+}
+
+func (a GetKernelCmdlineAction) Resume() (interface{}, error) {
+	return nil, errors.New("not supported")
+}
+
+func (a GetKernelCmdlineAction) Cancel() error {
+	return errors.New("not supported")
+}
+
+// splitKernelCmdline splits on whitespace like the kernel does,
+// keeping double-quoted values such as dyndbg="file foo.c +p" together
+func splitKernelCmdline(cmdline string) []string {
+	inQuotes := false
+
+	return strings.FieldsFunc(cmdline, func(r rune) bool {
+		if r == '"' {
+			inQuotes = !inQuotes
+		}
+		return !inQuotes && unicode.IsSpace(r)
+	})
+}
@@ -0,0 +1,79 @@
+package action_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-agent/agent/action"
+	fakefs "github.com/cloudfoundry/bosh-agent/platform/fakefs"
+)
+
+var _ = Describe("GetKernelCmdlineAction", func() {
+	var (
+		fs     *fakefs.FakeFileSystem
+		action GetKernelCmdlineAction
+	)
+
+	BeforeEach(func() {
+		fs = fakefs.NewFakeFileSystem()
+		action = NewGetKernelCmdline(fs)
+	})
+
+	AssertActionIsNotAsynchronous(action)
+	AssertActionIsNotPersistent(action)
+	AssertActionIsLoggable(action)
+
+	AssertActionIsNotResumable(action)
+	AssertActionIsNotCancelable(action)
+
+	Describe("Run", func() {
+		It("parses flags and key=value parameters", func() {
+			cmdline := `BOOT_IMAGE=/boot/vmlinuz-5.15.0 root=UUID=1234-abcd ro console=tty1 console=ttyS0 ` +
+				`cgroup_enable=memory swapaccount=1 hugepages=128 dyndbg="file foo.c +p" quiet nosplash` + "\n"
+			err := fs.WriteFileString("/proc/cmdline", cmdline)
+			Expect(err).ToNot(HaveOccurred())
+
+			response, err := action.Run()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response).To(Equal(GetKernelCmdlineResponse{
+				Cmdline: `BOOT_IMAGE=/boot/vmlinuz-5.15.0 root=UUID=1234-abcd ro console=tty1 console=ttyS0 ` +
+					`cgroup_enable=memory swapaccount=1 hugepages=128 dyndbg="file foo.c +p" quiet nosplash`,
+				Params: map[string][]string{
+					"BOOT_IMAGE":    {"/boot/vmlinuz-5.15.0"},
+					"root":          {"UUID=1234-abcd"},
+					"console":       {"tty1", "ttyS0"},
+					"cgroup_enable": {"memory"},
+					"swapaccount":   {"1"},
+					"hugepages":     {"128"},
+					"dyndbg":        {"file foo.c +p"},
+				},
+				Flags: []string{"ro", "quiet", "nosplash"},
+			}))
+		})
+
+		It("returns empty params and flags for an empty cmdline", func() {
+			err := fs.WriteFileString("/proc/cmdline", "\n")
+			Expect(err).ToNot(HaveOccurred())
+
+			response, err := action.Run()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response).To(Equal(GetKernelCmdlineResponse{
+				Params: map[string][]string{},
+				Flags:  []string{},
+			}))
+		})
+
+		It("returns an error when /proc/cmdline cannot be read", func() {
+			err := fs.WriteFileString("/proc/cmdline", "quiet\n")
+			Expect(err).ToNot(HaveOccurred())
+			fs.RegisterReadFileError("/proc/cmdline", errors.New("fake-read-error"))
+
+			_, err = action.Run()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Reading '/proc/cmdline'"))
+			Expect(err.Error()).To(ContainSubstring("fake-read-error"))
+		})
+	})
+})
@@ -0,0 +1,137 @@
+package action
+
+import (
+	"errors"
+	"strings"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+const (
+	timezonePath = "/etc/timezone"
+
+	// Debian style locale file, systemd based systems use locale.conf instead
+	defaultLocalePath = "/etc/default/locale"
+	localeConfPath    = "/etc/locale.conf"
+)
+
+type GetLocaleTimezoneResponse struct {
+	Timezone string `json:"timezone"`
+
+	// Variables such as LANG and LC_TIME as set for the system
+	Locale map[string]string `json:"locale"`
+}
+
+type GetLocaleTimezoneAction struct {
+	fs     boshsys.FileSystem
+	runner boshsys.CmdRunner
+}
+
+func NewGetLocaleTimezone(fs boshsys.FileSystem, runner boshsys.CmdRunner) GetLocaleTimezoneAction {
+	return GetLocaleTimezoneAction{fs: fs, runner: runner}
+}
+
+func (a GetLocaleTimezoneAction) IsAsynchronous(_ ProtocolVersion) bool {
+	return false
+}
+
+func (a GetLocaleTimezoneAction) IsPersistent() bool {
+	return false
+}
+
+func (a GetLocaleTimezoneAction) IsLoggable() bool {
+	return true
+}
+
+func (a GetLocaleTimezoneAction) Run() (GetLocaleTimezoneResponse, error) {
+	timezone, err := a.timezone()
+	if err != nil {
+		return GetLocaleTimezoneResponse{}, err
+	}
+
+	locale, err := a.locale()
+	if err != nil {
+		return GetLocaleTimezoneResponse{}, err
+	}
+
+	return GetLocaleTimezoneResponse{Timezone: timezone, Locale: locale}, nil
+}
+
+func (a GetLocaleTimezoneAction) Resume() (interface{}, error) {
+	return nil, errors.New("not supported")
+}
+
+func (a GetLocaleTimezoneAction) Cancel() error {
+	return errors.New("not supported")
+}
+
+// timezone prefers timedatectl on systemd based systems where /etc/timezone
+// may be missing or stale, as only /etc/localtime is authoritative there
+func (a GetLocaleTimezoneAction) timezone() (string, error) {
+	if a.runner.CommandExists("timedatectl") {
+		stdout, _, _, err := a.runner.RunCommand("timedatectl", "status")
+		if err != nil {
+			return "", bosherr.WrapError(err, "Running timedatectl")
+		}
+
+		// e.g. "                Time zone: Etc/UTC (UTC, +0000)"
+		for _, line := range strings.Split(stdout, "\n") {
+			line = strings.TrimSpace(line)
+			if !strings.HasPrefix(line, "Time zone:") {
+				continue
+			}
+
+			fields := strings.Fields(strings.TrimPrefix(line, "Time zone:"))
+			if len(fields) > 0 {
+				return fields[0], nil
+			}
+		}
+
+		return "", bosherr.Errorf("Parsing time zone from timedatectl output '%s'", strings.TrimSpace(stdout))
+	}
+
+	if !a.fs.FileExists(timezonePath) {
+		return "", nil
+	}
+
+	timezone, err := a.fs.ReadFileString(timezonePath)
+	if err != nil {
+		return "", bosherr.WrapErrorf(err, "Reading '%s'", timezonePath)
+	}
+
+	return strings.TrimSpace(timezone), nil
+}
+
+func (a GetLocaleTimezoneAction) locale() (map[string]string, error) {
+	locale := map[string]string{}
+
+	for _, path := range []string{defaultLocalePath, localeConfPath} {
+		if !a.fs.FileExists(path) {
+			continue
+		}
+
+		content, err := a.fs.ReadFileString(path)
+		if err != nil {
+			return nil, bosherr.WrapErrorf(err, "Reading '%s'", path)
+		}
+
+		for _, line := range strings.Split(content, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+
+			parts := strings.SplitN(line, "=", 2)
+			if len(parts) != 2 {
+				continue
+			}
+
+			locale[strings.TrimSpace(parts[0])] = strings.Trim(strings.TrimSpace(parts[1]), `"'`)
+		}
+
+		return locale, nil
+	}
+
+	return locale, nil
+}
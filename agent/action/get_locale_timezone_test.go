@@ -0,0 +1,110 @@
+package action_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-agent/agent/action"
+	fakefs "github.com/cloudfoundry/bosh-agent/platform/fakefs"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+)
+
+var _ = Describe("GetLocaleTimezoneAction", func() {
+	var (
+		fs        *fakefs.FakeFileSystem
+		cmdRunner *fakesys.FakeCmdRunner
+		action    GetLocaleTimezoneAction
+	)
+
+	BeforeEach(func() {
+		fs = fakefs.NewFakeFileSystem()
+		cmdRunner = fakesys.NewFakeCmdRunner()
+		action = NewGetLocaleTimezone(fs, cmdRunner)
+	})
+
+	AssertActionIsNotAsynchronous(action)
+	AssertActionIsNotPersistent(action)
+	AssertActionIsLoggable(action)
+
+	AssertActionIsNotResumable(action)
+	AssertActionIsNotCancelable(action)
+
+	Describe("Run", func() {
+		Context("on a systemd based system", func() {
+			BeforeEach(func() {
+				cmdRunner.AvailableCommands = map[string]bool{"timedatectl": true}
+			})
+
+			It("returns the timezone from timedatectl and the locale from locale.conf", func() {
+				cmdRunner.AddCmdResult("timedatectl status", fakesys.FakeCmdResult{Stdout: `               Local time: Thu 2026-10-15 10:00:00 CEST
+           Universal time: Thu 2026-10-15 08:00:00 UTC
+                Time zone: Europe/Berlin (CEST, +0200)
+System clock synchronized: yes
+`})
+				Expect(fs.WriteFileString("/etc/timezone", "Etc/UTC\n")).To(Succeed())
+				Expect(fs.WriteFileString("/etc/locale.conf", "LANG=de_DE.UTF-8\nLC_TIME=en_GB.UTF-8\n")).To(Succeed())
+
+				response, err := action.Run()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(response).To(Equal(GetLocaleTimezoneResponse{
+					Timezone: "Europe/Berlin",
+					Locale: map[string]string{
+						"LANG":    "de_DE.UTF-8",
+						"LC_TIME": "en_GB.UTF-8",
+					},
+				}))
+			})
+
+			It("returns an error when timedatectl fails", func() {
+				cmdRunner.AddCmdResult("timedatectl status", fakesys.FakeCmdResult{Error: errors.New("fake-timedatectl-error")})
+
+				_, err := action.Run()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-timedatectl-error"))
+			})
+
+			It("returns an error when the output of timedatectl has no time zone", func() {
+				cmdRunner.AddCmdResult("timedatectl status", fakesys.FakeCmdResult{Stdout: "System clock synchronized: yes\n"})
+
+				_, err := action.Run()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Parsing time zone from timedatectl output"))
+			})
+		})
+
+		Context("on a system without systemd", func() {
+			It("returns the timezone from /etc/timezone and the locale from /etc/default/locale", func() {
+				Expect(fs.WriteFileString("/etc/timezone", "America/New_York\n")).To(Succeed())
+				Expect(fs.WriteFileString("/etc/default/locale", "# Created by installer\nLANG=\"en_US.UTF-8\"\nLC_ALL='C'\n")).To(Succeed())
+
+				response, err := action.Run()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(response).To(Equal(GetLocaleTimezoneResponse{
+					Timezone: "America/New_York",
+					Locale: map[string]string{
+						"LANG":   "en_US.UTF-8",
+						"LC_ALL": "C",
+					},
+				}))
+				Expect(cmdRunner.RunCommands).To(BeEmpty())
+			})
+
+			It("returns empty values when neither timezone nor locale are configured", func() {
+				response, err := action.Run()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(response).To(Equal(GetLocaleTimezoneResponse{Timezone: "", Locale: map[string]string{}}))
+			})
+
+			It("returns an error when the timezone cannot be read", func() {
+				Expect(fs.WriteFileString("/etc/timezone", "Etc/UTC\n")).To(Succeed())
+				fs.RegisterReadFileError("/etc/timezone", errors.New("fake-read-error"))
+
+				_, err := action.Run()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-read-error"))
+			})
+		})
+	})
+})
@@ -0,0 +1,99 @@
+package action
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+// Values are reported in kB as found in /proc/meminfo
+type GetMemoryBreakdownResponse struct {
+	TotalKB        uint64 `json:"total_kb"`
+	FreeKB         uint64 `json:"free_kb"`
+	AvailableKB    uint64 `json:"available_kb"`
+	BuffersKB      uint64 `json:"buffers_kb"`
+	CachedKB       uint64 `json:"cached_kb"`
+	SlabKB         uint64 `json:"slab_kb"`
+	SReclaimableKB uint64 `json:"slab_reclaimable_kb"`
+	SUnreclaimKB   uint64 `json:"slab_unreclaimable_kb"`
+	SwapTotalKB    uint64 `json:"swap_total_kb"`
+	SwapFreeKB     uint64 `json:"swap_free_kb"`
+}
+
+type GetMemoryBreakdownAction struct {
+	fs boshsys.FileSystem
+}
+
+func NewGetMemoryBreakdown(fs boshsys.FileSystem) GetMemoryBreakdownAction {
+	return GetMemoryBreakdownAction{fs: fs}
+}
+
+func (a GetMemoryBreakdownAction) IsAsynchronous(_ ProtocolVersion) bool {
+	return false
+}
+
+func (a GetMemoryBreakdownAction) IsPersistent() bool {
+	return false
+}
+
+func (a GetMemoryBreakdownAction) IsLoggable() bool {
+	return true
+}
+
+func (a GetMemoryBreakdownAction) Run() (GetMemoryBreakdownResponse, error) {
+	var response GetMemoryBreakdownResponse
+
+	memInfo, err := a.fs.ReadFileString("/proc/meminfo")
+	if err != nil {
+		return response, bosherr.WrapError(err, "Reading /proc/meminfo")
+	}
+
+	fields := map[string]*uint64{
+		"MemTotal":     &response.TotalKB,
+		"MemFree":      &response.FreeKB,
+		"MemAvailable": &response.AvailableKB,
+		"Buffers":      &response.BuffersKB,
+		"Cached":       &response.CachedKB,
+		"Slab":         &response.SlabKB,
+		"SReclaimable": &response.SReclaimableKB,
+		"SUnreclaim":   &response.SUnreclaimKB,
+		"SwapTotal":    &response.SwapTotalKB,
+		"SwapFree":     &response.SwapFreeKB,
+	}
+
+	// Lines look like "MemTotal:        8167848 kB"
+	for _, line := range strings.Split(memInfo, "\n") {
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		field, found := fields[parts[0]]
+		if !found {
+			continue
+		}
+
+		values := strings.Fields(parts[1])
+		if len(values) == 0 {
+			return response, bosherr.Errorf("Missing value for '%s' in /proc/meminfo", parts[0])
+		}
+
+		*field, err = strconv.ParseUint(values[0], 10, 64)
+		if err != nil {
+			return response, bosherr.WrapErrorf(err, "Parsing '%s' in /proc/meminfo", parts[0])
+		}
+	}
+
+	return response, nil
+}
+
+func (a GetMemoryBreakdownAction) Resume() (interface{}, error) {
+	return nil, errors.New("not supported")
+}
+
+func (a GetMemoryBreakdownAction) Cancel() error {
+	return errors.New("not supported")
+}
@@ -0,0 +1,96 @@
+package action_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-agent/agent/action"
+	fakefs "github.com/cloudfoundry/bosh-agent/platform/fakefs"
+)
+
+var _ = Describe("GetMemoryBreakdownAction", func() {
+	var (
+		fs     *fakefs.FakeFileSystem
+		action GetMemoryBreakdownAction
+	)
+
+	BeforeEach(func() {
+		fs = fakefs.NewFakeFileSystem()
+		action = NewGetMemoryBreakdown(fs)
+	})
+
+	AssertActionIsNotAsynchronous(action)
+	AssertActionIsNotPersistent(action)
+	AssertActionIsLoggable(action)
+
+	AssertActionIsNotResumable(action)
+	AssertActionIsNotCancelable(action)
+
+	Describe("Run", func() {
+		It("reports the memory breakdown from /proc/meminfo", func() {
+			err := fs.WriteFileString("/proc/meminfo", `MemTotal:        8167848 kB
+MemFree:          361444 kB
+MemAvailable:    5121304 kB
+Buffers:          290460 kB
+Cached:          4404160 kB
+SwapCached:          308 kB
+Active:          4131160 kB
+Inactive:        2937916 kB
+SwapTotal:       2097148 kB
+SwapFree:        2093556 kB
+Slab:             484220 kB
+SReclaimable:     375208 kB
+SUnreclaim:       109012 kB
+HugePages_Total:       0
+`)
+			Expect(err).ToNot(HaveOccurred())
+
+			response, err := action.Run()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response).To(Equal(GetMemoryBreakdownResponse{
+				TotalKB:        8167848,
+				FreeKB:         361444,
+				AvailableKB:    5121304,
+				BuffersKB:      290460,
+				CachedKB:       4404160,
+				SlabKB:         484220,
+				SReclaimableKB: 375208,
+				SUnreclaimKB:   109012,
+				SwapTotalKB:    2097148,
+				SwapFreeKB:     2093556,
+			}))
+		})
+
+		It("leaves fields missing from older kernels at zero", func() {
+			err := fs.WriteFileString("/proc/meminfo", `MemTotal:        1024 kB
+MemFree:          512 kB
+`)
+			Expect(err).ToNot(HaveOccurred())
+
+			response, err := action.Run()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response).To(Equal(GetMemoryBreakdownResponse{TotalKB: 1024, FreeKB: 512}))
+		})
+
+		It("returns an error when a value cannot be parsed", func() {
+			err := fs.WriteFileString("/proc/meminfo", "MemTotal:        lots kB\n")
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = action.Run()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Parsing 'MemTotal' in /proc/meminfo"))
+		})
+
+		It("returns an error when /proc/meminfo cannot be read", func() {
+			err := fs.WriteFileString("/proc/meminfo", "")
+			Expect(err).ToNot(HaveOccurred())
+			fs.RegisterReadFileError("/proc/meminfo", errors.New("fake-read-err"))
+
+			_, err = action.Run()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-read-err"))
+		})
+	})
+})
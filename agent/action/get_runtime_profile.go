@@ -0,0 +1,80 @@
+package action
+
+import (
+	"errors"
+	"runtime"
+)
+
+type GetRuntimeProfileAction struct{}
+
+type GetRuntimeProfileArgs struct {
+	IncludeStacks bool `json:"include_stacks"`
+}
+
+type GetRuntimeProfileResponse struct {
+	Goroutines int    `json:"goroutines"`
+	HeapAlloc  uint64 `json:"heap_alloc"`
+	HeapSys    uint64 `json:"heap_sys"`
+	HeapInuse  uint64 `json:"heap_inuse"`
+	NumGC      uint32 `json:"num_gc"`
+	PauseTotal uint64 `json:"gc_pause_total_ns"`
+	LastGC     uint64 `json:"gc_last_ns"`
+	Stacks     string `json:"stacks,omitempty"`
+}
+
+func NewGetRuntimeProfile() GetRuntimeProfileAction {
+	return GetRuntimeProfileAction{}
+}
+
+func (a GetRuntimeProfileAction) IsAsynchronous(_ ProtocolVersion) bool {
+	return false
+}
+
+func (a GetRuntimeProfileAction) IsPersistent() bool {
+	return false
+}
+
+func (a GetRuntimeProfileAction) IsLoggable() bool {
+	return true
+}
+
+// Stack dumps can be very large so they are only included when requested
+func (a GetRuntimeProfileAction) Run(args ...GetRuntimeProfileArgs) (GetRuntimeProfileResponse, error) {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+
+	response := GetRuntimeProfileResponse{
+		Goroutines: runtime.NumGoroutine(),
+		HeapAlloc:  memStats.HeapAlloc,
+		HeapSys:    memStats.HeapSys,
+		HeapInuse:  memStats.HeapInuse,
+		NumGC:      memStats.NumGC,
+		PauseTotal: memStats.PauseTotalNs,
+		LastGC:     memStats.LastGC,
+	}
+
+	if len(args) > 0 && args[0].IncludeStacks {
+		response.Stacks = a.stacks()
+	}
+
+	return response, nil
+}
+
+func (a GetRuntimeProfileAction) stacks() string {
+	buf := make([]byte, 64*1024)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return string(buf[:n])
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
+func (a GetRuntimeProfileAction) Resume() (interface{}, error) {
+	return nil, errors.New("not supported")
+}
+
+func (a GetRuntimeProfileAction) Cancel() error {
+	return errors.New("not supported")
+}
@@ -0,0 +1,53 @@
+package action_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-agent/agent/action"
+)
+
+var _ = Describe("GetRuntimeProfile", func() {
+	var (
+		action GetRuntimeProfileAction
+	)
+
+	BeforeEach(func() {
+		action = NewGetRuntimeProfile()
+	})
+
+	AssertActionIsNotAsynchronous(action)
+	AssertActionIsNotPersistent(action)
+	AssertActionIsLoggable(action)
+
+	AssertActionIsNotResumable(action)
+	AssertActionIsNotCancelable(action)
+
+	Describe("Run", func() {
+		It("returns goroutine and memory stats", func() {
+			profile, err := action.Run()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(profile.Goroutines).To(BeNumerically(">", 0))
+			Expect(profile.HeapAlloc).To(BeNumerically(">", 0))
+			Expect(profile.HeapSys).To(BeNumerically(">", 0))
+			Expect(profile.HeapInuse).To(BeNumerically(">", 0))
+		})
+
+		It("does not include goroutine stacks by default", func() {
+			profile, err := action.Run()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(profile.Stacks).To(BeEmpty())
+
+			profile, err = action.Run(GetRuntimeProfileArgs{IncludeStacks: false})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(profile.Stacks).To(BeEmpty())
+		})
+
+		It("includes goroutine stacks when requested", func() {
+			profile, err := action.Run(GetRuntimeProfileArgs{IncludeStacks: true})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(profile.Stacks).To(ContainSubstring("goroutine"))
+			Expect(profile.Stacks).To(ContainSubstring("GetRuntimeProfileAction"))
+		})
+	})
+})
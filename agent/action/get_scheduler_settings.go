@@ -0,0 +1,114 @@
+package action
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+const (
+	cpuGovernorGlob = "/sys/devices/system/cpu/cpu*/cpufreq/scaling_governor"
+	ioSchedulerGlob = "/sys/block/*/queue/scheduler"
+)
+
+type IOScheduler struct {
+	Active    string   `json:"active"`
+	Available []string `json:"available"`
+}
+
+type GetSchedulerSettingsResponse struct {
+	// Keyed by CPU name, e.g. cpu0
+	CPUGovernors map[string]string `json:"cpu_governors"`
+
+	// Keyed by block device name, e.g. sda
+	IOSchedulers map[string]IOScheduler `json:"io_schedulers"`
+}
+
+type GetSchedulerSettingsAction struct {
+	fs boshsys.FileSystem
+}
+
+func NewGetSchedulerSettings(fs boshsys.FileSystem) GetSchedulerSettingsAction {
+	return GetSchedulerSettingsAction{fs: fs}
+}
+
+func (a GetSchedulerSettingsAction) IsAsynchronous(_ ProtocolVersion) bool {
+	return false
+}
+
+func (a GetSchedulerSettingsAction) IsPersistent() bool {
+	return false
+}
+
+func (a GetSchedulerSettingsAction) IsLoggable() bool {
+	return true
+}
+
+// Run reports the settings found in sysfs; CPUs without cpufreq support and
+// devices without a scheduler (e.g. on some virtualized hosts) are omitted
+func (a GetSchedulerSettingsAction) Run() (GetSchedulerSettingsResponse, error) {
+	response := GetSchedulerSettingsResponse{
+		CPUGovernors: map[string]string{},
+		IOSchedulers: map[string]IOScheduler{},
+	}
+
+	governorPaths, err := a.fs.Glob(cpuGovernorGlob)
+	if err != nil {
+		return response, bosherr.WrapErrorf(err, "Globbing '%s'", cpuGovernorGlob)
+	}
+
+	for _, governorPath := range governorPaths {
+		governor, err := a.fs.ReadFileString(governorPath)
+		if err != nil {
+			continue
+		}
+
+		// <cpu>/cpufreq/scaling_governor
+		cpu := filepath.Base(filepath.Dir(filepath.Dir(governorPath)))
+		response.CPUGovernors[cpu] = strings.TrimSpace(governor)
+	}
+
+	schedulerPaths, err := a.fs.Glob(ioSchedulerGlob)
+	if err != nil {
+		return response, bosherr.WrapErrorf(err, "Globbing '%s'", ioSchedulerGlob)
+	}
+
+	for _, schedulerPath := range schedulerPaths {
+		schedulers, err := a.fs.ReadFileString(schedulerPath)
+		if err != nil {
+			continue
+		}
+
+		// <device>/queue/scheduler
+		device := filepath.Base(filepath.Dir(filepath.Dir(schedulerPath)))
+		response.IOSchedulers[device] = parseIOScheduler(schedulers)
+	}
+
+	return response, nil
+}
+
+// The active scheduler is surrounded by brackets, e.g. "mq-deadline [none] kyber"
+func parseIOScheduler(schedulers string) IOScheduler {
+	scheduler := IOScheduler{Available: []string{}}
+
+	for _, name := range strings.Fields(schedulers) {
+		if strings.HasPrefix(name, "[") && strings.HasSuffix(name, "]") {
+			name = strings.Trim(name, "[]")
+			scheduler.Active = name
+		}
+		scheduler.Available = append(scheduler.Available, name)
+	}
+
+	return scheduler
+}
+
+func (a GetSchedulerSettingsAction) Resume() (interface{}, error) {
+	return nil, errors.New("not supported")
+}
+
+func (a GetSchedulerSettingsAction) Cancel() error {
+	return errors.New("not supported")
+}
@@ -0,0 +1,96 @@
+package action_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-agent/agent/action"
+	fakefs "github.com/cloudfoundry/bosh-agent/platform/fakefs"
+)
+
+var _ = Describe("GetSchedulerSettingsAction", func() {
+	var (
+		fs     *fakefs.FakeFileSystem
+		action GetSchedulerSettingsAction
+	)
+
+	BeforeEach(func() {
+		fs = fakefs.NewFakeFileSystem()
+		fs.GlobUsesRealMatching = true
+		action = NewGetSchedulerSettings(fs)
+	})
+
+	AssertActionIsNotAsynchronous(action)
+	AssertActionIsNotPersistent(action)
+	AssertActionIsLoggable(action)
+
+	AssertActionIsNotResumable(action)
+	AssertActionIsNotCancelable(action)
+
+	Describe("Run", func() {
+		It("reports CPU governors and block device I/O schedulers", func() {
+			for path, contents := range map[string]string{
+				"/sys/devices/system/cpu/cpu0/cpufreq/scaling_governor": "performance\n",
+				"/sys/devices/system/cpu/cpu1/cpufreq/scaling_governor": "powersave\n",
+				"/sys/block/sda/queue/scheduler":                        "mq-deadline kyber [bfq] none\n",
+				"/sys/block/nvme0n1/queue/scheduler":                    "[none] mq-deadline\n",
+			} {
+				err := fs.WriteFileString(path, contents)
+				Expect(err).ToNot(HaveOccurred())
+			}
+
+			response, err := action.Run()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response).To(Equal(GetSchedulerSettingsResponse{
+				CPUGovernors: map[string]string{
+					"cpu0": "performance",
+					"cpu1": "powersave",
+				},
+				IOSchedulers: map[string]IOScheduler{
+					"sda":     {Active: "bfq", Available: []string{"mq-deadline", "kyber", "bfq", "none"}},
+					"nvme0n1": {Active: "none", Available: []string{"none", "mq-deadline"}},
+				},
+			}))
+		})
+
+		It("omits CPUs and devices without sysfs entries", func() {
+			err := fs.MkdirAll("/sys/devices/system/cpu/cpu0", 0755)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = fs.MkdirAll("/sys/block/loop0/queue", 0755)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = fs.WriteFileString("/sys/block/sda/queue/scheduler", "[none]\n")
+			Expect(err).ToNot(HaveOccurred())
+
+			response, err := action.Run()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response).To(Equal(GetSchedulerSettingsResponse{
+				CPUGovernors: map[string]string{},
+				IOSchedulers: map[string]IOScheduler{
+					"sda": {Active: "none", Available: []string{"none"}},
+				},
+			}))
+		})
+
+		It("skips entries that cannot be read", func() {
+			err := fs.WriteFileString("/sys/devices/system/cpu/cpu0/cpufreq/scaling_governor", "performance\n")
+			Expect(err).ToNot(HaveOccurred())
+			fs.RegisterReadFileError("/sys/devices/system/cpu/cpu0/cpufreq/scaling_governor", errors.New("fake-read-err"))
+
+			response, err := action.Run()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.CPUGovernors).To(BeEmpty())
+		})
+
+		It("returns an error when globbing sysfs fails", func() {
+			fs.GlobErr = errors.New("fake-glob-err")
+
+			_, err := action.Run()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-glob-err"))
+		})
+	})
+})
@@ -0,0 +1,176 @@
+package action
+
+import (
+	"errors"
+	"strings"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+const (
+	// Comma separated list of the security modules the kernel initialized
+	securityModulesListPath = "/sys/kernel/security/lsm"
+
+	appArmorEnabledPath = "/sys/module/apparmor/parameters/enabled"
+	appArmorModePath    = "/sys/module/apparmor/parameters/mode"
+
+	selinuxFsPath      = "/sys/fs/selinux"
+	selinuxEnforcePath = "/sys/fs/selinux/enforce"
+)
+
+const (
+	SecurityModuleModeEnforcing  = "enforcing"
+	SecurityModuleModePermissive = "permissive"
+	SecurityModuleModeDisabled   = "disabled"
+)
+
+type SecurityModule struct {
+	Present bool   `json:"present"`
+	Mode    string `json:"mode"`
+}
+
+type GetSecurityModulesResponse struct {
+	// Empty when the kernel does not expose the list, e.g. securityfs is not mounted
+	Loaded []string `json:"loaded"`
+
+	AppArmor SecurityModule `json:"apparmor"`
+	SELinux  SecurityModule `json:"selinux"`
+}
+
+type GetSecurityModulesAction struct {
+	fs boshsys.FileSystem
+}
+
+func NewGetSecurityModules(fs boshsys.FileSystem) GetSecurityModulesAction {
+	return GetSecurityModulesAction{fs: fs}
+}
+
+func (a GetSecurityModulesAction) IsAsynchronous(_ ProtocolVersion) bool {
+	return false
+}
+
+func (a GetSecurityModulesAction) IsPersistent() bool {
+	return false
+}
+
+func (a GetSecurityModulesAction) IsLoggable() bool {
+	return true
+}
+
+func (a GetSecurityModulesAction) Run() (GetSecurityModulesResponse, error) {
+	response := GetSecurityModulesResponse{Loaded: []string{}}
+
+	if a.fs.FileExists(securityModulesListPath) {
+		list, err := a.readTrimmed(securityModulesListPath)
+		if err != nil {
+			return response, err
+		}
+
+		for _, module := range strings.Split(list, ",") {
+			if module != "" {
+				response.Loaded = append(response.Loaded, module)
+			}
+		}
+	}
+
+	var err error
+
+	response.AppArmor, err = a.appArmor()
+	if err != nil {
+		return response, err
+	}
+
+	response.SELinux, err = a.selinux()
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+func (a GetSecurityModulesAction) Resume() (interface{}, error) {
+	return nil, errors.New("not supported")
+}
+
+func (a GetSecurityModulesAction) Cancel() error {
+	return errors.New("not supported")
+}
+
+// appArmor reports the mode new profiles are loaded in, loaded profiles may
+// still be switched to complain mode individually
+func (a GetSecurityModulesAction) appArmor() (SecurityModule, error) {
+	module := SecurityModule{Mode: SecurityModuleModeDisabled}
+
+	if !a.fs.FileExists(appArmorEnabledPath) {
+		return module, nil
+	}
+
+	module.Present = true
+
+	enabled, err := a.readTrimmed(appArmorEnabledPath)
+	if err != nil {
+		return module, err
+	}
+
+	if enabled != "Y" {
+		return module, nil
+	}
+
+	module.Mode = SecurityModuleModeEnforcing
+
+	if !a.fs.FileExists(appArmorModePath) {
+		return module, nil
+	}
+
+	mode, err := a.readTrimmed(appArmorModePath)
+	if err != nil {
+		return module, err
+	}
+
+	if mode == "complain" {
+		module.Mode = SecurityModuleModePermissive
+	}
+
+	return module, nil
+}
+
+// selinux relies on selinuxfs only being mounted while SELinux is enabled
+func (a GetSecurityModulesAction) selinux() (SecurityModule, error) {
+	module := SecurityModule{Mode: SecurityModuleModeDisabled}
+
+	if !a.fs.FileExists(selinuxFsPath) {
+		return module, nil
+	}
+
+	module.Present = true
+
+	if !a.fs.FileExists(selinuxEnforcePath) {
+		return module, nil
+	}
+
+	enforce, err := a.readTrimmed(selinuxEnforcePath)
+	if err != nil {
+		return module, err
+	}
+
+	switch enforce {
+	case "1":
+		module.Mode = SecurityModuleModeEnforcing
+	case "0":
+		module.Mode = SecurityModuleModePermissive
+	default:
+		return module, bosherr.Errorf("Unexpected content '%s' in %s", enforce, selinuxEnforcePath)
+	}
+
+	return module, nil
+}
+
+func (a GetSecurityModulesAction) readTrimmed(path string) (string, error) {
+	content, err := a.fs.ReadFileString(path)
+	if err != nil {
+		return "", bosherr.WrapErrorf(err, "Reading '%s'", path)
+	}
+
+	return strings.TrimSpace(content), nil
+}
@@ -0,0 +1,113 @@
+package action_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-agent/agent/action"
+	fakefs "github.com/cloudfoundry/bosh-agent/platform/fakefs"
+)
+
+var _ = Describe("GetSecurityModulesAction", func() {
+	var (
+		fs     *fakefs.FakeFileSystem
+		action GetSecurityModulesAction
+	)
+
+	BeforeEach(func() {
+		fs = fakefs.NewFakeFileSystem()
+		action = NewGetSecurityModules(fs)
+	})
+
+	AssertActionIsNotAsynchronous(action)
+	AssertActionIsNotPersistent(action)
+	AssertActionIsLoggable(action)
+
+	AssertActionIsNotResumable(action)
+	AssertActionIsNotCancelable(action)
+
+	Describe("Run", func() {
+		It("reports both modules as disabled when neither is present", func() {
+			response, err := action.Run()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response).To(Equal(GetSecurityModulesResponse{
+				Loaded:   []string{},
+				AppArmor: SecurityModule{Present: false, Mode: "disabled"},
+				SELinux:  SecurityModule{Present: false, Mode: "disabled"},
+			}))
+		})
+
+		It("returns the security modules loaded by the kernel", func() {
+			Expect(fs.WriteFileString("/sys/kernel/security/lsm", "lockdown,capability,yama,apparmor\n")).To(Succeed())
+
+			response, err := action.Run()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Loaded).To(Equal([]string{"lockdown", "capability", "yama", "apparmor"}))
+		})
+
+		Context("when AppArmor is present", func() {
+			It("reports enforcing when enabled in enforce mode", func() {
+				Expect(fs.WriteFileString("/sys/module/apparmor/parameters/enabled", "Y\n")).To(Succeed())
+				Expect(fs.WriteFileString("/sys/module/apparmor/parameters/mode", "enforce\n")).To(Succeed())
+
+				response, err := action.Run()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(response.AppArmor).To(Equal(SecurityModule{Present: true, Mode: "enforcing"}))
+			})
+
+			It("reports permissive when enabled in complain mode", func() {
+				Expect(fs.WriteFileString("/sys/module/apparmor/parameters/enabled", "Y\n")).To(Succeed())
+				Expect(fs.WriteFileString("/sys/module/apparmor/parameters/mode", "complain\n")).To(Succeed())
+
+				response, err := action.Run()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(response.AppArmor).To(Equal(SecurityModule{Present: true, Mode: "permissive"}))
+			})
+
+			It("reports disabled when turned off on the kernel command line", func() {
+				Expect(fs.WriteFileString("/sys/module/apparmor/parameters/enabled", "N\n")).To(Succeed())
+
+				response, err := action.Run()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(response.AppArmor).To(Equal(SecurityModule{Present: true, Mode: "disabled"}))
+			})
+
+			It("returns an error when the enabled parameter cannot be read", func() {
+				Expect(fs.WriteFileString("/sys/module/apparmor/parameters/enabled", "Y\n")).To(Succeed())
+				fs.RegisterReadFileError("/sys/module/apparmor/parameters/enabled", errors.New("fake-read-error"))
+
+				_, err := action.Run()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-read-error"))
+			})
+		})
+
+		Context("when SELinux is present", func() {
+			It("reports enforcing", func() {
+				Expect(fs.WriteFileString("/sys/fs/selinux/enforce", "1")).To(Succeed())
+
+				response, err := action.Run()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(response.SELinux).To(Equal(SecurityModule{Present: true, Mode: "enforcing"}))
+			})
+
+			It("reports permissive", func() {
+				Expect(fs.WriteFileString("/sys/fs/selinux/enforce", "0")).To(Succeed())
+
+				response, err := action.Run()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(response.SELinux).To(Equal(SecurityModule{Present: true, Mode: "permissive"}))
+			})
+
+			It("returns an error for unexpected enforce values", func() {
+				Expect(fs.WriteFileString("/sys/fs/selinux/enforce", "2")).To(Succeed())
+
+				_, err := action.Run()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Unexpected content '2' in /sys/fs/selinux/enforce"))
+			})
+		})
+	})
+})
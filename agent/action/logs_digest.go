@@ -0,0 +1,53 @@
+package action
+
+import (
+	"os"
+
+	boshcrypto "github.com/cloudfoundry/bosh-utils/crypto"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+func digestAlgorithmFor(name string) (boshcrypto.Algorithm, error) {
+	switch name {
+	case "", boshcrypto.DigestAlgorithmSHA1.Name():
+		return boshcrypto.DigestAlgorithmSHA1, nil
+	case boshcrypto.DigestAlgorithmSHA256.Name():
+		return boshcrypto.DigestAlgorithmSHA256, nil
+	case boshcrypto.DigestAlgorithmSHA512.Name():
+		return boshcrypto.DigestAlgorithmSHA512, nil
+	default:
+		return nil, bosherr.Errorf("Unsupported digest algorithm '%s'", name)
+	}
+}
+
+// uploadedDigest returns the digest of an uploaded file for the requested algorithm.
+// When no algorithm was requested the digest reported by the blobstore is kept as is.
+func uploadedDigest(fs boshsys.FileSystem, path string, digest boshcrypto.MultipleDigest, algorithmName string) (string, string, error) {
+	algorithm, err := digestAlgorithmFor(algorithmName)
+	if err != nil {
+		return "", "", err
+	}
+
+	if algorithmName == "" {
+		return digest.String(), algorithm.Name(), nil
+	}
+
+	if algorithmDigest, err := digest.DigestFor(algorithm); err == nil {
+		return algorithmDigest.String(), algorithm.Name(), nil
+	}
+
+	file, err := fs.OpenFile(path, os.O_RDONLY, 0)
+	if err != nil {
+		return "", "", bosherr.WrapErrorf(err, "Opening '%s' for digest calculation", path)
+	}
+
+	defer file.Close()
+
+	algorithmDigest, err := algorithm.CreateDigest(file)
+	if err != nil {
+		return "", "", bosherr.WrapErrorf(err, "Calculating %s digest of '%s'", algorithm.Name(), path)
+	}
+
+	return algorithmDigest.String(), algorithm.Name(), nil
+}
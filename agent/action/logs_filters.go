@@ -0,0 +1,202 @@
+package action
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar"
+
+	boshsettings "github.com/cloudfoundry/bosh-agent/settings"
+	boshdirs "github.com/cloudfoundry/bosh-agent/settings/directories"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshcmd "github.com/cloudfoundry/bosh-utils/fileutil"
+)
+
+// Filters prefixed with excludeFilterPrefix exclude logs instead of including them
+const excludeFilterPrefix = "!"
+
+var globEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "{", `\{`)
+
+func logsDirFor(dirProvider boshdirs.Provider, logType string) (string, error) {
+	switch logType {
+	case "job":
+		return dirProvider.LogsDir(), nil
+	case "agent":
+		return dirProvider.AgentLogsDir(), nil
+	case "system":
+		return filepath.Join(dirProvider.BaseDir(), "sys", "log"), nil
+	default:
+		return "", bosherr.Error("Invalid log type")
+	}
+}
+
+// logsFilters includes all logs when no inclusion filter is given so that
+// exclusion-only filters such as "!**/*.gz" remove files from the full set
+func logsFilters(filters []string) []string {
+	for _, filter := range filters {
+		if !strings.HasPrefix(filter, excludeFilterPrefix) {
+			return filters
+		}
+	}
+
+	return append([]string{"**/*"}, filters...)
+}
+
+type logsCopier struct {
+	copier          boshcmd.Copier
+	settingsService boshsettings.Service
+}
+
+// NewLogsCopier wraps copier to support exclusion filters besides inclusion
+// globs. Inclusion filters are applied first and files matching any exclusion
+// filter (e.g. "!**/*.gz") are then left out, so an exclusion always wins over
+// an inclusion. The remaining files are passed on to copier by name.
+//
+// Symlinks are followed unless they lead back to one of their parent
+// directories, or left out altogether if the logs settings say so.
+func NewLogsCopier(copier boshcmd.Copier, settingsService boshsettings.Service) boshcmd.Copier {
+	return logsCopier{copier: copier, settingsService: settingsService}
+}
+
+func (c logsCopier) FilteredCopyToTemp(dir string, filters []string) (string, error) {
+	var includes, excludes []string
+
+	for _, filter := range filters {
+		if strings.HasPrefix(filter, excludeFilterPrefix) {
+			excludes = append(excludes, logsGlob(dir, strings.TrimPrefix(filter, excludeFilterPrefix)))
+		} else {
+			includes = append(includes, logsGlob(dir, filter))
+		}
+	}
+
+	globOS := symlinkAwareOS{skipSymlinks: c.settingsService.GetSettings().Env.Bosh.Logs.SkipSymlinks}
+
+	filesToCopy := []string{}
+
+	for _, include := range includes {
+		matches, err := doublestar.GlobOS(globOS, include)
+		if err != nil {
+			return "", bosherr.WrapError(err, "Finding files matching filters")
+		}
+
+		for _, match := range matches {
+			excluded, err := matchesAnyGlob(excludes, match)
+			if err != nil {
+				return "", bosherr.WrapError(err, "Matching files against exclude filters")
+			}
+
+			if excluded {
+				continue
+			}
+
+			// Directories would be copied as a whole, including excluded files
+			fileInfo, err := globOS.Stat(match)
+			if err != nil {
+				if linkInfo, lstatErr := os.Lstat(match); lstatErr == nil && linkInfo.Mode()&os.ModeSymlink != 0 {
+					continue
+				}
+
+				return "", bosherr.WrapErrorf(err, "Getting file info for '%s'", match)
+			}
+
+			if !fileInfo.IsDir() && fileInfo.Mode()&os.ModeSymlink == 0 {
+				relativePath := strings.TrimPrefix(strings.TrimPrefix(match, dir), string(filepath.Separator))
+				filesToCopy = append(filesToCopy, escapeGlob(relativePath))
+			}
+		}
+	}
+
+	return c.copier.FilteredCopyToTemp(dir, filesToCopy)
+}
+
+func (c logsCopier) CleanUp(tempDir string) {
+	c.copier.CleanUp(tempDir)
+}
+
+// logsGlob matches everything within dir/filter when it names a directory
+func logsGlob(dir, filter string) string {
+	path := filepath.Join(dir, filter)
+
+	fileInfo, err := os.Stat(path)
+	if err == nil && fileInfo.IsDir() {
+		return filepath.Join(path, "**", "*")
+	}
+
+	return path
+}
+
+func matchesAnyGlob(globs []string, path string) (bool, error) {
+	for _, glob := range globs {
+		matched, err := doublestar.PathMatch(glob, path)
+		if err != nil {
+			return false, err
+		}
+		if matched {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// symlinkAwareOS is used for globbing so that symlinks are either never
+// followed or followed unless they lead back to one of their parent
+// directories, which would otherwise make globbing loop
+type symlinkAwareOS struct {
+	skipSymlinks bool
+}
+
+func (o symlinkAwareOS) Lstat(name string) (os.FileInfo, error) { return os.Lstat(name) }
+func (o symlinkAwareOS) Open(name string) (*os.File, error)     { return os.Open(name) }
+func (o symlinkAwareOS) PathSeparator() rune                    { return os.PathSeparator }
+
+func (o symlinkAwareOS) Stat(name string) (os.FileInfo, error) {
+	linkInfo, err := os.Lstat(name)
+	if err != nil || linkInfo.Mode()&os.ModeSymlink == 0 {
+		return linkInfo, err
+	}
+
+	if o.skipSymlinks {
+		return linkInfo, nil
+	}
+
+	fileInfo, err := os.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if fileInfo.IsDir() && leadsToParentDir(name) {
+		return nil, bosherr.Errorf("Symlink '%s' leads back to one of its parent directories", name)
+	}
+
+	return fileInfo, nil
+}
+
+func leadsToParentDir(symlinkPath string) bool {
+	target, err := filepath.EvalSymlinks(symlinkPath)
+	if err != nil {
+		return true
+	}
+
+	for dir := filepath.Dir(symlinkPath); ; dir = filepath.Dir(dir) {
+		realDir, err := filepath.EvalSymlinks(dir)
+		if err == nil && realDir == target {
+			return true
+		}
+
+		if dir == filepath.Dir(dir) {
+			return false
+		}
+	}
+}
+
+// escapeGlob keeps copier from interpreting glob characters in file names.
+// Backslashes separate paths on Windows and thus cannot escape anything there.
+func escapeGlob(path string) string {
+	if filepath.Separator == '\\' {
+		return path
+	}
+
+	return globEscaper.Replace(path)
+}
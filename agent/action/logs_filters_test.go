@@ -0,0 +1,226 @@
+package action_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-agent/agent/action"
+	fakesettings "github.com/cloudfoundry/bosh-agent/settings/fakes"
+	boshcmd "github.com/cloudfoundry/bosh-utils/fileutil"
+	fakecmd "github.com/cloudfoundry/bosh-utils/fileutil/fakes"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+var _ = Describe("LogsCopier", func() {
+	var (
+		logsDir         string
+		settingsService *fakesettings.FakeSettingsService
+		copier          boshcmd.Copier
+	)
+
+	BeforeEach(func() {
+		settingsService = &fakesettings.FakeSettingsService{}
+		logger := boshlog.NewLogger(boshlog.LevelNone)
+		copier = NewLogsCopier(boshcmd.NewGenericCpCopier(boshsys.NewOsFileSystem(logger), logger), settingsService)
+
+		var err error
+		logsDir, err = ioutil.TempDir("", "logs-copier-test")
+		Expect(err).ToNot(HaveOccurred())
+
+		for _, path := range []string{
+			"app/app.stdout.log",
+			"app/app.stdout.log.1.gz",
+			"app/app.stderr.log",
+			"worker/worker.log",
+			"worker/worker.log.1.gz",
+		} {
+			fullPath := filepath.Join(logsDir, path)
+			Expect(os.MkdirAll(filepath.Dir(fullPath), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(fullPath, []byte(path), 0644)).To(Succeed())
+		}
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(logsDir)).To(Succeed())
+	})
+
+	copiedFiles := func(tempDir string) []string {
+		files := []string{}
+		err := filepath.Walk(tempDir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() {
+				relativePath, err := filepath.Rel(tempDir, path)
+				if err != nil {
+					return err
+				}
+				files = append(files, filepath.ToSlash(relativePath))
+			}
+			return nil
+		})
+		Expect(err).ToNot(HaveOccurred())
+		return files
+	}
+
+	Describe("FilteredCopyToTemp", func() {
+		It("copies files matching the inclusion filters", func() {
+			tempDir, err := copier.FilteredCopyToTemp(logsDir, []string{"app/*.log"})
+			Expect(err).ToNot(HaveOccurred())
+			defer copier.CleanUp(tempDir)
+
+			Expect(copiedFiles(tempDir)).To(ConsistOf("app/app.stdout.log", "app/app.stderr.log"))
+		})
+
+		It("copies whole directories given as filters", func() {
+			tempDir, err := copier.FilteredCopyToTemp(logsDir, []string{"worker"})
+			Expect(err).ToNot(HaveOccurred())
+			defer copier.CleanUp(tempDir)
+
+			Expect(copiedFiles(tempDir)).To(ConsistOf("worker/worker.log", "worker/worker.log.1.gz"))
+		})
+
+		It("leaves out files matching exclusion filters after applying inclusions", func() {
+			tempDir, err := copier.FilteredCopyToTemp(logsDir, []string{"**/*", "!**/*.1.gz"})
+			Expect(err).ToNot(HaveOccurred())
+			defer copier.CleanUp(tempDir)
+
+			Expect(copiedFiles(tempDir)).To(ConsistOf(
+				"app/app.stdout.log",
+				"app/app.stderr.log",
+				"worker/worker.log",
+			))
+		})
+
+		It("excludes whole directories", func() {
+			tempDir, err := copier.FilteredCopyToTemp(logsDir, []string{"**/*", "!worker"})
+			Expect(err).ToNot(HaveOccurred())
+			defer copier.CleanUp(tempDir)
+
+			Expect(copiedFiles(tempDir)).To(ConsistOf(
+				"app/app.stdout.log",
+				"app/app.stdout.log.1.gz",
+				"app/app.stderr.log",
+			))
+		})
+
+		It("gives exclusions precedence over inclusions regardless of order", func() {
+			tempDir, err := copier.FilteredCopyToTemp(logsDir, []string{"!app/app.stderr.log", "app/*"})
+			Expect(err).ToNot(HaveOccurred())
+			defer copier.CleanUp(tempDir)
+
+			Expect(copiedFiles(tempDir)).To(ConsistOf("app/app.stdout.log", "app/app.stdout.log.1.gz"))
+		})
+
+		It("copies files with glob characters in their names", func() {
+			Expect(ioutil.WriteFile(filepath.Join(logsDir, "app", "app[1].log"), []byte("app[1].log"), 0644)).To(Succeed())
+
+			tempDir, err := copier.FilteredCopyToTemp(logsDir, []string{"app/*", "!**/*.gz"})
+			Expect(err).ToNot(HaveOccurred())
+			defer copier.CleanUp(tempDir)
+
+			Expect(copiedFiles(tempDir)).To(ConsistOf("app/app.stdout.log", "app/app.stderr.log", "app/app[1].log"))
+		})
+
+		Context("when a log directory is a symlink", func() {
+			var ephemeralLogsDir string
+
+			BeforeEach(func() {
+				var err error
+				ephemeralLogsDir, err = ioutil.TempDir("", "logs-copier-test-ephemeral")
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(ioutil.WriteFile(filepath.Join(ephemeralLogsDir, "redirected.log"), []byte("redirected"), 0644)).To(Succeed())
+				Expect(os.Symlink(ephemeralLogsDir, filepath.Join(logsDir, "redirected"))).To(Succeed())
+			})
+
+			AfterEach(func() {
+				Expect(os.RemoveAll(ephemeralLogsDir)).To(Succeed())
+			})
+
+			It("copies the files of the symlinked directory", func() {
+				tempDir, err := copier.FilteredCopyToTemp(logsDir, []string{"**/*.log"})
+				Expect(err).ToNot(HaveOccurred())
+				defer copier.CleanUp(tempDir)
+
+				Expect(copiedFiles(tempDir)).To(ConsistOf(
+					"app/app.stdout.log",
+					"app/app.stderr.log",
+					"worker/worker.log",
+					"redirected/redirected.log",
+				))
+			})
+
+			It("copies the symlinked directory when it is given as a filter", func() {
+				tempDir, err := copier.FilteredCopyToTemp(logsDir, []string{"redirected"})
+				Expect(err).ToNot(HaveOccurred())
+				defer copier.CleanUp(tempDir)
+
+				Expect(copiedFiles(tempDir)).To(ConsistOf("redirected/redirected.log"))
+			})
+
+			It("leaves out symlinks when configured to skip them", func() {
+				settingsService.Settings.Env.Bosh.Logs.SkipSymlinks = true
+
+				tempDir, err := copier.FilteredCopyToTemp(logsDir, []string{"**/*.log"})
+				Expect(err).ToNot(HaveOccurred())
+				defer copier.CleanUp(tempDir)
+
+				Expect(copiedFiles(tempDir)).To(ConsistOf(
+					"app/app.stdout.log",
+					"app/app.stderr.log",
+					"worker/worker.log",
+				))
+			})
+		})
+
+		Context("when symlinks form a cycle", func() {
+			BeforeEach(func() {
+				Expect(os.Symlink(logsDir, filepath.Join(logsDir, "app", "loop"))).To(Succeed())
+				Expect(os.Symlink(filepath.Join(logsDir, "worker", "back"), filepath.Join(logsDir, "app", "back"))).To(Succeed())
+				Expect(os.Symlink(filepath.Join(logsDir, "app"), filepath.Join(logsDir, "worker", "back"))).To(Succeed())
+			})
+
+			It("copies each file once without following the cycle", func() {
+				tempDir, err := copier.FilteredCopyToTemp(logsDir, []string{"**/*.log"})
+				Expect(err).ToNot(HaveOccurred())
+				defer copier.CleanUp(tempDir)
+
+				Expect(copiedFiles(tempDir)).To(ConsistOf(
+					"app/app.stdout.log",
+					"app/app.stderr.log",
+					"worker/worker.log",
+					"worker/back/app.stdout.log",
+					"worker/back/app.stderr.log",
+				))
+			})
+		})
+
+		It("passes the files left after filtering to the wrapped copier", func() {
+			fakeCopier := fakecmd.NewFakeCopier()
+			fakeCopier.FilteredCopyToTempTempDir = "/fake-temp-dir"
+			copier = NewLogsCopier(fakeCopier, settingsService)
+
+			tempDir, err := copier.FilteredCopyToTemp(logsDir, []string{"worker/*", "!**/*.gz"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tempDir).To(Equal("/fake-temp-dir"))
+
+			Expect(fakeCopier.FilteredCopyToTempDir).To(Equal(logsDir))
+			Expect(fakeCopier.FilteredCopyToTempFilters).To(Equal([]string{filepath.Join("worker", "worker.log")}))
+		})
+	})
+
+	Describe("CleanUp", func() {
+		It("cleans up through the wrapped copier", func() {
+			fakeCopier := fakecmd.NewFakeCopier()
+			NewLogsCopier(fakeCopier, settingsService).CleanUp("/fake-temp-dir")
+
+			Expect(fakeCopier.CleanUpTempDir).To(Equal("/fake-temp-dir"))
+		})
+	})
+})
@@ -0,0 +1,83 @@
+package action
+
+import (
+	"errors"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshcmd "github.com/cloudfoundry/bosh-utils/fileutil"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+var errLogsTarballTooLarge = errors.New("logs tarball exceeds maximum size")
+
+// logsTarball compresses the copied logs. With a maximum size the tarball is
+// streamed from tar so that compression is aborted as soon as it grows too large.
+func logsTarball(
+	compressor boshcmd.Compressor,
+	runner boshsys.CmdRunner,
+	fs boshsys.FileSystem,
+	dir string,
+	maxSize uint64,
+) (string, error) {
+	if maxSize == 0 {
+		tarball, err := compressor.CompressFilesInDir(dir)
+		if err != nil {
+			return "", bosherr.WrapError(err, "Making logs tarball")
+		}
+
+		return tarball, nil
+	}
+
+	file, err := fs.TempFile("bosh-agent-logs-tarball")
+	if err != nil {
+		return "", bosherr.WrapError(err, "Creating temporary file for logs tarball")
+	}
+
+	tarball := file.Name()
+	writer := &sizeLimitedWriter{writer: file, maxSize: maxSize}
+
+	_, _, _, err = runner.RunComplexCommand(boshsys.Command{
+		Name:   "tar",
+		Args:   []string{"czf", "-", "-C", dir, "."},
+		Stdout: writer,
+	})
+
+	closeErr := file.Close()
+
+	if writer.exceeded {
+		_ = fs.RemoveAll(tarball)
+		return "", bosherr.Errorf("Logs exceed maximum size of %d bytes", maxSize)
+	}
+
+	if err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		_ = fs.RemoveAll(tarball)
+		return "", bosherr.WrapError(err, "Making logs tarball")
+	}
+
+	return tarball, nil
+}
+
+// sizeLimitedWriter fails every write once more than maxSize bytes were
+// written, which makes the command producing the output fail with a broken pipe
+type sizeLimitedWriter struct {
+	writer   boshsys.File
+	maxSize  uint64
+	written  uint64
+	exceeded bool
+}
+
+func (w *sizeLimitedWriter) Write(p []byte) (int, error) {
+	if w.exceeded || w.written+uint64(len(p)) > w.maxSize {
+		w.exceeded = true
+		return 0, errLogsTarballTooLarge
+	}
+
+	n, err := w.writer.Write(p)
+	w.written += uint64(n)
+
+	return n, err
+}
@@ -0,0 +1,56 @@
+package action
+
+import (
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+// PortProber checks whether a local TCP address can be listened on
+type PortProber interface {
+	// Probe binds address and releases it right away
+	Probe(network, address string) error
+
+	// Owner describes the process listening on port; empty when it cannot be determined
+	Owner(port int) (string, error)
+}
+
+// Matches the users column of ss, e.g. users:(("nats",pid=123,fd=6))
+var ssListenerProcessPattern = regexp.MustCompile(`users:\(\("([^"]+)",pid=(\d+)`)
+
+type netPortProber struct {
+	cmdRunner boshsys.CmdRunner
+}
+
+func NewPortProber(cmdRunner boshsys.CmdRunner) PortProber {
+	return netPortProber{cmdRunner: cmdRunner}
+}
+
+func (p netPortProber) Probe(network, address string) error {
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		return err
+	}
+
+	return listener.Close()
+}
+
+func (p netPortProber) Owner(port int) (string, error) {
+	stdout, _, _, err := p.cmdRunner.RunCommand("ss", "-H", "-ltnp", "sport", "=", ":"+strconv.Itoa(port))
+	if err != nil {
+		return "", bosherr.WrapError(err, "Listing listening sockets")
+	}
+
+	for _, line := range strings.Split(stdout, "\n") {
+		matches := ssListenerProcessPattern.FindStringSubmatch(line)
+		if matches != nil {
+			return matches[1] + " (pid " + matches[2] + ")", nil
+		}
+	}
+
+	return "", nil
+}
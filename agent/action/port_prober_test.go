@@ -0,0 +1,71 @@
+package action_test
+
+import (
+	"errors"
+	"net"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-agent/agent/action"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+)
+
+var _ = Describe("PortProber", func() {
+	var (
+		cmdRunner *fakesys.FakeCmdRunner
+		prober    PortProber
+	)
+
+	BeforeEach(func() {
+		cmdRunner = fakesys.NewFakeCmdRunner()
+		prober = NewPortProber(cmdRunner)
+	})
+
+	Describe("Probe", func() {
+		It("succeeds for a free port", func() {
+			Expect(prober.Probe("tcp4", "127.0.0.1:0")).To(Succeed())
+		})
+
+		It("returns an error for a port with a listener", func() {
+			listener, err := net.Listen("tcp4", "127.0.0.1:0")
+			Expect(err).ToNot(HaveOccurred())
+			defer listener.Close()
+
+			err = prober.Probe("tcp4", listener.Addr().String())
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Owner", func() {
+		It("returns the process listening on the port", func() {
+			cmdRunner.AddCmdResult("ss -H -ltnp sport = :6868", fakesys.FakeCmdResult{
+				Stdout: `LISTEN 0      128    0.0.0.0:6868    0.0.0.0:*    users:(("nginx",pid=42,fd=6))` + "\n",
+			})
+
+			owner, err := prober.Owner(6868)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(owner).To(Equal("nginx (pid 42)"))
+		})
+
+		It("returns an empty owner when the process is not reported", func() {
+			cmdRunner.AddCmdResult("ss -H -ltnp sport = :6868", fakesys.FakeCmdResult{
+				Stdout: "LISTEN 0      128    0.0.0.0:6868    0.0.0.0:*\n",
+			})
+
+			owner, err := prober.Owner(6868)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(owner).To(BeEmpty())
+		})
+
+		It("returns an error when listing sockets fails", func() {
+			cmdRunner.AddCmdResult("ss -H -ltnp sport = :6868", fakesys.FakeCmdResult{
+				Error: errors.New("fake-ss-err"),
+			})
+
+			_, err := prober.Owner(6868)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-ss-err"))
+		})
+	})
+})
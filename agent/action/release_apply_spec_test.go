@@ -5,21 +5,20 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	fakefs "github.com/cloudfoundry/bosh-agent/platform/fakefs"
 	"github.com/cloudfoundry/bosh-agent/platform/platformfakes"
-
-	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
 )
 
 var _ = Describe("ReleaseApplySpec", func() {
 	var (
 		platform   *platformfakes.FakePlatform
 		action     ReleaseApplySpecAction
-		fileSystem *fakesys.FakeFileSystem
+		fileSystem *fakefs.FakeFileSystem
 	)
 
 	BeforeEach(func() {
 		platform = &platformfakes.FakePlatform{}
-		fileSystem = fakesys.NewFakeFileSystem()
+		fileSystem = fakefs.NewFakeFileSystem()
 		platform.GetFsReturns(fileSystem)
 		action = NewReleaseApplySpec(platform)
 	})
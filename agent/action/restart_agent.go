@@ -0,0 +1,146 @@
+package action
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+
+	boshtask "github.com/cloudfoundry/bosh-agent/agent/task"
+	boshdirs "github.com/cloudfoundry/bosh-agent/settings/directories"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+const (
+	restartAgentTaskDrainTimeout  = 30 * time.Second
+	restartAgentTaskPollInterval  = 1 * time.Second
+	restartAgentMinInterval       = 5 * time.Minute
+	restartAgentWaitToKillAgent   = 2 * time.Second
+	restartAgentLastRestartedFile = "last_agent_restart"
+)
+
+type RestartAgentAction struct {
+	taskManager boshtask.Manager
+	killer      Killer
+	fs          boshsys.FileSystem
+	dirProvider boshdirs.Provider
+	timeService clock.Clock
+	logger      boshlog.Logger
+	logTag      string
+}
+
+func NewRestartAgent(
+	taskManager boshtask.Manager,
+	killer Killer,
+	fs boshsys.FileSystem,
+	dirProvider boshdirs.Provider,
+	timeService clock.Clock,
+	logger boshlog.Logger,
+) RestartAgentAction {
+	return RestartAgentAction{
+		taskManager: taskManager,
+		killer:      killer,
+		fs:          fs,
+		dirProvider: dirProvider,
+		timeService: timeService,
+		logger:      logger,
+		logTag:      "Restart Agent Action",
+	}
+}
+
+func (a RestartAgentAction) IsAsynchronous(_ ProtocolVersion) bool {
+	return false
+}
+
+func (a RestartAgentAction) IsPersistent() bool {
+	return false
+}
+
+func (a RestartAgentAction) IsLoggable() bool {
+	return true
+}
+
+// Run waits for in-flight persistent tasks to finish and schedules the agent
+// to exit shortly after responding; the process manager then starts it again
+func (a RestartAgentAction) Run() (string, error) {
+	lastRestartedPath := filepath.Join(a.dirProvider.BoshDir(), restartAgentLastRestartedFile)
+
+	err := a.checkRestartLoop(lastRestartedPath)
+	if err != nil {
+		return "", err
+	}
+
+	err = a.waitForTasks()
+	if err != nil {
+		return "", err
+	}
+
+	err = a.fs.WriteFileString(lastRestartedPath, a.timeService.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return "", bosherr.WrapError(err, "Recording agent restart")
+	}
+
+	a.logger.Info(a.logTag, "Restarting agent in %s", restartAgentWaitToKillAgent)
+
+	go a.killer.KillAgent(restartAgentWaitToKillAgent)
+
+	return "restarting", nil
+}
+
+func (a RestartAgentAction) checkRestartLoop(lastRestartedPath string) error {
+	if !a.fs.FileExists(lastRestartedPath) {
+		return nil
+	}
+
+	contents, err := a.fs.ReadFileString(lastRestartedPath)
+	if err != nil {
+		return bosherr.WrapError(err, "Reading last agent restart time")
+	}
+
+	lastRestarted, err := time.Parse(time.RFC3339, strings.TrimSpace(contents))
+	if err != nil {
+		a.logger.Warn(a.logTag, "Ignoring unparsable last agent restart time '%s'", contents)
+		return nil
+	}
+
+	sinceLastRestart := a.timeService.Since(lastRestarted)
+	if sinceLastRestart < restartAgentMinInterval {
+		return bosherr.Errorf("Agent was restarted %s ago, refusing to restart again within %s", sinceLastRestart, restartAgentMinInterval)
+	}
+
+	return nil
+}
+
+func (a RestartAgentAction) waitForTasks() error {
+	deadline := a.timeService.Now().Add(restartAgentTaskDrainTimeout)
+
+	for {
+		taskInfos, err := a.taskManager.GetInfos()
+		if err != nil {
+			return bosherr.WrapError(err, "Getting in-flight tasks")
+		}
+
+		if len(taskInfos) == 0 {
+			return nil
+		}
+
+		if !a.timeService.Now().Before(deadline) {
+			return bosherr.Errorf("Timed out waiting for %d in-flight task(s) to finish", len(taskInfos))
+		}
+
+		a.logger.Debug(a.logTag, "Waiting for %d in-flight task(s) to finish", len(taskInfos))
+		a.timeService.Sleep(restartAgentTaskPollInterval)
+	}
+}
+
+func (a RestartAgentAction) Resume() (interface{}, error) {
+	return nil, errors.New("not supported")
+}
+
+func (a RestartAgentAction) Cancel() error {
+	return errors.New("not supported")
+}
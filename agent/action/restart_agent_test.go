@@ -0,0 +1,149 @@
+package action_test
+
+import (
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-agent/agent/action"
+	fakeaction "github.com/cloudfoundry/bosh-agent/agent/action/fakes"
+	boshtask "github.com/cloudfoundry/bosh-agent/agent/task"
+	faketask "github.com/cloudfoundry/bosh-agent/agent/task/fakes"
+	fakefs "github.com/cloudfoundry/bosh-agent/platform/fakefs"
+	boshdirs "github.com/cloudfoundry/bosh-agent/settings/directories"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+)
+
+var _ = Describe("RestartAgentAction", func() {
+	const lastRestartedPath = "/var/vcap/bosh/last_agent_restart"
+
+	var (
+		taskManager *faketask.FakeManager
+		killer      *fakeaction.FakeAgentKiller
+		fs          *fakefs.FakeFileSystem
+		timeService *fakeclock.FakeClock
+		action      RestartAgentAction
+	)
+
+	BeforeEach(func() {
+		taskManager = faketask.NewFakeManager()
+		killer = fakeaction.NewFakeAgentKiller()
+		fs = fakefs.NewFakeFileSystem()
+		timeService = fakeclock.NewFakeClock(time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC))
+		logger := boshlog.NewLogger(boshlog.LevelNone)
+
+		action = NewRestartAgent(taskManager, killer, fs, boshdirs.NewProvider("/var/vcap"), timeService, logger)
+	})
+
+	AssertActionIsNotAsynchronous(action)
+	AssertActionIsNotPersistent(action)
+	AssertActionIsLoggable(action)
+
+	AssertActionIsNotResumable(action)
+	AssertActionIsNotCancelable(action)
+
+	Describe("Run", func() {
+		It("records the restart and kills the agent after responding", func() {
+			result, err := action.Run()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal("restarting"))
+
+			Eventually(killer.KillAgentIntervals).Should(Equal([]time.Duration{2 * time.Second}))
+
+			contents, err := fs.ReadFileString(lastRestartedPath)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(contents).To(Equal("2020-01-01T12:00:00Z"))
+		})
+
+		Context("when persistent tasks are in flight", func() {
+			BeforeEach(func() {
+				err := taskManager.AddInfo(boshtask.Info{TaskID: "fake-task-id", Method: "apply"})
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("waits for them to finish before killing the agent", func() {
+				done := make(chan error)
+				go func() {
+					_, err := action.Run()
+					done <- err
+				}()
+
+				timeService.WaitForWatcherAndIncrement(time.Second)
+				Consistently(killer.KillAgentIntervals).Should(BeEmpty())
+				Expect(fs.FileExists(lastRestartedPath)).To(BeFalse())
+
+				err := taskManager.RemoveInfo("fake-task-id")
+				Expect(err).ToNot(HaveOccurred())
+				timeService.WaitForWatcherAndIncrement(time.Second)
+
+				Eventually(done).Should(Receive(BeNil()))
+				Eventually(killer.KillAgentIntervals).Should(HaveLen(1))
+			})
+
+			It("gives up without killing the agent when they do not finish in time", func() {
+				done := make(chan error)
+				go func() {
+					_, err := action.Run()
+					done <- err
+				}()
+
+				for i := 0; i < 30; i++ {
+					timeService.WaitForWatcherAndIncrement(time.Second)
+				}
+
+				var err error
+				Eventually(done).Should(Receive(&err))
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Timed out waiting for 1 in-flight task(s) to finish"))
+
+				Expect(killer.KillAgentIntervals()).To(BeEmpty())
+			})
+		})
+
+		Context("when the agent was restarted recently", func() {
+			It("refuses to restart again", func() {
+				err := fs.WriteFileString(lastRestartedPath, "2020-01-01T11:58:00Z")
+				Expect(err).ToNot(HaveOccurred())
+
+				_, err = action.Run()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Agent was restarted 2m0s ago, refusing to restart again within 5m0s"))
+
+				Consistently(killer.KillAgentIntervals).Should(BeEmpty())
+			})
+
+			It("restarts once enough time has passed", func() {
+				err := fs.WriteFileString(lastRestartedPath, "2020-01-01T11:50:00Z")
+				Expect(err).ToNot(HaveOccurred())
+
+				_, err = action.Run()
+				Expect(err).ToNot(HaveOccurred())
+
+				Eventually(killer.KillAgentIntervals).Should(HaveLen(1))
+			})
+		})
+
+		It("ignores an unparsable last restart time", func() {
+			err := fs.WriteFileString(lastRestartedPath, "garbage")
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = action.Run()
+			Expect(err).ToNot(HaveOccurred())
+
+			Eventually(killer.KillAgentIntervals).Should(HaveLen(1))
+		})
+
+		It("does not kill the agent when the restart cannot be recorded", func() {
+			fs.WriteFileError = errors.New("fake-write-error")
+
+			_, err := action.Run()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-write-error"))
+
+			Consistently(killer.KillAgentIntervals).Should(BeEmpty())
+		})
+	})
+})
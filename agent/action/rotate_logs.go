@@ -0,0 +1,212 @@
+package action
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+
+	boshdirs "github.com/cloudfoundry/bosh-agent/settings/directories"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+const (
+	DefaultRotateLogsKeep = 5
+
+	// Sorts lexically in the order the rotations were made
+	rotatedLogTimeFormat = "20060102T150405Z"
+)
+
+type RotateLogsRequest struct {
+	// Rotates the logs of all jobs when empty
+	Job string `json:"job"`
+
+	// Rotations kept per log file including the new one, DefaultRotateLogsKeep when zero
+	Keep int `json:"keep"`
+}
+
+type RotateLogsResponse struct {
+	Rotated []string `json:"rotated"`
+	Removed []string `json:"removed"`
+}
+
+type RotateLogsAction struct {
+	fs          boshsys.FileSystem
+	settingsDir boshdirs.Provider
+	timeService clock.Clock
+}
+
+func NewRotateLogs(fs boshsys.FileSystem, settingsDir boshdirs.Provider, timeService clock.Clock) RotateLogsAction {
+	return RotateLogsAction{
+		fs:          fs,
+		settingsDir: settingsDir,
+		timeService: timeService,
+	}
+}
+
+func (a RotateLogsAction) IsAsynchronous(_ ProtocolVersion) bool {
+	return false
+}
+
+func (a RotateLogsAction) IsPersistent() bool {
+	return false
+}
+
+func (a RotateLogsAction) IsLoggable() bool {
+	return true
+}
+
+// Run copies every *.log file below the job log directories to a timestamped
+// name and truncates it in place, like logrotate's copytruncate, so that jobs
+// keep writing to the same file with its owner and mode, and removes the
+// oldest rotations of each log file beyond the keep count
+func (a RotateLogsAction) Run(request RotateLogsRequest) (RotateLogsResponse, error) {
+	response := RotateLogsResponse{Rotated: []string{}, Removed: []string{}}
+
+	if request.Keep < 0 {
+		return response, bosherr.Errorf("Invalid keep count %d, must not be negative", request.Keep)
+	}
+
+	keep := request.Keep
+	if keep == 0 {
+		keep = DefaultRotateLogsKeep
+	}
+
+	logsDir := a.settingsDir.LogsDir()
+
+	if request.Job != "" {
+		if strings.Contains(request.Job, "/") || strings.Contains(request.Job, "..") {
+			return response, bosherr.Errorf("Invalid job name '%s'", request.Job)
+		}
+
+		logsDir = filepath.Join(logsDir, request.Job)
+
+		if !a.fs.FileExists(logsDir) {
+			return response, bosherr.Errorf("No logs found for job '%s'", request.Job)
+		}
+	}
+
+	activeLogs, rotations, err := a.findLogs(logsDir)
+	if err != nil {
+		return response, err
+	}
+
+	suffix := "." + a.timeService.Now().UTC().Format(rotatedLogTimeFormat)
+
+	for _, logPath := range activeLogs {
+		rotatedPath := logPath + suffix
+
+		err = a.rotate(logPath, rotatedPath)
+		if err != nil {
+			return response, err
+		}
+
+		response.Rotated = append(response.Rotated, logPath)
+
+		removed, err := a.removeOldRotations(append(rotations[logPath], rotatedPath), keep)
+		response.Removed = append(response.Removed, removed...)
+		if err != nil {
+			return response, err
+		}
+	}
+
+	return response, nil
+}
+
+// findLogs returns the sorted active log files below dir and the previous
+// rotations of each of them
+func (a RotateLogsAction) findLogs(dir string) ([]string, map[string][]string, error) {
+	files := []string{}
+
+	err := a.fs.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !info.IsDir() {
+			files = append(files, path)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, nil, bosherr.WrapErrorf(err, "Finding log files in '%s'", dir)
+	}
+
+	activeLogs := []string{}
+	for _, path := range files {
+		if strings.HasSuffix(path, ".log") {
+			activeLogs = append(activeLogs, path)
+		}
+	}
+
+	sort.Strings(activeLogs)
+
+	rotations := map[string][]string{}
+	for _, logPath := range activeLogs {
+		for _, path := range files {
+			if isRotationOf(path, logPath) {
+				rotations[logPath] = append(rotations[logPath], path)
+			}
+		}
+	}
+
+	return activeLogs, rotations, nil
+}
+
+func (a RotateLogsAction) rotate(logPath, rotatedPath string) error {
+	err := a.fs.CopyFile(logPath, rotatedPath)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Rotating log file '%s'", logPath)
+	}
+
+	// Writing an existing file truncates it without replacing it
+	err = a.fs.WriteFile(logPath, []byte{})
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Truncating log file '%s'", logPath)
+	}
+
+	return nil
+}
+
+func (a RotateLogsAction) removeOldRotations(rotations []string, keep int) ([]string, error) {
+	removed := []string{}
+
+	sort.Strings(rotations)
+
+	for i := 0; i < len(rotations)-keep; i++ {
+		err := a.fs.RemoveAll(rotations[i])
+		if err != nil {
+			return removed, bosherr.WrapErrorf(err, "Removing old rotation '%s'", rotations[i])
+		}
+
+		removed = append(removed, rotations[i])
+	}
+
+	return removed, nil
+}
+
+// isRotationOf only matches names this action rotated logPath to, leaving
+// e.g. compressed copies made by logrotate alone
+func isRotationOf(path, logPath string) bool {
+	if !strings.HasPrefix(path, logPath+".") {
+		return false
+	}
+
+	_, err := time.Parse(rotatedLogTimeFormat, strings.TrimPrefix(path, logPath+"."))
+
+	return err == nil
+}
+
+func (a RotateLogsAction) Resume() (interface{}, error) {
+	return nil, errors.New("not supported")
+}
+
+func (a RotateLogsAction) Cancel() error {
+	return errors.New("not supported")
+}
@@ -0,0 +1,161 @@
+package action_test
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-agent/agent/action"
+	fakefs "github.com/cloudfoundry/bosh-agent/platform/fakefs"
+	boshdirs "github.com/cloudfoundry/bosh-agent/settings/directories"
+)
+
+var _ = Describe("RotateLogsAction", func() {
+	var (
+		fs        *fakefs.FakeFileSystem
+		fakeClock *fakeclock.FakeClock
+		action    RotateLogsAction
+	)
+
+	writeFile := func(path, contents string) {
+		err := fs.WriteFileString(path, contents)
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	BeforeEach(func() {
+		fs = fakefs.NewFakeFileSystem()
+		fakeClock = fakeclock.NewFakeClock(time.Date(2018, time.March, 4, 5, 6, 7, 0, time.UTC))
+		action = NewRotateLogs(fs, boshdirs.NewProvider("/var/vcap"), fakeClock)
+	})
+
+	AssertActionIsNotAsynchronous(action)
+	AssertActionIsNotPersistent(action)
+	AssertActionIsLoggable(action)
+
+	AssertActionIsNotResumable(action)
+	AssertActionIsNotCancelable(action)
+
+	Describe("Run", func() {
+		BeforeEach(func() {
+			writeFile("/var/vcap/sys/log/fake-job/fake-job.log", "fake-job-log")
+			writeFile("/var/vcap/sys/log/fake-job/nested/worker.log", "fake-worker-log")
+			writeFile("/var/vcap/sys/log/other-job/other-job.log", "other-job-log")
+
+			err := fs.Chmod("/var/vcap/sys/log/fake-job/fake-job.log", 0640)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("copies the log files of the job to timestamped names and truncates them in place", func() {
+			response, err := action.Run(RotateLogsRequest{Job: "fake-job"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response).To(Equal(RotateLogsResponse{
+				Rotated: []string{
+					"/var/vcap/sys/log/fake-job/fake-job.log",
+					"/var/vcap/sys/log/fake-job/nested/worker.log",
+				},
+				Removed: []string{},
+			}))
+
+			contents, err := fs.ReadFileString("/var/vcap/sys/log/fake-job/fake-job.log.20180304T050607Z")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(contents).To(Equal("fake-job-log"))
+
+			contents, err = fs.ReadFileString("/var/vcap/sys/log/fake-job/nested/worker.log.20180304T050607Z")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(contents).To(Equal("fake-worker-log"))
+
+			contents, err = fs.ReadFileString("/var/vcap/sys/log/fake-job/fake-job.log")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(contents).To(BeEmpty())
+			Expect(fs.GetFileTestStat("/var/vcap/sys/log/fake-job/fake-job.log").FileMode).To(Equal(os.FileMode(0640)))
+			Expect(fs.RenameOldPaths).To(BeEmpty())
+
+			contents, err = fs.ReadFileString("/var/vcap/sys/log/other-job/other-job.log")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(contents).To(Equal("other-job-log"))
+		})
+
+		It("rotates the logs of all jobs when no job is given", func() {
+			response, err := action.Run(RotateLogsRequest{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Rotated).To(Equal([]string{
+				"/var/vcap/sys/log/fake-job/fake-job.log",
+				"/var/vcap/sys/log/fake-job/nested/worker.log",
+				"/var/vcap/sys/log/other-job/other-job.log",
+			}))
+
+			Expect(fs.FileExists("/var/vcap/sys/log/other-job/other-job.log.20180304T050607Z")).To(BeTrue())
+		})
+
+		It("deletes the oldest rotations beyond the keep count", func() {
+			writeFile("/var/vcap/sys/log/fake-job/fake-job.log.20180101T000000Z", "")
+			writeFile("/var/vcap/sys/log/fake-job/fake-job.log.20180201T000000Z", "")
+			writeFile("/var/vcap/sys/log/fake-job/fake-job.log.20180301T000000Z", "")
+			writeFile("/var/vcap/sys/log/fake-job/fake-job.log.1.gz", "")
+
+			response, err := action.Run(RotateLogsRequest{Job: "fake-job", Keep: 2})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Removed).To(Equal([]string{
+				"/var/vcap/sys/log/fake-job/fake-job.log.20180101T000000Z",
+				"/var/vcap/sys/log/fake-job/fake-job.log.20180201T000000Z",
+			}))
+
+			Expect(fs.FileExists("/var/vcap/sys/log/fake-job/fake-job.log.20180101T000000Z")).To(BeFalse())
+			Expect(fs.FileExists("/var/vcap/sys/log/fake-job/fake-job.log.20180201T000000Z")).To(BeFalse())
+			Expect(fs.FileExists("/var/vcap/sys/log/fake-job/fake-job.log.20180301T000000Z")).To(BeTrue())
+			Expect(fs.FileExists("/var/vcap/sys/log/fake-job/fake-job.log.20180304T050607Z")).To(BeTrue())
+			Expect(fs.FileExists("/var/vcap/sys/log/fake-job/fake-job.log.1.gz")).To(BeTrue())
+		})
+
+		It("keeps the default number of rotations when no keep count is given", func() {
+			for month := 1; month <= DefaultRotateLogsKeep; month++ {
+				writeFile(time.Date(2017, time.Month(month), 1, 0, 0, 0, 0, time.UTC).Format("/var/vcap/sys/log/fake-job/fake-job.log.20060102T150405Z"), "")
+			}
+
+			response, err := action.Run(RotateLogsRequest{Job: "fake-job"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Removed).To(Equal([]string{"/var/vcap/sys/log/fake-job/fake-job.log.20170101T000000Z"}))
+		})
+
+		It("returns an error when the job has no log directory", func() {
+			_, err := action.Run(RotateLogsRequest{Job: "missing-job"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("No logs found for job 'missing-job'"))
+		})
+
+		It("returns an error for job names leaving the log directory", func() {
+			_, err := action.Run(RotateLogsRequest{Job: "../fake-job"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Invalid job name '../fake-job'"))
+		})
+
+		It("returns an error for a negative keep count", func() {
+			_, err := action.Run(RotateLogsRequest{Keep: -1})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Invalid keep count -1, must not be negative"))
+		})
+
+		It("returns an error when a log file cannot be copied", func() {
+			fs.CopyFileError = errors.New("fake-copy-error")
+
+			_, err := action.Run(RotateLogsRequest{Job: "fake-job"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Rotating log file '/var/vcap/sys/log/fake-job/fake-job.log': fake-copy-error"))
+		})
+
+		It("returns an error when an old rotation cannot be removed", func() {
+			writeFile("/var/vcap/sys/log/fake-job/fake-job.log.20180101T000000Z", "")
+			fs.RemoveAllStub = func(path string) error {
+				return errors.New("fake-remove-error")
+			}
+
+			_, err := action.Run(RotateLogsRequest{Job: "fake-job", Keep: 1})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Removing old rotation '/var/vcap/sys/log/fake-job/fake-job.log.20180101T000000Z': fake-remove-error"))
+		})
+	})
+})
@@ -2,20 +2,62 @@ package action
 
 import (
 	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	boshas "github.com/cloudfoundry/bosh-agent/agent/applier/applyspec"
 	boshscript "github.com/cloudfoundry/bosh-agent/agent/script"
+	boshdirs "github.com/cloudfoundry/bosh-agent/settings/directories"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 )
 
 type RunScriptOptions struct {
 	Env map[string]string `json:"env"`
+
+	// Timeout in seconds after which the scripts are killed; zero means no timeout
+	Timeout int `json:"timeout"`
+
+	// Interleave stderr with stdout into the script's stdout log
+	CombineOutput bool `json:"combine_output"`
+
+	// User to run the scripts as, e.g. vcap; scripts run as root when empty
+	RunAs string `json:"run_as"`
+
+	// Number of times a failing script is re-run, e.g. for flaky post-deploy hooks
+	Retries int `json:"retries"`
+
+	// Duration to wait between re-runs, e.g. "5s"
+	RetryDelay string `json:"retry_delay"`
+
+	// Maximum number of job scripts running at the same time; zero runs all of them at once
+	MaxConcurrency int `json:"max_concurrency"`
+
+	// Jobs whose scripts have to succeed before the script of a job runs, keyed by job name
+	DependsOn map[string][]string `json:"depends_on"`
+
+	// Octal mode of the log directories created for the scripts, e.g. "0755"; 0750 when empty
+	LogDirMode string `json:"log_dir_mode"`
+
+	// Patterns such as "AWS_*" limiting which of the agent's environment variables
+	// the scripts inherit; env is always passed on
+	EnvAllowlist []string `json:"env_allowlist"`
+	EnvDenylist  []string `json:"env_denylist"`
+
+	// Path the PID of each running script is written to, e.g. for monit to track
+	// scripts that fork; relative to the run directory of the script's job so
+	// that the scripts of different jobs do not clash
+	PidFile string `json:"pidfile"`
 }
 
 type RunScriptAction struct {
 	scriptProvider boshscript.JobScriptProvider
 	specService    boshas.V1Service
+	dirProvider    boshdirs.Provider
 
 	logTag string
 	logger boshlog.Logger
@@ -24,11 +66,13 @@ type RunScriptAction struct {
 func NewRunScript(
 	scriptProvider boshscript.JobScriptProvider,
 	specService boshas.V1Service,
+	dirProvider boshdirs.Provider,
 	logger boshlog.Logger,
 ) RunScriptAction {
 	return RunScriptAction{
 		scriptProvider: scriptProvider,
 		specService:    specService,
+		dirProvider:    dirProvider,
 
 		logTag: "RunScript Action",
 		logger: logger,
@@ -56,13 +100,80 @@ func (a RunScriptAction) Run(scriptName string, options RunScriptOptions) (map[s
 		return emptyResults, bosherr.WrapError(err, "Getting current spec")
 	}
 
+	if options.Timeout < 0 {
+		return emptyResults, bosherr.Errorf("Invalid script timeout %d, must not be negative", options.Timeout)
+	}
+
+	if options.MaxConcurrency < 0 {
+		return emptyResults, bosherr.Errorf("Invalid script max concurrency %d, must not be negative", options.MaxConcurrency)
+	}
+
+	if options.Retries < 0 {
+		return emptyResults, bosherr.Errorf("Invalid script retries %d, must not be negative", options.Retries)
+	}
+
+	var retryDelay time.Duration
+
+	if options.RetryDelay != "" {
+		retryDelay, err = time.ParseDuration(options.RetryDelay)
+		if err != nil {
+			return emptyResults, bosherr.WrapErrorf(err, "Parsing script retry delay '%s'", options.RetryDelay)
+		}
+
+		if retryDelay < 0 {
+			return emptyResults, bosherr.Errorf("Invalid script retry delay '%s', must not be negative", options.RetryDelay)
+		}
+	}
+
+	var logDirMode os.FileMode
+
+	if options.LogDirMode != "" {
+		parsedMode, err := strconv.ParseUint(options.LogDirMode, 8, 32)
+		if err != nil {
+			return emptyResults, bosherr.WrapErrorf(err, "Parsing script log dir mode '%s'", options.LogDirMode)
+		}
+
+		if parsedMode == 0 || parsedMode > uint64(os.ModePerm) {
+			return emptyResults, bosherr.Errorf("Invalid script log dir mode '%s', must be between 0001 and 0777", options.LogDirMode)
+		}
+
+		logDirMode = os.FileMode(parsedMode)
+	}
+
+	for _, pattern := range append(append([]string{}, options.EnvAllowlist...), options.EnvDenylist...) {
+		_, err := path.Match(pattern, "")
+		if err != nil {
+			return emptyResults, bosherr.WrapErrorf(err, "Invalid script env pattern '%s'", pattern)
+		}
+	}
+
+	pidFile := filepath.Clean(options.PidFile)
+	if options.PidFile != "" && (filepath.IsAbs(pidFile) || pidFile == "." || pidFile == ".." || strings.HasPrefix(pidFile, ".."+string(filepath.Separator))) {
+		return emptyResults, bosherr.Errorf("Invalid script pid file '%s', must be a path within the job's run directory", options.PidFile)
+	}
+
+	scriptOpts := boshscript.Options{
+		Timeout:       time.Duration(options.Timeout) * time.Second,
+		CombineOutput: options.CombineOutput,
+		RunAs:         options.RunAs,
+		Retries:       options.Retries,
+		RetryDelay:    retryDelay,
+		LogDirMode:    logDirMode,
+		EnvAllowlist:  options.EnvAllowlist,
+		EnvDenylist:   options.EnvDenylist,
+	}
+
 	var scripts []boshscript.Script
 	for _, job := range currentSpec.Jobs() {
-		script := a.scriptProvider.NewScript(job.BundleName(), scriptName, options.Env)
+		if options.PidFile != "" {
+			scriptOpts.PidFile = filepath.Join(a.dirProvider.JobRunDir(job.BundleName()), pidFile)
+		}
+
+		script := a.scriptProvider.NewScript(job.BundleName(), scriptName, options.Env, scriptOpts)
 		scripts = append(scripts, script)
 	}
 
-	parallelScript := a.scriptProvider.NewParallelScript(scriptName, scripts)
+	parallelScript := a.scriptProvider.NewParallelScript(scriptName, scripts, options.MaxConcurrency, options.DependsOn)
 
 	return emptyResults, parallelScript.Run()
 }
@@ -2,6 +2,9 @@ package action_test
 
 import (
 	"errors"
+	"os"
+	"path/filepath"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -11,6 +14,7 @@ import (
 	fakeapplyspec "github.com/cloudfoundry/bosh-agent/agent/applier/applyspec/fakes"
 	boshscript "github.com/cloudfoundry/bosh-agent/agent/script"
 	"github.com/cloudfoundry/bosh-agent/agent/script/scriptfakes"
+	boshdirs "github.com/cloudfoundry/bosh-agent/settings/directories"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 )
 
@@ -27,7 +31,7 @@ var _ = Describe("RunScript", func() {
 		specService = fakeapplyspec.NewFakeV1Service()
 		specService.Spec.RenderedTemplatesArchiveSpec = &applyspec.RenderedTemplatesArchiveSpec{}
 		logger := boshlog.NewLogger(boshlog.LevelNone)
-		action = NewRunScript(fakeJobScriptProvider, specService, logger)
+		action = NewRunScript(fakeJobScriptProvider, specService, boshdirs.NewProvider("/fake-base-dir"), logger)
 		options = RunScriptOptions{
 			Env: map[string]string{
 				"FOO": "foo",
@@ -67,9 +71,10 @@ var _ = Describe("RunScript", func() {
 				script2 := &scriptfakes.FakeScript{}
 				script2.TagReturns("fake-job-2")
 
-				fakeJobScriptProvider.NewScriptStub = func(jobName, scriptName string, scriptEnv map[string]string) boshscript.Script {
+				fakeJobScriptProvider.NewScriptStub = func(jobName, scriptName string, scriptEnv map[string]string, opts boshscript.Options) boshscript.Script {
 					Expect(scriptName).To(Equal("run-me"))
 					Expect(scriptEnv["FOO"]).To(Equal("foo"))
+					Expect(opts).To(Equal(boshscript.Options{}))
 
 					if jobName == "fake-job-1" {
 						return script1
@@ -88,9 +93,233 @@ var _ = Describe("RunScript", func() {
 
 				Expect(parallelScript.RunCallCount()).To(Equal(1))
 
-				scriptName, scripts := fakeJobScriptProvider.NewParallelScriptArgsForCall(0)
+				scriptName, scripts, maxConcurrency, dependsOn := fakeJobScriptProvider.NewParallelScriptArgsForCall(0)
 				Expect(scriptName).To(Equal("run-me"))
 				Expect(scripts).To(Equal([]boshscript.Script{script1, script2}))
+				Expect(maxConcurrency).To(Equal(0))
+				Expect(dependsOn).To(BeNil())
+			})
+
+			It("passes max_concurrency to the parallel script", func() {
+				createFakeJob("fake-job-1")
+				options.MaxConcurrency = 4
+
+				_, err := act()
+				Expect(err).ToNot(HaveOccurred())
+
+				_, _, maxConcurrency, _ := fakeJobScriptProvider.NewParallelScriptArgsForCall(0)
+				Expect(maxConcurrency).To(Equal(4))
+			})
+
+			It("passes depends_on to the parallel script", func() {
+				createFakeJob("fake-job-1")
+				createFakeJob("fake-job-2")
+				options.DependsOn = map[string][]string{"fake-job-2": {"fake-job-1"}}
+
+				_, err := act()
+				Expect(err).ToNot(HaveOccurred())
+
+				_, _, _, dependsOn := fakeJobScriptProvider.NewParallelScriptArgsForCall(0)
+				Expect(dependsOn).To(Equal(map[string][]string{"fake-job-2": {"fake-job-1"}}))
+			})
+
+			It("rejects a negative max_concurrency", func() {
+				createFakeJob("fake-job-1")
+				options.MaxConcurrency = -1
+
+				_, err := act()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Invalid script max concurrency -1, must not be negative"))
+				Expect(fakeJobScriptProvider.NewParallelScriptCallCount()).To(Equal(0))
+			})
+
+			It("passes the timeout to the job scripts", func() {
+				createFakeJob("fake-job-1")
+				options.Timeout = 30
+
+				_, err := act()
+				Expect(err).ToNot(HaveOccurred())
+
+				_, _, _, opts := fakeJobScriptProvider.NewScriptArgsForCall(0)
+				Expect(opts.Timeout).To(Equal(30 * time.Second))
+			})
+
+			It("passes combine_output to the job scripts", func() {
+				createFakeJob("fake-job-1")
+				options.CombineOutput = true
+
+				_, err := act()
+				Expect(err).ToNot(HaveOccurred())
+
+				_, _, _, opts := fakeJobScriptProvider.NewScriptArgsForCall(0)
+				Expect(opts.CombineOutput).To(BeTrue())
+			})
+
+			It("passes run_as to the job scripts", func() {
+				createFakeJob("fake-job-1")
+				options.RunAs = "vcap"
+
+				_, err := act()
+				Expect(err).ToNot(HaveOccurred())
+
+				_, _, _, opts := fakeJobScriptProvider.NewScriptArgsForCall(0)
+				Expect(opts.RunAs).To(Equal("vcap"))
+			})
+
+			It("rejects a negative timeout", func() {
+				createFakeJob("fake-job-1")
+				options.Timeout = -1
+
+				_, err := act()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Invalid script timeout -1, must not be negative"))
+				Expect(fakeJobScriptProvider.NewScriptCallCount()).To(Equal(0))
+			})
+
+			It("passes retries and retry_delay to the job scripts", func() {
+				createFakeJob("fake-job-1")
+				options.Retries = 3
+				options.RetryDelay = "5s"
+
+				_, err := act()
+				Expect(err).ToNot(HaveOccurred())
+
+				_, _, _, opts := fakeJobScriptProvider.NewScriptArgsForCall(0)
+				Expect(opts.Retries).To(Equal(3))
+				Expect(opts.RetryDelay).To(Equal(5 * time.Second))
+			})
+
+			It("rejects negative retries", func() {
+				createFakeJob("fake-job-1")
+				options.Retries = -1
+
+				_, err := act()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Invalid script retries -1, must not be negative"))
+				Expect(fakeJobScriptProvider.NewScriptCallCount()).To(Equal(0))
+			})
+
+			It("rejects an unparseable retry_delay", func() {
+				createFakeJob("fake-job-1")
+				options.RetryDelay = "soon"
+
+				_, err := act()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Parsing script retry delay 'soon'"))
+				Expect(fakeJobScriptProvider.NewScriptCallCount()).To(Equal(0))
+			})
+
+			It("rejects a negative retry_delay", func() {
+				createFakeJob("fake-job-1")
+				options.RetryDelay = "-1s"
+
+				_, err := act()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Invalid script retry delay '-1s', must not be negative"))
+				Expect(fakeJobScriptProvider.NewScriptCallCount()).To(Equal(0))
+			})
+
+			It("passes log_dir_mode to the job scripts", func() {
+				createFakeJob("fake-job-1")
+				options.LogDirMode = "0755"
+
+				_, err := act()
+				Expect(err).ToNot(HaveOccurred())
+
+				_, _, _, opts := fakeJobScriptProvider.NewScriptArgsForCall(0)
+				Expect(opts.LogDirMode).To(Equal(os.FileMode(0755)))
+			})
+
+			It("leaves the log dir mode to the scripts when log_dir_mode is not set", func() {
+				createFakeJob("fake-job-1")
+
+				_, err := act()
+				Expect(err).ToNot(HaveOccurred())
+
+				_, _, _, opts := fakeJobScriptProvider.NewScriptArgsForCall(0)
+				Expect(opts.LogDirMode).To(BeZero())
+			})
+
+			It("rejects a log_dir_mode that is not octal", func() {
+				createFakeJob("fake-job-1")
+				options.LogDirMode = "0799"
+
+				_, err := act()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Parsing script log dir mode '0799'"))
+				Expect(fakeJobScriptProvider.NewScriptCallCount()).To(Equal(0))
+			})
+
+			It("rejects a log_dir_mode that is not a permission mode", func() {
+				createFakeJob("fake-job-1")
+				options.LogDirMode = "4755"
+
+				_, err := act()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Invalid script log dir mode '4755', must be between 0001 and 0777"))
+				Expect(fakeJobScriptProvider.NewScriptCallCount()).To(Equal(0))
+			})
+
+			It("passes env_allowlist and env_denylist to the job scripts", func() {
+				createFakeJob("fake-job-1")
+				options.EnvAllowlist = []string{"HOME", "LANG"}
+				options.EnvDenylist = []string{"AWS_*"}
+
+				_, err := act()
+				Expect(err).ToNot(HaveOccurred())
+
+				_, _, _, opts := fakeJobScriptProvider.NewScriptArgsForCall(0)
+				Expect(opts.EnvAllowlist).To(Equal([]string{"HOME", "LANG"}))
+				Expect(opts.EnvDenylist).To(Equal([]string{"AWS_*"}))
+			})
+
+			It("rejects malformed env patterns", func() {
+				createFakeJob("fake-job-1")
+				options.EnvDenylist = []string{"AWS_["}
+
+				_, err := act()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Invalid script env pattern 'AWS_['"))
+				Expect(fakeJobScriptProvider.NewScriptCallCount()).To(Equal(0))
+			})
+
+			It("passes a pid file within the run directory of each job to the job scripts when pidfile is set", func() {
+				createFakeJob("fake-job-1")
+				createFakeJob("fake-job-2")
+				options.PidFile = "run-me.pid"
+
+				_, err := act()
+				Expect(err).ToNot(HaveOccurred())
+
+				_, _, _, opts := fakeJobScriptProvider.NewScriptArgsForCall(0)
+				Expect(opts.PidFile).To(Equal(filepath.Join("/fake-base-dir", "data", "sys", "run", "fake-job-1", "run-me.pid")))
+
+				_, _, _, opts = fakeJobScriptProvider.NewScriptArgsForCall(1)
+				Expect(opts.PidFile).To(Equal(filepath.Join("/fake-base-dir", "data", "sys", "run", "fake-job-2", "run-me.pid")))
+			})
+
+			It("does not pass a pid file when pidfile is not set", func() {
+				createFakeJob("fake-job-1")
+
+				_, err := act()
+				Expect(err).ToNot(HaveOccurred())
+
+				_, _, _, opts := fakeJobScriptProvider.NewScriptArgsForCall(0)
+				Expect(opts.PidFile).To(BeEmpty())
+			})
+
+			It("rejects pid files outside of the run directory of the jobs", func() {
+				createFakeJob("fake-job-1")
+
+				for _, pidFile := range []string{"/var/vcap/sys/run/run-me.pid", "../fake-job-2/run-me.pid", ".."} {
+					options.PidFile = pidFile
+
+					_, err := act()
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("Invalid script pid file '" + pidFile + "'"))
+				}
+
+				Expect(fakeJobScriptProvider.NewScriptCallCount()).To(Equal(0))
 			})
 
 			It("returns an error when parallel script fails", func() {
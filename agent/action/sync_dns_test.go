@@ -9,12 +9,12 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	fakefs "github.com/cloudfoundry/bosh-agent/platform/fakefs"
 	"github.com/cloudfoundry/bosh-agent/platform/platformfakes"
 
 	fakelogger "github.com/cloudfoundry/bosh-utils/logger/loggerfakes"
 
 	fakesettings "github.com/cloudfoundry/bosh-agent/settings/fakes"
-	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
 
 	boshsettings "github.com/cloudfoundry/bosh-agent/settings"
 	boshcrypto "github.com/cloudfoundry/bosh-utils/crypto"
@@ -26,7 +26,7 @@ var _ = Describe("SyncDNS", func() {
 		fakeBlobstore        *fakeblobdelegator.FakeBlobstoreDelegator
 		fakeSettingsService  *fakesettings.FakeSettingsService
 		fakePlatform         *platformfakes.FakePlatform
-		fakeFileSystem       *fakesys.FakeFileSystem
+		fakeFileSystem       *fakefs.FakeFileSystem
 		logger               *fakelogger.FakeLogger
 		fakeDNSRecordsString string
 	)
@@ -36,7 +36,7 @@ var _ = Describe("SyncDNS", func() {
 		fakeBlobstore = &fakeblobdelegator.FakeBlobstoreDelegator{}
 		fakeSettingsService = &fakesettings.FakeSettingsService{}
 		fakePlatform = &platformfakes.FakePlatform{}
-		fakeFileSystem = fakesys.NewFakeFileSystem()
+		fakeFileSystem = fakefs.NewFakeFileSystem()
 		fakePlatform.GetFsReturns(fakeFileSystem)
 
 		action = NewSyncDNS(fakeBlobstore, fakeSettingsService, fakePlatform, logger)
@@ -8,12 +8,12 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	fakefs "github.com/cloudfoundry/bosh-agent/platform/fakefs"
 	"github.com/cloudfoundry/bosh-agent/platform/platformfakes"
 
 	fakeblobdelegator "github.com/cloudfoundry/bosh-agent/agent/httpblobprovider/blobstore_delegator/blobstore_delegatorfakes"
 	fakesettings "github.com/cloudfoundry/bosh-agent/settings/fakes"
 	fakelogger "github.com/cloudfoundry/bosh-utils/logger/loggerfakes"
-	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
 
 	boshsettings "github.com/cloudfoundry/bosh-agent/settings"
 	boshcrypto "github.com/cloudfoundry/bosh-utils/crypto"
@@ -24,7 +24,7 @@ var _ = Describe("SyncDNSWithSignedURL", func() {
 		action               SyncDNSWithSignedURL
 		fakeSettingsService  *fakesettings.FakeSettingsService
 		fakePlatform         *platformfakes.FakePlatform
-		fakeFileSystem       *fakesys.FakeFileSystem
+		fakeFileSystem       *fakefs.FakeFileSystem
 		logger               *fakelogger.FakeLogger
 		fakeDNSRecordsString string
 		blobDelegator        *fakeblobdelegator.FakeBlobstoreDelegator
@@ -35,7 +35,7 @@ var _ = Describe("SyncDNSWithSignedURL", func() {
 		blobDelegator = &fakeblobdelegator.FakeBlobstoreDelegator{}
 		fakeSettingsService = &fakesettings.FakeSettingsService{}
 		fakePlatform = &platformfakes.FakePlatform{}
-		fakeFileSystem = fakesys.NewFakeFileSystem()
+		fakeFileSystem = fakefs.NewFakeFileSystem()
 		fakePlatform.GetFsReturns(fakeFileSystem)
 
 		action = NewSyncDNSWithSignedURL(fakeSettingsService, fakePlatform, logger, blobDelegator)
@@ -0,0 +1,121 @@
+package action
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	boshplatform "github.com/cloudfoundry/bosh-agent/platform"
+	boshdirs "github.com/cloudfoundry/bosh-agent/settings/directories"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+)
+
+// Matches both "/: 1.2 GiB (1288490188 bytes) trimmed" and "/: 4096 bytes were trimmed"
+var fstrimTrimmedBytesRegexp = regexp.MustCompile(`(\d+) bytes`)
+
+type TrimmedMount struct {
+	Disk         string `json:"disk"`
+	MountPoint   string `json:"mount_point"`
+	TrimmedBytes uint64 `json:"trimmed_bytes"`
+
+	// False when the device or filesystem does not support discard
+	Supported bool `json:"supported"`
+}
+
+type TrimDisksResponse struct {
+	Mounts []TrimmedMount `json:"mounts"`
+}
+
+type TrimDisksAction struct {
+	platform    boshplatform.Platform
+	dirProvider boshdirs.Provider
+}
+
+func NewTrimDisks(platform boshplatform.Platform, dirProvider boshdirs.Provider) TrimDisksAction {
+	return TrimDisksAction{
+		platform:    platform,
+		dirProvider: dirProvider,
+	}
+}
+
+// Trimming a large disk may take a while so the action runs as a task
+func (a TrimDisksAction) IsAsynchronous(_ ProtocolVersion) bool {
+	return true
+}
+
+func (a TrimDisksAction) IsPersistent() bool {
+	return false
+}
+
+func (a TrimDisksAction) IsLoggable() bool {
+	return true
+}
+
+func (a TrimDisksAction) Run() (TrimDisksResponse, error) {
+	response := TrimDisksResponse{Mounts: []TrimmedMount{}}
+
+	disks := []struct {
+		name       string
+		mountPoint string
+	}{
+		{"ephemeral", a.dirProvider.DataDir()},
+		{"persistent", a.dirProvider.StoreDir()},
+	}
+
+	for _, disk := range disks {
+		_, isMountPoint, err := a.platform.IsMountPoint(disk.mountPoint)
+		if err != nil {
+			return response, bosherr.WrapErrorf(err, "Checking if %s disk is mounted at '%s'", disk.name, disk.mountPoint)
+		}
+
+		// e.g. the VM has no persistent disk attached
+		if !isMountPoint {
+			continue
+		}
+
+		mount, err := a.trim(disk.name, disk.mountPoint)
+		if err != nil {
+			return response, err
+		}
+
+		response.Mounts = append(response.Mounts, mount)
+	}
+
+	return response, nil
+}
+
+func (a TrimDisksAction) Resume() (interface{}, error) {
+	return nil, errors.New("not supported")
+}
+
+func (a TrimDisksAction) Cancel() error {
+	return errors.New("not supported")
+}
+
+func (a TrimDisksAction) trim(diskName, mountPoint string) (TrimmedMount, error) {
+	mount := TrimmedMount{Disk: diskName, MountPoint: mountPoint}
+
+	stdout, stderr, _, err := a.platform.GetRunner().RunCommand("fstrim", "-v", mountPoint)
+	if err != nil {
+		if strings.Contains(stderr, "not supported") || strings.Contains(err.Error(), "not supported") {
+			return mount, nil
+		}
+
+		return mount, bosherr.WrapErrorf(err, "Trimming %s disk mounted at '%s'", diskName, mountPoint)
+	}
+
+	mount.Supported = true
+
+	matches := fstrimTrimmedBytesRegexp.FindStringSubmatch(stdout)
+	if matches == nil {
+		return mount, bosherr.Errorf("Parsing trimmed bytes from fstrim output '%s'", strings.TrimSpace(stdout))
+	}
+
+	mount.TrimmedBytes, err = strconv.ParseUint(matches[1], 10, 64)
+	if err != nil {
+		return mount, bosherr.WrapErrorf(err, "Parsing trimmed bytes from fstrim output '%s'", strings.TrimSpace(stdout))
+	}
+
+	return mount, nil
+}
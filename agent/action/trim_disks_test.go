@@ -0,0 +1,123 @@
+package action_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-agent/agent/action"
+	"github.com/cloudfoundry/bosh-agent/platform/platformfakes"
+	boshdirs "github.com/cloudfoundry/bosh-agent/settings/directories"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+)
+
+var _ = Describe("TrimDisksAction", func() {
+	var (
+		platform  *platformfakes.FakePlatform
+		cmdRunner *fakesys.FakeCmdRunner
+		action    TrimDisksAction
+	)
+
+	BeforeEach(func() {
+		platform = &platformfakes.FakePlatform{}
+		cmdRunner = fakesys.NewFakeCmdRunner()
+		platform.GetRunnerReturns(cmdRunner)
+		platform.IsMountPointReturns("/dev/sdb2", true, nil)
+		action = NewTrimDisks(platform, boshdirs.NewProvider("/var/vcap"))
+	})
+
+	AssertActionIsAsynchronous(action)
+	AssertActionIsNotPersistent(action)
+	AssertActionIsLoggable(action)
+
+	AssertActionIsNotResumable(action)
+	AssertActionIsNotCancelable(action)
+
+	Describe("Run", func() {
+		It("trims the ephemeral and persistent disks and reports the bytes trimmed", func() {
+			cmdRunner.AddCmdResult("fstrim -v /var/vcap/data", fakesys.FakeCmdResult{
+				Stdout: "/var/vcap/data: 1.2 GiB (1288490188 bytes) trimmed\n",
+			})
+			cmdRunner.AddCmdResult("fstrim -v /var/vcap/store", fakesys.FakeCmdResult{
+				Stdout: "/var/vcap/store: 4096 bytes were trimmed\n",
+			})
+
+			response, err := action.Run()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response).To(Equal(TrimDisksResponse{
+				Mounts: []TrimmedMount{
+					{Disk: "ephemeral", MountPoint: "/var/vcap/data", TrimmedBytes: 1288490188, Supported: true},
+					{Disk: "persistent", MountPoint: "/var/vcap/store", TrimmedBytes: 4096, Supported: true},
+				},
+			}))
+
+			Expect(platform.IsMountPointArgsForCall(0)).To(Equal("/var/vcap/data"))
+			Expect(platform.IsMountPointArgsForCall(1)).To(Equal("/var/vcap/store"))
+		})
+
+		It("skips disks that are not mounted", func() {
+			platform.IsMountPointStub = func(path string) (string, bool, error) {
+				return "", path == "/var/vcap/data", nil
+			}
+			cmdRunner.AddCmdResult("fstrim -v /var/vcap/data", fakesys.FakeCmdResult{
+				Stdout: "/var/vcap/data: 0 B (0 bytes) trimmed\n",
+			})
+
+			response, err := action.Run()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Mounts).To(Equal([]TrimmedMount{
+				{Disk: "ephemeral", MountPoint: "/var/vcap/data", TrimmedBytes: 0, Supported: true},
+			}))
+			Expect(cmdRunner.RunCommands).To(Equal([][]string{{"fstrim", "-v", "/var/vcap/data"}}))
+		})
+
+		It("reports mounts that do not support discard as unsupported", func() {
+			cmdRunner.AddCmdResult("fstrim -v /var/vcap/data", fakesys.FakeCmdResult{
+				Stderr:     "fstrim: /var/vcap/data: the discard operation is not supported\n",
+				ExitStatus: 1,
+				Error:      errors.New("fake-fstrim-error"),
+			})
+			cmdRunner.AddCmdResult("fstrim -v /var/vcap/store", fakesys.FakeCmdResult{
+				Stdout: "/var/vcap/store: 4096 bytes were trimmed\n",
+			})
+
+			response, err := action.Run()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Mounts).To(Equal([]TrimmedMount{
+				{Disk: "ephemeral", MountPoint: "/var/vcap/data", Supported: false},
+				{Disk: "persistent", MountPoint: "/var/vcap/store", TrimmedBytes: 4096, Supported: true},
+			}))
+		})
+
+		It("returns an error when fstrim fails for another reason", func() {
+			cmdRunner.AddCmdResult("fstrim -v /var/vcap/data", fakesys.FakeCmdResult{
+				Stderr:     "fstrim: /var/vcap/data: FITRIM ioctl failed: Input/output error\n",
+				ExitStatus: 1,
+				Error:      errors.New("fake-fstrim-error"),
+			})
+
+			_, err := action.Run()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Trimming ephemeral disk mounted at '/var/vcap/data'"))
+			Expect(err.Error()).To(ContainSubstring("fake-fstrim-error"))
+		})
+
+		It("returns an error when the fstrim output cannot be parsed", func() {
+			cmdRunner.AddCmdResult("fstrim -v /var/vcap/data", fakesys.FakeCmdResult{Stdout: "garbage\n"})
+
+			_, err := action.Run()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Parsing trimmed bytes from fstrim output 'garbage'"))
+		})
+
+		It("returns an error when checking the mount point fails", func() {
+			platform.IsMountPointReturns("", false, errors.New("fake-mount-point-error"))
+
+			_, err := action.Run()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Checking if ephemeral disk is mounted at '/var/vcap/data'"))
+			Expect(err.Error()).To(ContainSubstring("fake-mount-point-error"))
+		})
+	})
+})
@@ -10,11 +10,11 @@ import (
 
 	. "github.com/cloudfoundry/bosh-agent/agent/action"
 	"github.com/cloudfoundry/bosh-agent/platform/cert/certfakes"
+	fakefs "github.com/cloudfoundry/bosh-agent/platform/fakefs"
 	"github.com/cloudfoundry/bosh-agent/platform/platformfakes"
 	"github.com/cloudfoundry/bosh-utils/logger"
 
 	fakesettings "github.com/cloudfoundry/bosh-agent/settings/fakes"
-	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
 
 	boshsettings "github.com/cloudfoundry/bosh-agent/settings"
 )
@@ -27,7 +27,7 @@ var _ = Describe("UpdateSettings", func() {
 		log               logger.Logger
 		platform          *platformfakes.FakePlatform
 		newUpdateSettings boshsettings.UpdateSettings
-		fileSystem        *fakesys.FakeFileSystem
+		fileSystem        *fakefs.FakeFileSystem
 	)
 
 	BeforeEach(func() {
@@ -36,7 +36,7 @@ var _ = Describe("UpdateSettings", func() {
 		settingsService = &fakesettings.FakeSettingsService{}
 
 		platform = &platformfakes.FakePlatform{}
-		fileSystem = fakesys.NewFakeFileSystem()
+		fileSystem = fakefs.NewFakeFileSystem()
 		platform.GetFsReturns(fileSystem)
 
 		action = NewUpdateSettings(settingsService, platform, certManager, log)
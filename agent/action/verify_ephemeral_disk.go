@@ -0,0 +1,149 @@
+package action
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	boshplatform "github.com/cloudfoundry/bosh-agent/platform"
+	boshsettings "github.com/cloudfoundry/bosh-agent/settings"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+const blockDeviceSectorSizeInBytes = 512
+
+type VerifyEphemeralDiskArgs struct {
+	MinSizeInBytes uint64 `json:"min_size_in_bytes"`
+	MaxSizeInBytes uint64 `json:"max_size_in_bytes"`
+}
+
+type VerifyEphemeralDiskResponse struct {
+	DevicePath  string   `json:"device_path"`
+	SizeInBytes uint64   `json:"size_in_bytes"`
+	IsRootDisk  bool     `json:"is_root_disk"`
+	IsMounted   bool     `json:"is_mounted"`
+	Passed      bool     `json:"passed"`
+	Failures    []string `json:"failures"`
+}
+
+type VerifyEphemeralDiskAction struct {
+	settingsService boshsettings.Service
+	platform        boshplatform.Platform
+	fs              boshsys.FileSystem
+}
+
+func NewVerifyEphemeralDisk(
+	settingsService boshsettings.Service,
+	platform boshplatform.Platform,
+	fs boshsys.FileSystem,
+) VerifyEphemeralDiskAction {
+	return VerifyEphemeralDiskAction{
+		settingsService: settingsService,
+		platform:        platform,
+		fs:              fs,
+	}
+}
+
+func (a VerifyEphemeralDiskAction) IsAsynchronous(_ ProtocolVersion) bool {
+	return false
+}
+
+func (a VerifyEphemeralDiskAction) IsPersistent() bool {
+	return false
+}
+
+func (a VerifyEphemeralDiskAction) IsLoggable() bool {
+	return true
+}
+
+func (a VerifyEphemeralDiskAction) Run(args ...VerifyEphemeralDiskArgs) (VerifyEphemeralDiskResponse, error) {
+	var expected VerifyEphemeralDiskArgs
+	if len(args) > 0 {
+		expected = args[0]
+	}
+
+	response := VerifyEphemeralDiskResponse{Failures: []string{}}
+
+	diskSettings := a.settingsService.GetSettings().EphemeralDiskSettings()
+
+	response.DevicePath = a.platform.GetEphemeralDiskPath(diskSettings)
+	if response.DevicePath == "" {
+		response.Failures = append(response.Failures, "Ephemeral disk device could not be resolved")
+		return response, nil
+	}
+
+	sizeInBytes, err := a.deviceSizeInBytes(response.DevicePath)
+	if err != nil {
+		return response, bosherr.WrapErrorf(err, "Getting size of '%s'", response.DevicePath)
+	}
+	response.SizeInBytes = sizeInBytes
+
+	if expected.MinSizeInBytes > 0 && sizeInBytes < expected.MinSizeInBytes {
+		response.Failures = append(response.Failures, fmt.Sprintf("Device size %d is smaller than expected minimum %d", sizeInBytes, expected.MinSizeInBytes))
+	}
+
+	if expected.MaxSizeInBytes > 0 && sizeInBytes > expected.MaxSizeInBytes {
+		response.Failures = append(response.Failures, fmt.Sprintf("Device size %d is larger than expected maximum %d", sizeInBytes, expected.MaxSizeInBytes))
+	}
+
+	mountInfo, err := a.fs.ReadFileString("/proc/mounts")
+	if err != nil {
+		return response, bosherr.WrapError(err, "Reading /proc/mounts")
+	}
+
+	devicePattern := regexp.MustCompile("^" + regexp.QuoteMeta(response.DevicePath) + `p?\d*$`)
+
+	for _, mountEntry := range strings.Split(mountInfo, "\n") {
+		mountFields := strings.Fields(mountEntry)
+		if len(mountFields) < 2 || !devicePattern.MatchString(mountFields[0]) {
+			continue
+		}
+
+		response.IsMounted = true
+		if mountFields[1] == "/" {
+			response.IsRootDisk = true
+		}
+	}
+
+	if response.IsRootDisk {
+		response.Failures = append(response.Failures, "Device is the root disk")
+	}
+
+	if response.IsMounted {
+		response.Failures = append(response.Failures, "Device is currently mounted")
+	}
+
+	response.Passed = len(response.Failures) == 0
+
+	return response, nil
+}
+
+// Block devices report their size in 512-byte sectors regardless of the
+// device's logical block size
+func (a VerifyEphemeralDiskAction) deviceSizeInBytes(devicePath string) (uint64, error) {
+	sizePath := filepath.Join("/sys/class/block", filepath.Base(devicePath), "size")
+
+	sectors, err := a.fs.ReadFileString(sizePath)
+	if err != nil {
+		return 0, bosherr.WrapErrorf(err, "Reading '%s'", sizePath)
+	}
+
+	sectorCount, err := strconv.ParseUint(strings.TrimSpace(sectors), 10, 64)
+	if err != nil {
+		return 0, bosherr.WrapErrorf(err, "Parsing sector count '%s'", sectors)
+	}
+
+	return sectorCount * blockDeviceSectorSizeInBytes, nil
+}
+
+func (a VerifyEphemeralDiskAction) Resume() (interface{}, error) {
+	return nil, errors.New("not supported")
+}
+
+func (a VerifyEphemeralDiskAction) Cancel() error {
+	return errors.New("not supported")
+}
@@ -0,0 +1,138 @@
+package action_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-agent/agent/action"
+	fakefs "github.com/cloudfoundry/bosh-agent/platform/fakefs"
+	"github.com/cloudfoundry/bosh-agent/platform/platformfakes"
+	boshsettings "github.com/cloudfoundry/bosh-agent/settings"
+	fakesettings "github.com/cloudfoundry/bosh-agent/settings/fakes"
+)
+
+var _ = Describe("VerifyEphemeralDiskAction", func() {
+	var (
+		settingsService *fakesettings.FakeSettingsService
+		platform        *platformfakes.FakePlatform
+		fs              *fakefs.FakeFileSystem
+		action          VerifyEphemeralDiskAction
+	)
+
+	BeforeEach(func() {
+		settingsService = &fakesettings.FakeSettingsService{}
+		settingsService.Settings.Disks.Ephemeral = "/dev/xvdb"
+		platform = &platformfakes.FakePlatform{}
+		platform.GetEphemeralDiskPathReturns("/dev/sdb")
+		fs = fakefs.NewFakeFileSystem()
+		action = NewVerifyEphemeralDisk(settingsService, platform, fs)
+
+		// 20 GiB in 512-byte sectors
+		err := fs.WriteFileString("/sys/class/block/sdb/size", "41943040\n")
+		Expect(err).ToNot(HaveOccurred())
+
+		err = fs.WriteFileString("/proc/mounts", `/dev/sda1 / ext4 rw,relatime 0 0
+proc /proc proc rw,nosuid,nodev,noexec,relatime 0 0
+`)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AssertActionIsNotAsynchronous(action)
+	AssertActionIsNotPersistent(action)
+	AssertActionIsLoggable(action)
+
+	AssertActionIsNotResumable(action)
+	AssertActionIsNotCancelable(action)
+
+	Describe("Run", func() {
+		It("resolves the device from the ephemeral disk settings", func() {
+			_, err := action.Run()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(platform.GetEphemeralDiskPathCallCount()).To(Equal(1))
+			Expect(platform.GetEphemeralDiskPathArgsForCall(0)).To(Equal(boshsettings.DiskSettings{Path: "/dev/xvdb", VolumeID: "/dev/xvdb"}))
+		})
+
+		It("passes for an unmounted non-root device within the expected size range", func() {
+			response, err := action.Run(VerifyEphemeralDiskArgs{
+				MinSizeInBytes: 10 * 1024 * 1024 * 1024,
+				MaxSizeInBytes: 30 * 1024 * 1024 * 1024,
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response).To(Equal(VerifyEphemeralDiskResponse{
+				DevicePath:  "/dev/sdb",
+				SizeInBytes: 20 * 1024 * 1024 * 1024,
+				Passed:      true,
+				Failures:    []string{},
+			}))
+		})
+
+		It("fails when the resolved device is the root disk", func() {
+			platform.GetEphemeralDiskPathReturns("/dev/sda")
+			err := fs.WriteFileString("/sys/class/block/sda/size", "41943040\n")
+			Expect(err).ToNot(HaveOccurred())
+
+			response, err := action.Run()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Passed).To(BeFalse())
+			Expect(response.IsRootDisk).To(BeTrue())
+			Expect(response.Failures).To(ContainElement("Device is the root disk"))
+		})
+
+		It("fails when a partition of the device is mounted", func() {
+			err := fs.WriteFileString("/proc/mounts", `/dev/sda1 / ext4 rw,relatime 0 0
+/dev/sdb2 /var/vcap/data ext4 rw,relatime 0 0
+`)
+			Expect(err).ToNot(HaveOccurred())
+
+			response, err := action.Run()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Passed).To(BeFalse())
+			Expect(response.IsMounted).To(BeTrue())
+			Expect(response.IsRootDisk).To(BeFalse())
+			Expect(response.Failures).To(Equal([]string{"Device is currently mounted"}))
+		})
+
+		It("does not treat devices that only share a name prefix as mounted", func() {
+			err := fs.WriteFileString("/proc/mounts", `/dev/sda1 / ext4 rw,relatime 0 0
+/dev/sdba1 /mnt ext4 rw,relatime 0 0
+`)
+			Expect(err).ToNot(HaveOccurred())
+
+			response, err := action.Run()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.IsMounted).To(BeFalse())
+			Expect(response.Passed).To(BeTrue())
+		})
+
+		It("fails when the device is outside the expected size range", func() {
+			response, err := action.Run(VerifyEphemeralDiskArgs{MinSizeInBytes: 50 * 1024 * 1024 * 1024})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Passed).To(BeFalse())
+			Expect(response.Failures).To(Equal([]string{"Device size 21474836480 is smaller than expected minimum 53687091200"}))
+
+			response, err = action.Run(VerifyEphemeralDiskArgs{MaxSizeInBytes: 1024})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Passed).To(BeFalse())
+			Expect(response.Failures).To(Equal([]string{"Device size 21474836480 is larger than expected maximum 1024"}))
+		})
+
+		It("fails when the ephemeral device cannot be resolved", func() {
+			platform.GetEphemeralDiskPathReturns("")
+
+			response, err := action.Run()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Passed).To(BeFalse())
+			Expect(response.Failures).To(Equal([]string{"Ephemeral disk device could not be resolved"}))
+		})
+
+		It("returns an error when the device size cannot be read", func() {
+			err := fs.RemoveAll("/sys/class/block/sdb/size")
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = action.Run()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Getting size of '/dev/sdb'"))
+		})
+	})
+})
@@ -0,0 +1,89 @@
+package action
+
+import (
+	"errors"
+
+	boshappl "github.com/cloudfoundry/bosh-agent/agent/applier"
+	boshas "github.com/cloudfoundry/bosh-agent/agent/applier/applyspec"
+	boshpackages "github.com/cloudfoundry/bosh-agent/agent/applier/packages"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+)
+
+type VerifyJobPackagesOptions struct {
+	VerifyDigests bool `json:"verify_digests"`
+}
+
+type VerifyJobPackagesResponse struct {
+	// True only when every package is present and, if requested, matches its digest
+	OK       bool               `json:"ok"`
+	Packages []JobPackageStatus `json:"packages"`
+}
+
+type JobPackageStatus struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Status  string `json:"status"`
+}
+
+type VerifyJobPackagesAction struct {
+	applier     boshappl.Applier
+	specService boshas.V1Service
+}
+
+func NewVerifyJobPackages(applier boshappl.Applier, specService boshas.V1Service) VerifyJobPackagesAction {
+	return VerifyJobPackagesAction{
+		applier:     applier,
+		specService: specService,
+	}
+}
+
+func (a VerifyJobPackagesAction) IsAsynchronous(_ ProtocolVersion) bool {
+	return true
+}
+
+func (a VerifyJobPackagesAction) IsPersistent() bool {
+	return false
+}
+
+func (a VerifyJobPackagesAction) IsLoggable() bool {
+	return true
+}
+
+func (a VerifyJobPackagesAction) Run(options VerifyJobPackagesOptions) (VerifyJobPackagesResponse, error) {
+	currentSpec, err := a.specService.Get()
+	if err != nil {
+		return VerifyJobPackagesResponse{}, bosherr.WrapError(err, "Getting current spec")
+	}
+
+	verifications, err := a.applier.VerifyPackages(currentSpec, options.VerifyDigests)
+	if err != nil {
+		return VerifyJobPackagesResponse{}, bosherr.WrapError(err, "Verifying job packages")
+	}
+
+	response := VerifyJobPackagesResponse{
+		OK:       true,
+		Packages: []JobPackageStatus{},
+	}
+
+	for _, verification := range verifications {
+		if verification.Status != boshpackages.VerificationStatusOK {
+			response.OK = false
+		}
+
+		response.Packages = append(response.Packages, JobPackageStatus{
+			Name:    verification.Name,
+			Version: verification.Version,
+			Status:  string(verification.Status),
+		})
+	}
+
+	return response, nil
+}
+
+func (a VerifyJobPackagesAction) Resume() (interface{}, error) {
+	return nil, errors.New("not supported")
+}
+
+func (a VerifyJobPackagesAction) Cancel() error {
+	return errors.New("not supported")
+}
@@ -0,0 +1,108 @@
+package action_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-agent/agent/action"
+	boshappl "github.com/cloudfoundry/bosh-agent/agent/applier"
+	boshas "github.com/cloudfoundry/bosh-agent/agent/applier/applyspec"
+	fakeas "github.com/cloudfoundry/bosh-agent/agent/applier/applyspec/fakes"
+	fakeappl "github.com/cloudfoundry/bosh-agent/agent/applier/fakes"
+	boshpackages "github.com/cloudfoundry/bosh-agent/agent/applier/packages"
+)
+
+var _ = Describe("VerifyJobPackages", func() {
+	var (
+		applier     *fakeappl.FakeApplier
+		specService *fakeas.FakeV1Service
+		action      VerifyJobPackagesAction
+	)
+
+	BeforeEach(func() {
+		applier = fakeappl.NewFakeApplier()
+		specService = fakeas.NewFakeV1Service()
+		action = NewVerifyJobPackages(applier, specService)
+	})
+
+	AssertActionIsAsynchronous(action)
+	AssertActionIsNotPersistent(action)
+	AssertActionIsLoggable(action)
+
+	AssertActionIsNotResumable(action)
+	AssertActionIsNotCancelable(action)
+
+	It("verifies packages of the current spec", func() {
+		currentSpec := boshas.V1ApplySpec{Deployment: "fake-deployment"}
+		specService.Spec = currentSpec
+
+		_, err := action.Run(VerifyJobPackagesOptions{VerifyDigests: true})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(applier.VerifyPackagesDesiredApplySpec).To(Equal(currentSpec))
+		Expect(applier.VerifyPackagesVerifyDigests).To(BeTrue())
+	})
+
+	It("reports all packages as ok when they are present and intact", func() {
+		applier.VerifyPackagesVerifications = []boshappl.PackageVerification{
+			{Name: "pkg-a", Version: "v1", Status: boshpackages.VerificationStatusOK},
+			{Name: "pkg-b", Version: "v2", Status: boshpackages.VerificationStatusOK},
+		}
+
+		response, err := action.Run(VerifyJobPackagesOptions{VerifyDigests: true})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response).To(Equal(VerifyJobPackagesResponse{
+			OK: true,
+			Packages: []JobPackageStatus{
+				{Name: "pkg-a", Version: "v1", Status: "ok"},
+				{Name: "pkg-b", Version: "v2", Status: "ok"},
+			},
+		}))
+	})
+
+	It("reports a missing package", func() {
+		applier.VerifyPackagesVerifications = []boshappl.PackageVerification{
+			{Name: "pkg-a", Version: "v1", Status: boshpackages.VerificationStatusOK},
+			{Name: "pkg-b", Version: "v2", Status: boshpackages.VerificationStatusMissing},
+		}
+
+		response, err := action.Run(VerifyJobPackagesOptions{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.OK).To(BeFalse())
+		Expect(response.Packages).To(ContainElement(JobPackageStatus{Name: "pkg-b", Version: "v2", Status: "missing"}))
+	})
+
+	It("reports a corrupted package", func() {
+		applier.VerifyPackagesVerifications = []boshappl.PackageVerification{
+			{Name: "pkg-a", Version: "v1", Status: boshpackages.VerificationStatusCorrupted},
+		}
+
+		response, err := action.Run(VerifyJobPackagesOptions{VerifyDigests: true})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.OK).To(BeFalse())
+		Expect(response.Packages).To(Equal([]JobPackageStatus{{Name: "pkg-a", Version: "v1", Status: "corrupted"}}))
+	})
+
+	It("returns an empty package list when the spec has no packages", func() {
+		response, err := action.Run(VerifyJobPackagesOptions{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response).To(Equal(VerifyJobPackagesResponse{OK: true, Packages: []JobPackageStatus{}}))
+	})
+
+	It("returns error when getting the current spec fails", func() {
+		specService.GetErr = errors.New("fake-spec-get-error")
+
+		_, err := action.Run(VerifyJobPackagesOptions{})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("fake-spec-get-error"))
+	})
+
+	It("returns error when verifying packages fails", func() {
+		applier.VerifyPackagesError = errors.New("fake-verify-error")
+
+		_, err := action.Run(VerifyJobPackagesOptions{})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("fake-verify-error"))
+	})
+})
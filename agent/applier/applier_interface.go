@@ -2,10 +2,18 @@ package applier
 
 import (
 	boshas "github.com/cloudfoundry/bosh-agent/agent/applier/applyspec"
+	"github.com/cloudfoundry/bosh-agent/agent/applier/packages"
 )
 
+type PackageVerification struct {
+	Name    string
+	Version string
+	Status  packages.VerificationStatus
+}
+
 type Applier interface {
 	Prepare(desiredApplySpec boshas.ApplySpec) error
 	ConfigureJobs(desiredApplySpec boshas.ApplySpec) error
 	Apply(desiredApplySpec boshas.ApplySpec) error
+	VerifyPackages(desiredApplySpec boshas.ApplySpec, verifyDigests bool) ([]PackageVerification, error)
 }
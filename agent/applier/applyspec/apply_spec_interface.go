@@ -8,4 +8,6 @@ type ApplySpec interface {
 	Jobs() []models.Job
 	Packages() []models.Package
 	MaxLogFileSize() string
+	MaxLogFiles() int
+	JobMaxLogFiles() map[string]int
 }
@@ -8,6 +8,8 @@ type FakeApplySpec struct {
 	JobResults           []models.Job
 	PackageResults       []models.Package
 	MaxLogFileSizeResult string
+	MaxLogFilesResult    int
+	JobMaxLogFilesResult map[string]int
 }
 
 func (s FakeApplySpec) Jobs() []models.Job {
@@ -21,3 +23,11 @@ func (s FakeApplySpec) Packages() []models.Package {
 func (s FakeApplySpec) MaxLogFileSize() string {
 	return s.MaxLogFileSizeResult
 }
+
+func (s FakeApplySpec) MaxLogFiles() int {
+	return s.MaxLogFilesResult
+}
+
+func (s FakeApplySpec) JobMaxLogFiles() map[string]int {
+	return s.JobMaxLogFilesResult
+}
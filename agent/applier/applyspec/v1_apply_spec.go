@@ -34,6 +34,10 @@ type PropertiesSpec struct {
 
 type LoggingSpec struct {
 	MaxLogFileSize string `json:"max_log_file_size"`
+
+	// Number of rotated log files kept, overridable per job by job name
+	MaxLogFiles    int            `json:"max_log_files"`
+	JobMaxLogFiles map[string]int `json:"job_max_log_files"`
 }
 
 const (
@@ -83,6 +87,26 @@ func (s V1ApplySpec) MaxLogFileSize() string {
 	return "50M"
 }
 
+func (s V1ApplySpec) MaxLogFiles() int {
+	maxLogFiles := s.PropertiesSpec.LoggingSpec.MaxLogFiles
+	if maxLogFiles > 0 {
+		return maxLogFiles
+	}
+	return 7
+}
+
+func (s V1ApplySpec) JobMaxLogFiles() map[string]int {
+	jobMaxLogFiles := map[string]int{}
+
+	for jobName, maxLogFiles := range s.PropertiesSpec.LoggingSpec.JobMaxLogFiles {
+		if maxLogFiles > 0 {
+			jobMaxLogFiles[jobName] = maxLogFiles
+		}
+	}
+
+	return jobMaxLogFiles
+}
+
 func (s NetworkSpec) PopulateIPInfo(ip, netmask, gateway string) NetworkSpec {
 	if s.Fields == nil {
 		s.Fields = map[string]interface{}{}
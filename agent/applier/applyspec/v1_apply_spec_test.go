@@ -316,6 +316,32 @@ var _ = Describe("V1ApplySpec", func() {
 			Expect(spec.MaxLogFileSize()).To(Equal("fake-size"))
 		})
 	})
+
+	Describe("MaxLogFiles", func() {
+		It("returns 7 if the number of files is not provided", func() {
+			spec := V1ApplySpec{}
+			Expect(spec.MaxLogFiles()).To(Equal(7))
+		})
+
+		It("returns provided number of files", func() {
+			spec := V1ApplySpec{}
+			spec.PropertiesSpec.LoggingSpec.MaxLogFiles = 3
+			Expect(spec.MaxLogFiles()).To(Equal(3))
+		})
+	})
+
+	Describe("JobMaxLogFiles", func() {
+		It("returns no overrides if none are provided", func() {
+			spec := V1ApplySpec{}
+			Expect(spec.JobMaxLogFiles()).To(Equal(map[string]int{}))
+		})
+
+		It("returns provided overrides ignoring values that are not positive", func() {
+			spec := V1ApplySpec{}
+			spec.PropertiesSpec.LoggingSpec.JobMaxLogFiles = map[string]int{"verbose-job": 2, "other-job": 0}
+			Expect(spec.JobMaxLogFiles()).To(Equal(map[string]int{"verbose-job": 2}))
+		})
+	})
 })
 
 var _ = Describe("NetworkSpec", func() {
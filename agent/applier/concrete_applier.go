@@ -1,8 +1,12 @@
 package applier
 
 import (
+	"sort"
+	"sync"
+
 	as "github.com/cloudfoundry/bosh-agent/agent/applier/applyspec"
 	"github.com/cloudfoundry/bosh-agent/agent/applier/jobs"
+	"github.com/cloudfoundry/bosh-agent/agent/applier/models"
 	"github.com/cloudfoundry/bosh-agent/agent/applier/packages"
 	boshjobsuper "github.com/cloudfoundry/bosh-agent/jobsupervisor"
 	boshsettings "github.com/cloudfoundry/bosh-agent/settings"
@@ -18,6 +22,10 @@ type concreteApplier struct {
 	jobSupervisor     boshjobsuper.JobSupervisor
 	dirProvider       boshdirs.Provider
 	settings          boshsettings.Settings
+
+	// Jobs prepared since the last apply, which does not need to prepare them again
+	preparedJobs     map[string]bool
+	preparedJobsLock sync.Mutex
 }
 
 func NewConcreteApplier(
@@ -35,6 +43,7 @@ func NewConcreteApplier(
 		jobSupervisor:     jobSupervisor,
 		dirProvider:       dirProvider,
 		settings:          settings,
+		preparedJobs:      map[string]bool{},
 	}
 }
 
@@ -51,6 +60,11 @@ func (a *concreteApplier) Prepare(desiredApplySpec as.ApplySpec) error {
 			if jobErr != nil {
 				return bosherr.WrapErrorf(jobErr, "Preparing job %s", job.Name)
 			}
+
+			a.preparedJobsLock.Lock()
+			a.preparedJobs[preparedJobKey(job)] = true
+			a.preparedJobsLock.Unlock()
+
 			return nil
 		})
 	}
@@ -86,6 +100,12 @@ func (a *concreteApplier) Apply(desiredApplySpec as.ApplySpec) error {
 	}
 
 	jobs := desiredApplySpec.Jobs()
+
+	err = a.prepareJobs(jobs)
+	if err != nil {
+		return err
+	}
+
 	for _, job := range jobs {
 		err = a.jobApplier.Apply(job)
 		if err != nil {
@@ -103,11 +123,9 @@ func (a *concreteApplier) Apply(desiredApplySpec as.ApplySpec) error {
 		return bosherr.WrapError(err, "Keeping only needed jobs")
 	}
 
-	for _, pkg := range desiredApplySpec.Packages() {
-		err = a.packageApplier.Apply(pkg)
-		if err != nil {
-			return bosherr.WrapErrorf(err, "Applying package %s", pkg.Name)
-		}
+	err = a.applyPackages(desiredApplySpec.Packages())
+	if err != nil {
+		return err
 	}
 
 	err = a.packageApplier.KeepOnly(desiredApplySpec.Packages())
@@ -123,6 +141,68 @@ func (a *concreteApplier) Apply(desiredApplySpec as.ApplySpec) error {
 	return a.setUpLogrotate(desiredApplySpec)
 }
 
+// prepareJobs downloads the rendered templates archives of the jobs that were
+// not prepared before, up to apply_jobs_parallel at once, so that applying them
+// one after another only enables them
+func (a *concreteApplier) prepareJobs(jobs []models.Job) error {
+	a.preparedJobsLock.Lock()
+	preparedJobs := a.preparedJobs
+	a.preparedJobs = map[string]bool{}
+	a.preparedJobsLock.Unlock()
+
+	var tasks []func() error
+	pool := work.Pool{
+		Count: *a.settings.Env.GetApplyJobsParallel(),
+	}
+
+	for _, job := range jobs {
+		if preparedJobs[preparedJobKey(job)] {
+			continue
+		}
+
+		job := job
+		tasks = append(tasks, func() error {
+			jobErr := a.jobApplier.Prepare(job)
+			if jobErr != nil {
+				return bosherr.WrapErrorf(jobErr, "Preparing job %s", job.Name)
+			}
+			return nil
+		})
+	}
+
+	return pool.ParallelDo(tasks...)
+}
+
+func preparedJobKey(job models.Job) string {
+	key := job.Name + "/" + job.Version
+
+	if job.Source.Sha1 != nil {
+		key += "/" + job.Source.Sha1.String()
+	}
+
+	return key
+}
+
+func (a *concreteApplier) applyPackages(pkgs []models.Package) error {
+	var tasks []func() error
+	pool := work.Pool{
+		Count: *a.settings.Env.GetApplyParallel(),
+	}
+
+	for _, pkg := range pkgs {
+		pkg := pkg
+		tasks = append(tasks, func() error {
+			pkgErr := a.packageApplier.Apply(pkg)
+			if pkgErr != nil {
+				return bosherr.WrapErrorf(pkgErr, "Applying package %s", pkg.Name)
+			}
+			return nil
+		})
+	}
+
+	return pool.ParallelDo(tasks...)
+}
+
 func (a *concreteApplier) ConfigureJobs(desiredApplySpec as.ApplySpec) error {
 	jobs := desiredApplySpec.Jobs()
 	for i := 0; i < len(jobs); i++ {
@@ -142,11 +222,35 @@ func (a *concreteApplier) ConfigureJobs(desiredApplySpec as.ApplySpec) error {
 	return nil
 }
 
+func (a *concreteApplier) VerifyPackages(desiredApplySpec as.ApplySpec, verifyDigests bool) ([]PackageVerification, error) {
+	pkgs := desiredApplySpec.Packages()
+	sort.Slice(pkgs, func(i, j int) bool { return pkgs[i].Name < pkgs[j].Name })
+
+	verifications := []PackageVerification{}
+
+	for _, pkg := range pkgs {
+		status, err := a.packageApplier.Verify(pkg, verifyDigests)
+		if err != nil {
+			return nil, bosherr.WrapErrorf(err, "Verifying package %s", pkg.Name)
+		}
+
+		verifications = append(verifications, PackageVerification{
+			Name:    pkg.Name,
+			Version: pkg.Version,
+			Status:  status,
+		})
+	}
+
+	return verifications, nil
+}
+
 func (a *concreteApplier) setUpLogrotate(applySpec as.ApplySpec) error {
 	err := a.logrotateDelegate.SetupLogrotate(
 		boshsettings.VCAPUsername,
 		a.dirProvider.BaseDir(),
 		applySpec.MaxLogFileSize(),
+		applySpec.MaxLogFiles(),
+		applySpec.JobMaxLogFiles(),
 	)
 	if err != nil {
 		return bosherr.WrapError(err, "Logrotate setup failed")
@@ -3,6 +3,8 @@ package applier_test
 import (
 	"errors"
 	"path/filepath"
+	"sync"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -13,6 +15,7 @@ import (
 	fakeas "github.com/cloudfoundry/bosh-agent/agent/applier/applyspec/fakes"
 	fakejobs "github.com/cloudfoundry/bosh-agent/agent/applier/jobs/jobsfakes"
 	models "github.com/cloudfoundry/bosh-agent/agent/applier/models"
+	boshpackages "github.com/cloudfoundry/bosh-agent/agent/applier/packages"
 	fakepackages "github.com/cloudfoundry/bosh-agent/agent/applier/packages/fakes"
 	fakejobsuper "github.com/cloudfoundry/bosh-agent/jobsupervisor/fakes"
 	boshsettings "github.com/cloudfoundry/bosh-agent/settings"
@@ -27,13 +30,15 @@ type FakeLogRotateDelegate struct {
 }
 
 type SetupLogrotateArgs struct {
-	GroupName string
-	BasePath  string
-	Size      string
+	GroupName      string
+	BasePath       string
+	Size           string
+	MaxLogFiles    int
+	JobMaxLogFiles map[string]int
 }
 
-func (d *FakeLogRotateDelegate) SetupLogrotate(groupName, basePath, size string) error {
-	d.SetupLogrotateArgs = SetupLogrotateArgs{groupName, basePath, size}
+func (d *FakeLogRotateDelegate) SetupLogrotate(groupName, basePath, size string, maxLogFiles int, jobMaxLogFiles map[string]int) error {
+	d.SetupLogrotateArgs = SetupLogrotateArgs{groupName, basePath, size, maxLogFiles, jobMaxLogFiles}
 	return d.SetupLogrotateErr
 }
 
@@ -254,6 +259,226 @@ var _ = Describe("concreteApplier", func() {
 			Expect(packageApplier.AppliedPackages).To(Equal([]models.Package{pkg1, pkg2}))
 		})
 
+		Context("when apply_parallel is configured", func() {
+			var (
+				pkgs          []models.Package
+				inFlight      int
+				maxInFlight   int
+				inFlightMutex sync.Mutex
+			)
+
+			BeforeEach(func() {
+				pkgs = []models.Package{buildPackage(), buildPackage(), buildPackage(), buildPackage(), buildPackage()}
+				inFlight = 0
+				maxInFlight = 0
+
+				packageApplier.ApplyStub = func(models.Package) error {
+					inFlightMutex.Lock()
+					inFlight++
+					if inFlight > maxInFlight {
+						maxInFlight = inFlight
+					}
+					inFlightMutex.Unlock()
+
+					time.Sleep(10 * time.Millisecond)
+
+					inFlightMutex.Lock()
+					inFlight--
+					inFlightMutex.Unlock()
+					return nil
+				}
+			})
+
+			buildApplier := func(applyParallel *int) Applier {
+				settings := boshsettings.Settings{}
+				settings.Env.Bosh.ApplyParallel = applyParallel
+
+				return NewConcreteApplier(
+					jobApplier,
+					packageApplier,
+					logRotateDelegate,
+					jobSupervisor,
+					boshdirs.NewProvider("/fake-base-dir"),
+					settings,
+				)
+			}
+
+			It("applies packages sequentially by default", func() {
+				err := buildApplier(nil).Apply(&fakeas.FakeApplySpec{PackageResults: pkgs})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(packageApplier.AppliedPackages).To(Equal(pkgs))
+				Expect(maxInFlight).To(Equal(1))
+			})
+
+			It("applies the same packages as sequential application", func() {
+				applyParallel := 3
+				err := buildApplier(&applyParallel).Apply(&fakeas.FakeApplySpec{PackageResults: pkgs})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(packageApplier.AppliedPackages).To(ConsistOf(pkgs))
+				Expect(packageApplier.KeptOnlyPackages).To(Equal(pkgs))
+			})
+
+			It("does not apply more packages at once than configured", func() {
+				applyParallel := 2
+				err := buildApplier(&applyParallel).Apply(&fakeas.FakeApplySpec{PackageResults: pkgs})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(maxInFlight).To(BeNumerically("<=", 2))
+			})
+
+			It("returns errors from packages applied in parallel", func() {
+				packageApplier.ApplyStub = func(pkg models.Package) error {
+					if pkg.Name == pkgs[1].Name {
+						return errors.New("fake-apply-package-error")
+					}
+					return nil
+				}
+
+				applyParallel := 3
+				err := buildApplier(&applyParallel).Apply(&fakeas.FakeApplySpec{PackageResults: pkgs})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-apply-package-error"))
+				Expect(err.Error()).To(ContainSubstring(pkgs[1].Name))
+				Expect(packageApplier.KeptOnlyPackages).To(BeNil())
+			})
+		})
+
+		Context("when apply_jobs_parallel is configured", func() {
+			var (
+				jobs          []models.Job
+				inFlight      int
+				maxInFlight   int
+				inFlightMutex sync.Mutex
+			)
+
+			BeforeEach(func() {
+				jobs = []models.Job{buildJob(), buildJob(), buildJob(), buildJob(), buildJob()}
+				inFlight = 0
+				maxInFlight = 0
+
+				jobApplier.PrepareStub = func(models.Job) error {
+					inFlightMutex.Lock()
+					inFlight++
+					if inFlight > maxInFlight {
+						maxInFlight = inFlight
+					}
+					inFlightMutex.Unlock()
+
+					time.Sleep(10 * time.Millisecond)
+
+					inFlightMutex.Lock()
+					inFlight--
+					inFlightMutex.Unlock()
+					return nil
+				}
+			})
+
+			buildApplier := func(applyJobsParallel *int) Applier {
+				settings := boshsettings.Settings{}
+				settings.Env.Bosh.ApplyJobsParallel = applyJobsParallel
+
+				return NewConcreteApplier(
+					jobApplier,
+					packageApplier,
+					logRotateDelegate,
+					jobSupervisor,
+					boshdirs.NewProvider("/fake-base-dir"),
+					settings,
+				)
+			}
+
+			appliedJobs := func() []models.Job {
+				var applied []models.Job
+				for i := 0; i < jobApplier.ApplyCallCount(); i++ {
+					applied = append(applied, jobApplier.ApplyArgsForCall(i))
+				}
+				return applied
+			}
+
+			It("downloads the templates of one job at a time by default", func() {
+				err := buildApplier(nil).Apply(&fakeas.FakeApplySpec{JobResults: jobs})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(jobApplier.PrepareCallCount()).To(Equal(len(jobs)))
+				Expect(maxInFlight).To(Equal(1))
+				Expect(appliedJobs()).To(Equal(jobs))
+			})
+
+			It("does not download the templates of jobs again that were prepared before", func() {
+				applyJobsParallel := 3
+				applier := buildApplier(&applyJobsParallel)
+
+				err := applier.Prepare(&fakeas.FakeApplySpec{JobResults: jobs[:3]})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(jobApplier.PrepareCallCount()).To(Equal(3))
+
+				err = applier.Apply(&fakeas.FakeApplySpec{JobResults: jobs})
+				Expect(err).ToNot(HaveOccurred())
+
+				var preparedJobs []models.Job
+				for i := 0; i < jobApplier.PrepareCallCount(); i++ {
+					preparedJobs = append(preparedJobs, jobApplier.PrepareArgsForCall(i))
+				}
+				Expect(preparedJobs).To(ConsistOf(jobs))
+				Expect(appliedJobs()).To(Equal(jobs))
+			})
+
+			It("downloads the templates of jobs again on the next apply", func() {
+				applyJobsParallel := 3
+				applier := buildApplier(&applyJobsParallel)
+
+				err := applier.Prepare(&fakeas.FakeApplySpec{JobResults: jobs})
+				Expect(err).ToNot(HaveOccurred())
+
+				err = applier.Apply(&fakeas.FakeApplySpec{JobResults: jobs})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(jobApplier.PrepareCallCount()).To(Equal(len(jobs)))
+
+				err = applier.Apply(&fakeas.FakeApplySpec{JobResults: jobs})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(jobApplier.PrepareCallCount()).To(Equal(2 * len(jobs)))
+			})
+
+			It("downloads the templates of every job before applying the same jobs in order", func() {
+				jobApplier.ApplyStub = func(models.Job) error {
+					Expect(jobApplier.PrepareCallCount()).To(Equal(len(jobs)))
+					return nil
+				}
+
+				applyJobsParallel := 3
+				err := buildApplier(&applyJobsParallel).Apply(&fakeas.FakeApplySpec{JobResults: jobs})
+				Expect(err).ToNot(HaveOccurred())
+
+				var preparedJobs []models.Job
+				for i := 0; i < jobApplier.PrepareCallCount(); i++ {
+					preparedJobs = append(preparedJobs, jobApplier.PrepareArgsForCall(i))
+				}
+				Expect(preparedJobs).To(ConsistOf(jobs))
+				Expect(appliedJobs()).To(Equal(jobs))
+			})
+
+			It("does not download the templates of more jobs at once than configured", func() {
+				applyJobsParallel := 2
+				err := buildApplier(&applyJobsParallel).Apply(&fakeas.FakeApplySpec{JobResults: jobs})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(maxInFlight).To(BeNumerically("<=", 2))
+			})
+
+			It("returns the errors of all jobs that failed to download without applying any job", func() {
+				jobApplier.PrepareStub = func(job models.Job) error {
+					if job.Name == jobs[1].Name || job.Name == jobs[3].Name {
+						return errors.New("fake-digest-mismatch-error")
+					}
+					return nil
+				}
+
+				applyJobsParallel := 5
+				err := buildApplier(&applyJobsParallel).Apply(&fakeas.FakeApplySpec{JobResults: jobs})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Preparing job " + jobs[1].Name))
+				Expect(err.Error()).To(ContainSubstring("Preparing job " + jobs[3].Name))
+				Expect(jobApplier.ApplyCallCount()).To(Equal(0))
+			})
+		})
+
 		It("apply errs when applying packages errs", func() {
 			pkg := buildPackage()
 
@@ -305,13 +530,19 @@ var _ = Describe("concreteApplier", func() {
 		})
 
 		It("apply sets up logrotation", func() {
-			err := applier.Apply(&fakeas.FakeApplySpec{MaxLogFileSizeResult: "fake-size"})
+			err := applier.Apply(&fakeas.FakeApplySpec{
+				MaxLogFileSizeResult: "fake-size",
+				MaxLogFilesResult:    5,
+				JobMaxLogFilesResult: map[string]int{"fake-job": 2},
+			})
 			Expect(err).ToNot(HaveOccurred())
 
 			assert.Equal(GinkgoT(), logRotateDelegate.SetupLogrotateArgs, SetupLogrotateArgs{
-				GroupName: boshsettings.VCAPUsername,
-				BasePath:  filepath.Clean("/fake-base-dir"),
-				Size:      "fake-size",
+				GroupName:      boshsettings.VCAPUsername,
+				BasePath:       filepath.Clean("/fake-base-dir"),
+				Size:           "fake-size",
+				MaxLogFiles:    5,
+				JobMaxLogFiles: map[string]int{"fake-job": 2},
 			})
 		})
 
@@ -344,4 +575,40 @@ var _ = Describe("concreteApplier", func() {
 			Expect(jobApplier.DeleteSourceBlobsArgsForCall(0)).To(Equal([]models.Job{job}))
 		})
 	})
+
+	Describe("VerifyPackages", func() {
+		It("verifies each package and returns their statuses sorted by name", func() {
+			pkg1 := models.Package{Name: "pkg-b", Version: "v2"}
+			pkg2 := models.Package{Name: "pkg-a", Version: "v1"}
+
+			packageApplier.VerifyStub = func(pkg models.Package, verifyDigest bool) (boshpackages.VerificationStatus, error) {
+				Expect(verifyDigest).To(BeTrue())
+				if pkg.Name == "pkg-b" {
+					return boshpackages.VerificationStatusCorrupted, nil
+				}
+				return boshpackages.VerificationStatusOK, nil
+			}
+
+			verifications, err := applier.VerifyPackages(
+				&fakeas.FakeApplySpec{PackageResults: []models.Package{pkg1, pkg2}},
+				true,
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(verifications).To(Equal([]PackageVerification{
+				{Name: "pkg-a", Version: "v1", Status: boshpackages.VerificationStatusOK},
+				{Name: "pkg-b", Version: "v2", Status: boshpackages.VerificationStatusCorrupted},
+			}))
+		})
+
+		It("returns error when verifying a package fails", func() {
+			packageApplier.VerifyErr = errors.New("fake-verify-error")
+
+			_, err := applier.VerifyPackages(
+				&fakeas.FakeApplySpec{PackageResults: []models.Package{buildPackage()}},
+				false,
+			)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-verify-error"))
+		})
+	})
 })
@@ -1,6 +1,7 @@
 package fakes
 
 import (
+	boshappl "github.com/cloudfoundry/bosh-agent/agent/applier"
 	boshas "github.com/cloudfoundry/bosh-agent/agent/applier/applyspec"
 	"github.com/cloudfoundry/bosh-agent/agent/applier/models"
 )
@@ -18,6 +19,11 @@ type FakeApplier struct {
 	ConfiguredDesiredApplySpec boshas.ApplySpec
 	ConfiguredJobs             []models.Job
 	ConfiguredError            error
+
+	VerifyPackagesDesiredApplySpec boshas.ApplySpec
+	VerifyPackagesVerifyDigests    bool
+	VerifyPackagesVerifications    []boshappl.PackageVerification
+	VerifyPackagesError            error
 }
 
 func NewFakeApplier() *FakeApplier {
@@ -41,3 +47,9 @@ func (s *FakeApplier) Apply(desiredApplySpec boshas.ApplySpec) error {
 	s.ApplyDesiredApplySpec = desiredApplySpec
 	return s.ApplyError
 }
+
+func (s *FakeApplier) VerifyPackages(desiredApplySpec boshas.ApplySpec, verifyDigests bool) ([]boshappl.PackageVerification, error) {
+	s.VerifyPackagesDesiredApplySpec = desiredApplySpec
+	s.VerifyPackagesVerifyDigests = verifyDigests
+	return s.VerifyPackagesVerifications, s.VerifyPackagesError
+}
@@ -44,3 +44,24 @@ func FixPermissions(fs boshsys.FileSystem, path string, user string, group strin
 
 	return nil
 }
+
+// ConvergeFileContentsWithOwner converges the file like ConvergeFileContents
+// and gives it the owner (user or user:group) when it is created. Ownership of
+// an existing file is left alone so that it can be changed by job scripts.
+func ConvergeFileContentsWithOwner(fs boshsys.FileSystem, path string, content []byte, owner string, opts boshsys.ConvergeFileContentsOpts) (bool, error) {
+	created := !fs.FileExists(path)
+
+	changed, err := fs.ConvergeFileContents(path, content, opts)
+	if err != nil {
+		return changed, err
+	}
+
+	if created && !opts.DryRun && owner != "" {
+		err = fs.Chown(path, owner)
+		if err != nil {
+			return changed, bosherr.WrapErrorf(err, "Setting owner of created file %s", path)
+		}
+	}
+
+	return changed, nil
+}
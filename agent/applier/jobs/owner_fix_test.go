@@ -7,14 +7,15 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
-	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	fakefs "github.com/cloudfoundry/bosh-agent/platform/fakefs"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
 )
 
 var _ = Describe("fixing job template permissions and ownership", func() {
-	var fs *fakesys.FakeFileSystem
+	var fs *fakefs.FakeFileSystem
 
 	BeforeEach(func() {
-		fs = fakesys.NewFakeFileSystem()
+		fs = fakefs.NewFakeFileSystem()
 
 		err := fs.MkdirAll("/jobs/bin", 0700)
 		Expect(err).NotTo(HaveOccurred())
@@ -78,3 +79,58 @@ var _ = Describe("fixing job template permissions and ownership", func() {
 		})
 	})
 })
+
+var _ = Describe("converging job files with an owner", func() {
+	var fs *fakefs.FakeFileSystem
+
+	BeforeEach(func() {
+		fs = fakefs.NewFakeFileSystem()
+	})
+
+	It("sets the owner when creating the file", func() {
+		changed, err := ConvergeFileContentsWithOwner(fs, "/jobs/config/foo.yml", []byte("a: 1"), "vcap:vcap", boshsys.ConvergeFileContentsOpts{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeTrue())
+
+		stat := fs.GetFileTestStat("/jobs/config/foo.yml")
+		Expect(stat.StringContents()).To(Equal("a: 1"))
+		Expect(stat.Username).To(Equal("vcap"))
+		Expect(stat.Groupname).To(Equal("vcap"))
+	})
+
+	It("leaves the owner of an existing file alone when re-converging", func() {
+		_, err := ConvergeFileContentsWithOwner(fs, "/jobs/config/foo.yml", []byte("a: 1"), "vcap:vcap", boshsys.ConvergeFileContentsOpts{})
+		Expect(err).NotTo(HaveOccurred())
+
+		err = fs.Chown("/jobs/config/foo.yml", "root:vcap")
+		Expect(err).NotTo(HaveOccurred())
+
+		changed, err := ConvergeFileContentsWithOwner(fs, "/jobs/config/foo.yml", []byte("a: 2"), "vcap:vcap", boshsys.ConvergeFileContentsOpts{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeTrue())
+
+		stat := fs.GetFileTestStat("/jobs/config/foo.yml")
+		Expect(stat.StringContents()).To(Equal("a: 2"))
+		Expect(stat.Username).To(Equal("root"))
+		Expect(stat.Groupname).To(Equal("vcap"))
+		Expect(fs.ChownCalls).To(HaveLen(2))
+	})
+
+	It("neither creates the file nor sets its owner on a dry run", func() {
+		changed, err := ConvergeFileContentsWithOwner(fs, "/jobs/config/foo.yml", []byte("a: 1"), "vcap", boshsys.ConvergeFileContentsOpts{DryRun: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeTrue())
+
+		Expect(fs.FileExists("/jobs/config/foo.yml")).To(BeFalse())
+		Expect(fs.ChownCalls).To(BeEmpty())
+	})
+
+	It("returns an error when the owner of the created file cannot be set", func() {
+		fs.ChownErr = errors.New("fake-chown-err")
+
+		_, err := ConvergeFileContentsWithOwner(fs, "/jobs/config/foo.yml", []byte("a: 1"), "vcap", boshsys.ConvergeFileContentsOpts{})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Setting owner of created file /jobs/config/foo.yml"))
+		Expect(err.Error()).To(ContainSubstring("fake-chown-err"))
+	})
+})
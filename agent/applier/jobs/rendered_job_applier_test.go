@@ -10,6 +10,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	"github.com/cloudfoundry/bosh-agent/agent/applier/models"
+	fakefs "github.com/cloudfoundry/bosh-agent/platform/fakefs"
 	"github.com/cloudfoundry/bosh-agent/settings/directories"
 
 	boshbc "github.com/cloudfoundry/bosh-agent/agent/applier/bundlecollection"
@@ -22,7 +23,6 @@ import (
 	fakepackages "github.com/cloudfoundry/bosh-agent/agent/applier/packages/fakes"
 	fakeblobdelegator "github.com/cloudfoundry/bosh-agent/agent/httpblobprovider/blobstore_delegator/blobstore_delegatorfakes"
 	fakejobsuper "github.com/cloudfoundry/bosh-agent/jobsupervisor/fakes"
-	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
 )
 
 var _ = Describe("renderedJobApplier", func() {
@@ -31,7 +31,7 @@ var _ = Describe("renderedJobApplier", func() {
 		jobSupervisor          *fakejobsuper.FakeJobSupervisor
 		packageApplierProvider *fakepackages.FakeApplierProvider
 		blobstore              *fakeblobdelegator.FakeBlobstoreDelegator
-		fs                     *fakesys.FakeFileSystem
+		fs                     *fakefs.FakeFileSystem
 		applier                Applier
 		fixPermissions         *fakeFixer
 	)
@@ -41,7 +41,7 @@ var _ = Describe("renderedJobApplier", func() {
 		jobSupervisor = fakejobsuper.NewFakeJobSupervisor()
 		packageApplierProvider = fakepackages.NewFakeApplierProvider()
 		blobstore = &fakeblobdelegator.FakeBlobstoreDelegator{}
-		fs = fakesys.NewFakeFileSystem()
+		fs = fakefs.NewFakeFileSystem()
 		logger := boshlog.NewLogger(boshlog.LevelNone)
 		dirProvider := directories.NewProvider("/fakebasedir")
 		fixPermissions = &fakeFixer{}
@@ -173,21 +173,21 @@ var _ = Describe("renderedJobApplier", func() {
 				Expect(err).ToNot(HaveOccurred())
 				stat := fs.GetFileTestStat("/fakebasedir/data/sys/log/" + job.Name)
 				Expect(stat).ToNot(BeNil())
-				Expect(stat.FileType).To(Equal(fakesys.FakeFileTypeDir))
+				Expect(stat.FileType).To(Equal(fakefs.FakeFileTypeDir))
 				Expect(stat.FileMode).To(Equal(os.FileMode(0770)))
 				Expect(stat.Username).To(Equal("root"))
 				Expect(stat.Groupname).To(Equal("vcap"))
 
 				stat = fs.GetFileTestStat("/fakebasedir/data/sys/run/" + job.Name)
 				Expect(stat).ToNot(BeNil())
-				Expect(stat.FileType).To(Equal(fakesys.FakeFileTypeDir))
+				Expect(stat.FileType).To(Equal(fakefs.FakeFileTypeDir))
 				Expect(stat.FileMode).To(Equal(os.FileMode(0770)))
 				Expect(stat.Username).To(Equal("root"))
 				Expect(stat.Groupname).To(Equal("vcap"))
 
 				stat = fs.GetFileTestStat("/fakebasedir/data/" + job.Name)
 				Expect(stat).ToNot(BeNil())
-				Expect(stat.FileType).To(Equal(fakesys.FakeFileTypeDir))
+				Expect(stat.FileType).To(Equal(fakefs.FakeFileTypeDir))
 				Expect(stat.FileMode).To(Equal(os.FileMode(0770)))
 				Expect(stat.Username).To(Equal("root"))
 				Expect(stat.Groupname).To(Equal("vcap"))
@@ -1,5 +1,5 @@
 package applier
 
 type LogrotateDelegate interface {
-	SetupLogrotate(groupName, basePath, size string) (err error)
+	SetupLogrotate(groupName, basePath, size string, maxLogFiles int, jobMaxLogFiles map[string]int) (err error)
 }
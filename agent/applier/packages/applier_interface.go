@@ -4,8 +4,25 @@ import (
 	models "github.com/cloudfoundry/bosh-agent/agent/applier/models"
 )
 
+// VerificationStatus describes the state of an installed package on disk
+type VerificationStatus string
+
+const (
+	VerificationStatusOK        VerificationStatus = "ok"
+	VerificationStatusMissing   VerificationStatus = "missing"
+	VerificationStatusCorrupted VerificationStatus = "corrupted"
+
+	// Package is installed but had not been verified before; its digest
+	// is recorded so that later verifications can detect changes
+	VerificationStatusUnverified VerificationStatus = "unverified"
+)
+
 type Applier interface {
 	Prepare(pkg models.Package) error
 	Apply(pkg models.Package) error
 	KeepOnly(pkgs []models.Package) error
+
+	// Verify checks that pkg is installed and, when verifyDigest is set,
+	// that its contents still match the digest recorded when first verified
+	Verify(pkg models.Package, verifyDigest bool) (VerificationStatus, error)
 }
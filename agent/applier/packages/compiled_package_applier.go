@@ -1,6 +1,13 @@
 package packages
 
 import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
 	bc "github.com/cloudfoundry/bosh-agent/agent/applier/bundlecollection"
 	models "github.com/cloudfoundry/bosh-agent/agent/applier/models"
 	"github.com/cloudfoundry/bosh-agent/agent/httpblobprovider/blobstore_delegator"
@@ -17,6 +24,9 @@ type compiledPackageApplier struct {
 	// KeepOnly will permanently uninstall packages when operating as owner
 	packagesBcOwner bool
 
+	// Directory holding digests of package contents recorded when first verified
+	digestsPath string
+
 	blobstore blobstore_delegator.BlobstoreDelegator
 	fs        boshsys.FileSystem
 	logger    boshlog.Logger
@@ -25,6 +35,7 @@ type compiledPackageApplier struct {
 func NewCompiledPackageApplier(
 	packagesBc bc.BundleCollection,
 	packagesBcOwner bool,
+	digestsPath string,
 	blobstore blobstore_delegator.BlobstoreDelegator,
 	fs boshsys.FileSystem,
 	logger boshlog.Logger,
@@ -32,6 +43,7 @@ func NewCompiledPackageApplier(
 	return &compiledPackageApplier{
 		packagesBc:      packagesBc,
 		packagesBcOwner: packagesBcOwner,
+		digestsPath:     digestsPath,
 		blobstore:       blobstore,
 		fs:              fs,
 		logger:          logger,
@@ -94,14 +106,129 @@ func (s *compiledPackageApplier) downloadAndInstall(pkg models.Package, pkgBundl
 		}
 	}()
 
-	_, err = pkgBundle.Install(file, "")
+	installPath, err := pkgBundle.Install(file, "")
 	if err != nil {
 		return bosherr.WrapError(err, "Installing package directory")
 	}
 
+	// A digest left behind by an earlier install of the same version no longer applies
+	err = s.fs.RemoveAll(s.digestPath(installPath))
+	if err != nil {
+		s.logger.Warn(logTag, "Failed to remove digest of package %s: %s", pkg.Name, err.Error())
+	}
+
 	return nil
 }
 
+func (s compiledPackageApplier) Verify(pkg models.Package, verifyDigest bool) (VerificationStatus, error) {
+	s.logger.Debug(logTag, "Verifying package %v", pkg)
+
+	pkgBundle, err := s.packagesBc.Get(pkg)
+	if err != nil {
+		return "", bosherr.WrapError(err, "Getting package bundle")
+	}
+
+	pkgInstalled, err := pkgBundle.IsInstalled()
+	if err != nil {
+		return "", bosherr.WrapError(err, "Checking if package is installed")
+	}
+
+	if !pkgInstalled {
+		return VerificationStatusMissing, nil
+	}
+
+	if !verifyDigest {
+		return VerificationStatusOK, nil
+	}
+
+	installPath, err := pkgBundle.GetInstallPath()
+	if err != nil {
+		return "", bosherr.WrapError(err, "Getting package install path")
+	}
+
+	actualDigest, err := s.contentsDigest(installPath)
+	if err != nil {
+		return "", bosherr.WrapError(err, "Calculating package digest")
+	}
+
+	// Packages are only hashed when verified so the first verification records
+	// the digest that later ones are checked against
+	digestPath := s.digestPath(installPath)
+	if !s.fs.FileExists(digestPath) {
+		err = s.fs.WriteFileString(digestPath, actualDigest)
+		if err != nil {
+			return "", bosherr.WrapError(err, "Recording package digest")
+		}
+
+		return VerificationStatusUnverified, nil
+	}
+
+	recordedDigest, err := s.fs.ReadFileString(digestPath)
+	if err != nil {
+		return "", bosherr.WrapError(err, "Reading recorded package digest")
+	}
+
+	if actualDigest != recordedDigest {
+		return VerificationStatusCorrupted, nil
+	}
+
+	return VerificationStatusOK, nil
+}
+
+// digestPath mirrors the <name>/<version> layout of the package bundle collection
+func (s compiledPackageApplier) digestPath(installPath string) string {
+	return filepath.Join(s.digestsPath, filepath.Base(filepath.Dir(installPath)), filepath.Base(installPath))
+}
+
+// contentsDigest fingerprints file names, file contents and symlink targets under installPath
+func (s compiledPackageApplier) contentsDigest(installPath string) (string, error) {
+	hash := sha1.New()
+
+	err := s.fs.Walk(installPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(installPath, path)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			target, err := s.fs.Readlink(path)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(hash, "link %s %s\n", relPath, target)
+
+		case info.IsDir():
+			fmt.Fprintf(hash, "dir %s\n", relPath)
+
+		default:
+			fmt.Fprintf(hash, "file %s %d\n", relPath, info.Size())
+
+			file, err := s.fs.OpenFile(path, os.O_RDONLY, 0)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+
+			_, err = io.Copy(hash, file)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
 func (s *compiledPackageApplier) KeepOnly(pkgs []models.Package) error {
 	s.logger.Debug(logTag, "Keeping only packages %v", pkgs)
 
@@ -132,6 +259,8 @@ func (s *compiledPackageApplier) KeepOnly(pkgs []models.Package) error {
 			}
 
 			if s.packagesBcOwner {
+				installPath, installPathErr := installedBundle.GetInstallPath()
+
 				// If we uninstall the bundle first, and the disable failed (leaving the symlink),
 				// then the next time bundle collection will not include bundle in its list
 				// which means that symlink will never be deleted.
@@ -139,6 +268,13 @@ func (s *compiledPackageApplier) KeepOnly(pkgs []models.Package) error {
 				if err != nil {
 					return bosherr.WrapError(err, "Uninstalling package bundle")
 				}
+
+				if installPathErr == nil {
+					err = s.fs.RemoveAll(s.digestPath(installPath))
+					if err != nil {
+						s.logger.Warn(logTag, "Failed to remove digest of package bundle %s: %s", installPath, err.Error())
+					}
+				}
 			}
 		}
 	}
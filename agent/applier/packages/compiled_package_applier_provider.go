@@ -50,7 +50,7 @@ func NewCompiledPackageApplierProvider(
 // Root provides package applier that operates on system-wide packages.
 // (e.g manages /var/vcap/packages/pkg-a -> /var/vcap/data/packages/pkg-a)
 func (p compiledPackageApplierProvider) Root() Applier {
-	return NewCompiledPackageApplier(p.RootBundleCollection(), true, p.blobstore, p.fs, p.logger)
+	return NewCompiledPackageApplier(p.RootBundleCollection(), true, p.digestsPath(), p.blobstore, p.fs, p.logger)
 }
 
 // JobSpecific provides package applier that operates on job-specific packages.
@@ -67,7 +67,7 @@ func (p compiledPackageApplierProvider) JobSpecific(jobName string) Applier {
 		p.compressor,
 		p.logger,
 	)
-	return NewCompiledPackageApplier(packagesBc, false, p.blobstore, p.fs, p.logger)
+	return NewCompiledPackageApplier(packagesBc, false, p.digestsPath(), p.blobstore, p.fs, p.logger)
 }
 
 func (p compiledPackageApplierProvider) RootBundleCollection() boshbc.BundleCollection {
@@ -82,3 +82,8 @@ func (p compiledPackageApplierProvider) RootBundleCollection() boshbc.BundleColl
 		p.logger,
 	)
 }
+
+// Job-specific appliers share installed packages with the root applier, and therefore their digests
+func (p compiledPackageApplierProvider) digestsPath() string {
+	return path.Join(p.installPath, p.name+"_digests")
+}
@@ -58,6 +58,7 @@ var _ = Describe("compiledPackageApplierProvider", func() {
 					logger,
 				),
 				true,
+				"fake-install-path/fake-name_digests",
 				blobstore,
 				fs,
 				logger,
@@ -84,6 +85,7 @@ var _ = Describe("compiledPackageApplierProvider", func() {
 				// should not delete packages that could potentially be used by other jobs
 				false,
 
+				"fake-install-path/fake-name_digests",
 				blobstore,
 				fs,
 				logger,
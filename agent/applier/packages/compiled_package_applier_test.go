@@ -53,7 +53,7 @@ func init() {
 			blobstore = &fakeblobdelegator.FakeBlobstoreDelegator{}
 			fs = fakesys.NewFakeFileSystem()
 			logger = boshlog.NewLogger(boshlog.LevelNone)
-			applier = NewCompiledPackageApplier(packagesBc, true, blobstore, fs, logger)
+			applier = NewCompiledPackageApplier(packagesBc, true, "/fake-digests", blobstore, fs, logger)
 		})
 
 		Describe("Prepare & Apply", func() {
@@ -125,6 +125,28 @@ func init() {
 					Expect(fingerPrint).To(Equal(boshcrypto.MustNewMultipleDigest(boshcrypto.NewDigest(boshcrypto.DigestAlgorithmSHA256, "sha256:fake-blob-sha256"))))
 				})
 
+				It("does not hash the installed package contents", func() {
+					bundle.InstallPath = "/fake-install/fake-package/fake-version"
+					err := fs.WriteFileString("/fake-install/fake-package/fake-version/bin/run", "fake-contents")
+					Expect(err).ToNot(HaveOccurred())
+
+					err = act()
+					Expect(err).ToNot(HaveOccurred())
+
+					Expect(fs.FileExists("/fake-digests/fake-package/fake-version")).To(BeFalse())
+				})
+
+				It("removes a digest recorded for an earlier install of the package", func() {
+					bundle.InstallPath = "/fake-install/fake-package/fake-version"
+					err := fs.WriteFileString("/fake-digests/fake-package/fake-version", "fake-stale-digest")
+					Expect(err).ToNot(HaveOccurred())
+
+					err = act()
+					Expect(err).ToNot(HaveOccurred())
+
+					Expect(fs.FileExists("/fake-digests/fake-package/fake-version")).To(BeFalse())
+				})
+
 				It("installs bundle from archive", func() {
 					blobstore.GetReturns("/fake-blobstore-file-name", nil)
 					err := act()
@@ -292,7 +314,7 @@ func init() {
 
 			Context("when operating on packages as a package owner", func() {
 				BeforeEach(func() {
-					applier = NewCompiledPackageApplier(packagesBc, true, blobstore, fs, logger)
+					applier = NewCompiledPackageApplier(packagesBc, true, "/fake-digests", blobstore, fs, logger)
 				})
 
 				It("first disables and then uninstalls packages that are not in keeponly list", func() {
@@ -312,6 +334,19 @@ func init() {
 					Expect(bundle4.ActionsCalled).To(Equal([]string{}))
 				})
 
+				It("removes the recorded digest of uninstalled packages", func() {
+					_, bundle1 := buildPkg(packagesBc)
+					bundle1.GetDirPath = "/fake-install/fake-package/fake-version"
+					err := fs.WriteFileString("/fake-digests/fake-package/fake-version", "fake-digest")
+					Expect(err).ToNot(HaveOccurred())
+
+					packagesBc.ListBundles = []boshbc.Bundle{bundle1}
+
+					err = applier.KeepOnly([]models.Package{})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(fs.FileExists("/fake-digests/fake-package/fake-version")).To(BeFalse())
+				})
+
 				ItReturnsErrors()
 
 				It("returns error when at least one bundle cannot be uninstalled", func() {
@@ -328,7 +363,7 @@ func init() {
 
 			Context("when operating on packages not as a package owner", func() {
 				BeforeEach(func() {
-					applier = NewCompiledPackageApplier(packagesBc, false, blobstore, fs, logger)
+					applier = NewCompiledPackageApplier(packagesBc, false, "/fake-digests", blobstore, fs, logger)
 				})
 
 				It("disables and but does not uninstall packages that are not in keeponly list", func() {
@@ -352,5 +387,100 @@ func init() {
 			})
 
 		})
+
+		Describe("Verify", func() {
+			var (
+				pkg    models.Package
+				bundle *fakebc.FakeBundle
+			)
+
+			BeforeEach(func() {
+				pkg, bundle = buildPkg(packagesBc)
+				bundle.InstallPath = "/fake-install/fake-package/fake-version"
+				bundle.GetDirPath = "/fake-install/fake-package/fake-version"
+
+				err := fs.WriteFileString("/fake-install/fake-package/fake-version/bin/run", "fake-contents")
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("returns an error if getting file bundle fails", func() {
+				packagesBc.GetErr = errors.New("fake-get-bundle-error")
+
+				_, err := applier.Verify(pkg, true)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-get-bundle-error"))
+			})
+
+			It("reports a package that is not installed as missing", func() {
+				status, err := applier.Verify(pkg, true)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(status).To(Equal(VerificationStatusMissing))
+			})
+
+			Context("when package is installed", func() {
+				BeforeEach(func() {
+					err := applier.Prepare(pkg)
+					Expect(err).ToNot(HaveOccurred())
+				})
+
+				It("reports the package as ok without checking the digest when not requested", func() {
+					err := fs.RemoveAll("/fake-digests")
+					Expect(err).ToNot(HaveOccurred())
+
+					status, err := applier.Verify(pkg, false)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(status).To(Equal(VerificationStatusOK))
+				})
+
+				It("records the digest and reports the package as unverified on the first verification", func() {
+					status, err := applier.Verify(pkg, true)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(status).To(Equal(VerificationStatusUnverified))
+
+					digest, err := fs.ReadFileString("/fake-digests/fake-package/fake-version")
+					Expect(err).ToNot(HaveOccurred())
+					Expect(digest).To(HaveLen(40))
+				})
+
+				Context("when the package was verified before", func() {
+					BeforeEach(func() {
+						_, err := applier.Verify(pkg, true)
+						Expect(err).ToNot(HaveOccurred())
+					})
+
+					It("reports the package as ok when its contents match the recorded digest", func() {
+						status, err := applier.Verify(pkg, true)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(status).To(Equal(VerificationStatusOK))
+					})
+
+					It("reports the package as corrupted when its contents were modified", func() {
+						err := fs.WriteFileString("/fake-install/fake-package/fake-version/bin/run", "modified-contents")
+						Expect(err).ToNot(HaveOccurred())
+
+						status, err := applier.Verify(pkg, true)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(status).To(Equal(VerificationStatusCorrupted))
+					})
+
+					It("reports the package as corrupted when some of its files were removed", func() {
+						err := fs.RemoveAll("/fake-install/fake-package/fake-version/bin/run")
+						Expect(err).ToNot(HaveOccurred())
+
+						status, err := applier.Verify(pkg, true)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(status).To(Equal(VerificationStatusCorrupted))
+					})
+				})
+
+				It("returns an error if reading the package contents fails", func() {
+					fs.WalkErr = errors.New("fake-walk-error")
+
+					_, err := applier.Verify(pkg, true)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("fake-walk-error"))
+				})
+			})
+		})
 	})
 }
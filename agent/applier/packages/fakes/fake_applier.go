@@ -4,6 +4,7 @@ import (
 	"sync"
 
 	models "github.com/cloudfoundry/bosh-agent/agent/applier/models"
+	boshpackages "github.com/cloudfoundry/bosh-agent/agent/applier/packages"
 )
 
 type FakeApplier struct {
@@ -17,8 +18,14 @@ type FakeApplier struct {
 
 	KeptOnlyPackages []models.Package
 	KeepOnlyErr      error
+
+	VerifiedPackages []models.Package
+	VerifyStatus     boshpackages.VerificationStatus
+	VerifyErr        error
+	VerifyStub       func(pkg models.Package, verifyDigest bool) (boshpackages.VerificationStatus, error)
 	applyMutex       sync.Mutex
 	PrepareStub      func(pkg models.Package) error
+	ApplyStub        func(pkg models.Package) error
 }
 
 func NewFakeApplier() *FakeApplier {
@@ -39,8 +46,13 @@ func (s *FakeApplier) Prepare(pkg models.Package) error {
 }
 
 func (s *FakeApplier) Apply(pkg models.Package) error {
+	s.applyMutex.Lock()
 	s.ActionsCalled = append(s.ActionsCalled, "Apply")
 	s.AppliedPackages = append(s.AppliedPackages, pkg)
+	s.applyMutex.Unlock()
+	if s.ApplyStub != nil {
+		return s.ApplyStub(pkg)
+	}
 	return s.ApplyError
 }
 
@@ -49,3 +61,14 @@ func (s *FakeApplier) KeepOnly(pkgs []models.Package) error {
 	s.KeptOnlyPackages = pkgs
 	return s.KeepOnlyErr
 }
+
+func (s *FakeApplier) Verify(pkg models.Package, verifyDigest bool) (boshpackages.VerificationStatus, error) {
+	s.applyMutex.Lock()
+	s.ActionsCalled = append(s.ActionsCalled, "Verify")
+	s.VerifiedPackages = append(s.VerifiedPackages, pkg)
+	s.applyMutex.Unlock()
+	if s.VerifyStub != nil {
+		return s.VerifyStub(pkg, verifyDigest)
+	}
+	return s.VerifyStatus, s.VerifyErr
+}
@@ -104,12 +104,25 @@ func (boot bootstrap) Run() (err error) {
 		return bosherr.WrapError(err, "Setting up raw ephemeral disk")
 	}
 
-	ephemeralDiskPath := boot.platform.GetEphemeralDiskPath(settings.EphemeralDiskSettings())
+	ephemeralDisksSettings := settings.EphemeralDisksSettings()
+
+	ephemeralDiskPath := boot.platform.GetEphemeralDiskPath(ephemeralDisksSettings[0])
 	desiredSwapSizeInBytes := settings.Env.GetSwapSizeInBytes()
 	if err = boot.platform.SetupEphemeralDiskWithPath(ephemeralDiskPath, desiredSwapSizeInBytes, settings.AgentID); err != nil {
 		return bosherr.WrapError(err, "Setting up ephemeral disk")
 	}
 
+	if len(ephemeralDisksSettings) > 1 {
+		var additionalEphemeralDiskPaths []string
+		for _, diskSettings := range ephemeralDisksSettings[1:] {
+			additionalEphemeralDiskPaths = append(additionalEphemeralDiskPaths, boot.platform.GetEphemeralDiskPath(diskSettings))
+		}
+
+		if err = boot.platform.SetupAdditionalEphemeralDisks(additionalEphemeralDiskPaths, settings.AgentID); err != nil {
+			return bosherr.WrapError(err, "Setting up additional ephemeral disks")
+		}
+	}
+
 	if err = boot.platform.SetupRootDisk(ephemeralDiskPath); err != nil {
 		return bosherr.WrapError(err, "Setting up root disk")
 	}
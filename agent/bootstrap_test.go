@@ -299,6 +299,52 @@ var _ = Describe("bootstrap", func() {
 			})
 		})
 
+		Context("when several ephemeral disks are given", func() {
+			BeforeEach(func() {
+				settingsService.Settings.Disks = boshsettings.Disks{
+					Ephemeral:      "ignored-ephemeral-disk-setting",
+					EphemeralDisks: []string{"/dev/xvdb", "/dev/xvdc"},
+				}
+
+				platform.GetEphemeralDiskPathStub = func(diskSettings boshsettings.DiskSettings) string {
+					return "/dev/real-" + diskSettings.Path
+				}
+			})
+
+			It("sets up the first as the ephemeral disk and the others as additional ephemeral disks", func() {
+				err := bootstrap()
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(platform.SetupEphemeralDiskWithPathCallCount()).To(Equal(1))
+				devicePath, _, _ := platform.SetupEphemeralDiskWithPathArgsForCall(0)
+				Expect(devicePath).To(Equal("/dev/real-/dev/xvdb"))
+
+				Expect(platform.SetupAdditionalEphemeralDisksCallCount()).To(Equal(1))
+				devicePaths, labelPrefix := platform.SetupAdditionalEphemeralDisksArgsForCall(0)
+				Expect(devicePaths).To(Equal([]string{"/dev/real-/dev/xvdc"}))
+				Expect(labelPrefix).To(Equal(settingsService.Settings.AgentID))
+			})
+
+			It("returns error if setting up additional ephemeral disks fails", func() {
+				platform.SetupAdditionalEphemeralDisksReturns(errors.New("fake-setup-additional-ephemeral-disks-err"))
+
+				err := bootstrap()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-setup-additional-ephemeral-disks-err"))
+			})
+		})
+
+		It("does not set up additional ephemeral disks for a single ephemeral disk", func() {
+			settingsService.Settings.Disks = boshsettings.Disks{
+				Ephemeral: "fake-ephemeral-disk-setting",
+			}
+
+			err := bootstrap()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(platform.SetupAdditionalEphemeralDisksCallCount()).To(Equal(0))
+		})
+
 		It("sets up raw ephemeral disks if paths exist", func() {
 			diskSettings := []boshsettings.DiskSettings{{Path: "/dev/xvdb"}, {Path: "/dev/xvdc"}}
 
@@ -1,12 +1,32 @@
 package compiler
 
 import (
+	"fmt"
+	"time"
+
 	boshmodels "github.com/cloudfoundry/bosh-agent/agent/applier/models"
 	boshcrypto "github.com/cloudfoundry/bosh-utils/crypto"
 )
 
 type Compiler interface {
-	Compile(pkg Package, deps []boshmodels.Package) (blobID string, digest boshcrypto.Digest, err error)
+	// Compile returns the ID, digest and size in bytes of the uploaded compiled package
+	Compile(pkg Package, deps []boshmodels.Package, opts ...CompileOptions) (blobID string, digest boshcrypto.Digest, size int64, err error)
+}
+
+type CompileOptions struct {
+	// MaxParallelDependencyDownloads bounds how many dependencies are downloaded
+	// at the same time; values below two download them one after another
+	MaxParallelDependencyDownloads int
+
+	// UploadDigestAlgorithm is used for the digest of the uploaded compiled
+	// package instead of the blobstore's default algorithms when set
+	UploadDigestAlgorithm boshcrypto.Algorithm
+
+	// FetchRetries is how often fetching the package is retried after network
+	// or server (5xx) errors, waiting FetchRetryDelay before the first retry and
+	// doubling the wait for each following one
+	FetchRetries    int
+	FetchRetryDelay time.Duration
 }
 
 type Package struct {
@@ -17,6 +37,32 @@ type Package struct {
 	BlobstoreHeaders    map[string]string `json:"blobstore_headers"`
 	Sha1                boshcrypto.MultipleDigest
 	Version             string
+
+	// UploadExistingBlobSignedURL is used by the blobstore for checking the
+	// blob already stored at UploadSignedURL, see blobstore_delegator.DuplicateUploadMode
+	UploadExistingBlobSignedURL string `json:"upload_existing_blob_signed_url"`
 }
 
 type Dependencies map[string]Package
+
+// FetchError is returned by Compile when the package blob could not be
+// fetched, allowing callers to tell download failures from compilation ones
+type FetchError struct {
+	PackageName string
+	Cause       error
+}
+
+func (e FetchError) Error() string {
+	return fmt.Sprintf("Fetching package %s: %s", e.PackageName, e.Cause.Error())
+}
+
+// UnsupportedDigestAlgorithmError is returned by Compile when a package digest
+// only uses algorithms the agent cannot verify, unlike a digest that is missing
+type UnsupportedDigestAlgorithmError struct {
+	PackageName string
+	Algorithm   string
+}
+
+func (e UnsupportedDigestAlgorithmError) Error() string {
+	return fmt.Sprintf("Unsupported digest algorithm '%s' for package '%s'. Supported algorithms: sha1, sha256, sha512", e.Algorithm, e.PackageName)
+}
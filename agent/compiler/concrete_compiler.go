@@ -2,8 +2,11 @@ package compiler
 
 import (
 	"fmt"
+	"net"
 	"os"
 	"path"
+	"sync/atomic"
+	"time"
 
 	"code.cloudfoundry.org/clock"
 
@@ -11,11 +14,13 @@ import (
 	boshmodels "github.com/cloudfoundry/bosh-agent/agent/applier/models"
 	"github.com/cloudfoundry/bosh-agent/agent/applier/packages"
 	boshcmdrunner "github.com/cloudfoundry/bosh-agent/agent/cmdrunner"
+	"github.com/cloudfoundry/bosh-agent/agent/httpblobprovider"
 	"github.com/cloudfoundry/bosh-agent/agent/httpblobprovider/blobstore_delegator"
 	boshcrypto "github.com/cloudfoundry/bosh-utils/crypto"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshcmd "github.com/cloudfoundry/bosh-utils/fileutil"
 	boshsys "github.com/cloudfoundry/bosh-utils/system"
+	"github.com/cloudfoundry/bosh-utils/work"
 )
 
 const PackagingScriptName = "packaging"
@@ -57,24 +62,48 @@ func NewConcreteCompiler(
 	}
 }
 
-func (c concreteCompiler) Compile(pkg Package, deps []boshmodels.Package) (blobID string, digest boshcrypto.Digest, err error) {
+func (c concreteCompiler) Compile(pkg Package, deps []boshmodels.Package, opts ...CompileOptions) (blobID string, digest boshcrypto.Digest, size int64, err error) {
+	err = validateDigest(pkg.Name, pkg.Sha1)
+	if err != nil {
+		return "", nil, 0, err
+	}
+
+	for _, dep := range deps {
+		err = validateDigest(dep.Name, dep.Source.Sha1)
+		if err != nil {
+			return "", nil, 0, err
+		}
+	}
+
 	err = c.packageApplier.KeepOnly([]boshmodels.Package{})
 	if err != nil {
-		return "", nil, bosherr.WrapError(err, "Removing packages")
+		return "", nil, 0, bosherr.WrapError(err, "Removing packages")
+	}
+
+	var compileOpts CompileOptions
+	if len(opts) > 0 {
+		compileOpts = opts[0]
+	}
+
+	if compileOpts.MaxParallelDependencyDownloads > 1 {
+		err = c.prepareDependencies(deps, compileOpts.MaxParallelDependencyDownloads)
+		if err != nil {
+			return "", nil, 0, err
+		}
 	}
 
 	for _, dep := range deps {
 		err := c.packageApplier.Apply(dep)
 		if err != nil {
-			return "", nil, bosherr.WrapErrorf(err, "Installing dependent package: '%s'", dep.Name)
+			return "", nil, 0, bosherr.WrapErrorf(err, "Installing dependent package: '%s'", dep.Name)
 		}
 	}
 
 	compilePath := path.Join(c.compileDirProvider.CompileDir(), pkg.Name)
 
-	err = c.fetchAndUncompress(pkg, compilePath)
+	err = c.fetchAndUncompressWithRetries(pkg, compilePath, compileOpts.FetchRetries, compileOpts.FetchRetryDelay)
 	if err != nil {
-		return "", nil, bosherr.WrapErrorf(err, "Fetching package %s", pkg.Name)
+		return "", nil, 0, FetchError{PackageName: pkg.Name, Cause: err}
 	}
 
 	defer func() {
@@ -91,57 +120,187 @@ func (c concreteCompiler) Compile(pkg Package, deps []boshmodels.Package) (blobI
 
 	compiledPkgBundle, err := c.packagesBc.Get(compiledPkg)
 	if err != nil {
-		return "", nil, bosherr.WrapError(err, "Getting bundle for new package")
+		return "", nil, 0, bosherr.WrapError(err, "Getting bundle for new package")
 	}
 
 	installPath, err := compiledPkgBundle.InstallWithoutContents()
 	if err != nil {
-		return "", nil, bosherr.WrapError(err, "Setting up new package bundle")
+		return "", nil, 0, bosherr.WrapError(err, "Setting up new package bundle")
 	}
 
 	enablePath, err := compiledPkgBundle.Enable()
 	if err != nil {
-		return "", nil, bosherr.WrapError(err, "Enabling new package bundle")
+		return "", nil, 0, bosherr.WrapError(err, "Enabling new package bundle")
 	}
 
 	scriptPath := path.Join(compilePath, PackagingScriptName)
 
 	if c.fs.FileExists(scriptPath) {
 		if err := c.runPackagingCommand(compilePath, enablePath, pkg); err != nil {
-			return "", nil, bosherr.WrapError(err, "Running packaging script")
+			return "", nil, 0, bosherr.WrapError(err, "Running packaging script")
 		}
 	}
 
 	tmpPackageTar, err := c.compressor.CompressFilesInDir(installPath)
 	if err != nil {
-		return "", nil, bosherr.WrapError(err, "Compressing compiled package")
+		return "", nil, 0, bosherr.WrapError(err, "Compressing compiled package")
 	}
 
 	defer func() {
 		_ = c.compressor.CleanUp(tmpPackageTar)
 	}()
 
-	uploadedBlobID, digest, err := c.blobstore.Write(pkg.UploadSignedURL, tmpPackageTar, pkg.BlobstoreHeaders)
+	tarballStat, err := c.fs.Stat(tmpPackageTar)
 	if err != nil {
-		return "", nil, bosherr.WrapError(err, "Uploading compiled package")
+		return "", nil, 0, bosherr.WrapError(err, "Getting compiled package size")
+	}
+
+	uploadedBlobID, digest, err := c.uploadCompiledPackage(pkg, tmpPackageTar, compileOpts.UploadDigestAlgorithm)
+	if err != nil {
+		return "", nil, 0, bosherr.WrapError(err, "Uploading compiled package")
 	}
 
 	err = compiledPkgBundle.Disable()
 	if err != nil {
-		return "", nil, bosherr.WrapError(err, "Disabling compiled package")
+		return "", nil, 0, bosherr.WrapError(err, "Disabling compiled package")
 	}
 
 	err = compiledPkgBundle.Uninstall()
 	if err != nil {
-		return "", nil, bosherr.WrapError(err, "Uninstalling compiled package")
+		return "", nil, 0, bosherr.WrapError(err, "Uninstalling compiled package")
 	}
 
 	err = c.packageApplier.KeepOnly([]boshmodels.Package{})
 	if err != nil {
-		return "", nil, bosherr.WrapError(err, "Removing packages")
+		return "", nil, 0, bosherr.WrapError(err, "Removing packages")
+	}
+
+	return uploadedBlobID, digest, tarballStat.Size(), nil
+}
+
+// uploadCompiledPackage returns the digest of the uploaded package computed
+// with algorithm, or the one of the blobstore's default algorithms when nil
+func (c concreteCompiler) uploadCompiledPackage(pkg Package, path string, algorithm boshcrypto.Algorithm) (string, boshcrypto.Digest, error) {
+	var (
+		blobID         string
+		multipleDigest boshcrypto.MultipleDigest
+		err            error
+	)
+
+	switch {
+	case pkg.UploadExistingBlobSignedURL != "":
+		algorithms := httpblobprovider.DefaultCryptoAlgorithms
+		if algorithm != nil {
+			algorithms = []boshcrypto.Algorithm{algorithm}
+		}
+
+		multipleDigest, err = c.blobstore.WriteWithExistingBlobCheck(pkg.UploadSignedURL, pkg.UploadExistingBlobSignedURL, path, pkg.BlobstoreHeaders, algorithms)
+	case algorithm == nil:
+		blobID, multipleDigest, err = c.blobstore.Write(pkg.UploadSignedURL, path, pkg.BlobstoreHeaders)
+	default:
+		blobID, multipleDigest, err = c.blobstore.WriteWithDigestAlgorithms(pkg.UploadSignedURL, path, pkg.BlobstoreHeaders, []boshcrypto.Algorithm{algorithm})
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	if algorithm == nil {
+		return blobID, multipleDigest, nil
+	}
+
+	digest, err := multipleDigest.DigestFor(algorithm)
+	if err != nil {
+		return "", nil, bosherr.WrapErrorf(err, "Getting %s digest of uploaded package", algorithm.Name())
+	}
+
+	return blobID, digest, nil
+}
+
+// prepareDependencies downloads and installs dependencies concurrently so that
+// applying them afterwards only needs to enable them. Downloads that have not
+// started yet are skipped as soon as one of them fails.
+func (c concreteCompiler) prepareDependencies(deps []boshmodels.Package, maxParallel int) error {
+	var aborted int32
+
+	tasks := []func() error{}
+	for _, dep := range deps {
+		dep := dep
+		tasks = append(tasks, func() error {
+			if atomic.LoadInt32(&aborted) != 0 {
+				return nil
+			}
+
+			err := c.packageApplier.Prepare(dep)
+			if err != nil {
+				atomic.StoreInt32(&aborted, 1)
+				return bosherr.WrapErrorf(err, "Downloading dependent package: '%s'", dep.Name)
+			}
+
+			return nil
+		})
+	}
+
+	pool := work.Pool{
+		Count: maxParallel,
+	}
+
+	return pool.ParallelDo(tasks...)
+}
+
+// validateDigest rejects digests that could never be verified before anything
+// is downloaded so that the director gets an actionable error
+func validateDigest(pkgName string, digest boshcrypto.Digest) error {
+	if digest == nil || digest.String() == "" {
+		return bosherr.Errorf("No digest algorithm found for package '%s'. Supported algorithms: sha1, sha256, sha512", pkgName)
 	}
 
-	return uploadedBlobID, digest, nil
+	// The strongest digest is only of an unknown algorithm when all of them are
+	algoName := digest.Algorithm().Name()
+
+	for _, algo := range []boshcrypto.Algorithm{
+		boshcrypto.DigestAlgorithmSHA1,
+		boshcrypto.DigestAlgorithmSHA256,
+		boshcrypto.DigestAlgorithmSHA512,
+	} {
+		if algoName == algo.Name() {
+			return nil
+		}
+	}
+
+	return UnsupportedDigestAlgorithmError{PackageName: pkgName, Algorithm: algoName}
+}
+
+// fetchAndUncompressWithRetries only retries failures that are likely transient
+func (c concreteCompiler) fetchAndUncompressWithRetries(pkg Package, targetDir string, retries int, retryDelay time.Duration) error {
+	delay := retryDelay
+
+	for attempt := 0; ; attempt++ {
+		err := c.fetchAndUncompress(pkg, targetDir)
+		if err == nil || attempt >= retries || !isTransientFetchError(err) {
+			return err
+		}
+
+		c.timeProvider.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// isTransientFetchError reports whether fetching failed because of the network
+// or a server side (5xx) response. Digest mismatches and any other failure are
+// not worth retrying.
+func isTransientFetchError(err error) bool {
+	for {
+		switch typedErr := err.(type) {
+		case bosherr.ComplexError:
+			err = typedErr.Cause
+		case httpblobprovider.HTTPStatusError:
+			return typedErr.StatusCode >= 500
+		case net.Error:
+			return true
+		default:
+			return false
+		}
+	}
 }
 
 func (c concreteCompiler) fetchAndUncompress(pkg Package, targetDir string) error {
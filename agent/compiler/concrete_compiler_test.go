@@ -3,8 +3,11 @@ package compiler_test
 import (
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"runtime"
+	"sync"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -15,8 +18,12 @@ import (
 	boshmodels "github.com/cloudfoundry/bosh-agent/agent/applier/models"
 	fakepackages "github.com/cloudfoundry/bosh-agent/agent/applier/packages/fakes"
 	fakecmdrunner "github.com/cloudfoundry/bosh-agent/agent/cmdrunner/fakes"
+	"github.com/cloudfoundry/bosh-agent/agent/httpblobprovider"
+	"github.com/cloudfoundry/bosh-agent/agent/httpblobprovider/blobstore_delegator"
 	fakeblobdelegator "github.com/cloudfoundry/bosh-agent/agent/httpblobprovider/blobstore_delegator/blobstore_delegatorfakes"
+	"github.com/cloudfoundry/bosh-agent/agent/httpblobprovider/httpblobproviderfakes"
 	boshcrypto "github.com/cloudfoundry/bosh-utils/crypto"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	fakecmd "github.com/cloudfoundry/bosh-utils/fileutil/fakes"
 	boshsys "github.com/cloudfoundry/bosh-utils/system"
 	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
@@ -28,6 +35,12 @@ type FakeCompileDirProvider struct {
 
 func (cdp FakeCompileDirProvider) CompileDir() string { return cdp.Dir }
 
+type fakeNetError struct{}
+
+func (fakeNetError) Error() string   { return "fake-net-error" }
+func (fakeNetError) Timeout() bool   { return true }
+func (fakeNetError) Temporary() bool { return true }
+
 func getCompileArgs() (Package, []boshmodels.Package) {
 	pkg := Package{
 		BlobstoreID:         "blobstore_id",
@@ -128,13 +141,28 @@ func init() {
 					),
 				), nil)
 
-				blobID, digest, err := compiler.Compile(pkg, pkgDeps)
+				blobID, digest, _, err := compiler.Compile(pkg, pkgDeps)
 				Expect(err).ToNot(HaveOccurred())
 
 				Expect(blobID).To(Equal("fake-blob-id"))
 				Expect(digest.String()).To(Equal("978ad524a02039f261773fe93d94973ae7de6470"))
 			})
 
+			It("returns the size of the uploaded compiled package", func() {
+				_, _, size, err := compiler.Compile(pkg, pkgDeps)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(size).To(Equal(int64(len("fake-contents"))))
+			})
+
+			It("returns an error without uploading when the compiled package size cannot be read", func() {
+				fs.RegisterOpenFile("/tmp/compressed-compiled-package", &fakesys.FakeFile{StatErr: errors.New("fake-stat-error")})
+
+				_, _, _, err := compiler.Compile(pkg, pkgDeps)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Getting compiled package size: fake-stat-error"))
+				Expect(blobstore.WriteCallCount()).To(Equal(0))
+			})
+
 			It("returns blob id and correct sha algo of created compiled package", func() {
 				blobstore.WriteReturns("fake-blob-id", boshcrypto.MustNewMultipleDigest(
 					boshcrypto.NewDigest(
@@ -146,7 +174,7 @@ func init() {
 				// Currently algo of source package is used for compilation pkg algo
 				pkg.Sha1 = boshcrypto.MustNewMultipleDigest(boshcrypto.NewDigest(boshcrypto.DigestAlgorithmSHA256, "fakesha"))
 
-				_, digest, err := compiler.Compile(pkg, pkgDeps)
+				_, digest, _, err := compiler.Compile(pkg, pkgDeps)
 				Expect(err).ToNot(HaveOccurred())
 				// echo -n fake-contents|shasum -a 256
 				Expect(digest.String()).To(Equal("sha256:d12d3a3ee8dcdc9e7ea3416fd618298ea50abde2cf434313c6c3edb213f441cd"))
@@ -159,8 +187,109 @@ func init() {
 				Expect(fingerprint).To(Equal(pkg.Sha1))
 			})
 
+			Context("when fetching the package fails", func() {
+				var (
+					httpBlobProvider *httpblobproviderfakes.FakeHTTPBlobProvider
+					clock            *fakebc.FakeClock
+					opts             CompileOptions
+					transientErr     error
+				)
+
+				BeforeEach(func() {
+					httpBlobProvider = &httpblobproviderfakes.FakeHTTPBlobProvider{}
+					httpBlobProvider.UploadReturns(boshcrypto.MustNewMultipleDigest(boshcrypto.NewDigest(boshcrypto.DigestAlgorithmSHA1, "compiled-sha1")), nil)
+					clock = new(fakebc.FakeClock)
+
+					compiler = NewConcreteCompiler(
+						compressor,
+						blobstore_delegator.NewBlobstoreDelegator(httpBlobProvider, nil, fs),
+						fs,
+						runner,
+						FakeCompileDirProvider{Dir: "/fake-compile-dir"},
+						packageApplier,
+						packagesBc,
+						clock,
+					)
+
+					pkg.UploadSignedURL = "/some/upload/url"
+					opts = CompileOptions{FetchRetries: 2, FetchRetryDelay: 5 * time.Second}
+					transientErr = bosherr.WrapError(&url.Error{Op: "Get", URL: "/some/signed/url", Err: fakeNetError{}}, "Getting blob")
+				})
+
+				It("retries fetching the package after network and server errors", func() {
+					httpBlobProvider.GetReturnsOnCall(0, "", transientErr)
+					httpBlobProvider.GetReturnsOnCall(1, "", httpblobprovider.HTTPStatusError{Method: "GET", StatusCode: 503})
+					httpBlobProvider.GetReturnsOnCall(2, "/tmp/fetched-package", nil)
+
+					_, _, _, err := compiler.Compile(pkg, pkgDeps, opts)
+					Expect(err).ToNot(HaveOccurred())
+
+					Expect(httpBlobProvider.GetCallCount()).To(Equal(3))
+					Expect(compressor.DecompressFileToDirTarballPaths).To(Equal([]string{"/tmp/fetched-package"}))
+				})
+
+				It("doubles the delay before each retry", func() {
+					httpBlobProvider.GetReturnsOnCall(0, "", transientErr)
+					httpBlobProvider.GetReturnsOnCall(1, "", transientErr)
+
+					_, _, _, err := compiler.Compile(pkg, pkgDeps, opts)
+					Expect(err).ToNot(HaveOccurred())
+
+					Expect(clock.SleepCallCount()).To(Equal(2))
+					Expect(clock.SleepArgsForCall(0)).To(Equal(5 * time.Second))
+					Expect(clock.SleepArgsForCall(1)).To(Equal(10 * time.Second))
+				})
+
+				It("prepares the dependencies only once", func() {
+					httpBlobProvider.GetReturnsOnCall(0, "", transientErr)
+
+					_, _, _, err := compiler.Compile(pkg, pkgDeps, opts)
+					Expect(err).ToNot(HaveOccurred())
+
+					Expect(packageApplier.ActionsCalled).To(Equal([]string{"KeepOnly", "Apply", "Apply", "KeepOnly"}))
+				})
+
+				It("gives up after the configured number of retries", func() {
+					httpBlobProvider.GetReturns("", transientErr)
+
+					_, _, _, err := compiler.Compile(pkg, pkgDeps, opts)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("Fetching package blob"))
+
+					Expect(httpBlobProvider.GetCallCount()).To(Equal(3))
+				})
+
+				It("does not retry by default", func() {
+					httpBlobProvider.GetReturns("", transientErr)
+
+					_, _, _, err := compiler.Compile(pkg, pkgDeps)
+					Expect(err).To(HaveOccurred())
+
+					Expect(httpBlobProvider.GetCallCount()).To(Equal(1))
+					Expect(clock.SleepCallCount()).To(Equal(0))
+				})
+
+				It("does not retry client errors", func() {
+					httpBlobProvider.GetReturns("", httpblobprovider.HTTPStatusError{Method: "GET", StatusCode: 403})
+
+					_, _, _, err := compiler.Compile(pkg, pkgDeps, opts)
+					Expect(err).To(HaveOccurred())
+
+					Expect(httpBlobProvider.GetCallCount()).To(Equal(1))
+				})
+
+				It("does not retry packages not matching their digest", func() {
+					httpBlobProvider.GetReturns("", bosherr.Error("Checking downloaded blob: Expected stream to have digest 'sha1' but was 'other'"))
+
+					_, _, _, err := compiler.Compile(pkg, pkgDeps, opts)
+					Expect(err).To(HaveOccurred())
+
+					Expect(httpBlobProvider.GetCallCount()).To(Equal(1))
+				})
+			})
+
 			It("cleans up all packages before and after applying dependent packages", func() {
-				_, _, err := compiler.Compile(pkg, pkgDeps)
+				_, _, _, err := compiler.Compile(pkg, pkgDeps)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(packageApplier.ActionsCalled).To(Equal([]string{"KeepOnly", "Apply", "Apply", "KeepOnly"}))
 				Expect(packageApplier.KeptOnlyPackages).To(BeEmpty())
@@ -169,7 +298,7 @@ func init() {
 			It("returns an error if cleaning up packages fails", func() {
 				packageApplier.KeepOnlyErr = errors.New("fake-keep-only-error")
 
-				_, _, err := compiler.Compile(pkg, pkgDeps)
+				_, _, _, err := compiler.Compile(pkg, pkgDeps)
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("fake-keep-only-error"))
 			})
@@ -182,7 +311,7 @@ func init() {
 					return nil
 				}
 
-				_, _, err := compiler.Compile(pkg, pkgDeps)
+				_, _, _, err := compiler.Compile(pkg, pkgDeps)
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("fake-remove-error"))
 			})
@@ -195,7 +324,7 @@ func init() {
 					return nil
 				}
 
-				_, _, err := compiler.Compile(pkg, pkgDeps)
+				_, _, _, err := compiler.Compile(pkg, pkgDeps)
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("fake-mkdir-error"))
 			})
@@ -208,7 +337,7 @@ func init() {
 					return nil
 				}
 
-				_, _, err := compiler.Compile(pkg, pkgDeps)
+				_, _, _, err := compiler.Compile(pkg, pkgDeps)
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("fake-remove-error"))
 			})
@@ -216,7 +345,7 @@ func init() {
 			It("returns an error if creating temporary compile target directory during uncompression fails", func() {
 				fs.RegisterMkdirAllError("/fake-compile-dir/pkg_name-bosh-agent-unpack", errors.New("fake-mkdir-error"))
 
-				_, _, err := compiler.Compile(pkg, pkgDeps)
+				_, _, _, err := compiler.Compile(pkg, pkgDeps)
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("fake-mkdir-error"))
 			})
@@ -225,25 +354,152 @@ func init() {
 				pkg.BlobstoreID = ""
 				pkg.PackageGetSignedURL = ""
 
-				_, _, err := compiler.Compile(pkg, pkgDeps)
+				_, _, _, err := compiler.Compile(pkg, pkgDeps)
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("No blobstore reference for package '%s'", pkg.Name))
 			})
 
+			It("returns an unsupported digest algorithm error when the package digest uses an unknown algorithm", func() {
+				pkg.Sha1 = boshcrypto.MustParseMultipleDigest("sha3:fakedigest")
+
+				_, _, _, err := compiler.Compile(pkg, pkgDeps)
+				Expect(err).To(Equal(UnsupportedDigestAlgorithmError{PackageName: "pkg_name", Algorithm: "sha3"}))
+				Expect(err.Error()).To(Equal("Unsupported digest algorithm 'sha3' for package 'pkg_name'. Supported algorithms: sha1, sha256, sha512"))
+				Expect(blobstore.GetCallCount()).To(Equal(0))
+			})
+
+			It("returns an unsupported digest algorithm error when a dependency digest uses an unknown algorithm", func() {
+				pkgDeps[1].Source.Sha1 = boshcrypto.MustParseMultipleDigest("sha3:fakedigest")
+
+				_, _, _, err := compiler.Compile(pkg, pkgDeps)
+				Expect(err).To(Equal(UnsupportedDigestAlgorithmError{PackageName: "sec_dep_name", Algorithm: "sha3"}))
+				Expect(packageApplier.AppliedPackages).To(BeEmpty())
+			})
+
+			It("accepts a digest that includes a supported algorithm alongside an unknown one", func() {
+				pkg.Sha1 = boshcrypto.MustParseMultipleDigest("sha3:fakedigest;sha1:fakedigest")
+
+				_, _, _, err := compiler.Compile(pkg, pkgDeps)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("returns a missing digest error when the package has no digest", func() {
+				pkg.Sha1 = boshcrypto.MultipleDigest{}
+
+				_, _, _, err := compiler.Compile(pkg, pkgDeps)
+				Expect(err).To(HaveOccurred())
+				Expect(err).ToNot(BeAssignableToTypeOf(UnsupportedDigestAlgorithmError{}))
+				Expect(err.Error()).To(Equal("No digest algorithm found for package 'pkg_name'. Supported algorithms: sha1, sha256, sha512"))
+			})
+
 			It("installs dependent packages", func() {
-				_, _, err := compiler.Compile(pkg, pkgDeps)
+				_, _, _, err := compiler.Compile(pkg, pkgDeps)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(packageApplier.AppliedPackages).To(Equal(pkgDeps))
 			})
 
+			Context("when dependencies may be downloaded in parallel", func() {
+				var opts CompileOptions
+
+				BeforeEach(func() {
+					opts = CompileOptions{MaxParallelDependencyDownloads: 2}
+
+					pkgDeps = nil
+					for i := 0; i < 5; i++ {
+						pkgDeps = append(pkgDeps, boshmodels.Package{
+							Name:    fmt.Sprintf("dep_%d", i),
+							Version: "dep_version",
+							Source: boshmodels.Source{
+								SignedURL: fmt.Sprintf("dep_%d/signed/url", i),
+								Sha1:      boshcrypto.MustNewMultipleDigest(boshcrypto.NewDigest(boshcrypto.DigestAlgorithmSHA1, fmt.Sprintf("dep_%d_sha1", i))),
+							},
+						})
+					}
+				})
+
+				It("downloads up to the given number of dependencies at once before applying them", func() {
+					var (
+						inFlightLock sync.Mutex
+						inFlight     int
+						maxInFlight  int
+					)
+
+					started := make(chan string, len(pkgDeps))
+					release := make(chan struct{})
+
+					packageApplier.PrepareStub = func(dep boshmodels.Package) error {
+						inFlightLock.Lock()
+						inFlight++
+						if inFlight > maxInFlight {
+							maxInFlight = inFlight
+						}
+						inFlightLock.Unlock()
+
+						started <- dep.Name
+						<-release
+
+						inFlightLock.Lock()
+						inFlight--
+						inFlightLock.Unlock()
+
+						return nil
+					}
+
+					errCh := make(chan error)
+					go func() {
+						_, _, _, err := compiler.Compile(pkg, pkgDeps, opts)
+						errCh <- err
+					}()
+
+					Eventually(started).Should(HaveLen(2))
+					Consistently(started).Should(HaveLen(2))
+
+					close(release)
+					Eventually(errCh).Should(Receive(BeNil()))
+
+					Expect(maxInFlight).To(Equal(2))
+					Expect(packageApplier.PreparedPackages).To(ConsistOf(pkgDeps))
+					Expect(packageApplier.AppliedPackages).To(Equal(pkgDeps))
+				})
+
+				It("stops starting downloads once one fails", func() {
+					release := make(chan struct{})
+
+					packageApplier.PrepareStub = func(dep boshmodels.Package) error {
+						if dep.Name == "dep_0" {
+							return errors.New("fake-prepare-error")
+						}
+
+						<-release
+						return nil
+					}
+
+					errCh := make(chan error)
+					go func() {
+						_, _, _, err := compiler.Compile(pkg, pkgDeps, opts)
+						errCh <- err
+					}()
+
+					close(release)
+
+					var err error
+					Eventually(errCh).Should(Receive(&err))
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("Downloading dependent package: 'dep_0': fake-prepare-error"))
+
+					Expect(len(packageApplier.PreparedPackages)).To(BeNumerically("<=", 2))
+					Expect(packageApplier.AppliedPackages).To(BeEmpty())
+				})
+			})
+
 			It("cleans up the compile directory", func() {
-				_, _, err := compiler.Compile(pkg, pkgDeps)
+				_, _, _, err := compiler.Compile(pkg, pkgDeps)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(fs.FileExists("/fake-compile-dir/pkg_name")).To(BeFalse())
 			})
 
 			It("installs, enables and later cleans up bundle", func() {
-				_, _, err := compiler.Compile(pkg, pkgDeps)
+				_, _, _, err := compiler.Compile(pkg, pkgDeps)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(bundle.ActionsCalled).To(Equal([]string{
 					"InstallWithoutContents",
@@ -265,7 +521,7 @@ func init() {
 					return nil
 				}
 
-				_, _, err := compiler.Compile(pkg, pkgDeps)
+				_, _, _, err := compiler.Compile(pkg, pkgDeps)
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("fake-remove-error"))
 			})
@@ -280,7 +536,7 @@ func init() {
 				})
 
 				It("runs packaging script ", func() {
-					_, _, err := compiler.Compile(pkg, pkgDeps)
+					_, _, _, err := compiler.Compile(pkg, pkgDeps)
 					Expect(err).ToNot(HaveOccurred())
 
 					expectedCmd := boshsys.Command{
@@ -311,20 +567,20 @@ func init() {
 				It("propagates the error from packaging script", func() {
 					runner.RunCommandErr = errors.New("fake-packaging-error")
 
-					_, _, err := compiler.Compile(pkg, pkgDeps)
+					_, _, _, err := compiler.Compile(pkg, pkgDeps)
 					Expect(err).To(HaveOccurred())
 					Expect(err.Error()).To(ContainSubstring("fake-packaging-error"))
 				})
 			})
 
 			It("does not run packaging script when script does not exist", func() {
-				_, _, err := compiler.Compile(pkg, pkgDeps)
+				_, _, _, err := compiler.Compile(pkg, pkgDeps)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(runner.RunCommands).To(BeEmpty())
 			})
 
 			It("compresses compiled package", func() {
-				_, _, err := compiler.Compile(pkg, pkgDeps)
+				_, _, _, err := compiler.Compile(pkg, pkgDeps)
 				Expect(err).ToNot(HaveOccurred())
 
 				// archive was downloaded from the blobstore and decompress to this temp dir
@@ -343,7 +599,7 @@ func init() {
 			It("uploads compressed package to blobstore", func() {
 				compressor.CompressFilesInDirTarballPath = "/tmp/compressed-compiled-package"
 
-				_, _, err := compiler.Compile(pkg, pkgDeps)
+				_, _, _, err := compiler.Compile(pkg, pkgDeps)
 				Expect(err).ToNot(HaveOccurred())
 
 				_, filePathArg, headers := blobstore.WriteArgsForCall(0)
@@ -351,10 +607,65 @@ func init() {
 				Expect(headers).To(Equal(map[string]string{"key": "value"}))
 			})
 
+			It("returns the digest of the uploaded package for the requested algorithm", func() {
+				blobstore.WriteWithDigestAlgorithmsReturns("fake-blob-id", boshcrypto.MustNewMultipleDigest(
+					boshcrypto.NewDigest(
+						boshcrypto.DigestAlgorithmSHA256,
+						"d12d3a3ee8dcdc9e7ea3416fd618298ea50abde2cf434313c6c3edb213f441cd",
+					),
+				), nil)
+
+				blobID, digest, _, err := compiler.Compile(pkg, pkgDeps, CompileOptions{UploadDigestAlgorithm: boshcrypto.DigestAlgorithmSHA256})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(blobID).To(Equal("fake-blob-id"))
+				Expect(digest.String()).To(Equal("sha256:d12d3a3ee8dcdc9e7ea3416fd618298ea50abde2cf434313c6c3edb213f441cd"))
+
+				Expect(blobstore.WriteCallCount()).To(Equal(0))
+				Expect(blobstore.WriteWithDigestAlgorithmsCallCount()).To(Equal(1))
+				signedURL, filePathArg, headers, algorithms := blobstore.WriteWithDigestAlgorithmsArgsForCall(0)
+				Expect(signedURL).To(Equal(pkg.UploadSignedURL))
+				Expect(filePathArg).To(Equal("/tmp/compressed-compiled-package"))
+				Expect(headers).To(Equal(map[string]string{"key": "value"}))
+				Expect(algorithms).To(Equal([]boshcrypto.Algorithm{boshcrypto.DigestAlgorithmSHA256}))
+			})
+
+			It("returns an error when the upload does not report a digest for the requested algorithm", func() {
+				blobstore.WriteWithDigestAlgorithmsReturns("fake-blob-id", boshcrypto.MustNewMultipleDigest(
+					boshcrypto.NewDigest(boshcrypto.DigestAlgorithmSHA1, "978ad524a02039f261773fe93d94973ae7de6470"),
+				), nil)
+
+				_, _, _, err := compiler.Compile(pkg, pkgDeps, CompileOptions{UploadDigestAlgorithm: boshcrypto.DigestAlgorithmSHA256})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Getting sha256 digest of uploaded package"))
+			})
+
+			It("lets the blobstore check the existing blob when given a URL for it", func() {
+				compressor.CompressFilesInDirTarballPath = "/tmp/compressed-compiled-package"
+				uploadedDigest := boshcrypto.MustNewMultipleDigest(
+					boshcrypto.NewDigest(boshcrypto.DigestAlgorithmSHA1, "978ad524a02039f261773fe93d94973ae7de6470"),
+				)
+				blobstore.WriteWithExistingBlobCheckReturns(uploadedDigest, nil)
+				pkg.UploadSignedURL = "fake-upload-signed-url"
+				pkg.UploadExistingBlobSignedURL = "fake-head-signed-url"
+
+				blobID, digest, _, err := compiler.Compile(pkg, pkgDeps)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(blobID).To(BeEmpty())
+				Expect(digest).To(Equal(uploadedDigest))
+
+				Expect(blobstore.WriteCallCount()).To(Equal(0))
+				signedURL, existingBlobSignedURL, filePathArg, headers, algorithms := blobstore.WriteWithExistingBlobCheckArgsForCall(0)
+				Expect(signedURL).To(Equal("fake-upload-signed-url"))
+				Expect(existingBlobSignedURL).To(Equal("fake-head-signed-url"))
+				Expect(filePathArg).To(Equal("/tmp/compressed-compiled-package"))
+				Expect(headers).To(Equal(map[string]string{"key": "value"}))
+				Expect(algorithms).To(Equal(httpblobprovider.DefaultCryptoAlgorithms))
+			})
+
 			It("returs error if uploading compressed package fails", func() {
 				blobstore.WriteReturns("", boshcrypto.MultipleDigest{}, errors.New("fake-create-err"))
 
-				_, _, err := compiler.Compile(pkg, pkgDeps)
+				_, _, _, err := compiler.Compile(pkg, pkgDeps)
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("fake-create-err"))
 			})
@@ -367,7 +678,7 @@ func init() {
 					return "my-blob-id", boshcrypto.MultipleDigest{}, nil
 				}
 
-				_, _, err := compiler.Compile(pkg, pkgDeps)
+				_, _, _, err := compiler.Compile(pkg, pkgDeps)
 				Expect(err).ToNot(HaveOccurred())
 
 				// Compressed package is not cleaned up before blobstore upload
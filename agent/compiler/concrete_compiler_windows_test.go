@@ -92,7 +92,7 @@ func init() {
 					return nil
 				}
 
-				_, _, err := compiler.Compile(pkg, pkgDeps)
+				_, _, _, err := compiler.Compile(pkg, pkgDeps)
 				Expect(err).ToNot(HaveOccurred())
 
 				Expect(fs.RenameOldPaths[0]).To(Equal("/fake-compile-dir/pkg_name-bosh-agent-unpack"))
@@ -116,7 +116,7 @@ func init() {
 				fakeClock.NowReturns(startTime)
 				fakeClock.SinceReturns(CompileTimeout + time.Second)
 
-				_, _, err := compiler.Compile(pkg, pkgDeps)
+				_, _, _, err := compiler.Compile(pkg, pkgDeps)
 				Expect(err).To(MatchError(ContainSubstring("can't perform filesystem rename")))
 
 				Expect(fakeClock.SinceCallCount()).To(Equal(1))
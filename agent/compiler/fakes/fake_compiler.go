@@ -9,9 +9,13 @@ import (
 type FakeCompiler struct {
 	CompilePkg    boshcomp.Package
 	CompileDeps   []boshmodels.Package
+	CompileOpts   []boshcomp.CompileOptions
 	CompileBlobID string
 	CompileDigest boshcrypto.Digest
+	CompileSize   int64
 	CompileErr    error
+
+	CompileCallCount int
 }
 
 func NewFakeCompiler() (c *FakeCompiler) {
@@ -19,11 +23,15 @@ func NewFakeCompiler() (c *FakeCompiler) {
 	return
 }
 
-func (c *FakeCompiler) Compile(pkg boshcomp.Package, deps []boshmodels.Package) (blobID string, digest boshcrypto.Digest, err error) {
+func (c *FakeCompiler) Compile(pkg boshcomp.Package, deps []boshmodels.Package, opts ...boshcomp.CompileOptions) (blobID string, digest boshcrypto.Digest, size int64, err error) {
 	c.CompilePkg = pkg
 	c.CompileDeps = deps
+	c.CompileOpts = opts
+	c.CompileCallCount++
+
 	blobID = c.CompileBlobID
 	digest = c.CompileDigest
+	size = c.CompileSize
 	err = c.CompileErr
 	return
 }
@@ -4,19 +4,73 @@ import (
 	"fmt"
 
 	httpblobprovider "github.com/cloudfoundry/bosh-agent/agent/httpblobprovider"
+	"github.com/cloudfoundry/bosh-agent/settings"
 	"github.com/cloudfoundry/bosh-utils/blobstore"
 	boshcrypto "github.com/cloudfoundry/bosh-utils/crypto"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+var supportedDigestAlgorithms = []boshcrypto.Algorithm{
+	boshcrypto.DigestAlgorithmSHA1,
+	boshcrypto.DigestAlgorithmSHA256,
+	boshcrypto.DigestAlgorithmSHA512,
+}
+
+// DuplicateUploadMode decides what happens when a blob is written to a signed
+// URL that already holds a blob. Blobs created in the blobstore always get a
+// new blob ID so they are never duplicates. Modes other than overwrite inspect
+// the existing blob through a separate signed URL, see WriteWithExistingBlobCheck.
+type DuplicateUploadMode string
+
+const (
+	DuplicateUploadOverwrite       DuplicateUploadMode = "overwrite"
+	DuplicateUploadSkipIfIdentical DuplicateUploadMode = "skip-if-identical"
+	DuplicateUploadError           DuplicateUploadMode = "error"
 )
 
 type BlobstoreDelegatorImpl struct {
-	h httpblobprovider.HTTPBlobProvider
-	b blobstore.DigestBlobstore
+	h  httpblobprovider.HTTPBlobProvider
+	b  blobstore.DigestBlobstore
+	fs boshsys.FileSystem
+
+	duplicateUploads DuplicateUploadMode
 }
 
-func NewBlobstoreDelegator(hp httpblobprovider.HTTPBlobProvider, bp blobstore.DigestBlobstore) *BlobstoreDelegatorImpl {
+func NewBlobstoreDelegator(hp httpblobprovider.HTTPBlobProvider, bp blobstore.DigestBlobstore, fs boshsys.FileSystem) *BlobstoreDelegatorImpl {
+	return NewBlobstoreDelegatorWithDuplicateUploadMode(hp, bp, fs, DuplicateUploadOverwrite)
+}
+
+func NewBlobstoreDelegatorWithDuplicateUploadMode(hp httpblobprovider.HTTPBlobProvider, bp blobstore.DigestBlobstore, fs boshsys.FileSystem, mode DuplicateUploadMode) *BlobstoreDelegatorImpl {
 	return &BlobstoreDelegatorImpl{
-		h: hp,
-		b: bp,
+		h:  hp,
+		b:  bp,
+		fs: fs,
+
+		duplicateUploads: mode,
+	}
+}
+
+// DuplicateUploadModeFromSettings reads the mode from the duplicate_uploads
+// blobstore option, defaulting to overwriting existing blobs
+func DuplicateUploadModeFromSettings(blobstoreSettings settings.Blobstore) (DuplicateUploadMode, error) {
+	value, found := blobstoreSettings.Options["duplicate_uploads"]
+	if !found {
+		return DuplicateUploadOverwrite, nil
+	}
+
+	mode, ok := value.(string)
+	if !ok {
+		return "", bosherr.Errorf("Expected blobstore option 'duplicate_uploads' to be a string but was '%v'", value)
+	}
+
+	switch DuplicateUploadMode(mode) {
+	case DuplicateUploadOverwrite, DuplicateUploadSkipIfIdentical, DuplicateUploadError:
+		return DuplicateUploadMode(mode), nil
+	case "":
+		return DuplicateUploadOverwrite, nil
+	default:
+		return "", bosherr.Errorf("Unknown duplicate uploads mode '%s'", mode)
 	}
 }
 
@@ -35,10 +89,201 @@ func (b *BlobstoreDelegatorImpl) Write(signedURL, path string, headers map[strin
 		return b.b.Create(path)
 	}
 
-	digest, err := b.h.Upload(signedURL, path, headers)
+	// The provider computes the digests of uploads for its default algorithms
+	digest, err := b.uploadToSignedURL("", path, headers, httpblobprovider.DefaultCryptoAlgorithms, func() (boshcrypto.MultipleDigest, error) {
+		return b.h.Upload(signedURL, path, headers)
+	})
 	return "", digest, err
 }
 
+// WriteWithExistingBlobCheck uploads to a signed URL like WriteWithDigestAlgorithms.
+// Upload URLs are only signed for PUT requests, so the blob already stored there
+// is inspected through existingBlobSignedURL: it must be signed for HEAD requests
+// in the error mode and for GET requests in the skip-if-identical mode.
+func (b *BlobstoreDelegatorImpl) WriteWithExistingBlobCheck(signedURL, existingBlobSignedURL, path string, headers map[string]string, algorithms []boshcrypto.Algorithm) (boshcrypto.MultipleDigest, error) {
+	if signedURL == "" {
+		return boshcrypto.MultipleDigest{}, fmt.Errorf("Checking for existing blobs is only supported for signed URLs")
+	}
+
+	err := validateDigestAlgorithms(algorithms)
+	if err != nil {
+		return boshcrypto.MultipleDigest{}, err
+	}
+
+	return b.uploadToSignedURL(existingBlobSignedURL, path, headers, algorithms, func() (boshcrypto.MultipleDigest, error) {
+		return b.h.UploadWithDigestAlgorithms(signedURL, path, headers, algorithms)
+	})
+}
+
+// Copy fetches a blob, verifying it against digest, and writes it to the
+// destination the same way WriteWithDigestAlgorithms does. The digest computed
+// while writing is checked against digest for the algorithms it contains, so
+// that the destination never has to be read back.
+func (b *BlobstoreDelegatorImpl) Copy(digest boshcrypto.MultipleDigest, srcBlobID, srcSignedURL, dstSignedURL string) (string, boshcrypto.MultipleDigest, error) {
+	algorithms := digestAlgorithmsOf(digest)
+	if len(algorithms) == 0 {
+		return "", boshcrypto.MultipleDigest{}, bosherr.Error("No supported digest provided for source blob")
+	}
+
+	fileName, err := b.Get(digest, srcSignedURL, srcBlobID, nil)
+	if fileName != "" {
+		defer func() {
+			_ = b.fs.RemoveAll(fileName)
+		}()
+	}
+	if err != nil {
+		return "", boshcrypto.MultipleDigest{}, bosherr.WrapError(err, "Fetching source blob")
+	}
+
+	blobID, writtenDigest, err := b.WriteWithDigestAlgorithms(dstSignedURL, fileName, nil, algorithms)
+	if err != nil {
+		return "", boshcrypto.MultipleDigest{}, bosherr.WrapError(err, "Writing destination blob")
+	}
+
+	for _, algorithm := range algorithms {
+		srcDigest, _ := digest.DigestFor(algorithm)
+
+		dstDigest, err := writtenDigest.DigestFor(algorithm)
+		if err != nil || dstDigest.String() != srcDigest.String() {
+			return "", boshcrypto.MultipleDigest{}, bosherr.Error("Destination blob does not match the source digest")
+		}
+	}
+
+	return blobID, writtenDigest, nil
+}
+
+func (b *BlobstoreDelegatorImpl) GetWithDigestAlgorithms(digest boshcrypto.MultipleDigest, signedURL, blobID string, headers map[string]string, algorithms []boshcrypto.Algorithm) (string, error) {
+	err := validateDigestAlgorithms(algorithms)
+	if err != nil {
+		return "", err
+	}
+
+	digests := []boshcrypto.Digest{}
+	for _, algorithm := range algorithms {
+		algorithmDigest, err := digest.DigestFor(algorithm)
+		if err != nil {
+			return "", bosherr.Errorf("No %s digest provided for blob", algorithm.Name())
+		}
+		digests = append(digests, algorithmDigest)
+	}
+
+	return b.Get(boshcrypto.MustNewMultipleDigest(digests...), signedURL, blobID, headers)
+}
+
+func (b *BlobstoreDelegatorImpl) WriteWithDigestAlgorithms(signedURL, path string, headers map[string]string, algorithms []boshcrypto.Algorithm) (string, boshcrypto.MultipleDigest, error) {
+	err := validateDigestAlgorithms(algorithms)
+	if err != nil {
+		return "", boshcrypto.MultipleDigest{}, err
+	}
+
+	if signedURL != "" {
+		digest, err := b.uploadToSignedURL("", path, headers, algorithms, func() (boshcrypto.MultipleDigest, error) {
+			return b.h.UploadWithDigestAlgorithms(signedURL, path, headers, algorithms)
+		})
+		return "", digest, err
+	}
+
+	blobID, createdDigest, err := b.b.Create(path)
+	if err != nil {
+		return "", boshcrypto.MultipleDigest{}, err
+	}
+
+	// The blobstore computes its own configured digests; only calculate the
+	// requested ones it did not already provide
+	digests := []boshcrypto.Digest{}
+	for _, algorithm := range algorithms {
+		algorithmDigest, err := createdDigest.DigestFor(algorithm)
+		if err != nil {
+			computedDigest, err := boshcrypto.NewMultipleDigestFromPath(path, b.fs, []boshcrypto.Algorithm{algorithm})
+			if err != nil {
+				return "", boshcrypto.MultipleDigest{}, bosherr.WrapErrorf(err, "Calculating %s digest", algorithm.Name())
+			}
+
+			algorithmDigest, _ = computedDigest.DigestFor(algorithm)
+		}
+		digests = append(digests, algorithmDigest)
+	}
+
+	return blobID, boshcrypto.MustNewMultipleDigest(digests...), nil
+}
+
+// uploadToSignedURL only calls upload if the duplicate upload mode allows it;
+// signed URLs are left out of errors since they carry credentials
+func (b *BlobstoreDelegatorImpl) uploadToSignedURL(existingBlobSignedURL, path string, headers map[string]string, algorithms []boshcrypto.Algorithm, upload func() (boshcrypto.MultipleDigest, error)) (boshcrypto.MultipleDigest, error) {
+	if b.duplicateUploads != DuplicateUploadOverwrite && existingBlobSignedURL == "" {
+		return boshcrypto.MultipleDigest{}, bosherr.Errorf("Duplicate uploads mode '%s' requires a signed URL for checking the existing blob", b.duplicateUploads)
+	}
+
+	switch b.duplicateUploads {
+	case DuplicateUploadError:
+		exists, err := b.h.Exists(existingBlobSignedURL, headers)
+		if err != nil {
+			return boshcrypto.MultipleDigest{}, bosherr.WrapError(err, "Checking for an existing blob")
+		}
+
+		if exists {
+			return boshcrypto.MultipleDigest{}, bosherr.Error("A blob already exists at the signed URL")
+		}
+
+	case DuplicateUploadSkipIfIdentical:
+		digest, err := boshcrypto.NewMultipleDigestFromPath(path, b.fs, algorithms)
+		if err != nil {
+			return boshcrypto.MultipleDigest{}, bosherr.WrapError(err, "Calculating blob digest")
+		}
+
+		identical, err := b.h.Matches(existingBlobSignedURL, digest, headers)
+		if err != nil {
+			return boshcrypto.MultipleDigest{}, bosherr.WrapError(err, "Comparing with the existing blob")
+		}
+
+		if identical {
+			return digest, nil
+		}
+	}
+
+	return upload()
+}
+
+// digestAlgorithmsOf returns the supported algorithms digest has a digest for
+func digestAlgorithmsOf(digest boshcrypto.MultipleDigest) []boshcrypto.Algorithm {
+	algorithms := []boshcrypto.Algorithm{}
+	for _, algorithm := range supportedDigestAlgorithms {
+		if _, err := digest.DigestFor(algorithm); err == nil {
+			algorithms = append(algorithms, algorithm)
+		}
+	}
+
+	return algorithms
+}
+
+func validateDigestAlgorithms(algorithms []boshcrypto.Algorithm) error {
+	if len(algorithms) == 0 {
+		return bosherr.Error("At least one digest algorithm must be requested")
+	}
+
+	for _, algorithm := range algorithms {
+		supported := false
+		for _, supportedAlgorithm := range supportedDigestAlgorithms {
+			if algorithm.Name() == supportedAlgorithm.Name() {
+				supported = true
+			}
+		}
+
+		if !supported {
+			return bosherr.Errorf("Unsupported digest algorithm '%s'", algorithm.Name())
+		}
+	}
+
+	return nil
+}
+
+func (b *BlobstoreDelegatorImpl) Size(signedURL string, headers map[string]string) (int64, error) {
+	if signedURL == "" {
+		return 0, fmt.Errorf("Size is only supported for signed URLs")
+	}
+	return b.h.Size(signedURL, headers)
+}
+
 func (b *BlobstoreDelegatorImpl) CleanUp(signedURL, fileName string) (err error) {
 	if signedURL != "" {
 		return fmt.Errorf("CleanUp is not supported for signed URLs")
@@ -48,7 +293,7 @@ func (b *BlobstoreDelegatorImpl) CleanUp(signedURL, fileName string) (err error)
 
 func (b *BlobstoreDelegatorImpl) Delete(signedURL, blobID string) (err error) {
 	if signedURL != "" {
-		return fmt.Errorf("Delete is not supported for signed URLs")
+		return b.h.Delete(signedURL, nil)
 	}
 	return b.b.Delete(blobID)
 }
@@ -9,6 +9,25 @@ import (
 type BlobstoreDelegator interface {
 	Get(digest boshcrypto.Digest, signedURL, blobID string, headers map[string]string) (fileName string, err error)
 	Write(signedURL, path string, headers map[string]string) (string, boshcrypto.MultipleDigest, error)
+
+	// GetWithDigestAlgorithms verifies the fetched blob only against the digests
+	// of the given algorithms, which must all be present in digest
+	GetWithDigestAlgorithms(digest boshcrypto.MultipleDigest, signedURL, blobID string, headers map[string]string, algorithms []boshcrypto.Algorithm) (fileName string, err error)
+
+	// WriteWithDigestAlgorithms returns a digest containing exactly the given algorithms
+	WriteWithDigestAlgorithms(signedURL, path string, headers map[string]string, algorithms []boshcrypto.Algorithm) (string, boshcrypto.MultipleDigest, error)
+
+	// WriteWithExistingBlobCheck is needed to write to signed URLs unless the
+	// duplicate uploads mode is overwrite, see DuplicateUploadMode
+	WriteWithExistingBlobCheck(signedURL, existingBlobSignedURL, path string, headers map[string]string, algorithms []boshcrypto.Algorithm) (boshcrypto.MultipleDigest, error)
+
+	// Copy moves a blob between backends, e.g. from the local blobstore to a
+	// signed URL, returning the blob ID when the destination is the blobstore
+	Copy(digest boshcrypto.MultipleDigest, srcBlobID, srcSignedURL, dstSignedURL string) (blobID string, blobDigest boshcrypto.MultipleDigest, err error)
+
+	// Size is only supported for signed URLs
+	Size(signedURL string, headers map[string]string) (int64, error)
+
 	CleanUp(signedURL, path string) error
 	Delete(signedURL, blobID string) error
 }
@@ -2,15 +2,18 @@ package blobstore_delegator_test
 
 import (
 	"errors"
+	"fmt"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
 	"github.com/cloudfoundry/bosh-agent/agent/httpblobprovider/blobstore_delegator"
 	fakeblobprovider "github.com/cloudfoundry/bosh-agent/agent/httpblobprovider/httpblobproviderfakes"
+	"github.com/cloudfoundry/bosh-agent/settings"
 	fakeblobstore "github.com/cloudfoundry/bosh-utils/blobstore/fakes"
 
 	boshcrypto "github.com/cloudfoundry/bosh-utils/crypto"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
 )
 
 var _ = Describe("BlobstoreDelegator", func() {
@@ -18,6 +21,7 @@ var _ = Describe("BlobstoreDelegator", func() {
 		blobstoreDelegator   blobstore_delegator.BlobstoreDelegator
 		fakeHTTPBlobProvider *fakeblobprovider.FakeHTTPBlobProvider
 		fakeBlobManager      *fakeblobstore.FakeDigestBlobstore
+		fs                   *fakesys.FakeFileSystem
 
 		digest = boshcrypto.MustNewMultipleDigest(boshcrypto.NewDigest(boshcrypto.DigestAlgorithmSHA1, "some-digest"))
 	)
@@ -25,8 +29,9 @@ var _ = Describe("BlobstoreDelegator", func() {
 	BeforeEach(func() {
 		fakeHTTPBlobProvider = &fakeblobprovider.FakeHTTPBlobProvider{}
 		fakeBlobManager = &fakeblobstore.FakeDigestBlobstore{}
+		fs = fakesys.NewFakeFileSystem()
 
-		blobstoreDelegator = blobstore_delegator.NewBlobstoreDelegator(fakeHTTPBlobProvider, fakeBlobManager)
+		blobstoreDelegator = blobstore_delegator.NewBlobstoreDelegator(fakeHTTPBlobProvider, fakeBlobManager, fs)
 	})
 
 	Context("Get", func() {
@@ -164,6 +169,409 @@ var _ = Describe("BlobstoreDelegator", func() {
 		})
 	})
 
+	Describe("selecting digest algorithms", func() {
+		var (
+			// sha sums for "abc"
+			sha1   = boshcrypto.NewDigest(boshcrypto.DigestAlgorithmSHA1, "a9993e364706816aba3e25717850c26c9cd0d89d")
+			sha256 = boshcrypto.NewDigest(boshcrypto.DigestAlgorithmSHA256, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad")
+			sha512 = boshcrypto.NewDigest(boshcrypto.DigestAlgorithmSHA512, "ddaf35a193617abacc417349ae20413112e6fa4e89a97ea20a9eeee64b55d39a2192992a274fc1a836ba3c23a3feebbd454d4423643ce80e2a9ac94fa54ca49f")
+
+			allDigests = boshcrypto.MustNewMultipleDigest(sha1, sha256, sha512)
+		)
+
+		for _, d := range []boshcrypto.Digest{sha1, sha256, sha512} {
+			expectedDigest := d
+			algorithm := expectedDigest.Algorithm()
+
+			Context(fmt.Sprintf("when %s is requested", algorithm.Name()), func() {
+				It("passes the algorithm to the HTTP blobstore when writing", func() {
+					fakeHTTPBlobProvider.UploadWithDigestAlgorithmsReturns(boshcrypto.MustNewMultipleDigest(expectedDigest), nil)
+
+					_, digestResult, err := blobstoreDelegator.WriteWithDigestAlgorithms("some-signed-url", "/some/file", nil, []boshcrypto.Algorithm{algorithm})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(digestResult).To(Equal(boshcrypto.MustNewMultipleDigest(expectedDigest)))
+
+					Expect(fakeHTTPBlobProvider.UploadCallCount()).To(Equal(0))
+					_, _, _, algorithmsArg := fakeHTTPBlobProvider.UploadWithDigestAlgorithmsArgsForCall(0)
+					Expect(algorithmsArg).To(Equal([]boshcrypto.Algorithm{algorithm}))
+				})
+
+				It("returns only that digest when writing to the local blobstore", func() {
+					Expect(fs.WriteFileString("/some/file", "abc")).To(Succeed())
+					fakeBlobManager.CreateReturns("123", boshcrypto.MustNewMultipleDigest(sha1), nil)
+
+					blobID, digestResult, err := blobstoreDelegator.WriteWithDigestAlgorithms("", "/some/file", nil, []boshcrypto.Algorithm{algorithm})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(blobID).To(Equal("123"))
+					Expect(digestResult).To(Equal(boshcrypto.MustNewMultipleDigest(expectedDigest)))
+				})
+
+				It("verifies fetched blobs against that digest only", func() {
+					fakeHTTPBlobProvider.GetReturns("/some/file", nil)
+
+					fileName, err := blobstoreDelegator.GetWithDigestAlgorithms(allDigests, "some-signed-url", "", nil, []boshcrypto.Algorithm{algorithm})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(fileName).To(Equal("/some/file"))
+
+					_, digestArg, _ := fakeHTTPBlobProvider.GetArgsForCall(0)
+					Expect(digestArg).To(Equal(boshcrypto.MustNewMultipleDigest(expectedDigest)))
+				})
+			})
+		}
+
+		It("errors when the requested digest was not provided for a fetched blob", func() {
+			_, err := blobstoreDelegator.GetWithDigestAlgorithms(boshcrypto.MustNewMultipleDigest(sha1), "", "1234", nil, []boshcrypto.Algorithm{boshcrypto.DigestAlgorithmSHA256})
+			Expect(err).To(MatchError("No sha256 digest provided for blob"))
+			Expect(fakeBlobManager.GetCallCount()).To(Equal(0))
+		})
+
+		It("errors when the local blobstore digest cannot be calculated", func() {
+			fakeBlobManager.CreateReturns("123", boshcrypto.MustNewMultipleDigest(sha1), nil)
+			fs.OpenFileErr = errors.New("fake-open-error")
+
+			_, _, err := blobstoreDelegator.WriteWithDigestAlgorithms("", "/some/file", nil, []boshcrypto.Algorithm{boshcrypto.DigestAlgorithmSHA512})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Calculating sha512 digest"))
+			Expect(err.Error()).To(ContainSubstring("fake-open-error"))
+		})
+
+		Context("when an unsupported algorithm is requested", func() {
+			unsupported := []boshcrypto.Algorithm{boshcrypto.NewUnknownAlgorithm("md5")}
+
+			It("errors without writing the blob", func() {
+				_, _, err := blobstoreDelegator.WriteWithDigestAlgorithms("some-signed-url", "/some/file", nil, unsupported)
+				Expect(err).To(MatchError("Unsupported digest algorithm 'md5'"))
+				Expect(fakeHTTPBlobProvider.UploadWithDigestAlgorithmsCallCount()).To(Equal(0))
+			})
+
+			It("errors without fetching the blob", func() {
+				_, err := blobstoreDelegator.GetWithDigestAlgorithms(allDigests, "", "1234", nil, unsupported)
+				Expect(err).To(MatchError("Unsupported digest algorithm 'md5'"))
+				Expect(fakeBlobManager.GetCallCount()).To(Equal(0))
+			})
+		})
+
+		It("errors when no algorithm is requested", func() {
+			_, _, err := blobstoreDelegator.WriteWithDigestAlgorithms("", "/some/file", nil, nil)
+			Expect(err).To(MatchError("At least one digest algorithm must be requested"))
+		})
+	})
+
+	Context("when writing to a signed URL that already holds a blob", func() {
+		var (
+			filePath = "/some/path/to/a/file"
+
+			// sha256 of "abc", the contents of our file
+			localDigest = boshcrypto.MustNewMultipleDigest(boshcrypto.NewDigest(boshcrypto.DigestAlgorithmSHA256, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"))
+			algorithms  = []boshcrypto.Algorithm{boshcrypto.DigestAlgorithmSHA256}
+		)
+
+		newDelegator := func(mode blobstore_delegator.DuplicateUploadMode) blobstore_delegator.BlobstoreDelegator {
+			return blobstore_delegator.NewBlobstoreDelegatorWithDuplicateUploadMode(fakeHTTPBlobProvider, fakeBlobManager, fs, mode)
+		}
+
+		BeforeEach(func() {
+			Expect(fs.WriteFileString(filePath, "abc")).To(Succeed())
+			fakeHTTPBlobProvider.UploadWithDigestAlgorithmsReturns(localDigest, nil)
+		})
+
+		Context("in overwrite mode", func() {
+			It("uploads without looking at the existing blob", func() {
+				_, digestResult, err := newDelegator(blobstore_delegator.DuplicateUploadOverwrite).WriteWithDigestAlgorithms("some-signed-url", filePath, nil, algorithms)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(digestResult).To(Equal(localDigest))
+
+				Expect(fakeHTTPBlobProvider.ExistsCallCount()).To(Equal(0))
+				Expect(fakeHTTPBlobProvider.MatchesCallCount()).To(Equal(0))
+				Expect(fakeHTTPBlobProvider.UploadWithDigestAlgorithmsCallCount()).To(Equal(1))
+			})
+
+			It("is the default", func() {
+				_, _, err := blobstoreDelegator.WriteWithDigestAlgorithms("some-signed-url", filePath, nil, algorithms)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeHTTPBlobProvider.ExistsCallCount()).To(Equal(0))
+				Expect(fakeHTTPBlobProvider.MatchesCallCount()).To(Equal(0))
+				Expect(fakeHTTPBlobProvider.UploadWithDigestAlgorithmsCallCount()).To(Equal(1))
+			})
+		})
+
+		Context("in skip-if-identical mode", func() {
+			var delegator blobstore_delegator.BlobstoreDelegator
+
+			BeforeEach(func() {
+				delegator = newDelegator(blobstore_delegator.DuplicateUploadSkipIfIdentical)
+			})
+
+			It("skips the upload when the existing blob is identical", func() {
+				fakeHTTPBlobProvider.MatchesReturns(true, nil)
+
+				digestResult, err := delegator.WriteWithExistingBlobCheck("some-signed-url", "some-get-signed-url", filePath, map[string]string{"key": "value"}, algorithms)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(digestResult).To(Equal(localDigest))
+
+				signedURLArg, digestArg, headersArg := fakeHTTPBlobProvider.MatchesArgsForCall(0)
+				Expect(signedURLArg).To(Equal("some-get-signed-url"))
+				Expect(digestArg).To(Equal(localDigest))
+				Expect(headersArg).To(Equal(map[string]string{"key": "value"}))
+
+				Expect(fakeHTTPBlobProvider.UploadWithDigestAlgorithmsCallCount()).To(Equal(0))
+			})
+
+			It("uploads to the signed URL when the existing blob differs", func() {
+				fakeHTTPBlobProvider.MatchesReturns(false, nil)
+
+				digestResult, err := delegator.WriteWithExistingBlobCheck("some-signed-url", "some-get-signed-url", filePath, nil, algorithms)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(digestResult).To(Equal(localDigest))
+
+				Expect(fakeHTTPBlobProvider.UploadWithDigestAlgorithmsCallCount()).To(Equal(1))
+				signedURLArg, pathArg, _, algorithmsArg := fakeHTTPBlobProvider.UploadWithDigestAlgorithmsArgsForCall(0)
+				Expect(signedURLArg).To(Equal("some-signed-url"))
+				Expect(pathArg).To(Equal(filePath))
+				Expect(algorithmsArg).To(Equal(algorithms))
+			})
+
+			It("returns an error when the existing blob cannot be compared", func() {
+				fakeHTTPBlobProvider.MatchesReturns(false, errors.New("fake-matches-error"))
+
+				_, err := delegator.WriteWithExistingBlobCheck("some-signed-url", "some-get-signed-url", filePath, nil, algorithms)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Comparing with the existing blob: fake-matches-error"))
+
+				Expect(fakeHTTPBlobProvider.UploadWithDigestAlgorithmsCallCount()).To(Equal(0))
+			})
+
+			It("returns an error without uploading when there is no URL for the existing blob", func() {
+				_, _, err := delegator.Write("some-signed-url", filePath, nil)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Duplicate uploads mode 'skip-if-identical' requires a signed URL for checking the existing blob"))
+
+				Expect(fakeHTTPBlobProvider.MatchesCallCount()).To(Equal(0))
+				Expect(fakeHTTPBlobProvider.UploadCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("in error mode", func() {
+			var delegator blobstore_delegator.BlobstoreDelegator
+
+			BeforeEach(func() {
+				delegator = newDelegator(blobstore_delegator.DuplicateUploadError)
+			})
+
+			It("returns an error when a blob exists, even an identical one", func() {
+				fakeHTTPBlobProvider.ExistsReturns(true, nil)
+				fakeHTTPBlobProvider.MatchesReturns(true, nil)
+
+				_, err := delegator.WriteWithExistingBlobCheck("some-signed-url", "some-head-signed-url", filePath, nil, algorithms)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("A blob already exists at the signed URL"))
+
+				signedURLArg, _ := fakeHTTPBlobProvider.ExistsArgsForCall(0)
+				Expect(signedURLArg).To(Equal("some-head-signed-url"))
+
+				Expect(fakeHTTPBlobProvider.UploadWithDigestAlgorithmsCallCount()).To(Equal(0))
+			})
+
+			It("uploads to the signed URL when there is no blob yet", func() {
+				fakeHTTPBlobProvider.ExistsReturns(false, nil)
+
+				_, err := delegator.WriteWithExistingBlobCheck("some-signed-url", "some-head-signed-url", filePath, nil, algorithms)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeHTTPBlobProvider.UploadWithDigestAlgorithmsCallCount()).To(Equal(1))
+				signedURLArg, _, _, _ := fakeHTTPBlobProvider.UploadWithDigestAlgorithmsArgsForCall(0)
+				Expect(signedURLArg).To(Equal("some-signed-url"))
+			})
+
+			It("returns an error when the existing blob cannot be checked", func() {
+				fakeHTTPBlobProvider.ExistsReturns(false, errors.New("fake-exists-error"))
+
+				_, err := delegator.WriteWithExistingBlobCheck("some-signed-url", "some-head-signed-url", filePath, nil, algorithms)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Checking for an existing blob: fake-exists-error"))
+
+				Expect(fakeHTTPBlobProvider.UploadWithDigestAlgorithmsCallCount()).To(Equal(0))
+			})
+
+			It("returns an error without uploading when there is no URL for the existing blob", func() {
+				_, _, err := delegator.WriteWithDigestAlgorithms("some-signed-url", filePath, nil, algorithms)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Duplicate uploads mode 'error' requires a signed URL for checking the existing blob"))
+
+				Expect(fakeHTTPBlobProvider.ExistsCallCount()).To(Equal(0))
+				Expect(fakeHTTPBlobProvider.UploadWithDigestAlgorithmsCallCount()).To(Equal(0))
+			})
+		})
+
+		It("does not need a URL for the existing blob in overwrite mode", func() {
+			_, err := newDelegator(blobstore_delegator.DuplicateUploadOverwrite).WriteWithExistingBlobCheck("some-signed-url", "", filePath, nil, algorithms)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeHTTPBlobProvider.UploadWithDigestAlgorithmsCallCount()).To(Equal(1))
+		})
+
+		It("only checks existing blobs for signed URLs", func() {
+			_, err := newDelegator(blobstore_delegator.DuplicateUploadError).WriteWithExistingBlobCheck("", "some-head-signed-url", filePath, nil, algorithms)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Checking for existing blobs is only supported for signed URLs"))
+
+			Expect(fakeBlobManager.CreateCallCount()).To(Equal(0))
+		})
+
+		It("does not check blobs created in the blobstore", func() {
+			fakeBlobManager.CreateReturns("123", localDigest, nil)
+
+			_, _, err := newDelegator(blobstore_delegator.DuplicateUploadError).Write("", filePath, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeHTTPBlobProvider.ExistsCallCount()).To(Equal(0))
+			Expect(fakeBlobManager.CreateCallCount()).To(Equal(1))
+		})
+	})
+
+	Context("Copy", func() {
+		var (
+			fetchedPath = "/some/fetched/blob"
+			otherDigest = boshcrypto.MustNewMultipleDigest(boshcrypto.NewDigest(boshcrypto.DigestAlgorithmSHA1, "some-other-digest"))
+		)
+
+		BeforeEach(func() {
+			Expect(fs.WriteFileString(fetchedPath, "abc")).To(Succeed())
+		})
+
+		Context("when copying from the blobstore to a signed URL", func() {
+			BeforeEach(func() {
+				fakeBlobManager.GetReturns(fetchedPath, nil)
+				fakeHTTPBlobProvider.UploadWithDigestAlgorithmsReturns(digest, nil)
+			})
+
+			It("uploads the verified blob computing the digests of the source", func() {
+				blobID, digestResult, err := blobstoreDelegator.Copy(digest, "some-blob-id", "", "some-dst-signed-url")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(blobID).To(BeEmpty())
+				Expect(digestResult).To(Equal(digest))
+
+				blobIDArg, digestArg := fakeBlobManager.GetArgsForCall(0)
+				Expect(blobIDArg).To(Equal("some-blob-id"))
+				Expect(digestArg).To(Equal(digest))
+
+				signedURLArg, pathArg, _, algorithmsArg := fakeHTTPBlobProvider.UploadWithDigestAlgorithmsArgsForCall(0)
+				Expect(signedURLArg).To(Equal("some-dst-signed-url"))
+				Expect(pathArg).To(Equal(fetchedPath))
+				Expect(algorithmsArg).To(Equal([]boshcrypto.Algorithm{boshcrypto.DigestAlgorithmSHA1}))
+			})
+
+			It("does not read the destination back", func() {
+				_, _, err := blobstoreDelegator.Copy(digest, "some-blob-id", "", "some-dst-signed-url")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeHTTPBlobProvider.GetCallCount()).To(Equal(0))
+				Expect(fakeHTTPBlobProvider.MatchesCallCount()).To(Equal(0))
+				Expect(fakeHTTPBlobProvider.ExistsCallCount()).To(Equal(0))
+			})
+
+			It("removes the fetched blob", func() {
+				_, _, err := blobstoreDelegator.Copy(digest, "some-blob-id", "", "some-dst-signed-url")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fs.FileExists(fetchedPath)).To(BeFalse())
+			})
+
+			It("returns an error when the written blob does not match the source digest", func() {
+				fakeHTTPBlobProvider.UploadWithDigestAlgorithmsReturns(otherDigest, nil)
+
+				_, _, err := blobstoreDelegator.Copy(digest, "some-blob-id", "", "some-dst-signed-url")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Destination blob does not match the source digest"))
+			})
+
+			It("returns an error when the upload fails", func() {
+				fakeHTTPBlobProvider.UploadWithDigestAlgorithmsReturns(boshcrypto.MultipleDigest{}, errors.New("fake-upload-error"))
+
+				_, _, err := blobstoreDelegator.Copy(digest, "some-blob-id", "", "some-dst-signed-url")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Writing destination blob: fake-upload-error"))
+
+				Expect(fs.FileExists(fetchedPath)).To(BeFalse())
+			})
+		})
+
+		Context("when copying from a signed URL to the blobstore", func() {
+			BeforeEach(func() {
+				fakeHTTPBlobProvider.GetReturns(fetchedPath, nil)
+			})
+
+			It("creates a new blob and returns its ID", func() {
+				fakeBlobManager.CreateReturns("some-new-blob-id", digest, nil)
+
+				blobID, digestResult, err := blobstoreDelegator.Copy(digest, "", "some-src-signed-url", "")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(blobID).To(Equal("some-new-blob-id"))
+				Expect(digestResult).To(Equal(digest))
+
+				signedURLArg, digestArg, _ := fakeHTTPBlobProvider.GetArgsForCall(0)
+				Expect(signedURLArg).To(Equal("some-src-signed-url"))
+				Expect(digestArg).To(Equal(digest))
+
+				Expect(fakeBlobManager.CreateArgsForCall(0)).To(Equal(fetchedPath))
+			})
+
+			It("returns an error when the created blob does not match the source digest", func() {
+				fakeBlobManager.CreateReturns("some-new-blob-id", otherDigest, nil)
+
+				_, _, err := blobstoreDelegator.Copy(digest, "", "some-src-signed-url", "")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Destination blob does not match the source digest"))
+			})
+		})
+
+		It("returns an error without writing when the source blob does not match its digest", func() {
+			fakeBlobManager.GetReturns(fetchedPath, errors.New("fake-digest-error"))
+
+			_, _, err := blobstoreDelegator.Copy(digest, "some-blob-id", "", "some-dst-signed-url")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Fetching source blob: fake-digest-error"))
+
+			Expect(fakeHTTPBlobProvider.UploadWithDigestAlgorithmsCallCount()).To(Equal(0))
+			Expect(fs.FileExists(fetchedPath)).To(BeFalse())
+		})
+
+		It("returns an error without fetching when the source digest has no supported algorithm", func() {
+			unknownDigest := boshcrypto.MustNewMultipleDigest(boshcrypto.NewDigest(boshcrypto.NewUnknownAlgorithm("md5"), "some-md5"))
+
+			_, _, err := blobstoreDelegator.Copy(unknownDigest, "some-blob-id", "", "some-dst-signed-url")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("No supported digest provided for source blob"))
+
+			Expect(fakeBlobManager.GetCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("DuplicateUploadModeFromSettings", func() {
+		It("defaults to overwrite", func() {
+			mode, err := blobstore_delegator.DuplicateUploadModeFromSettings(settings.Blobstore{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mode).To(Equal(blobstore_delegator.DuplicateUploadOverwrite))
+		})
+
+		It("reads the duplicate_uploads option", func() {
+			mode, err := blobstore_delegator.DuplicateUploadModeFromSettings(settings.Blobstore{
+				Options: map[string]interface{}{"duplicate_uploads": "skip-if-identical"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mode).To(Equal(blobstore_delegator.DuplicateUploadSkipIfIdentical))
+		})
+
+		It("rejects unknown modes", func() {
+			_, err := blobstore_delegator.DuplicateUploadModeFromSettings(settings.Blobstore{
+				Options: map[string]interface{}{"duplicate_uploads": "ignore"},
+			})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Unknown duplicate uploads mode 'ignore'"))
+		})
+	})
+
 	Context("CleanUp", func() {
 		Context("when there is a signed URL provided", func() {
 			It("errors", func() {
@@ -184,11 +592,47 @@ var _ = Describe("BlobstoreDelegator", func() {
 		})
 	})
 
-	Context("Delete", func() {
+	Context("Size", func() {
 		Context("when there is a signed URL provided", func() {
+			It("asks the HTTP blobstore", func() {
+				fakeHTTPBlobProvider.SizeReturns(1024, nil)
+
+				size, err := blobstoreDelegator.Size("some-signed-url", map[string]string{"key": "value"})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(size).To(Equal(int64(1024)))
+
+				signedURLArg, headersArg := fakeHTTPBlobProvider.SizeArgsForCall(0)
+				Expect(signedURLArg).To(Equal("some-signed-url"))
+				Expect(headersArg).To(Equal(map[string]string{"key": "value"}))
+			})
+		})
+
+		Context("when there is no signed URL provided", func() {
 			It("errors", func() {
-				err := blobstoreDelegator.Delete("some-signed-url", "nothing")
-				Expect(err).To(MatchError("Delete is not supported for signed URLs"))
+				_, err := blobstoreDelegator.Size("", nil)
+				Expect(err).To(MatchError("Size is only supported for signed URLs"))
+			})
+		})
+	})
+
+	Context("Delete", func() {
+		Context("when there is a signed URL provided", func() {
+			It("deletes from the HTTP blobstore", func() {
+				err := blobstoreDelegator.Delete("some-signed-url", "")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeBlobManager.DeleteCallCount()).To(Equal(0))
+				Expect(fakeHTTPBlobProvider.DeleteCallCount()).To(Equal(1))
+
+				signedURLArg, _ := fakeHTTPBlobProvider.DeleteArgsForCall(0)
+				Expect(signedURLArg).To(Equal("some-signed-url"))
+			})
+
+			It("errors when there is an error", func() {
+				fakeHTTPBlobProvider.DeleteReturns(errors.New("some error"))
+
+				err := blobstoreDelegator.Delete("some-signed-url", "")
+				Expect(err).To(MatchError("some error"))
 			})
 		})
 
@@ -21,6 +21,24 @@ type FakeBlobstoreDelegator struct {
 	cleanUpReturnsOnCall map[int]struct {
 		result1 error
 	}
+	CopyStub        func(crypto.MultipleDigest, string, string, string) (string, crypto.MultipleDigest, error)
+	copyMutex       sync.RWMutex
+	copyArgsForCall []struct {
+		arg1 crypto.MultipleDigest
+		arg2 string
+		arg3 string
+		arg4 string
+	}
+	copyReturns struct {
+		result1 string
+		result2 crypto.MultipleDigest
+		result3 error
+	}
+	copyReturnsOnCall map[int]struct {
+		result1 string
+		result2 crypto.MultipleDigest
+		result3 error
+	}
 	DeleteStub        func(string, string) error
 	deleteMutex       sync.RWMutex
 	deleteArgsForCall []struct {
@@ -49,6 +67,37 @@ type FakeBlobstoreDelegator struct {
 		result1 string
 		result2 error
 	}
+	GetWithDigestAlgorithmsStub        func(crypto.MultipleDigest, string, string, map[string]string, []crypto.Algorithm) (string, error)
+	getWithDigestAlgorithmsMutex       sync.RWMutex
+	getWithDigestAlgorithmsArgsForCall []struct {
+		arg1 crypto.MultipleDigest
+		arg2 string
+		arg3 string
+		arg4 map[string]string
+		arg5 []crypto.Algorithm
+	}
+	getWithDigestAlgorithmsReturns struct {
+		result1 string
+		result2 error
+	}
+	getWithDigestAlgorithmsReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
+	SizeStub        func(string, map[string]string) (int64, error)
+	sizeMutex       sync.RWMutex
+	sizeArgsForCall []struct {
+		arg1 string
+		arg2 map[string]string
+	}
+	sizeReturns struct {
+		result1 int64
+		result2 error
+	}
+	sizeReturnsOnCall map[int]struct {
+		result1 int64
+		result2 error
+	}
 	WriteStub        func(string, string, map[string]string) (string, crypto.MultipleDigest, error)
 	writeMutex       sync.RWMutex
 	writeArgsForCall []struct {
@@ -66,6 +115,41 @@ type FakeBlobstoreDelegator struct {
 		result2 crypto.MultipleDigest
 		result3 error
 	}
+	WriteWithDigestAlgorithmsStub        func(string, string, map[string]string, []crypto.Algorithm) (string, crypto.MultipleDigest, error)
+	writeWithDigestAlgorithmsMutex       sync.RWMutex
+	writeWithDigestAlgorithmsArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 map[string]string
+		arg4 []crypto.Algorithm
+	}
+	writeWithDigestAlgorithmsReturns struct {
+		result1 string
+		result2 crypto.MultipleDigest
+		result3 error
+	}
+	writeWithDigestAlgorithmsReturnsOnCall map[int]struct {
+		result1 string
+		result2 crypto.MultipleDigest
+		result3 error
+	}
+	WriteWithExistingBlobCheckStub        func(string, string, string, map[string]string, []crypto.Algorithm) (crypto.MultipleDigest, error)
+	writeWithExistingBlobCheckMutex       sync.RWMutex
+	writeWithExistingBlobCheckArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 map[string]string
+		arg5 []crypto.Algorithm
+	}
+	writeWithExistingBlobCheckReturns struct {
+		result1 crypto.MultipleDigest
+		result2 error
+	}
+	writeWithExistingBlobCheckReturnsOnCall map[int]struct {
+		result1 crypto.MultipleDigest
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -131,6 +215,75 @@ func (fake *FakeBlobstoreDelegator) CleanUpReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeBlobstoreDelegator) Copy(arg1 crypto.MultipleDigest, arg2 string, arg3 string, arg4 string) (string, crypto.MultipleDigest, error) {
+	fake.copyMutex.Lock()
+	ret, specificReturn := fake.copyReturnsOnCall[len(fake.copyArgsForCall)]
+	fake.copyArgsForCall = append(fake.copyArgsForCall, struct {
+		arg1 crypto.MultipleDigest
+		arg2 string
+		arg3 string
+		arg4 string
+	}{arg1, arg2, arg3, arg4})
+	fake.recordInvocation("Copy", []interface{}{arg1, arg2, arg3, arg4})
+	fake.copyMutex.Unlock()
+	if fake.CopyStub != nil {
+		return fake.CopyStub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	fakeReturns := fake.copyReturns
+	return fakeReturns.result1, fakeReturns.result2, fakeReturns.result3
+}
+
+func (fake *FakeBlobstoreDelegator) CopyCallCount() int {
+	fake.copyMutex.RLock()
+	defer fake.copyMutex.RUnlock()
+	return len(fake.copyArgsForCall)
+}
+
+func (fake *FakeBlobstoreDelegator) CopyCalls(stub func(crypto.MultipleDigest, string, string, string) (string, crypto.MultipleDigest, error)) {
+	fake.copyMutex.Lock()
+	defer fake.copyMutex.Unlock()
+	fake.CopyStub = stub
+}
+
+func (fake *FakeBlobstoreDelegator) CopyArgsForCall(i int) (crypto.MultipleDigest, string, string, string) {
+	fake.copyMutex.RLock()
+	defer fake.copyMutex.RUnlock()
+	argsForCall := fake.copyArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeBlobstoreDelegator) CopyReturns(result1 string, result2 crypto.MultipleDigest, result3 error) {
+	fake.copyMutex.Lock()
+	defer fake.copyMutex.Unlock()
+	fake.CopyStub = nil
+	fake.copyReturns = struct {
+		result1 string
+		result2 crypto.MultipleDigest
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeBlobstoreDelegator) CopyReturnsOnCall(i int, result1 string, result2 crypto.MultipleDigest, result3 error) {
+	fake.copyMutex.Lock()
+	defer fake.copyMutex.Unlock()
+	fake.CopyStub = nil
+	if fake.copyReturnsOnCall == nil {
+		fake.copyReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 crypto.MultipleDigest
+			result3 error
+		})
+	}
+	fake.copyReturnsOnCall[i] = struct {
+		result1 string
+		result2 crypto.MultipleDigest
+		result3 error
+	}{result1, result2, result3}
+}
+
 func (fake *FakeBlobstoreDelegator) Delete(arg1 string, arg2 string) error {
 	fake.deleteMutex.Lock()
 	ret, specificReturn := fake.deleteReturnsOnCall[len(fake.deleteArgsForCall)]
@@ -258,6 +411,142 @@ func (fake *FakeBlobstoreDelegator) GetReturnsOnCall(i int, result1 string, resu
 	}{result1, result2}
 }
 
+func (fake *FakeBlobstoreDelegator) GetWithDigestAlgorithms(arg1 crypto.MultipleDigest, arg2 string, arg3 string, arg4 map[string]string, arg5 []crypto.Algorithm) (string, error) {
+	var arg5Copy []crypto.Algorithm
+	if arg5 != nil {
+		arg5Copy = make([]crypto.Algorithm, len(arg5))
+		copy(arg5Copy, arg5)
+	}
+	fake.getWithDigestAlgorithmsMutex.Lock()
+	ret, specificReturn := fake.getWithDigestAlgorithmsReturnsOnCall[len(fake.getWithDigestAlgorithmsArgsForCall)]
+	fake.getWithDigestAlgorithmsArgsForCall = append(fake.getWithDigestAlgorithmsArgsForCall, struct {
+		arg1 crypto.MultipleDigest
+		arg2 string
+		arg3 string
+		arg4 map[string]string
+		arg5 []crypto.Algorithm
+	}{arg1, arg2, arg3, arg4, arg5Copy})
+	fake.recordInvocation("GetWithDigestAlgorithms", []interface{}{arg1, arg2, arg3, arg4, arg5Copy})
+	fake.getWithDigestAlgorithmsMutex.Unlock()
+	if fake.GetWithDigestAlgorithmsStub != nil {
+		return fake.GetWithDigestAlgorithmsStub(arg1, arg2, arg3, arg4, arg5)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.getWithDigestAlgorithmsReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeBlobstoreDelegator) GetWithDigestAlgorithmsCallCount() int {
+	fake.getWithDigestAlgorithmsMutex.RLock()
+	defer fake.getWithDigestAlgorithmsMutex.RUnlock()
+	return len(fake.getWithDigestAlgorithmsArgsForCall)
+}
+
+func (fake *FakeBlobstoreDelegator) GetWithDigestAlgorithmsCalls(stub func(crypto.MultipleDigest, string, string, map[string]string, []crypto.Algorithm) (string, error)) {
+	fake.getWithDigestAlgorithmsMutex.Lock()
+	defer fake.getWithDigestAlgorithmsMutex.Unlock()
+	fake.GetWithDigestAlgorithmsStub = stub
+}
+
+func (fake *FakeBlobstoreDelegator) GetWithDigestAlgorithmsArgsForCall(i int) (crypto.MultipleDigest, string, string, map[string]string, []crypto.Algorithm) {
+	fake.getWithDigestAlgorithmsMutex.RLock()
+	defer fake.getWithDigestAlgorithmsMutex.RUnlock()
+	argsForCall := fake.getWithDigestAlgorithmsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+}
+
+func (fake *FakeBlobstoreDelegator) GetWithDigestAlgorithmsReturns(result1 string, result2 error) {
+	fake.getWithDigestAlgorithmsMutex.Lock()
+	defer fake.getWithDigestAlgorithmsMutex.Unlock()
+	fake.GetWithDigestAlgorithmsStub = nil
+	fake.getWithDigestAlgorithmsReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeBlobstoreDelegator) GetWithDigestAlgorithmsReturnsOnCall(i int, result1 string, result2 error) {
+	fake.getWithDigestAlgorithmsMutex.Lock()
+	defer fake.getWithDigestAlgorithmsMutex.Unlock()
+	fake.GetWithDigestAlgorithmsStub = nil
+	if fake.getWithDigestAlgorithmsReturnsOnCall == nil {
+		fake.getWithDigestAlgorithmsReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.getWithDigestAlgorithmsReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeBlobstoreDelegator) Size(arg1 string, arg2 map[string]string) (int64, error) {
+	fake.sizeMutex.Lock()
+	ret, specificReturn := fake.sizeReturnsOnCall[len(fake.sizeArgsForCall)]
+	fake.sizeArgsForCall = append(fake.sizeArgsForCall, struct {
+		arg1 string
+		arg2 map[string]string
+	}{arg1, arg2})
+	fake.recordInvocation("Size", []interface{}{arg1, arg2})
+	fake.sizeMutex.Unlock()
+	if fake.SizeStub != nil {
+		return fake.SizeStub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.sizeReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeBlobstoreDelegator) SizeCallCount() int {
+	fake.sizeMutex.RLock()
+	defer fake.sizeMutex.RUnlock()
+	return len(fake.sizeArgsForCall)
+}
+
+func (fake *FakeBlobstoreDelegator) SizeCalls(stub func(string, map[string]string) (int64, error)) {
+	fake.sizeMutex.Lock()
+	defer fake.sizeMutex.Unlock()
+	fake.SizeStub = stub
+}
+
+func (fake *FakeBlobstoreDelegator) SizeArgsForCall(i int) (string, map[string]string) {
+	fake.sizeMutex.RLock()
+	defer fake.sizeMutex.RUnlock()
+	argsForCall := fake.sizeArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeBlobstoreDelegator) SizeReturns(result1 int64, result2 error) {
+	fake.sizeMutex.Lock()
+	defer fake.sizeMutex.Unlock()
+	fake.SizeStub = nil
+	fake.sizeReturns = struct {
+		result1 int64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeBlobstoreDelegator) SizeReturnsOnCall(i int, result1 int64, result2 error) {
+	fake.sizeMutex.Lock()
+	defer fake.sizeMutex.Unlock()
+	fake.SizeStub = nil
+	if fake.sizeReturnsOnCall == nil {
+		fake.sizeReturnsOnCall = make(map[int]struct {
+			result1 int64
+			result2 error
+		})
+	}
+	fake.sizeReturnsOnCall[i] = struct {
+		result1 int64
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeBlobstoreDelegator) Write(arg1 string, arg2 string, arg3 map[string]string) (string, crypto.MultipleDigest, error) {
 	fake.writeMutex.Lock()
 	ret, specificReturn := fake.writeReturnsOnCall[len(fake.writeArgsForCall)]
@@ -326,17 +615,173 @@ func (fake *FakeBlobstoreDelegator) WriteReturnsOnCall(i int, result1 string, re
 	}{result1, result2, result3}
 }
 
+func (fake *FakeBlobstoreDelegator) WriteWithDigestAlgorithms(arg1 string, arg2 string, arg3 map[string]string, arg4 []crypto.Algorithm) (string, crypto.MultipleDigest, error) {
+	var arg4Copy []crypto.Algorithm
+	if arg4 != nil {
+		arg4Copy = make([]crypto.Algorithm, len(arg4))
+		copy(arg4Copy, arg4)
+	}
+	fake.writeWithDigestAlgorithmsMutex.Lock()
+	ret, specificReturn := fake.writeWithDigestAlgorithmsReturnsOnCall[len(fake.writeWithDigestAlgorithmsArgsForCall)]
+	fake.writeWithDigestAlgorithmsArgsForCall = append(fake.writeWithDigestAlgorithmsArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 map[string]string
+		arg4 []crypto.Algorithm
+	}{arg1, arg2, arg3, arg4Copy})
+	fake.recordInvocation("WriteWithDigestAlgorithms", []interface{}{arg1, arg2, arg3, arg4Copy})
+	fake.writeWithDigestAlgorithmsMutex.Unlock()
+	if fake.WriteWithDigestAlgorithmsStub != nil {
+		return fake.WriteWithDigestAlgorithmsStub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	fakeReturns := fake.writeWithDigestAlgorithmsReturns
+	return fakeReturns.result1, fakeReturns.result2, fakeReturns.result3
+}
+
+func (fake *FakeBlobstoreDelegator) WriteWithDigestAlgorithmsCallCount() int {
+	fake.writeWithDigestAlgorithmsMutex.RLock()
+	defer fake.writeWithDigestAlgorithmsMutex.RUnlock()
+	return len(fake.writeWithDigestAlgorithmsArgsForCall)
+}
+
+func (fake *FakeBlobstoreDelegator) WriteWithDigestAlgorithmsCalls(stub func(string, string, map[string]string, []crypto.Algorithm) (string, crypto.MultipleDigest, error)) {
+	fake.writeWithDigestAlgorithmsMutex.Lock()
+	defer fake.writeWithDigestAlgorithmsMutex.Unlock()
+	fake.WriteWithDigestAlgorithmsStub = stub
+}
+
+func (fake *FakeBlobstoreDelegator) WriteWithDigestAlgorithmsArgsForCall(i int) (string, string, map[string]string, []crypto.Algorithm) {
+	fake.writeWithDigestAlgorithmsMutex.RLock()
+	defer fake.writeWithDigestAlgorithmsMutex.RUnlock()
+	argsForCall := fake.writeWithDigestAlgorithmsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeBlobstoreDelegator) WriteWithDigestAlgorithmsReturns(result1 string, result2 crypto.MultipleDigest, result3 error) {
+	fake.writeWithDigestAlgorithmsMutex.Lock()
+	defer fake.writeWithDigestAlgorithmsMutex.Unlock()
+	fake.WriteWithDigestAlgorithmsStub = nil
+	fake.writeWithDigestAlgorithmsReturns = struct {
+		result1 string
+		result2 crypto.MultipleDigest
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeBlobstoreDelegator) WriteWithDigestAlgorithmsReturnsOnCall(i int, result1 string, result2 crypto.MultipleDigest, result3 error) {
+	fake.writeWithDigestAlgorithmsMutex.Lock()
+	defer fake.writeWithDigestAlgorithmsMutex.Unlock()
+	fake.WriteWithDigestAlgorithmsStub = nil
+	if fake.writeWithDigestAlgorithmsReturnsOnCall == nil {
+		fake.writeWithDigestAlgorithmsReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 crypto.MultipleDigest
+			result3 error
+		})
+	}
+	fake.writeWithDigestAlgorithmsReturnsOnCall[i] = struct {
+		result1 string
+		result2 crypto.MultipleDigest
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeBlobstoreDelegator) WriteWithExistingBlobCheck(arg1 string, arg2 string, arg3 string, arg4 map[string]string, arg5 []crypto.Algorithm) (crypto.MultipleDigest, error) {
+	var arg5Copy []crypto.Algorithm
+	if arg5 != nil {
+		arg5Copy = make([]crypto.Algorithm, len(arg5))
+		copy(arg5Copy, arg5)
+	}
+	fake.writeWithExistingBlobCheckMutex.Lock()
+	ret, specificReturn := fake.writeWithExistingBlobCheckReturnsOnCall[len(fake.writeWithExistingBlobCheckArgsForCall)]
+	fake.writeWithExistingBlobCheckArgsForCall = append(fake.writeWithExistingBlobCheckArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 map[string]string
+		arg5 []crypto.Algorithm
+	}{arg1, arg2, arg3, arg4, arg5Copy})
+	fake.recordInvocation("WriteWithExistingBlobCheck", []interface{}{arg1, arg2, arg3, arg4, arg5Copy})
+	fake.writeWithExistingBlobCheckMutex.Unlock()
+	if fake.WriteWithExistingBlobCheckStub != nil {
+		return fake.WriteWithExistingBlobCheckStub(arg1, arg2, arg3, arg4, arg5)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.writeWithExistingBlobCheckReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeBlobstoreDelegator) WriteWithExistingBlobCheckCallCount() int {
+	fake.writeWithExistingBlobCheckMutex.RLock()
+	defer fake.writeWithExistingBlobCheckMutex.RUnlock()
+	return len(fake.writeWithExistingBlobCheckArgsForCall)
+}
+
+func (fake *FakeBlobstoreDelegator) WriteWithExistingBlobCheckCalls(stub func(string, string, string, map[string]string, []crypto.Algorithm) (crypto.MultipleDigest, error)) {
+	fake.writeWithExistingBlobCheckMutex.Lock()
+	defer fake.writeWithExistingBlobCheckMutex.Unlock()
+	fake.WriteWithExistingBlobCheckStub = stub
+}
+
+func (fake *FakeBlobstoreDelegator) WriteWithExistingBlobCheckArgsForCall(i int) (string, string, string, map[string]string, []crypto.Algorithm) {
+	fake.writeWithExistingBlobCheckMutex.RLock()
+	defer fake.writeWithExistingBlobCheckMutex.RUnlock()
+	argsForCall := fake.writeWithExistingBlobCheckArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+}
+
+func (fake *FakeBlobstoreDelegator) WriteWithExistingBlobCheckReturns(result1 crypto.MultipleDigest, result2 error) {
+	fake.writeWithExistingBlobCheckMutex.Lock()
+	defer fake.writeWithExistingBlobCheckMutex.Unlock()
+	fake.WriteWithExistingBlobCheckStub = nil
+	fake.writeWithExistingBlobCheckReturns = struct {
+		result1 crypto.MultipleDigest
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeBlobstoreDelegator) WriteWithExistingBlobCheckReturnsOnCall(i int, result1 crypto.MultipleDigest, result2 error) {
+	fake.writeWithExistingBlobCheckMutex.Lock()
+	defer fake.writeWithExistingBlobCheckMutex.Unlock()
+	fake.WriteWithExistingBlobCheckStub = nil
+	if fake.writeWithExistingBlobCheckReturnsOnCall == nil {
+		fake.writeWithExistingBlobCheckReturnsOnCall = make(map[int]struct {
+			result1 crypto.MultipleDigest
+			result2 error
+		})
+	}
+	fake.writeWithExistingBlobCheckReturnsOnCall[i] = struct {
+		result1 crypto.MultipleDigest
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeBlobstoreDelegator) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
 	fake.cleanUpMutex.RLock()
 	defer fake.cleanUpMutex.RUnlock()
+	fake.copyMutex.RLock()
+	defer fake.copyMutex.RUnlock()
 	fake.deleteMutex.RLock()
 	defer fake.deleteMutex.RUnlock()
 	fake.getMutex.RLock()
 	defer fake.getMutex.RUnlock()
+	fake.getWithDigestAlgorithmsMutex.RLock()
+	defer fake.getWithDigestAlgorithmsMutex.RUnlock()
+	fake.sizeMutex.RLock()
+	defer fake.sizeMutex.RUnlock()
 	fake.writeMutex.RLock()
 	defer fake.writeMutex.RUnlock()
+	fake.writeWithDigestAlgorithmsMutex.RLock()
+	defer fake.writeWithDigestAlgorithmsMutex.RUnlock()
+	fake.writeWithExistingBlobCheckMutex.RLock()
+	defer fake.writeWithExistingBlobCheckMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -2,12 +2,14 @@ package httpblobprovider
 
 import (
 	"crypto/x509"
+	"math"
 	"net/http"
 
 	"github.com/cloudfoundry/bosh-agent/settings"
 
 	boshblob "github.com/cloudfoundry/bosh-utils/blobstore"
 	boshcrypto "github.com/cloudfoundry/bosh-utils/crypto"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshhttp "github.com/cloudfoundry/bosh-utils/httpclient"
 )
 
@@ -31,6 +33,28 @@ func NewBlobstoreHTTPClient(blobstoreSettings settings.Blobstore) (*http.Client,
 	return boshhttp.CreateExternalDefaultClient(certpool), nil
 }
 
+// ETagCacheMaxEntriesFromSettings reads how many signed URL blobs are kept
+// for conditional downloads; caching is disabled when the option is not set
+func ETagCacheMaxEntriesFromSettings(blobstoreSettings settings.Blobstore) (int, error) {
+	value, found := blobstoreSettings.Options["etag_cache_max_entries"]
+	if !found {
+		return 0, nil
+	}
+
+	switch maxEntries := value.(type) {
+	case int:
+		if maxEntries >= 0 {
+			return maxEntries, nil
+		}
+	case float64:
+		if maxEntries >= 0 && maxEntries == math.Trunc(maxEntries) {
+			return int(maxEntries), nil
+		}
+	}
+
+	return 0, bosherr.Errorf("Expected blobstore option 'etag_cache_max_entries' to be a non-negative integer but was '%v'", value)
+}
+
 func isInternalBlobstore(provider string) bool {
 	switch provider {
 	case boshblob.BlobstoreTypeDummy, boshblob.BlobstoreTypeLocal, "dav":
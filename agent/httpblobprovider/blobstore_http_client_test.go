@@ -101,3 +101,27 @@ var _ = Describe("NewBlobstoreHTTPClient", func() {
 		})
 	})
 })
+
+var _ = Describe("ETagCacheMaxEntriesFromSettings", func() {
+	It("disables the cache by default", func() {
+		maxEntries, err := httpblobprovider.ETagCacheMaxEntriesFromSettings(settings.Blobstore{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(maxEntries).To(Equal(0))
+	})
+
+	It("reads the etag_cache_max_entries option", func() {
+		maxEntries, err := httpblobprovider.ETagCacheMaxEntriesFromSettings(settings.Blobstore{
+			Options: map[string]interface{}{"etag_cache_max_entries": float64(20)},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(maxEntries).To(Equal(20))
+	})
+
+	It("rejects values that are not a non-negative integer", func() {
+		_, err := httpblobprovider.ETagCacheMaxEntriesFromSettings(settings.Blobstore{
+			Options: map[string]interface{}{"etag_cache_max_entries": float64(-1)},
+		})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected blobstore option 'etag_cache_max_entries' to be a non-negative integer but was '-1'"))
+	})
+})
@@ -1,11 +1,16 @@
 package httpblobprovider
 
 import (
+	"crypto/sha1"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	boshcrypto "github.com/cloudfoundry/bosh-utils/crypto"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
@@ -18,6 +23,12 @@ type HTTPBlobImpl struct {
 	fs               boshsys.FileSystem
 	createAlgorithms []boshcrypto.Algorithm
 	httpClient       *http.Client
+
+	// Directory keeping downloaded blobs along with their ETag; empty disables caching
+	etagCacheDir string
+
+	// Number of cached blobs after which the least recently used ones are evicted
+	etagCacheMaxEntries int
 }
 
 func NewHTTPBlobImpl(fs boshsys.FileSystem, httpClient *http.Client) *HTTPBlobImpl {
@@ -32,8 +43,23 @@ func NewHTTPBlobImplWithDigestAlgorithms(fs boshsys.FileSystem, httpClient *http
 	}
 }
 
+// NewHTTPBlobImplWithETagCache keeps up to maxEntries downloaded blobs in cacheDir
+// so that fetching an unchanged blob again only needs a conditional request
+func NewHTTPBlobImplWithETagCache(fs boshsys.FileSystem, httpClient *http.Client, cacheDir string, maxEntries int) *HTTPBlobImpl {
+	blobImpl := NewHTTPBlobImpl(fs, httpClient)
+	blobImpl.etagCacheDir = cacheDir
+	blobImpl.etagCacheMaxEntries = maxEntries
+	return blobImpl
+}
+
 func (h *HTTPBlobImpl) Upload(signedURL, filepath string, headers map[string]string) (boshcrypto.MultipleDigest, error) {
-	digest, err := boshcrypto.NewMultipleDigestFromPath(filepath, h.fs, h.createAlgorithms)
+	return h.UploadWithDigestAlgorithms(signedURL, filepath, headers, h.createAlgorithms)
+}
+
+// UploadWithDigestAlgorithms uploads the file like Upload but only computes
+// digests for the given algorithms instead of the configured ones
+func (h *HTTPBlobImpl) UploadWithDigestAlgorithms(signedURL, filepath string, headers map[string]string, algorithms []boshcrypto.Algorithm) (boshcrypto.MultipleDigest, error) {
+	digest, err := boshcrypto.NewMultipleDigestFromPath(filepath, h.fs, algorithms)
 	if err != nil {
 		return boshcrypto.MultipleDigest{}, err
 	}
@@ -84,25 +110,35 @@ func (h *HTTPBlobImpl) Get(signedURL string, digest boshcrypto.Digest, headers m
 		return "", bosherr.WrapError(err, "Creating temporary file")
 	}
 
-	req, err := http.NewRequest("GET", signedURL, strings.NewReader(""))
+	cachedBlobPath, cachedETagPath := h.etagCachePaths(signedURL)
+	cachedETag := h.readCachedETag(cachedBlobPath, cachedETagPath)
+
+	resp, err := h.doGet(signedURL, headers, cachedETag)
 	if err != nil {
-		defer file.Close()
-		return "", bosherr.WrapError(err, "Creating Get Request")
+		return file.Name(), err
 	}
+	defer resp.Body.Close()
 
-	if headers != nil {
-		for k, v := range headers {
-			req.Header.Set(k, v)
+	if resp.StatusCode == http.StatusNotModified && cachedETag != "" {
+		err = h.copyCachedBlob(cachedBlobPath, file, digest)
+		if err == nil {
+			// Rewriting the ETag marks the entry as recently used for eviction
+			_ = h.fs.WriteFileString(cachedETagPath, cachedETag)
+			return file.Name(), nil
 		}
-	}
 
-	resp, err := h.httpClient.Do(req)
-	if err != nil {
-		return file.Name(), bosherr.WrapError(err, "Excuting GET request")
+		// The cached copy cannot be trusted anymore so fetch the blob again
+		h.removeCachedBlob(cachedBlobPath, cachedETagPath)
+
+		resp, err = h.doGet(signedURL, headers, "")
+		if err != nil {
+			return file.Name(), err
+		}
+		defer resp.Body.Close()
 	}
 
 	if !isSuccess(resp) {
-		return file.Name(), fmt.Errorf("Error executing GET, response was %d", resp.StatusCode)
+		return file.Name(), HTTPStatusError{Method: "GET", StatusCode: resp.StatusCode}
 	}
 
 	_, err = io.Copy(file, resp.Body)
@@ -120,9 +156,266 @@ func (h *HTTPBlobImpl) Get(signedURL string, digest boshcrypto.Digest, headers m
 		return file.Name(), bosherr.WrapErrorf(err, "Checking downloaded blob digest")
 	}
 
+	// Blobstores not returning an ETag are always downloaded in full
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		h.cacheBlob(file.Name(), etag, cachedBlobPath, cachedETagPath)
+	}
+
 	return file.Name(), nil
 }
 
+func (h *HTTPBlobImpl) Exists(signedURL string, headers map[string]string) (bool, error) {
+	req, err := http.NewRequest("HEAD", signedURL, nil)
+	if err != nil {
+		return false, bosherr.WrapError(err, "Creating Head Request")
+	}
+
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		return false, bosherr.WrapError(err, "Excuting HEAD request")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+
+	if !isSuccess(resp) {
+		return false, HTTPStatusError{Method: "HEAD", StatusCode: resp.StatusCode}
+	}
+
+	return true, nil
+}
+
+// Size reads the length of the stored blob from the response to a HEAD request
+func (h *HTTPBlobImpl) Size(signedURL string, headers map[string]string) (int64, error) {
+	req, err := http.NewRequest("HEAD", signedURL, nil)
+	if err != nil {
+		return 0, bosherr.WrapError(err, "Creating Head Request")
+	}
+
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		return 0, bosherr.WrapError(err, "Excuting HEAD request")
+	}
+	defer resp.Body.Close()
+
+	if !isSuccess(resp) {
+		return 0, HTTPStatusError{Method: "HEAD", StatusCode: resp.StatusCode}
+	}
+
+	if resp.ContentLength < 0 {
+		return 0, bosherr.Error("Blob server did not report the blob size")
+	}
+
+	return resp.ContentLength, nil
+}
+
+func (h *HTTPBlobImpl) Delete(signedURL string, headers map[string]string) error {
+	req, err := http.NewRequest("DELETE", signedURL, nil)
+	if err != nil {
+		return bosherr.WrapError(err, "Creating Delete Request")
+	}
+
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		return bosherr.WrapError(err, "Excuting DELETE request")
+	}
+	defer resp.Body.Close()
+
+	if !isSuccess(resp) {
+		return HTTPStatusError{Method: "DELETE", StatusCode: resp.StatusCode}
+	}
+
+	return nil
+}
+
+// Matches streams the stored blob through the digest without keeping a copy
+func (h *HTTPBlobImpl) Matches(signedURL string, digest boshcrypto.Digest, headers map[string]string) (bool, error) {
+	resp, err := h.doGet(signedURL, headers, "")
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+
+	if !isSuccess(resp) {
+		return false, HTTPStatusError{Method: "GET", StatusCode: resp.StatusCode}
+	}
+
+	return digest.Verify(resp.Body) == nil, nil
+}
+
+func (h *HTTPBlobImpl) doGet(signedURL string, headers map[string]string, etag string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", signedURL, strings.NewReader(""))
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Creating Get Request")
+	}
+
+	if headers != nil {
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+	}
+
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Excuting GET request")
+	}
+
+	return resp, nil
+}
+
+// etagCachePaths names cache entries after the signed URL without its query
+// since signatures differ on every request for the same blob
+func (h *HTTPBlobImpl) etagCachePaths(signedURL string) (string, string) {
+	if h.etagCacheDir == "" {
+		return "", ""
+	}
+
+	parsedURL, err := url.Parse(signedURL)
+	if err != nil {
+		return "", ""
+	}
+
+	parsedURL.RawQuery = ""
+	parsedURL.Fragment = ""
+
+	key := fmt.Sprintf("%x", sha1.Sum([]byte(parsedURL.String())))
+	blobPath := filepath.Join(h.etagCacheDir, key)
+
+	return blobPath, blobPath + ".etag"
+}
+
+func (h *HTTPBlobImpl) readCachedETag(blobPath, etagPath string) string {
+	if blobPath == "" || !h.fs.FileExists(blobPath) || !h.fs.FileExists(etagPath) {
+		return ""
+	}
+
+	etag, err := h.fs.ReadFileString(etagPath)
+	if err != nil {
+		return ""
+	}
+
+	return etag
+}
+
+func (h *HTTPBlobImpl) copyCachedBlob(blobPath string, file boshsys.File, digest boshcrypto.Digest) error {
+	cachedBlob, err := h.fs.OpenFile(blobPath, os.O_RDONLY, 0)
+	if err != nil {
+		return bosherr.WrapError(err, "Opening cached blob")
+	}
+	defer cachedBlob.Close()
+
+	err = digest.Verify(cachedBlob)
+	if err != nil {
+		return bosherr.WrapError(err, "Checking cached blob digest")
+	}
+
+	_, err = cachedBlob.Seek(0, io.SeekStart)
+	if err != nil {
+		return bosherr.WrapError(err, "Rewinding cached blob")
+	}
+
+	_, err = io.Copy(file, cachedBlob)
+	if err != nil {
+		return bosherr.WrapError(err, "Copying cached blob to tempfile")
+	}
+
+	return nil
+}
+
+// cacheBlob is best effort; a blob missing from the cache is simply downloaded again
+func (h *HTTPBlobImpl) cacheBlob(downloadedPath, etag, blobPath, etagPath string) {
+	if blobPath == "" {
+		return
+	}
+
+	err := h.fs.MkdirAll(h.etagCacheDir, os.FileMode(0700))
+	if err != nil {
+		return
+	}
+
+	// The ETag is written last so that it never refers to a partially copied blob
+	err = h.fs.CopyFile(downloadedPath, blobPath)
+	if err == nil {
+		err = h.fs.WriteFileString(etagPath, etag)
+	}
+
+	if err != nil {
+		h.removeCachedBlob(blobPath, etagPath)
+		return
+	}
+
+	h.evictCachedBlobs(etagPath)
+}
+
+// evictCachedBlobs removes the least recently used blobs other than the one
+// just cached until no more than the maximum number of entries are left
+func (h *HTTPBlobImpl) evictCachedBlobs(keptETagPath string) {
+	etagPaths, err := h.fs.Glob(filepath.Join(h.etagCacheDir, "*.etag"))
+	if err != nil || len(etagPaths) <= h.etagCacheMaxEntries {
+		return
+	}
+
+	var candidates []string
+	usedAt := map[string]time.Time{}
+
+	for _, etagPath := range etagPaths {
+		if etagPath == keptETagPath {
+			continue
+		}
+
+		if info, err := h.fs.Stat(etagPath); err == nil {
+			usedAt[etagPath] = info.ModTime()
+		}
+
+		candidates = append(candidates, etagPath)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return usedAt[candidates[i]].Before(usedAt[candidates[j]])
+	})
+
+	for _, etagPath := range candidates[:len(etagPaths)-h.etagCacheMaxEntries] {
+		h.removeCachedBlob(strings.TrimSuffix(etagPath, ".etag"), etagPath)
+	}
+}
+
+func (h *HTTPBlobImpl) removeCachedBlob(blobPath, etagPath string) {
+	_ = h.fs.RemoveAll(etagPath)
+	_ = h.fs.RemoveAll(blobPath)
+}
+
+// HTTPStatusError is returned when the blob server responds with a non 2xx status
+type HTTPStatusError struct {
+	Method     string
+	StatusCode int
+}
+
+func (e HTTPStatusError) Error() string {
+	return fmt.Sprintf("Error executing %s, response was %d", e.Method, e.StatusCode)
+}
+
 func isSuccess(resp *http.Response) bool {
 	return resp.StatusCode >= 200 && resp.StatusCode < 300
 }
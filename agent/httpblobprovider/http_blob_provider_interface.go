@@ -8,5 +8,18 @@ import (
 
 type HTTPBlobProvider interface {
 	Upload(signedURL, filepath string, headers map[string]string) (boshcrypto.MultipleDigest, error)
+	UploadWithDigestAlgorithms(signedURL, filepath string, headers map[string]string, algorithms []boshcrypto.Algorithm) (boshcrypto.MultipleDigest, error)
 	Get(signedURL string, digest boshcrypto.Digest, headers map[string]string) (string, error)
+
+	// Exists reports whether a blob is already stored at the signed URL
+	Exists(signedURL string, headers map[string]string) (bool, error)
+
+	// Size returns an HTTPStatusError with status 404 for a missing blob
+	Size(signedURL string, headers map[string]string) (int64, error)
+
+	Delete(signedURL string, headers map[string]string) error
+
+	// Matches reports whether the blob stored at the signed URL has the given
+	// digest; a missing blob does not match
+	Matches(signedURL string, digest boshcrypto.Digest, headers map[string]string) (bool, error)
 }
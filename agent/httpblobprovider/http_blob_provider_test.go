@@ -4,27 +4,30 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"regexp"
+	"strings"
+	"time"
 
 	. "github.com/cloudfoundry/bosh-agent/agent/httpblobprovider"
+	fakefs "github.com/cloudfoundry/bosh-agent/platform/fakefs"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/ghttp"
 
 	boshcrypto "github.com/cloudfoundry/bosh-utils/crypto"
 	"github.com/cloudfoundry/bosh-utils/system"
-	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
 )
 
 var _ = Describe("HTTPBlobImpl", func() {
 	var (
-		fakeFileSystem *fakesys.FakeFileSystem
+		fakeFileSystem *fakefs.FakeFileSystem
 		server         *ghttp.Server
 		tempFile       system.File
 		blobProvider   *HTTPBlobImpl
 	)
 
 	BeforeEach(func() {
-		fakeFileSystem = fakesys.NewFakeFileSystem()
+		fakeFileSystem = fakefs.NewFakeFileSystem()
 		server = ghttp.NewServer()
 
 		blobProvider = NewHTTPBlobImpl(fakeFileSystem, server.HTTPTestServer.Client())
@@ -109,6 +112,142 @@ var _ = Describe("HTTPBlobImpl", func() {
 			_, err := blobProvider.Get(fmt.Sprintf("%s/success-get-signed-url", server.URL()), badMultiDigest, nil)
 			Expect(err).To(HaveOccurred())
 		})
+
+		Context("when an ETag cache is configured", func() {
+			var secondTempFile system.File
+
+			verifyNoIfNoneMatch := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.Header.Get("If-None-Match")).To(BeEmpty())
+			})
+
+			BeforeEach(func() {
+				blobProvider = NewHTTPBlobImplWithETagCache(fakeFileSystem, server.HTTPTestServer.Client(), "/fake-cache-dir", 2)
+
+				var err error
+				secondTempFile, err = fakeFileSystem.OpenFile("fake-second-file", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0640)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("returns the cached blob when the server responds with 304 Not Modified", func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						verifyNoIfNoneMatch,
+						ghttp.RespondWith(http.StatusOK, "abc", http.Header{"ETag": []string{`"fake-etag"`}}),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyHeaderKV("If-None-Match", `"fake-etag"`),
+						ghttp.RespondWith(http.StatusNotModified, nil),
+					),
+				)
+
+				_, err := blobProvider.Get(fmt.Sprintf("%s/blob?signature=first", server.URL()), multiDigest, nil)
+				Expect(err).NotTo(HaveOccurred())
+
+				fakeFileSystem.ReturnTempFile = secondTempFile
+
+				filepath, err := blobProvider.Get(fmt.Sprintf("%s/blob?signature=second", server.URL()), multiDigest, nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(filepath).To(Equal("fake-second-file"))
+				Expect(server.ReceivedRequests()).To(HaveLen(2))
+
+				content, err := fakeFileSystem.ReadFileString(filepath)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(content).To(Equal("abc"))
+			})
+
+			It("downloads the full blob every time when the server does not return an ETag", func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						verifyNoIfNoneMatch,
+						ghttp.RespondWith(http.StatusOK, "abc"),
+					),
+					ghttp.CombineHandlers(
+						verifyNoIfNoneMatch,
+						ghttp.RespondWith(http.StatusOK, "abc"),
+					),
+				)
+
+				_, err := blobProvider.Get(fmt.Sprintf("%s/blob", server.URL()), multiDigest, nil)
+				Expect(err).NotTo(HaveOccurred())
+
+				fakeFileSystem.ReturnTempFile = secondTempFile
+
+				filepath, err := blobProvider.Get(fmt.Sprintf("%s/blob", server.URL()), multiDigest, nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(server.ReceivedRequests()).To(HaveLen(2))
+
+				content, err := fakeFileSystem.ReadFileString(filepath)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(content).To(Equal("abc"))
+			})
+
+			It("downloads the full blob again when the cached blob does not match the digest", func() {
+				server.AppendHandlers(
+					ghttp.RespondWith(http.StatusOK, "abc", http.Header{"ETag": []string{`"fake-etag"`}}),
+					ghttp.RespondWith(http.StatusNotModified, nil),
+					ghttp.CombineHandlers(
+						verifyNoIfNoneMatch,
+						ghttp.RespondWith(http.StatusOK, "abc", http.Header{"ETag": []string{`"fake-etag"`}}),
+					),
+				)
+
+				_, err := blobProvider.Get(fmt.Sprintf("%s/blob", server.URL()), multiDigest, nil)
+				Expect(err).NotTo(HaveOccurred())
+
+				fakeFileSystem.GlobUsesRealMatching = true
+				cachedBlobs, err := fakeFileSystem.Glob("/fake-cache-dir/*.etag")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cachedBlobs).To(HaveLen(1))
+
+				err = fakeFileSystem.WriteFileString(strings.TrimSuffix(cachedBlobs[0], ".etag"), "corrupted")
+				Expect(err).NotTo(HaveOccurred())
+
+				fakeFileSystem.ReturnTempFile = secondTempFile
+
+				filepath, err := blobProvider.Get(fmt.Sprintf("%s/blob", server.URL()), multiDigest, nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(server.ReceivedRequests()).To(HaveLen(3))
+
+				content, err := fakeFileSystem.ReadFileString(filepath)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(content).To(Equal("abc"))
+			})
+
+			It("evicts the least recently used blobs beyond the maximum number of entries", func() {
+				fakeFileSystem.GlobUsesRealMatching = true
+				server.RouteToHandler("GET", regexp.MustCompile(`/blob-\d`),
+					ghttp.RespondWith(http.StatusOK, "abc", http.Header{"ETag": []string{`"fake-etag"`}}),
+				)
+
+				cachedETags := func() []string {
+					etagPaths, err := fakeFileSystem.Glob("/fake-cache-dir/*.etag")
+					Expect(err).NotTo(HaveOccurred())
+					return etagPaths
+				}
+
+				_, err := blobProvider.Get(fmt.Sprintf("%s/blob-1", server.URL()), multiDigest, nil)
+				Expect(err).NotTo(HaveOccurred())
+				firstETag := cachedETags()[0]
+				fakeFileSystem.GetFileTestStat(firstETag).ModTime = time.Now().Add(-time.Hour)
+
+				_, err = blobProvider.Get(fmt.Sprintf("%s/blob-2", server.URL()), multiDigest, nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cachedETags()).To(HaveLen(2))
+
+				for _, etagPath := range cachedETags() {
+					if etagPath != firstETag {
+						fakeFileSystem.GetFileTestStat(etagPath).ModTime = time.Now()
+					}
+				}
+
+				_, err = blobProvider.Get(fmt.Sprintf("%s/blob-3", server.URL()), multiDigest, nil)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(cachedETags()).To(HaveLen(2))
+				Expect(cachedETags()).NotTo(ContainElement(firstETag))
+				Expect(fakeFileSystem.FileExists(strings.TrimSuffix(firstETag, ".etag"))).To(BeFalse())
+			})
+		})
 	})
 
 	Describe("Upload", func() {
@@ -141,6 +280,35 @@ var _ = Describe("HTTPBlobImpl", func() {
 			Expect(digest.DigestFor(boshcrypto.DigestAlgorithmSHA512)).To(Equal(sha512))
 		})
 
+		It("sends the given content type", func() {
+			server.RouteToHandler("PUT", "/success-signed-url",
+				ghttp.CombineHandlers(
+					ghttp.VerifyHeaderKV("Content-Type", "application/gzip"),
+					ghttp.RespondWith(http.StatusCreated, ``),
+				),
+			)
+
+			err := fakeFileSystem.WriteFileString("/some/path.tgz", "abc")
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = blobProvider.Upload(fmt.Sprintf("%s/success-signed-url", server.URL()), "/some/path.tgz", map[string]string{"Content-Type": "application/gzip"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(server.ReceivedRequests()).To(HaveLen(1))
+		})
+
+		It("only calculates the digests for the requested algorithms", func() {
+			server.RouteToHandler("PUT", "/success-signed-url", ghttp.RespondWith(http.StatusCreated, ``))
+
+			err := fakeFileSystem.WriteFileString("/some/path.tgz", "abc")
+			Expect(err).NotTo(HaveOccurred())
+
+			digest, err := blobProvider.UploadWithDigestAlgorithms(fmt.Sprintf("%s/success-signed-url", server.URL()), "/some/path.tgz", nil, []boshcrypto.Algorithm{boshcrypto.DigestAlgorithmSHA256})
+			Expect(err).NotTo(HaveOccurred())
+
+			sha256 := boshcrypto.NewDigest(boshcrypto.DigestAlgorithmSHA256, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad")
+			Expect(digest).To(Equal(boshcrypto.MustNewMultipleDigest(sha256)))
+		})
+
 		It("does something when the server responds with a bad status code", func() {
 			server.RouteToHandler("PUT", "/bad-status-code",
 				ghttp.CombineHandlers(
@@ -167,4 +335,116 @@ var _ = Describe("HTTPBlobImpl", func() {
 			Expect(err).To(HaveOccurred())
 		})
 	})
+
+	Describe("Exists", func() {
+		It("returns true when the blob is found", func() {
+			server.RouteToHandler("HEAD", "/existing-blob",
+				ghttp.CombineHandlers(
+					ghttp.RespondWith(http.StatusOK, ""),
+					http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						Expect(r.Header.Get("key")).To(Equal("value"))
+					}),
+				),
+			)
+
+			exists, err := blobProvider.Exists(fmt.Sprintf("%s/existing-blob", server.URL()), map[string]string{"key": "value"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(exists).To(BeTrue())
+		})
+
+		It("returns false when the blob is not found", func() {
+			server.RouteToHandler("HEAD", "/missing-blob", ghttp.RespondWith(http.StatusNotFound, ""))
+
+			exists, err := blobProvider.Exists(fmt.Sprintf("%s/missing-blob", server.URL()), nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(exists).To(BeFalse())
+		})
+
+		It("returns an error for other status codes", func() {
+			server.RouteToHandler("HEAD", "/forbidden-blob", ghttp.RespondWith(http.StatusForbidden, ""))
+
+			_, err := blobProvider.Exists(fmt.Sprintf("%s/forbidden-blob", server.URL()), nil)
+			Expect(err).To(Equal(HTTPStatusError{Method: "HEAD", StatusCode: http.StatusForbidden}))
+		})
+	})
+
+	Describe("Size", func() {
+		It("returns the content length of the blob", func() {
+			server.RouteToHandler("HEAD", "/existing-blob",
+				ghttp.CombineHandlers(
+					ghttp.VerifyHeaderKV("key", "value"),
+					ghttp.RespondWith(http.StatusOK, "", http.Header{"Content-Length": []string{"1024"}}),
+				),
+			)
+
+			size, err := blobProvider.Size(fmt.Sprintf("%s/existing-blob", server.URL()), map[string]string{"key": "value"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(size).To(Equal(int64(1024)))
+		})
+
+		It("returns a status error when the blob is not found", func() {
+			server.RouteToHandler("HEAD", "/missing-blob", ghttp.RespondWith(http.StatusNotFound, ""))
+
+			_, err := blobProvider.Size(fmt.Sprintf("%s/missing-blob", server.URL()), nil)
+			Expect(err).To(Equal(HTTPStatusError{Method: "HEAD", StatusCode: http.StatusNotFound}))
+		})
+	})
+
+	Describe("Delete", func() {
+		It("deletes the blob", func() {
+			server.RouteToHandler("DELETE", "/existing-blob",
+				ghttp.CombineHandlers(
+					ghttp.VerifyHeaderKV("key", "value"),
+					ghttp.RespondWith(http.StatusNoContent, ""),
+				),
+			)
+
+			err := blobProvider.Delete(fmt.Sprintf("%s/existing-blob", server.URL()), map[string]string{"key": "value"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(server.ReceivedRequests()).To(HaveLen(1))
+		})
+
+		It("returns a status error when the blob is not found", func() {
+			server.RouteToHandler("DELETE", "/missing-blob", ghttp.RespondWith(http.StatusNotFound, ""))
+
+			err := blobProvider.Delete(fmt.Sprintf("%s/missing-blob", server.URL()), nil)
+			Expect(err).To(Equal(HTTPStatusError{Method: "DELETE", StatusCode: http.StatusNotFound}))
+		})
+	})
+
+	Describe("Matches", func() {
+		// sha1 of "abc"
+		digest := boshcrypto.MustNewMultipleDigest(boshcrypto.NewDigest(boshcrypto.DigestAlgorithmSHA1, "a9993e364706816aba3e25717850c26c9cd0d89d"))
+
+		It("returns true when the stored blob has the digest", func() {
+			server.RouteToHandler("GET", "/blob", ghttp.RespondWith(http.StatusOK, "abc"))
+
+			matches, err := blobProvider.Matches(fmt.Sprintf("%s/blob", server.URL()), digest, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(matches).To(BeTrue())
+		})
+
+		It("returns false when the stored blob differs", func() {
+			server.RouteToHandler("GET", "/blob", ghttp.RespondWith(http.StatusOK, "abd"))
+
+			matches, err := blobProvider.Matches(fmt.Sprintf("%s/blob", server.URL()), digest, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(matches).To(BeFalse())
+		})
+
+		It("returns false when there is no stored blob", func() {
+			server.RouteToHandler("GET", "/blob", ghttp.RespondWith(http.StatusNotFound, ""))
+
+			matches, err := blobProvider.Matches(fmt.Sprintf("%s/blob", server.URL()), digest, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(matches).To(BeFalse())
+		})
+
+		It("returns an error for other status codes", func() {
+			server.RouteToHandler("GET", "/blob", ghttp.RespondWith(http.StatusForbidden, ""))
+
+			_, err := blobProvider.Matches(fmt.Sprintf("%s/blob", server.URL()), digest, nil)
+			Expect(err).To(Equal(HTTPStatusError{Method: "GET", StatusCode: http.StatusForbidden}))
+		})
+	})
 })
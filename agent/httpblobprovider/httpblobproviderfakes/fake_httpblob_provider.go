@@ -9,6 +9,32 @@ import (
 )
 
 type FakeHTTPBlobProvider struct {
+	DeleteStub        func(string, map[string]string) error
+	deleteMutex       sync.RWMutex
+	deleteArgsForCall []struct {
+		arg1 string
+		arg2 map[string]string
+	}
+	deleteReturns struct {
+		result1 error
+	}
+	deleteReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ExistsStub        func(string, map[string]string) (bool, error)
+	existsMutex       sync.RWMutex
+	existsArgsForCall []struct {
+		arg1 string
+		arg2 map[string]string
+	}
+	existsReturns struct {
+		result1 bool
+		result2 error
+	}
+	existsReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
 	GetStub        func(string, crypto.Digest, map[string]string) (string, error)
 	getMutex       sync.RWMutex
 	getArgsForCall []struct {
@@ -24,6 +50,35 @@ type FakeHTTPBlobProvider struct {
 		result1 string
 		result2 error
 	}
+	MatchesStub        func(string, crypto.Digest, map[string]string) (bool, error)
+	matchesMutex       sync.RWMutex
+	matchesArgsForCall []struct {
+		arg1 string
+		arg2 crypto.Digest
+		arg3 map[string]string
+	}
+	matchesReturns struct {
+		result1 bool
+		result2 error
+	}
+	matchesReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
+	SizeStub        func(string, map[string]string) (int64, error)
+	sizeMutex       sync.RWMutex
+	sizeArgsForCall []struct {
+		arg1 string
+		arg2 map[string]string
+	}
+	sizeReturns struct {
+		result1 int64
+		result2 error
+	}
+	sizeReturnsOnCall map[int]struct {
+		result1 int64
+		result2 error
+	}
 	UploadStub        func(string, string, map[string]string) (crypto.MultipleDigest, error)
 	uploadMutex       sync.RWMutex
 	uploadArgsForCall []struct {
@@ -39,10 +94,151 @@ type FakeHTTPBlobProvider struct {
 		result1 crypto.MultipleDigest
 		result2 error
 	}
+	UploadWithDigestAlgorithmsStub        func(string, string, map[string]string, []crypto.Algorithm) (crypto.MultipleDigest, error)
+	uploadWithDigestAlgorithmsMutex       sync.RWMutex
+	uploadWithDigestAlgorithmsArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 map[string]string
+		arg4 []crypto.Algorithm
+	}
+	uploadWithDigestAlgorithmsReturns struct {
+		result1 crypto.MultipleDigest
+		result2 error
+	}
+	uploadWithDigestAlgorithmsReturnsOnCall map[int]struct {
+		result1 crypto.MultipleDigest
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeHTTPBlobProvider) Delete(arg1 string, arg2 map[string]string) error {
+	fake.deleteMutex.Lock()
+	ret, specificReturn := fake.deleteReturnsOnCall[len(fake.deleteArgsForCall)]
+	fake.deleteArgsForCall = append(fake.deleteArgsForCall, struct {
+		arg1 string
+		arg2 map[string]string
+	}{arg1, arg2})
+	fake.recordInvocation("Delete", []interface{}{arg1, arg2})
+	fake.deleteMutex.Unlock()
+	if fake.DeleteStub != nil {
+		return fake.DeleteStub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.deleteReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeHTTPBlobProvider) DeleteCallCount() int {
+	fake.deleteMutex.RLock()
+	defer fake.deleteMutex.RUnlock()
+	return len(fake.deleteArgsForCall)
+}
+
+func (fake *FakeHTTPBlobProvider) DeleteCalls(stub func(string, map[string]string) error) {
+	fake.deleteMutex.Lock()
+	defer fake.deleteMutex.Unlock()
+	fake.DeleteStub = stub
+}
+
+func (fake *FakeHTTPBlobProvider) DeleteArgsForCall(i int) (string, map[string]string) {
+	fake.deleteMutex.RLock()
+	defer fake.deleteMutex.RUnlock()
+	argsForCall := fake.deleteArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeHTTPBlobProvider) DeleteReturns(result1 error) {
+	fake.deleteMutex.Lock()
+	defer fake.deleteMutex.Unlock()
+	fake.DeleteStub = nil
+	fake.deleteReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeHTTPBlobProvider) DeleteReturnsOnCall(i int, result1 error) {
+	fake.deleteMutex.Lock()
+	defer fake.deleteMutex.Unlock()
+	fake.DeleteStub = nil
+	if fake.deleteReturnsOnCall == nil {
+		fake.deleteReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeHTTPBlobProvider) Exists(arg1 string, arg2 map[string]string) (bool, error) {
+	fake.existsMutex.Lock()
+	ret, specificReturn := fake.existsReturnsOnCall[len(fake.existsArgsForCall)]
+	fake.existsArgsForCall = append(fake.existsArgsForCall, struct {
+		arg1 string
+		arg2 map[string]string
+	}{arg1, arg2})
+	fake.recordInvocation("Exists", []interface{}{arg1, arg2})
+	fake.existsMutex.Unlock()
+	if fake.ExistsStub != nil {
+		return fake.ExistsStub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.existsReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeHTTPBlobProvider) ExistsCallCount() int {
+	fake.existsMutex.RLock()
+	defer fake.existsMutex.RUnlock()
+	return len(fake.existsArgsForCall)
+}
+
+func (fake *FakeHTTPBlobProvider) ExistsCalls(stub func(string, map[string]string) (bool, error)) {
+	fake.existsMutex.Lock()
+	defer fake.existsMutex.Unlock()
+	fake.ExistsStub = stub
+}
+
+func (fake *FakeHTTPBlobProvider) ExistsArgsForCall(i int) (string, map[string]string) {
+	fake.existsMutex.RLock()
+	defer fake.existsMutex.RUnlock()
+	argsForCall := fake.existsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeHTTPBlobProvider) ExistsReturns(result1 bool, result2 error) {
+	fake.existsMutex.Lock()
+	defer fake.existsMutex.Unlock()
+	fake.ExistsStub = nil
+	fake.existsReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeHTTPBlobProvider) ExistsReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.existsMutex.Lock()
+	defer fake.existsMutex.Unlock()
+	fake.ExistsStub = nil
+	if fake.existsReturnsOnCall == nil {
+		fake.existsReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.existsReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeHTTPBlobProvider) Get(arg1 string, arg2 crypto.Digest, arg3 map[string]string) (string, error) {
 	fake.getMutex.Lock()
 	ret, specificReturn := fake.getReturnsOnCall[len(fake.getArgsForCall)]
@@ -108,6 +304,135 @@ func (fake *FakeHTTPBlobProvider) GetReturnsOnCall(i int, result1 string, result
 	}{result1, result2}
 }
 
+func (fake *FakeHTTPBlobProvider) Matches(arg1 string, arg2 crypto.Digest, arg3 map[string]string) (bool, error) {
+	fake.matchesMutex.Lock()
+	ret, specificReturn := fake.matchesReturnsOnCall[len(fake.matchesArgsForCall)]
+	fake.matchesArgsForCall = append(fake.matchesArgsForCall, struct {
+		arg1 string
+		arg2 crypto.Digest
+		arg3 map[string]string
+	}{arg1, arg2, arg3})
+	fake.recordInvocation("Matches", []interface{}{arg1, arg2, arg3})
+	fake.matchesMutex.Unlock()
+	if fake.MatchesStub != nil {
+		return fake.MatchesStub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.matchesReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeHTTPBlobProvider) MatchesCallCount() int {
+	fake.matchesMutex.RLock()
+	defer fake.matchesMutex.RUnlock()
+	return len(fake.matchesArgsForCall)
+}
+
+func (fake *FakeHTTPBlobProvider) MatchesCalls(stub func(string, crypto.Digest, map[string]string) (bool, error)) {
+	fake.matchesMutex.Lock()
+	defer fake.matchesMutex.Unlock()
+	fake.MatchesStub = stub
+}
+
+func (fake *FakeHTTPBlobProvider) MatchesArgsForCall(i int) (string, crypto.Digest, map[string]string) {
+	fake.matchesMutex.RLock()
+	defer fake.matchesMutex.RUnlock()
+	argsForCall := fake.matchesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeHTTPBlobProvider) MatchesReturns(result1 bool, result2 error) {
+	fake.matchesMutex.Lock()
+	defer fake.matchesMutex.Unlock()
+	fake.MatchesStub = nil
+	fake.matchesReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeHTTPBlobProvider) MatchesReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.matchesMutex.Lock()
+	defer fake.matchesMutex.Unlock()
+	fake.MatchesStub = nil
+	if fake.matchesReturnsOnCall == nil {
+		fake.matchesReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.matchesReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeHTTPBlobProvider) Size(arg1 string, arg2 map[string]string) (int64, error) {
+	fake.sizeMutex.Lock()
+	ret, specificReturn := fake.sizeReturnsOnCall[len(fake.sizeArgsForCall)]
+	fake.sizeArgsForCall = append(fake.sizeArgsForCall, struct {
+		arg1 string
+		arg2 map[string]string
+	}{arg1, arg2})
+	fake.recordInvocation("Size", []interface{}{arg1, arg2})
+	fake.sizeMutex.Unlock()
+	if fake.SizeStub != nil {
+		return fake.SizeStub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.sizeReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeHTTPBlobProvider) SizeCallCount() int {
+	fake.sizeMutex.RLock()
+	defer fake.sizeMutex.RUnlock()
+	return len(fake.sizeArgsForCall)
+}
+
+func (fake *FakeHTTPBlobProvider) SizeCalls(stub func(string, map[string]string) (int64, error)) {
+	fake.sizeMutex.Lock()
+	defer fake.sizeMutex.Unlock()
+	fake.SizeStub = stub
+}
+
+func (fake *FakeHTTPBlobProvider) SizeArgsForCall(i int) (string, map[string]string) {
+	fake.sizeMutex.RLock()
+	defer fake.sizeMutex.RUnlock()
+	argsForCall := fake.sizeArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeHTTPBlobProvider) SizeReturns(result1 int64, result2 error) {
+	fake.sizeMutex.Lock()
+	defer fake.sizeMutex.Unlock()
+	fake.SizeStub = nil
+	fake.sizeReturns = struct {
+		result1 int64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeHTTPBlobProvider) SizeReturnsOnCall(i int, result1 int64, result2 error) {
+	fake.sizeMutex.Lock()
+	defer fake.sizeMutex.Unlock()
+	fake.SizeStub = nil
+	if fake.sizeReturnsOnCall == nil {
+		fake.sizeReturnsOnCall = make(map[int]struct {
+			result1 int64
+			result2 error
+		})
+	}
+	fake.sizeReturnsOnCall[i] = struct {
+		result1 int64
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeHTTPBlobProvider) Upload(arg1 string, arg2 string, arg3 map[string]string) (crypto.MultipleDigest, error) {
 	fake.uploadMutex.Lock()
 	ret, specificReturn := fake.uploadReturnsOnCall[len(fake.uploadArgsForCall)]
@@ -173,13 +498,94 @@ func (fake *FakeHTTPBlobProvider) UploadReturnsOnCall(i int, result1 crypto.Mult
 	}{result1, result2}
 }
 
+func (fake *FakeHTTPBlobProvider) UploadWithDigestAlgorithms(arg1 string, arg2 string, arg3 map[string]string, arg4 []crypto.Algorithm) (crypto.MultipleDigest, error) {
+	var arg4Copy []crypto.Algorithm
+	if arg4 != nil {
+		arg4Copy = make([]crypto.Algorithm, len(arg4))
+		copy(arg4Copy, arg4)
+	}
+	fake.uploadWithDigestAlgorithmsMutex.Lock()
+	ret, specificReturn := fake.uploadWithDigestAlgorithmsReturnsOnCall[len(fake.uploadWithDigestAlgorithmsArgsForCall)]
+	fake.uploadWithDigestAlgorithmsArgsForCall = append(fake.uploadWithDigestAlgorithmsArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 map[string]string
+		arg4 []crypto.Algorithm
+	}{arg1, arg2, arg3, arg4Copy})
+	fake.recordInvocation("UploadWithDigestAlgorithms", []interface{}{arg1, arg2, arg3, arg4Copy})
+	fake.uploadWithDigestAlgorithmsMutex.Unlock()
+	if fake.UploadWithDigestAlgorithmsStub != nil {
+		return fake.UploadWithDigestAlgorithmsStub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.uploadWithDigestAlgorithmsReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeHTTPBlobProvider) UploadWithDigestAlgorithmsCallCount() int {
+	fake.uploadWithDigestAlgorithmsMutex.RLock()
+	defer fake.uploadWithDigestAlgorithmsMutex.RUnlock()
+	return len(fake.uploadWithDigestAlgorithmsArgsForCall)
+}
+
+func (fake *FakeHTTPBlobProvider) UploadWithDigestAlgorithmsCalls(stub func(string, string, map[string]string, []crypto.Algorithm) (crypto.MultipleDigest, error)) {
+	fake.uploadWithDigestAlgorithmsMutex.Lock()
+	defer fake.uploadWithDigestAlgorithmsMutex.Unlock()
+	fake.UploadWithDigestAlgorithmsStub = stub
+}
+
+func (fake *FakeHTTPBlobProvider) UploadWithDigestAlgorithmsArgsForCall(i int) (string, string, map[string]string, []crypto.Algorithm) {
+	fake.uploadWithDigestAlgorithmsMutex.RLock()
+	defer fake.uploadWithDigestAlgorithmsMutex.RUnlock()
+	argsForCall := fake.uploadWithDigestAlgorithmsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeHTTPBlobProvider) UploadWithDigestAlgorithmsReturns(result1 crypto.MultipleDigest, result2 error) {
+	fake.uploadWithDigestAlgorithmsMutex.Lock()
+	defer fake.uploadWithDigestAlgorithmsMutex.Unlock()
+	fake.UploadWithDigestAlgorithmsStub = nil
+	fake.uploadWithDigestAlgorithmsReturns = struct {
+		result1 crypto.MultipleDigest
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeHTTPBlobProvider) UploadWithDigestAlgorithmsReturnsOnCall(i int, result1 crypto.MultipleDigest, result2 error) {
+	fake.uploadWithDigestAlgorithmsMutex.Lock()
+	defer fake.uploadWithDigestAlgorithmsMutex.Unlock()
+	fake.UploadWithDigestAlgorithmsStub = nil
+	if fake.uploadWithDigestAlgorithmsReturnsOnCall == nil {
+		fake.uploadWithDigestAlgorithmsReturnsOnCall = make(map[int]struct {
+			result1 crypto.MultipleDigest
+			result2 error
+		})
+	}
+	fake.uploadWithDigestAlgorithmsReturnsOnCall[i] = struct {
+		result1 crypto.MultipleDigest
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeHTTPBlobProvider) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.deleteMutex.RLock()
+	defer fake.deleteMutex.RUnlock()
+	fake.existsMutex.RLock()
+	defer fake.existsMutex.RUnlock()
 	fake.getMutex.RLock()
 	defer fake.getMutex.RUnlock()
+	fake.matchesMutex.RLock()
+	defer fake.matchesMutex.RUnlock()
+	fake.sizeMutex.RLock()
+	defer fake.sizeMutex.RUnlock()
 	fake.uploadMutex.RLock()
 	defer fake.uploadMutex.RUnlock()
+	fake.uploadWithDigestAlgorithmsMutex.RLock()
+	defer fake.uploadWithDigestAlgorithmsMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -15,3 +15,21 @@ func BuildCommand(path string) boshsys.Command {
 		},
 	}
 }
+
+// BuildCommandAsUser drops privileges with chpst (shipped with runit on stemcells)
+func BuildCommandAsUser(path string, user string) (boshsys.Command, error) {
+	return boshsys.Command{
+		Name: "chpst",
+		Args: []string{"-u", user, path},
+		Env: map[string]string{
+			"PATH": boshenv.Path(),
+		},
+	}, nil
+}
+
+// IsolateEnv makes the command see only its own Env instead of the agent's
+// whole environment merged with it
+func IsolateEnv(command boshsys.Command) (boshsys.Command, error) {
+	command.UseIsolatedEnv = true
+	return command, nil
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	boshenv "github.com/cloudfoundry/bosh-agent/agent/script/pathenv"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshsys "github.com/cloudfoundry/bosh-utils/system"
 )
 
@@ -14,3 +15,11 @@ func BuildCommand(path string) boshsys.Command {
 		},
 	}
 }
+
+func BuildCommandAsUser(path string, user string) (boshsys.Command, error) {
+	return boshsys.Command{}, bosherr.Error("Running scripts as another user is not supported on Windows")
+}
+
+func IsolateEnv(command boshsys.Command) (boshsys.Command, error) {
+	return boshsys.Command{}, bosherr.Error("Filtering the environment of scripts is not supported on Windows")
+}
@@ -0,0 +1,44 @@
+package cmdfakes
+
+import (
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+)
+
+// FakePidCmdRunner starts the processes added to the embedded FakeCmdRunner
+// and reports Pid as their pid
+type FakePidCmdRunner struct {
+	*fakesys.FakeCmdRunner
+
+	Pid int
+
+	// Called when a started process is waited on, i.e. while it is running
+	WaitCallback func()
+}
+
+func NewFakePidCmdRunner() *FakePidCmdRunner {
+	return &FakePidCmdRunner{FakeCmdRunner: fakesys.NewFakeCmdRunner()}
+}
+
+func (r *FakePidCmdRunner) RunComplexCommandAsyncWithPid(command boshsys.Command) (boshsys.Process, int, error) {
+	process, err := r.RunComplexCommandAsync(command)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return fakeProcess{Process: process, waitCallback: r.WaitCallback}, r.Pid, nil
+}
+
+type fakeProcess struct {
+	boshsys.Process
+
+	waitCallback func()
+}
+
+func (p fakeProcess) Wait() <-chan boshsys.Result {
+	if p.waitCallback != nil {
+		p.waitCallback()
+	}
+
+	return p.Process.Wait()
+}
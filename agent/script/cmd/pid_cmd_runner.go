@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+// PidCmdRunner is a CmdRunner that also reports the pid of the commands it
+// starts asynchronously, e.g. for writing the pid files of scripts
+type PidCmdRunner interface {
+	boshsys.CmdRunner
+
+	RunComplexCommandAsyncWithPid(command boshsys.Command) (boshsys.Process, int, error)
+}
+
+type pidCmdRunner struct {
+	boshsys.CmdRunner
+
+	logger boshlog.Logger
+}
+
+func NewPidCmdRunner(runner boshsys.CmdRunner, logger boshlog.Logger) PidCmdRunner {
+	return pidCmdRunner{CmdRunner: runner, logger: logger}
+}
+
+// RunComplexCommandAsyncWithPid starts the command like the exec runner of
+// bosh-utils but keeps hold of the started process to learn its pid
+func (r pidCmdRunner) RunComplexCommandAsyncWithPid(command boshsys.Command) (boshsys.Process, int, error) {
+	execCmd := exec.Command(command.Name, command.Args...)
+
+	if command.Stdin != nil {
+		execCmd.Stdin = command.Stdin
+	}
+
+	if command.Stdout != nil {
+		execCmd.Stdout = command.Stdout
+	}
+
+	if command.Stderr != nil {
+		execCmd.Stderr = command.Stderr
+	}
+
+	execCmd.Dir = command.WorkingDir
+	execCmd.Env = commandEnv(command)
+
+	process := boshsys.NewExecProcess(execCmd, command.KeepAttached, command.Quiet, r.logger)
+
+	err := process.Start()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return process, execCmd.Process.Pid, nil
+}
+
+// commandEnv lets the command's own variables override inherited ones
+func commandEnv(command boshsys.Command) []string {
+	var env []string
+
+	for key, val := range command.Env {
+		env = append(env, key+"="+val)
+	}
+
+	if command.UseIsolatedEnv {
+		return env
+	}
+
+	for _, keyVal := range os.Environ() {
+		if n := strings.IndexByte(keyVal, '='); n != -1 {
+			if _, found := command.Env[keyVal[:n]]; !found {
+				env = append(env, keyVal)
+			}
+		}
+	}
+
+	return env
+}
@@ -7,6 +7,7 @@ import (
 
 	"code.cloudfoundry.org/clock"
 
+	"github.com/cloudfoundry/bosh-agent/agent/script/cmd"
 	boshdrain "github.com/cloudfoundry/bosh-agent/agent/script/drain"
 	boshdir "github.com/cloudfoundry/bosh-agent/settings/directories"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
@@ -14,7 +15,7 @@ import (
 )
 
 type ConcreteJobScriptProvider struct {
-	cmdRunner   boshsys.CmdRunner
+	cmdRunner   cmd.PidCmdRunner
 	fs          boshsys.FileSystem
 	dirProvider boshdir.Provider
 	timeService clock.Clock
@@ -29,7 +30,7 @@ func NewConcreteJobScriptProvider(
 	logger boshlog.Logger,
 ) ConcreteJobScriptProvider {
 	return ConcreteJobScriptProvider{
-		cmdRunner:   cmdRunner,
+		cmdRunner:   cmd.NewPidCmdRunner(cmdRunner, logger),
 		fs:          fs,
 		dirProvider: dirProvider,
 		timeService: timeService,
@@ -37,7 +38,7 @@ func NewConcreteJobScriptProvider(
 	}
 }
 
-func (p ConcreteJobScriptProvider) NewScript(jobName string, scriptName string, scriptEnv map[string]string) Script {
+func (p ConcreteJobScriptProvider) NewScript(jobName string, scriptName string, scriptEnv map[string]string, opts Options) Script {
 	path := path.Join(p.dirProvider.JobBinDir(jobName), scriptName+ScriptExt)
 
 	stdoutLogFilename := fmt.Sprintf("%s.stdout.log", scriptName)
@@ -46,7 +47,7 @@ func (p ConcreteJobScriptProvider) NewScript(jobName string, scriptName string,
 	stderrLogFilename := fmt.Sprintf("%s.stderr.log", scriptName)
 	stderrLogPath := filepath.Join(p.dirProvider.LogsDir(), jobName, stderrLogFilename)
 
-	return NewScript(p.fs, p.cmdRunner, jobName, path, stdoutLogPath, stderrLogPath, scriptEnv)
+	return NewScript(p.fs, p.cmdRunner, jobName, path, stdoutLogPath, stderrLogPath, scriptEnv, opts)
 }
 
 func (p ConcreteJobScriptProvider) NewDrainScript(jobName string, params boshdrain.ScriptParams) CancellableScript {
@@ -55,6 +56,12 @@ func (p ConcreteJobScriptProvider) NewDrainScript(jobName string, params boshdra
 	return boshdrain.NewConcreteScript(p.fs, p.cmdRunner, jobName, path, params, p.timeService, p.logger)
 }
 
-func (p ConcreteJobScriptProvider) NewParallelScript(scriptName string, scripts []Script) CancellableScript {
-	return NewParallelScript(scriptName, scripts, p.logger)
+// NewHealthScript returns the job's health check script, which may be
+// run repeatedly by monitoring without any custom env
+func (p ConcreteJobScriptProvider) NewHealthScript(jobName string) Script {
+	return p.NewScript(jobName, "health_check", map[string]string{}, Options{})
+}
+
+func (p ConcreteJobScriptProvider) NewParallelScript(scriptName string, scripts []Script, maxConcurrency int, dependsOn map[string][]string) CancellableScript {
+	return NewParallelScript(scriptName, scripts, maxConcurrency, dependsOn, p.logger)
 }
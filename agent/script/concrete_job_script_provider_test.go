@@ -9,6 +9,7 @@ import (
 	boshdrain "github.com/cloudfoundry/bosh-agent/agent/script/drain"
 	"github.com/cloudfoundry/bosh-agent/agent/script/drain/drainfakes"
 	"github.com/cloudfoundry/bosh-agent/agent/script/scriptfakes"
+	fakefs "github.com/cloudfoundry/bosh-agent/platform/fakefs"
 	boshdir "github.com/cloudfoundry/bosh-agent/settings/directories"
 	boshassert "github.com/cloudfoundry/bosh-utils/assert"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
@@ -24,7 +25,7 @@ var _ = Describe("ConcreteJobScriptProvider", func() {
 
 	BeforeEach(func() {
 		runner := fakesys.NewFakeCmdRunner()
-		fs := fakesys.NewFakeFileSystem()
+		fs := fakefs.NewFakeFileSystem()
 		dirProvider := boshdir.NewProvider("/the/base/dir")
 		logger = boshlog.NewLogger(boshlog.LevelNone)
 		scriptProvider = boshscript.NewConcreteJobScriptProvider(
@@ -38,7 +39,7 @@ var _ = Describe("ConcreteJobScriptProvider", func() {
 
 	Describe("NewScript", func() {
 		It("returns script with relative job paths to the base directory", func() {
-			script := scriptProvider.NewScript("myjob", "the-best-hook-ever", scriptEnv)
+			script := scriptProvider.NewScript("myjob", "the-best-hook-ever", scriptEnv, boshscript.Options{})
 			Expect(script.Tag()).To(Equal("myjob"))
 
 			expPath := "/the/base/dir/jobs/myjob/bin/the-best-hook-ever" + boshscript.ScriptExt
@@ -58,11 +59,22 @@ var _ = Describe("ConcreteJobScriptProvider", func() {
 		})
 	})
 
+	Describe("NewHealthScript", func() {
+		It("returns health check script", func() {
+			script := scriptProvider.NewHealthScript("foo")
+			Expect(script.Tag()).To(Equal("foo"))
+
+			expPath := "/the/base/dir/jobs/foo/bin/health_check" + boshscript.ScriptExt
+			Expect(script.Path()).To(boshassert.MatchPath(expPath))
+		})
+	})
+
 	Describe("NewParallelScript", func() {
 		It("returns parallel script", func() {
 			scripts := []boshscript.Script{&scriptfakes.FakeScript{}}
-			script := scriptProvider.NewParallelScript("foo", scripts)
-			Expect(script).To(Equal(boshscript.NewParallelScript("foo", scripts, logger)))
+			dependsOn := map[string][]string{"job-2": {"job-1"}}
+			script := scriptProvider.NewParallelScript("foo", scripts, 2, dependsOn)
+			Expect(script).To(Equal(boshscript.NewParallelScript("foo", scripts, 2, dependsOn, logger)))
 		})
 	})
 })
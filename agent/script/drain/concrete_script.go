@@ -66,7 +66,7 @@ func (s ConcreteScript) Run() error {
 		if err != nil {
 			return err
 		} else if value < 0 {
-			s.timeService.Sleep(time.Duration(-value) * time.Second)
+			s.timeService.Sleep(s.pollInterval(params, time.Duration(-value)*time.Second))
 			params = params.ToStatusParams()
 		} else {
 			s.timeService.Sleep(time.Duration(value) * time.Second)
@@ -75,6 +75,15 @@ func (s ConcreteScript) Run() error {
 	}
 }
 
+func (s ConcreteScript) pollInterval(params ScriptParams, requested time.Duration) time.Duration {
+	interval := params.PollIntervalRange().Clamp(requested)
+	if interval != requested {
+		s.logger.Info(s.logTag, "Clamping status poll interval of %s requested by drain script '%s' to %s", requested, s.path, interval)
+	}
+
+	return interval
+}
+
 func (s ConcreteScript) Cancel() error {
 	select {
 	case s.cancelCh <- struct{}{}:
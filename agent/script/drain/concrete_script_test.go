@@ -82,9 +82,11 @@ var _ = Describe("ConcreteScript", func() {
 	})
 
 	Describe("Run", func() {
+		var oldSpec, newSpec applyspec.V1ApplySpec
+
 		BeforeEach(func() {
-			oldSpec := exampleSpec()
-			newSpec := exampleSpec()
+			oldSpec = exampleSpec()
+			newSpec = exampleSpec()
 
 			s := newSpec.PackageSpecs["foo"]
 			s.Sha1 = crypto.MustParseMultipleDigest("sha1:fooupdatedsha1")
@@ -94,7 +96,7 @@ var _ = Describe("ConcreteScript", func() {
 			s.Sha1 = crypto.MustParseMultipleDigest("sha1:barupdatedsha1")
 			newSpec.PackageSpecs["bar"] = s
 
-			params = NewUpdateParams(oldSpec, newSpec)
+			params = NewUpdateParams(oldSpec, newSpec, PollIntervalRange{})
 		})
 
 		It("runs drain script", func() {
@@ -170,6 +172,58 @@ var _ = Describe("ConcreteScript", func() {
 			Expect(fakeClock.SleepArgsForCall(3)).To(Equal(0 * time.Second))
 		})
 
+		Context("when a status poll interval range is set", func() {
+			BeforeEach(func() {
+				params = NewUpdateParams(oldSpec, newSpec, PollIntervalRange{
+					Min: 10 * time.Second,
+					Max: 60 * time.Second,
+				})
+			})
+
+			It("waits at least the minimum before checking the status again", func() {
+				runner.AddProcess(jobChangedFullCommand,
+					&fakesys.FakeProcess{WaitResult: boshsys.Result{Stdout: "-2"}})
+				runner.AddProcess(jobCheckStatusFullCommand,
+					&fakesys.FakeProcess{WaitResult: boshsys.Result{Stdout: "3"}})
+
+				err := script.Run()
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(fakeClock.SleepCallCount()).To(Equal(2))
+				Expect(fakeClock.SleepArgsForCall(0)).To(Equal(10 * time.Second))
+				Expect(fakeClock.SleepArgsForCall(1)).To(Equal(3 * time.Second))
+			})
+
+			It("waits as long as requested within the range", func() {
+				runner.AddProcess(jobChangedFullCommand,
+					&fakesys.FakeProcess{WaitResult: boshsys.Result{Stdout: "-30"}})
+				runner.AddProcess(jobCheckStatusFullCommand,
+					&fakesys.FakeProcess{WaitResult: boshsys.Result{Stdout: "0"}})
+
+				err := script.Run()
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(fakeClock.SleepCallCount()).To(Equal(2))
+				Expect(fakeClock.SleepArgsForCall(0)).To(Equal(30 * time.Second))
+			})
+
+			It("waits at most the maximum before checking the status again", func() {
+				runner.AddProcess(jobChangedFullCommand,
+					&fakesys.FakeProcess{WaitResult: boshsys.Result{Stdout: "-300"}})
+				runner.AddProcess(jobCheckStatusFullCommand,
+					&fakesys.FakeProcess{WaitResult: boshsys.Result{Stdout: "-120"}})
+				runner.AddProcess(jobCheckStatusFullCommand,
+					&fakesys.FakeProcess{WaitResult: boshsys.Result{Stdout: "0"}})
+
+				err := script.Run()
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(fakeClock.SleepCallCount()).To(Equal(3))
+				Expect(fakeClock.SleepArgsForCall(0)).To(Equal(60 * time.Second))
+				Expect(fakeClock.SleepArgsForCall(1)).To(Equal(60 * time.Second))
+			})
+		})
+
 		It("ignores whitespace in stdout", func() {
 			runner.AddProcess(jobChangedFullCommand,
 				&fakesys.FakeProcess{WaitResult: boshsys.Result{Stdout: "-56\n"}})
@@ -250,7 +304,7 @@ var _ = Describe("ConcreteScript", func() {
 
 			Context("when job next state is empty", func() {
 				BeforeEach(func() {
-					params = NewShutdownParams(exampleSpec(), nil)
+					params = NewShutdownParams(exampleSpec(), nil, PollIntervalRange{})
 
 					runner.AddProcess(jobShutdownFullCommand,
 						&fakesys.FakeProcess{WaitResult: boshsys.Result{Stdout: "1"}})
@@ -296,7 +350,7 @@ var _ = Describe("ConcreteScript", func() {
 			s.Sha1 = crypto.MustParseMultipleDigest("sha1:barupdatedsha1")
 			newSpec.PackageSpecs["bar"] = s
 
-			params = NewUpdateParams(oldSpec, newSpec)
+			params = NewUpdateParams(oldSpec, newSpec, PollIntervalRange{})
 		})
 
 		It("succeeds", func() {
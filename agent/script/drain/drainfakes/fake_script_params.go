@@ -52,6 +52,16 @@ type FakeScriptParams struct {
 		result1 string
 		result2 error
 	}
+	PollIntervalRangeStub        func() drain.PollIntervalRange
+	pollIntervalRangeMutex       sync.RWMutex
+	pollIntervalRangeArgsForCall []struct {
+	}
+	pollIntervalRangeReturns struct {
+		result1 drain.PollIntervalRange
+	}
+	pollIntervalRangeReturnsOnCall map[int]struct {
+		result1 drain.PollIntervalRange
+	}
 	ToStatusParamsStub        func() drain.ScriptParams
 	toStatusParamsMutex       sync.RWMutex
 	toStatusParamsArgsForCall []struct {
@@ -290,6 +300,58 @@ func (fake *FakeScriptParams) JobStateReturnsOnCall(i int, result1 string, resul
 	}{result1, result2}
 }
 
+func (fake *FakeScriptParams) PollIntervalRange() drain.PollIntervalRange {
+	fake.pollIntervalRangeMutex.Lock()
+	ret, specificReturn := fake.pollIntervalRangeReturnsOnCall[len(fake.pollIntervalRangeArgsForCall)]
+	fake.pollIntervalRangeArgsForCall = append(fake.pollIntervalRangeArgsForCall, struct {
+	}{})
+	fake.recordInvocation("PollIntervalRange", []interface{}{})
+	fake.pollIntervalRangeMutex.Unlock()
+	if fake.PollIntervalRangeStub != nil {
+		return fake.PollIntervalRangeStub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.pollIntervalRangeReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeScriptParams) PollIntervalRangeCallCount() int {
+	fake.pollIntervalRangeMutex.RLock()
+	defer fake.pollIntervalRangeMutex.RUnlock()
+	return len(fake.pollIntervalRangeArgsForCall)
+}
+
+func (fake *FakeScriptParams) PollIntervalRangeCalls(stub func() drain.PollIntervalRange) {
+	fake.pollIntervalRangeMutex.Lock()
+	defer fake.pollIntervalRangeMutex.Unlock()
+	fake.PollIntervalRangeStub = stub
+}
+
+func (fake *FakeScriptParams) PollIntervalRangeReturns(result1 drain.PollIntervalRange) {
+	fake.pollIntervalRangeMutex.Lock()
+	defer fake.pollIntervalRangeMutex.Unlock()
+	fake.PollIntervalRangeStub = nil
+	fake.pollIntervalRangeReturns = struct {
+		result1 drain.PollIntervalRange
+	}{result1}
+}
+
+func (fake *FakeScriptParams) PollIntervalRangeReturnsOnCall(i int, result1 drain.PollIntervalRange) {
+	fake.pollIntervalRangeMutex.Lock()
+	defer fake.pollIntervalRangeMutex.Unlock()
+	fake.PollIntervalRangeStub = nil
+	if fake.pollIntervalRangeReturnsOnCall == nil {
+		fake.pollIntervalRangeReturnsOnCall = make(map[int]struct {
+			result1 drain.PollIntervalRange
+		})
+	}
+	fake.pollIntervalRangeReturnsOnCall[i] = struct {
+		result1 drain.PollIntervalRange
+	}{result1}
+}
+
 func (fake *FakeScriptParams) ToStatusParams() drain.ScriptParams {
 	fake.toStatusParamsMutex.Lock()
 	ret, specificReturn := fake.toStatusParamsReturnsOnCall[len(fake.toStatusParamsArgsForCall)]
@@ -405,6 +467,8 @@ func (fake *FakeScriptParams) Invocations() map[string][][]interface{} {
 	defer fake.jobNextStateMutex.RUnlock()
 	fake.jobStateMutex.RLock()
 	defer fake.jobStateMutex.RUnlock()
+	fake.pollIntervalRangeMutex.RLock()
+	defer fake.pollIntervalRangeMutex.RUnlock()
 	fake.toStatusParamsMutex.RLock()
 	defer fake.toStatusParamsMutex.RUnlock()
 	fake.updatedPackagesMutex.RLock()
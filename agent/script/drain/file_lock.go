@@ -0,0 +1,182 @@
+package drain
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+const fileLockPollInterval = 1 * time.Second
+
+// FileLock serializes drains through a lock file that records the pid
+// of its holder and when it was acquired so that a lock left behind by
+// a holder that died can be reclaimed
+type FileLock struct {
+	fs               boshsys.FileSystem
+	path             string
+	pid              int
+	isProcessRunning func(pid int) bool
+	timeService      clock.Clock
+
+	logTag string
+	logger boshlog.Logger
+}
+
+func NewFileLock(
+	fs boshsys.FileSystem,
+	path string,
+	pid int,
+	isProcessRunning func(pid int) bool,
+	timeService clock.Clock,
+	logger boshlog.Logger,
+) FileLock {
+	return FileLock{
+		fs:               fs,
+		path:             path,
+		pid:              pid,
+		isProcessRunning: isProcessRunning,
+		timeService:      timeService,
+
+		logTag: "DrainFileLock",
+		logger: logger,
+	}
+}
+
+// Lock blocks until the lock is acquired or cancelCh is signalled. A lock
+// held for longer than staleAfter by a process that is no longer running is
+// considered abandoned and is taken over.
+func (l FileLock) Lock(staleAfter time.Duration, cancelCh <-chan struct{}) error {
+	for {
+		acquired, err := l.tryLock(staleAfter)
+		if err != nil {
+			return err
+		}
+
+		if acquired {
+			return nil
+		}
+
+		l.logger.Debug(l.logTag, "Waiting for lock '%s'", l.path)
+
+		select {
+		case <-l.timeService.After(fileLockPollInterval):
+		case <-cancelCh:
+			return bosherr.Errorf("Waiting for lock '%s' was cancelled", l.path)
+		}
+	}
+}
+
+func (l FileLock) Unlock() error {
+	err := l.fs.RemoveAll(l.path)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Removing lock file '%s'", l.path)
+	}
+
+	return nil
+}
+
+func (l FileLock) tryLock(staleAfter time.Duration) (bool, error) {
+	if l.fs.FileExists(l.path) {
+		if !l.isStale(l.path, staleAfter) {
+			return false, nil
+		}
+
+		reclaimed, err := l.reclaim(staleAfter)
+		if err != nil || !reclaimed {
+			return false, err
+		}
+	}
+
+	file, err := l.fs.OpenFile(l.path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, os.FileMode(0644))
+	if err != nil {
+		if os.IsExist(err) {
+			return false, nil
+		}
+		return false, bosherr.WrapErrorf(err, "Creating lock file '%s'", l.path)
+	}
+
+	defer file.Close()
+
+	_, err = fmt.Fprintf(file, "%d %s", l.pid, l.timeService.Now().Format(time.RFC3339Nano))
+	if err != nil {
+		return false, bosherr.WrapErrorf(err, "Writing lock file '%s'", l.path)
+	}
+
+	return true, nil
+}
+
+// reclaim moves a stale lock file out of the way instead of removing it so
+// that only one waiter can take it over. The moved file is checked again
+// because a new holder may have replaced the lock after it was found stale.
+func (l FileLock) reclaim(staleAfter time.Duration) (bool, error) {
+	stalePath := fmt.Sprintf("%s.stale-%d", l.path, l.timeService.Now().UnixNano())
+
+	err := l.fs.Rename(l.path, stalePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// Another waiter reclaimed the lock or its holder released it
+			return false, nil
+		}
+		return false, bosherr.WrapErrorf(err, "Moving stale lock file '%s'", l.path)
+	}
+
+	if !l.isStale(stalePath, staleAfter) {
+		if !l.fs.FileExists(l.path) {
+			err = l.fs.Rename(stalePath, l.path)
+			if err != nil {
+				return false, bosherr.WrapErrorf(err, "Restoring lock file '%s'", l.path)
+			}
+		}
+
+		return false, nil
+	}
+
+	l.logger.Info(l.logTag, "Reclaiming stale lock '%s'", l.path)
+
+	err = l.fs.RemoveAll(stalePath)
+	if err != nil {
+		return false, bosherr.WrapErrorf(err, "Removing stale lock file '%s'", stalePath)
+	}
+
+	return true, nil
+}
+
+func (l FileLock) isStale(path string, staleAfter time.Duration) bool {
+	contents, err := l.fs.ReadFileString(path)
+	if err != nil {
+		// Lock may have been released in the meantime
+		return false
+	}
+
+	var acquiredAt time.Time
+
+	fields := strings.Fields(contents)
+	if len(fields) == 2 {
+		pid, pidErr := strconv.Atoi(fields[0])
+		if pidErr == nil && l.isProcessRunning(pid) {
+			return false
+		}
+
+		acquiredAt, err = time.Parse(time.RFC3339Nano, fields[1])
+	}
+
+	if len(fields) != 2 || err != nil {
+		// Lock file is empty or was left half written by a holder that
+		// died before recording itself, so it is aged by its mtime
+		info, err := l.fs.Stat(path)
+		if err != nil {
+			return false
+		}
+
+		acquiredAt = info.ModTime()
+	}
+
+	return l.timeService.Now().Sub(acquiredAt) >= staleAfter
+}
@@ -0,0 +1,202 @@
+package drain_test
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-agent/agent/script/drain"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+)
+
+var _ = Describe("FileLock", func() {
+	var (
+		fs          *fakesys.FakeFileSystem
+		fakeClock   *fakeclock.FakeClock
+		runningPids map[int]bool
+		runningMu   sync.Mutex
+		lockA       FileLock
+		lockB       FileLock
+	)
+
+	BeforeEach(func() {
+		fs = fakesys.NewFakeFileSystem()
+		Expect(fs.MkdirAll("/fake", 0755)).To(Succeed())
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		runningPids = map[int]bool{}
+		isProcessRunning := func(pid int) bool {
+			runningMu.Lock()
+			defer runningMu.Unlock()
+			return runningPids[pid]
+		}
+		logger := boshlog.NewLogger(boshlog.LevelNone)
+		lockA = NewFileLock(fs, "/fake/drain.lock", 1001, isProcessRunning, fakeClock, logger)
+		lockB = NewFileLock(fs, "/fake/drain.lock", 1002, isProcessRunning, fakeClock, logger)
+	})
+
+	It("creates the lock file when acquired and removes it when released", func() {
+		err := lockA.Lock(time.Minute, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		contents, err := fs.ReadFileString("/fake/drain.lock")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(contents).To(Equal("1001 " + fakeClock.Now().Format(time.RFC3339Nano)))
+
+		err = lockA.Unlock()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fs.FileExists("/fake/drain.lock")).To(BeFalse())
+	})
+
+	It("waits for the current holder to release the lock", func() {
+		err := lockA.Lock(time.Minute, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		acquiredCh := make(chan error, 1)
+		go func() { acquiredCh <- lockB.Lock(time.Minute, nil) }()
+
+		Eventually(fakeClock.WatcherCount).Should(Equal(1))
+		Consistently(acquiredCh).ShouldNot(Receive())
+
+		err = lockA.Unlock()
+		Expect(err).ToNot(HaveOccurred())
+
+		fakeClock.Increment(time.Second)
+		Eventually(acquiredCh).Should(Receive(BeNil()))
+		Expect(fs.FileExists("/fake/drain.lock")).To(BeTrue())
+	})
+
+	It("reclaims a lock that has been held for longer than the stale timeout by a process that died", func() {
+		err := lockA.Lock(time.Minute, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		fakeClock.Increment(time.Minute)
+
+		err = lockB.Lock(time.Minute, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		contents, err := fs.ReadFileString("/fake/drain.lock")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(contents).To(Equal("1002 " + fakeClock.Now().Format(time.RFC3339Nano)))
+	})
+
+	It("does not reclaim a lock held by a running process however long it is held", func() {
+		runningPids[1001] = true
+
+		err := lockA.Lock(time.Minute, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		fakeClock.Increment(time.Hour)
+
+		acquiredCh := make(chan error, 1)
+		go func() { acquiredCh <- lockB.Lock(time.Minute, nil) }()
+
+		Eventually(fakeClock.WatcherCount).Should(Equal(1))
+		Consistently(acquiredCh).ShouldNot(Receive())
+		Expect(fs.RenameOldPaths).To(BeEmpty())
+
+		runningMu.Lock()
+		runningPids[1001] = false
+		runningMu.Unlock()
+
+		fakeClock.Increment(time.Second)
+		Eventually(acquiredCh).Should(Receive(BeNil()))
+	})
+
+	It("moves a stale lock aside before taking it over", func() {
+		err := lockA.Lock(time.Minute, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		fakeClock.Increment(time.Minute)
+
+		err = lockB.Lock(time.Minute, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(fs.RenameOldPaths).To(Equal([]string{"/fake/drain.lock"}))
+		Expect(fs.RenameNewPaths).To(HaveLen(1))
+		Expect(fs.FileExists(fs.RenameNewPaths[0])).To(BeFalse())
+	})
+
+	It("leaves a lock in place that was replaced after it was found stale", func() {
+		err := lockA.Lock(time.Minute, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		fakeClock.Increment(time.Minute)
+
+		lockStats := fs.GetFileTestStat("/fake/drain.lock")
+		fs.RenameStub = func(_, _ string) error {
+			fs.RenameStub = nil
+			// Another waiter reclaims the lock between the staleness check and the rename
+			lockStats.Content = []byte("1003 " + fakeClock.Now().Format(time.RFC3339Nano))
+			return nil
+		}
+
+		acquiredCh := make(chan error, 1)
+		go func() { acquiredCh <- lockB.Lock(time.Minute, nil) }()
+
+		Eventually(fakeClock.WatcherCount).Should(Equal(1))
+		Consistently(acquiredCh).ShouldNot(Receive())
+
+		contents, err := fs.ReadFileString("/fake/drain.lock")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(contents).To(Equal("1003 " + fakeClock.Now().Format(time.RFC3339Nano)))
+	})
+
+	Context("when the lock file does not record its holder", func() {
+		BeforeEach(func() {
+			err := fs.WriteFileString("/fake/drain.lock", "")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("reclaims the lock once its mtime is older than the stale timeout", func() {
+			fs.GetFileTestStat("/fake/drain.lock").ModTime = fakeClock.Now().Add(-time.Minute)
+
+			err := lockA.Lock(time.Minute, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			contents, err := fs.ReadFileString("/fake/drain.lock")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(contents).To(Equal("1001 " + fakeClock.Now().Format(time.RFC3339Nano)))
+		})
+
+		It("waits while its mtime is within the stale timeout", func() {
+			fs.GetFileTestStat("/fake/drain.lock").ModTime = fakeClock.Now()
+
+			acquiredCh := make(chan error, 1)
+			go func() { acquiredCh <- lockA.Lock(time.Minute, nil) }()
+
+			Eventually(fakeClock.WatcherCount).Should(Equal(1))
+			Consistently(acquiredCh).ShouldNot(Receive())
+
+			fakeClock.Increment(time.Minute)
+			Eventually(acquiredCh).Should(Receive(BeNil()))
+		})
+	})
+
+	It("stops waiting when cancelled", func() {
+		err := lockA.Lock(time.Minute, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		cancelCh := make(chan struct{})
+		acquiredCh := make(chan error, 1)
+		go func() { acquiredCh <- lockB.Lock(time.Minute, cancelCh) }()
+
+		Eventually(fakeClock.WatcherCount).Should(Equal(1))
+		close(cancelCh)
+
+		var lockErr error
+		Eventually(acquiredCh).Should(Receive(&lockErr))
+		Expect(lockErr).To(HaveOccurred())
+		Expect(lockErr.Error()).To(Equal("Waiting for lock '/fake/drain.lock' was cancelled"))
+	})
+
+	Describe("IsProcessRunning", func() {
+		It("reports the current process as running", func() {
+			Expect(IsProcessRunning(os.Getpid())).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,13 @@
+// +build !windows
+
+package drain
+
+import (
+	"syscall"
+)
+
+// IsProcessRunning reports whether a process with the given pid exists
+func IsProcessRunning(pid int) bool {
+	err := syscall.Kill(pid, syscall.Signal(0))
+	return err == nil || err == syscall.EPERM
+}
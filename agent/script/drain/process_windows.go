@@ -0,0 +1,23 @@
+package drain
+
+import (
+	"golang.org/x/sys/windows"
+)
+
+// stillActive is the exit code reported for processes that did not exit yet
+const stillActive = 259
+
+// IsProcessRunning reports whether a process with the given pid exists
+func IsProcessRunning(pid int) bool {
+	handle, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		// Processes of other users cannot be opened but are still running
+		return err == windows.ERROR_ACCESS_DENIED
+	}
+
+	defer windows.CloseHandle(handle)
+
+	var exitCode uint32
+	err = windows.GetExitCodeProcess(handle, &exitCode)
+	return err != nil || exitCode == stillActive
+}
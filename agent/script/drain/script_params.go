@@ -2,6 +2,7 @@ package drain
 
 import (
 	"sort"
+	"time"
 
 	boshas "github.com/cloudfoundry/bosh-agent/agent/applier/applyspec"
 )
@@ -15,11 +16,37 @@ type ScriptParams interface {
 	JobState() (string, error)
 	JobNextState() (string, error)
 
+	// PollIntervalRange bounds how long to wait before checking the status of
+	// a dynamic drain script again
+	PollIntervalRange() PollIntervalRange
+
 	// ToStatusParams derives a new set of script params that can be used to do the
 	// status check call on a dynamic drain script.
 	ToStatusParams() ScriptParams
 }
 
+// PollIntervalRange keeps misbehaving dynamic drain scripts from making the
+// agent poll them too often or wait on them too long. Zero values leave the
+// respective bound out.
+type PollIntervalRange struct {
+	Min time.Duration
+	Max time.Duration
+}
+
+// Clamp returns interval limited to the range, Min taking precedence over Max
+// when they contradict each other
+func (r PollIntervalRange) Clamp(interval time.Duration) time.Duration {
+	if r.Max > 0 && interval > r.Max {
+		interval = r.Max
+	}
+
+	if interval < r.Min {
+		interval = r.Min
+	}
+
+	return interval
+}
+
 type concreteScriptParams struct {
 	jobChange       string
 	hashChange      string
@@ -27,25 +54,30 @@ type concreteScriptParams struct {
 
 	oldSpec boshas.V1ApplySpec
 	newSpec *boshas.V1ApplySpec
+
+	pollIntervalRange PollIntervalRange
 }
 
 func NewShutdownParams(
 	oldSpec boshas.V1ApplySpec,
 	newSpec *boshas.V1ApplySpec,
+	pollIntervalRange PollIntervalRange,
 ) ScriptParams {
 	return concreteScriptParams{
-		jobChange:       "job_shutdown",
-		hashChange:      "hash_unchanged",
-		updatedPackages: []string{},
-		oldSpec:         oldSpec,
-		newSpec:         newSpec,
+		jobChange:         "job_shutdown",
+		hashChange:        "hash_unchanged",
+		updatedPackages:   []string{},
+		oldSpec:           oldSpec,
+		newSpec:           newSpec,
+		pollIntervalRange: pollIntervalRange,
 	}
 }
 
-func NewUpdateParams(oldSpec, newSpec boshas.V1ApplySpec) ScriptParams {
+func NewUpdateParams(oldSpec, newSpec boshas.V1ApplySpec, pollIntervalRange PollIntervalRange) ScriptParams {
 	p := concreteScriptParams{
-		oldSpec: oldSpec,
-		newSpec: &newSpec,
+		oldSpec:           oldSpec,
+		newSpec:           &newSpec,
+		pollIntervalRange: pollIntervalRange,
 	}
 
 	switch {
@@ -90,12 +122,15 @@ func (p concreteScriptParams) JobNextState() (string, error) {
 	return newPresentedJobState(p.newSpec).MarshalToJSONString()
 }
 
+func (p concreteScriptParams) PollIntervalRange() PollIntervalRange { return p.pollIntervalRange }
+
 func (p concreteScriptParams) ToStatusParams() ScriptParams {
 	return concreteScriptParams{
-		jobChange:       "job_check_status",
-		hashChange:      "hash_unchanged",
-		updatedPackages: []string{},
-		oldSpec:         p.oldSpec,
-		newSpec:         nil,
+		jobChange:         "job_check_status",
+		hashChange:        "hash_unchanged",
+		updatedPackages:   []string{},
+		oldSpec:           p.oldSpec,
+		newSpec:           nil,
+		pollIntervalRange: p.pollIntervalRange,
 	}
 }
@@ -1,6 +1,8 @@
 package drain_test
 
 import (
+	"time"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
@@ -21,7 +23,7 @@ var _ = Describe("NewShutdownParams", func() {
 
 	Describe("JobState", func() {
 		It("returns JSON serialized current spec that only includes persistent disk", func() {
-			state, err := NewShutdownParams(oldSpec, &newSpec).JobState()
+			state, err := NewShutdownParams(oldSpec, &newSpec, PollIntervalRange{}).JobState()
 			Expect(err).ToNot(HaveOccurred())
 			Expect(state).To(Equal(`{"persistent_disk":200}`))
 		})
@@ -29,13 +31,13 @@ var _ = Describe("NewShutdownParams", func() {
 
 	Describe("JobNextState", func() {
 		It("returns JSON serialized future spec that only includes persistent disk", func() {
-			state, err := NewShutdownParams(oldSpec, &newSpec).JobNextState()
+			state, err := NewShutdownParams(oldSpec, &newSpec, PollIntervalRange{}).JobNextState()
 			Expect(err).ToNot(HaveOccurred())
 			Expect(state).To(Equal(`{"persistent_disk":301}`))
 		})
 
 		It("returns empty string if next state is not available", func() {
-			state, err := NewShutdownParams(oldSpec, nil).JobNextState()
+			state, err := NewShutdownParams(oldSpec, nil, PollIntervalRange{}).JobNextState()
 			Expect(err).ToNot(HaveOccurred())
 			Expect(state).To(Equal(""))
 		})
@@ -54,7 +56,7 @@ var _ = Describe("ToStatusParams", func() {
 
 	Describe("JobState", func() {
 		It("returns JSON serialized current spec that only includes persistent disk", func() {
-			state, err := NewUpdateParams(oldSpec, newSpec).ToStatusParams().JobState()
+			state, err := NewUpdateParams(oldSpec, newSpec, PollIntervalRange{}).ToStatusParams().JobState()
 			Expect(err).ToNot(HaveOccurred())
 			Expect(state).To(Equal(`{"persistent_disk":200}`))
 		})
@@ -62,11 +64,20 @@ var _ = Describe("ToStatusParams", func() {
 
 	Describe("JobNextState", func() {
 		It("returns empty string because next state is never available", func() {
-			state, err := NewUpdateParams(oldSpec, newSpec).ToStatusParams().JobNextState()
+			state, err := NewUpdateParams(oldSpec, newSpec, PollIntervalRange{}).ToStatusParams().JobNextState()
 			Expect(err).ToNot(HaveOccurred())
 			Expect(state).To(Equal(""))
 		})
 	})
+
+	Describe("PollIntervalRange", func() {
+		It("keeps the poll interval range", func() {
+			pollIntervalRange := PollIntervalRange{Min: 5 * time.Second, Max: time.Minute}
+
+			params := NewUpdateParams(oldSpec, newSpec, pollIntervalRange).ToStatusParams()
+			Expect(params.PollIntervalRange()).To(Equal(pollIntervalRange))
+		})
+	})
 })
 
 var _ = Describe("NewUpdateParams", func() {
@@ -106,7 +117,7 @@ var _ = Describe("NewUpdateParams", func() {
 				PackageSpecs: newPkgs,
 			}
 
-			params := NewUpdateParams(oldSpec, newSpec)
+			params := NewUpdateParams(oldSpec, newSpec, PollIntervalRange{})
 
 			Expect(params.UpdatedPackages()).To(Equal([]string{"baz", "foo"}))
 		})
@@ -116,7 +127,7 @@ var _ = Describe("NewUpdateParams", func() {
 		It("returns JSON serialized current spec that only includes persistent disk", func() {
 			oldSpec := boshas.V1ApplySpec{PersistentDisk: 200}
 			newSpec := boshas.V1ApplySpec{PersistentDisk: 301}
-			params := NewUpdateParams(oldSpec, newSpec)
+			params := NewUpdateParams(oldSpec, newSpec, PollIntervalRange{})
 
 			state, err := params.JobState()
 			Expect(err).ToNot(HaveOccurred())
@@ -128,7 +139,7 @@ var _ = Describe("NewUpdateParams", func() {
 		It("returns JSON serialized future spec that only includes persistent disk", func() {
 			oldSpec := boshas.V1ApplySpec{PersistentDisk: 200}
 			newSpec := boshas.V1ApplySpec{PersistentDisk: 301}
-			params := NewUpdateParams(oldSpec, newSpec)
+			params := NewUpdateParams(oldSpec, newSpec, PollIntervalRange{})
 
 			state, err := params.JobNextState()
 			Expect(err).ToNot(HaveOccurred())
@@ -136,3 +147,28 @@ var _ = Describe("NewUpdateParams", func() {
 		})
 	})
 })
+
+var _ = Describe("PollIntervalRange", func() {
+	Describe("Clamp", func() {
+		It("raises intervals below the minimum", func() {
+			Expect(PollIntervalRange{Min: 5 * time.Second}.Clamp(time.Second)).To(Equal(5 * time.Second))
+		})
+
+		It("lowers intervals above the maximum", func() {
+			Expect(PollIntervalRange{Max: time.Minute}.Clamp(time.Hour)).To(Equal(time.Minute))
+		})
+
+		It("keeps intervals within the range", func() {
+			Expect(PollIntervalRange{Min: 5 * time.Second, Max: time.Minute}.Clamp(30 * time.Second)).To(Equal(30 * time.Second))
+		})
+
+		It("does not bound intervals when the range is empty", func() {
+			Expect(PollIntervalRange{}.Clamp(0)).To(Equal(time.Duration(0)))
+			Expect(PollIntervalRange{}.Clamp(time.Hour)).To(Equal(time.Hour))
+		})
+
+		It("prefers the minimum when it exceeds the maximum", func() {
+			Expect(PollIntervalRange{Min: time.Minute, Max: 5 * time.Second}.Clamp(30 * time.Second)).To(Equal(time.Minute))
+		})
+	})
+})
@@ -1,21 +1,36 @@
 package script
 
 import (
+	"io"
+	"io/ioutil"
 	"os"
+	"path"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/cloudfoundry/bosh-agent/agent/script/cmd"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshsys "github.com/cloudfoundry/bosh-utils/system"
 )
 
 const (
 	fileOpenFlag int         = os.O_RDWR | os.O_CREATE | os.O_APPEND
 	fileOpenPerm os.FileMode = os.FileMode(0640)
+
+	DefaultLogDirMode os.FileMode = os.FileMode(0750)
+
+	DefaultMaxOutputBytes int64 = 1024 * 1024
+
+	pidFileDirMode os.FileMode = os.FileMode(0755)
+
+	timedOutScriptKillGracePeriod = 10 * time.Second
 )
 
 type GenericScript struct {
 	fs     boshsys.FileSystem
-	runner boshsys.CmdRunner
+	runner cmd.PidCmdRunner
 
 	tag  string
 	path string
@@ -24,16 +39,71 @@ type GenericScript struct {
 	stderrLogPath string
 
 	env map[string]string
+
+	opts Options
+}
+
+// ScriptResult describes how a run of a script ended
+type ScriptResult struct {
+	// Exit status of the script, 128 plus the signal number when it was killed
+	// by a signal and -1 when the script did not run or is not known to have exited
+	ExitStatus int
+
+	// Output written by this run, cut to its last Options.MaxOutputBytes bytes;
+	// Stderr is empty when output is combined
+	Stdout string
+	Stderr string
+
+	TimedOut bool
+}
+
+// Options control how a script is run
+type Options struct {
+	// Zero means the script may run for as long as it needs
+	Timeout time.Duration
+
+	// When set stderr is interleaved with stdout into the stdout log
+	CombineOutput bool
+
+	// User to run the script as instead of the agent's user (root)
+	RunAs string
+
+	// Number of times a failed script is re-run before giving up
+	Retries int
+
+	// Time to wait before each re-run of a failed script
+	RetryDelay time.Duration
+
+	// Mode of the log directories created for the script, DefaultLogDirMode when zero
+	LogDirMode os.FileMode
+
+	// Number of bytes of each output stream kept in the ScriptResult, which holds
+	// the end of longer output; DefaultMaxOutputBytes when zero. The log files
+	// always receive the whole output.
+	MaxOutputBytes int64
+
+	// Path the PID of the running script is written to, e.g. for monit to track
+	// scripts that fork; written once the script has started and removed again
+	// once it completes
+	PidFile string
+
+	// Patterns as understood by path.Match selecting the inherited environment
+	// variables passed to the script, e.g. "AWS_*"; all of them when empty.
+	// Variables matching a denylist pattern are never inherited. Neither list
+	// applies to the script's own env. Filtering is not supported on Windows.
+	EnvAllowlist []string
+	EnvDenylist  []string
 }
 
 func NewScript(
 	fs boshsys.FileSystem,
-	runner boshsys.CmdRunner,
+	runner cmd.PidCmdRunner,
 	tag string,
 	path string,
 	stdoutLogPath string,
 	stderrLogPath string,
 	env map[string]string,
+	opts Options,
 ) GenericScript {
 	return GenericScript{
 		fs:     fs,
@@ -46,6 +116,8 @@ func NewScript(
 		stderrLogPath: stderrLogPath,
 
 		env: env,
+
+		opts: opts,
 	}
 }
 
@@ -54,46 +126,281 @@ func (s GenericScript) Path() string { return s.path }
 func (s GenericScript) Exists() bool { return s.fs.FileExists(s.path) }
 
 func (s GenericScript) Run() error {
-	err := s.ensureContainingDir(s.stdoutLogPath)
+	_, err := s.RunWithResult()
+	return err
+}
+
+// RunWithResult runs the script like Run and reports how its last attempt
+// ended, e.g. to tell a failing script from one killed after timing out
+func (s GenericScript) RunWithResult() (ScriptResult, error) {
+	command, err := s.buildCommand()
 	if err != nil {
-		return err
+		return ScriptResult{ExitStatus: -1}, err
+	}
+
+	result, err := s.runOnce(command)
+
+	for attempt := 1; err != nil && attempt <= s.opts.Retries; attempt++ {
+		time.Sleep(s.opts.RetryDelay)
+		result, err = s.runOnce(command)
+	}
+
+	if err != nil && s.opts.Retries > 0 {
+		return result, bosherr.WrapErrorf(err, "Script '%s' failed after %d attempts", s.path, s.opts.Retries+1)
 	}
 
-	err = s.ensureContainingDir(s.stderrLogPath)
+	return result, err
+}
+
+func (s GenericScript) runOnce(command boshsys.Command) (ScriptResult, error) {
+	result := ScriptResult{ExitStatus: -1}
+
+	err := s.ensureContainingDir(s.stdoutLogPath)
 	if err != nil {
-		return err
+		return result, err
 	}
 
 	stdoutFile, err := s.fs.OpenFile(s.stdoutLogPath, fileOpenFlag, fileOpenPerm)
 	if err != nil {
-		return err
+		return result, err
 	}
 	defer func() {
 		_ = stdoutFile.Close()
 	}()
 
-	stderrFile, err := s.fs.OpenFile(s.stderrLogPath, fileOpenFlag, fileOpenPerm)
-	if err != nil {
-		return err
+	stderrFile := stdoutFile
+
+	if !s.opts.CombineOutput {
+		err = s.ensureContainingDir(s.stderrLogPath)
+		if err != nil {
+			return result, err
+		}
+
+		stderrFile, err = s.fs.OpenFile(s.stderrLogPath, fileOpenFlag, fileOpenPerm)
+		if err != nil {
+			return result, err
+		}
+		defer func() {
+			_ = stderrFile.Close()
+		}()
 	}
-	defer func() {
-		_ = stderrFile.Close()
-	}()
 
-	command := cmd.BuildCommand(s.path)
+	// The log files are handed to the script as they are, since writers other
+	// than files are fed through pipes that forked children would keep open
+	stdoutOffset := fileSize(stdoutFile)
+	stderrOffset := fileSize(stderrFile)
+
 	command.Stdout = stdoutFile
 	command.Stderr = stderrFile
+	command.Env = s.buildEnv(command.Env)
+
+	if s.opts.PidFile != "" {
+		err = s.fs.MkdirAll(filepath.Dir(s.opts.PidFile), pidFileDirMode)
+		if err != nil {
+			return result, bosherr.WrapErrorf(err, "Creating directory of pid file '%s' for script '%s'", s.opts.PidFile, s.path)
+		}
+
+		// The pid file must not outlive the script
+		defer func() {
+			_ = s.fs.RemoveAll(s.opts.PidFile)
+		}()
+	}
+
+	if s.opts.Timeout <= 0 && s.opts.PidFile == "" {
+		_, _, result.ExitStatus, err = s.runner.RunComplexCommand(command)
+	} else {
+		var exited bool
+
+		result, exited, err = s.runAsync(command)
+		if !exited {
+			// The script may still be writing to the log files
+			return result, err
+		}
+	}
+
+	maxOutputBytes := s.opts.MaxOutputBytes
+	if maxOutputBytes <= 0 {
+		maxOutputBytes = DefaultMaxOutputBytes
+	}
+
+	result.Stdout = readFileFrom(stdoutFile, stdoutOffset, maxOutputBytes)
+	if !s.opts.CombineOutput {
+		result.Stderr = readFileFrom(stderrFile, stderrOffset, maxOutputBytes)
+	}
+
+	return result, err
+}
+
+func (s GenericScript) buildCommand() (boshsys.Command, error) {
+	command := cmd.BuildCommand(s.path)
+
+	if s.opts.RunAs != "" {
+		var err error
+
+		command, err = cmd.BuildCommandAsUser(s.path, s.opts.RunAs)
+		if err != nil {
+			return boshsys.Command{}, err
+		}
+
+		// Fail instead of letting the script run with the agent's privileges
+		_, _, _, err = s.runner.RunCommand("id", "-u", s.opts.RunAs)
+		if err != nil {
+			return boshsys.Command{}, bosherr.WrapErrorf(err, "Looking up user '%s' to run script '%s'", s.opts.RunAs, s.path)
+		}
+	}
+
+	if len(s.opts.EnvAllowlist) > 0 || len(s.opts.EnvDenylist) > 0 {
+		// The runner would otherwise merge the filtered variables back in
+		var err error
+
+		command, err = cmd.IsolateEnv(command)
+		if err != nil {
+			return boshsys.Command{}, err
+		}
+	}
+
+	return command, nil
+}
+
+// buildEnv starts from the agent's inherited environment so that scripts keep
+// variables such as HOME, then layers the command's own variables (e.g. PATH)
+// and finally the script's custom env on top
+func (s GenericScript) buildEnv(commandEnv map[string]string) map[string]string {
+	env := map[string]string{}
+
+	for _, keyVal := range os.Environ() {
+		// Windows has special per-drive variables like "=C:=C:\" which are skipped
+		if n := strings.IndexByte(keyVal, '='); n > 0 && s.inheritsEnv(keyVal[:n]) {
+			env[keyVal[:n]] = keyVal[n+1:]
+		}
+	}
+
+	for key, val := range commandEnv {
+		env[key] = val
+	}
 
 	for key, val := range s.env {
-		command.Env[key] = val
+		env[key] = val
 	}
 
-	_, _, _, err = s.runner.RunComplexCommand(command)
+	return env
+}
 
-	return err
+func (s GenericScript) inheritsEnv(name string) bool {
+	if matchesAnyEnvPattern(name, s.opts.EnvDenylist) {
+		return false
+	}
+
+	return len(s.opts.EnvAllowlist) == 0 || matchesAnyEnvPattern(name, s.opts.EnvAllowlist)
+}
+
+func matchesAnyEnvPattern(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		// Malformed patterns are rejected before scripts are built
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// runAsync runs the command as a separate process so that its pid can be
+// recorded and it can be terminated after the timeout. It reports whether
+// the process is known to have exited.
+func (s GenericScript) runAsync(command boshsys.Command) (ScriptResult, bool, error) {
+	result := ScriptResult{ExitStatus: -1}
+
+	process, pid, err := s.runner.RunComplexCommandAsyncWithPid(command)
+	if err != nil {
+		return result, false, err
+	}
+
+	if s.opts.PidFile != "" {
+		err = s.fs.WriteFileString(s.opts.PidFile, strconv.Itoa(pid))
+		if err != nil {
+			// The script must not run untracked by whoever relies on the pid file
+			resultCh := process.Wait()
+
+			termErr := process.TerminateNicely(timedOutScriptKillGracePeriod)
+			if termErr != nil {
+				return result, false, bosherr.WrapErrorf(termErr, "Terminating script '%s' after failing to write its pid file", s.path)
+			}
+
+			result.ExitStatus = (<-resultCh).ExitStatus
+
+			return result, true, bosherr.WrapErrorf(err, "Writing pid file '%s' of script '%s'", s.opts.PidFile, s.path)
+		}
+	}
+
+	resultCh := process.Wait()
+
+	var timeoutCh <-chan time.Time
+
+	if s.opts.Timeout > 0 {
+		timer := time.NewTimer(s.opts.Timeout)
+		defer timer.Stop()
+
+		timeoutCh = timer.C
+	}
+
+	select {
+	case processResult := <-resultCh:
+		result.ExitStatus = processResult.ExitStatus
+		return result, true, processResult.Error
+
+	case <-timeoutCh:
+		result.TimedOut = true
+
+		// Terminates the whole process group, killing it if it does not exit within the grace period
+		err = process.TerminateNicely(timedOutScriptKillGracePeriod)
+		if err != nil {
+			return result, false, bosherr.WrapErrorf(err, "Terminating script '%s' after it timed out", s.path)
+		}
+
+		result.ExitStatus = (<-resultCh).ExitStatus
+
+		return result, true, bosherr.Errorf("Script '%s' timed out after %s", s.path, s.opts.Timeout)
+	}
 }
 
 func (s GenericScript) ensureContainingDir(fullLogFilename string) error {
 	dir, _ := filepath.Split(fullLogFilename)
-	return s.fs.MkdirAll(dir, os.FileMode(0750))
+
+	mode := s.opts.LogDirMode
+	if mode == 0 {
+		mode = DefaultLogDirMode
+	}
+
+	return s.fs.MkdirAll(dir, mode)
+}
+
+func fileSize(file boshsys.File) int64 {
+	stat, err := file.Stat()
+	if err != nil {
+		return 0
+	}
+
+	return stat.Size()
+}
+
+// readFileFrom is best effort since the output is only reported in addition
+// to being logged and must not fail an otherwise successful script. Only the
+// last maxBytes bytes are read so that chatty scripts are not held in memory.
+func readFileFrom(file boshsys.File, offset int64, maxBytes int64) string {
+	if size := fileSize(file); size-offset > maxBytes {
+		offset = size - maxBytes
+	}
+
+	_, err := file.Seek(offset, io.SeekStart)
+	if err != nil {
+		return ""
+	}
+
+	contents, err := ioutil.ReadAll(io.LimitReader(file, maxBytes))
+	if err != nil {
+		return ""
+	}
+
+	return string(contents)
 }
@@ -2,21 +2,30 @@ package script_test
 
 import (
 	"errors"
+	"io/ioutil"
+	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
 	boshscript "github.com/cloudfoundry/bosh-agent/agent/script"
+	scriptcmd "github.com/cloudfoundry/bosh-agent/agent/script/cmd"
+	"github.com/cloudfoundry/bosh-agent/agent/script/cmd/cmdfakes"
 	boshenv "github.com/cloudfoundry/bosh-agent/agent/script/pathenv"
+	fakefs "github.com/cloudfoundry/bosh-agent/platform/fakefs"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
 	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
 	"runtime"
 )
 
 var _ = Describe("GenericScript", func() {
 	var (
-		fs            *fakesys.FakeFileSystem
-		cmdRunner     *fakesys.FakeCmdRunner
+		fs            *fakefs.FakeFileSystem
+		cmdRunner     *cmdfakes.FakePidCmdRunner
 		genericScript boshscript.GenericScript
 		stdoutLogPath string
 		stderrLogPath string
@@ -25,8 +34,8 @@ var _ = Describe("GenericScript", func() {
 	)
 
 	BeforeEach(func() {
-		fs = fakesys.NewFakeFileSystem()
-		cmdRunner = fakesys.NewFakeCmdRunner()
+		fs = fakefs.NewFakeFileSystem()
+		cmdRunner = cmdfakes.NewFakePidCmdRunner()
 		stdoutLogPath = filepath.Join("base", "stdout", "logdir", "stdout.log")
 		stderrLogPath = filepath.Join("base", "stderr", "logdir", "stderr.log")
 		scriptEnv = map[string]string{
@@ -42,6 +51,7 @@ var _ = Describe("GenericScript", func() {
 			stdoutLogPath,
 			stderrLogPath,
 			scriptEnv,
+			boshscript.Options{},
 		)
 		if runtime.GOOS == "windows" {
 			fullCommand = "powershell /path-to-script"
@@ -100,6 +110,134 @@ var _ = Describe("GenericScript", func() {
 			Expect(cmd.Env).To(HaveKeyWithValue("PATH", boshenv.Path()))
 		})
 
+		It("keeps the inherited environment alongside the provided env", func() {
+			err := os.Setenv("GENERIC_SCRIPT_INHERITED", "inherited-value")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.Unsetenv("GENERIC_SCRIPT_INHERITED")
+
+			Expect(genericScript.Run()).To(Succeed())
+			Expect(cmdRunner.RunComplexCommands).To(HaveLen(1))
+			cmd := cmdRunner.RunComplexCommands[0]
+			Expect(cmd.Env).To(HaveKeyWithValue("GENERIC_SCRIPT_INHERITED", "inherited-value"))
+			Expect(cmd.Env).To(HaveKeyWithValue("FOO", "foo"))
+			Expect(cmd.Env).To(HaveKeyWithValue("PATH", boshenv.Path()))
+		})
+
+		It("lets the provided env override inherited variables", func() {
+			err := os.Setenv("FOO", "inherited-foo")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.Unsetenv("FOO")
+
+			Expect(genericScript.Run()).To(Succeed())
+			Expect(cmdRunner.RunComplexCommands).To(HaveLen(1))
+			cmd := cmdRunner.RunComplexCommands[0]
+			Expect(cmd.Env).To(HaveKeyWithValue("FOO", "foo"))
+		})
+
+		Context("when inherited environment variables are filtered", func() {
+			newScriptWithEnvFilter := func(allowlist, denylist []string) boshscript.GenericScript {
+				return boshscript.NewScript(
+					fs,
+					cmdRunner,
+					"my-tag",
+					"/path-to-script",
+					stdoutLogPath,
+					stderrLogPath,
+					scriptEnv,
+					boshscript.Options{EnvAllowlist: allowlist, EnvDenylist: denylist},
+				)
+			}
+
+			BeforeEach(func() {
+				if runtime.GOOS == "windows" {
+					Skip("Filtering the environment of scripts is not supported on Windows")
+				}
+
+				Expect(os.Setenv("GENERIC_SCRIPT_ALLOWED", "allowed-value")).To(Succeed())
+				Expect(os.Setenv("GENERIC_SCRIPT_SECRET_KEY", "secret-value")).To(Succeed())
+				Expect(os.Setenv("BAR", "inherited-bar")).To(Succeed())
+			})
+
+			AfterEach(func() {
+				os.Unsetenv("GENERIC_SCRIPT_ALLOWED")
+				os.Unsetenv("GENERIC_SCRIPT_SECRET_KEY")
+				os.Unsetenv("BAR")
+			})
+
+			It("only inherits allowlisted variables", func() {
+				script := newScriptWithEnvFilter([]string{"GENERIC_SCRIPT_ALLOWED"}, nil)
+
+				Expect(script.Run()).To(Succeed())
+				Expect(cmdRunner.RunComplexCommands).To(HaveLen(1))
+				cmd := cmdRunner.RunComplexCommands[0]
+				Expect(cmd.Env).To(HaveKeyWithValue("GENERIC_SCRIPT_ALLOWED", "allowed-value"))
+				Expect(cmd.Env).ToNot(HaveKey("GENERIC_SCRIPT_SECRET_KEY"))
+				Expect(cmd.Env).ToNot(HaveKey("HOME"))
+				Expect(cmd.UseIsolatedEnv).To(BeTrue())
+			})
+
+			It("does not inherit denylisted variables", func() {
+				script := newScriptWithEnvFilter(nil, []string{"GENERIC_SCRIPT_SECRET_*"})
+
+				Expect(script.Run()).To(Succeed())
+				Expect(cmdRunner.RunComplexCommands).To(HaveLen(1))
+				cmd := cmdRunner.RunComplexCommands[0]
+				Expect(cmd.Env).To(HaveKeyWithValue("GENERIC_SCRIPT_ALLOWED", "allowed-value"))
+				Expect(cmd.Env).ToNot(HaveKey("GENERIC_SCRIPT_SECRET_KEY"))
+			})
+
+			It("lets the denylist win over the allowlist", func() {
+				script := newScriptWithEnvFilter([]string{"GENERIC_SCRIPT_*"}, []string{"GENERIC_SCRIPT_SECRET_KEY"})
+
+				Expect(script.Run()).To(Succeed())
+				Expect(cmdRunner.RunComplexCommands).To(HaveLen(1))
+				cmd := cmdRunner.RunComplexCommands[0]
+				Expect(cmd.Env).To(HaveKeyWithValue("GENERIC_SCRIPT_ALLOWED", "allowed-value"))
+				Expect(cmd.Env).ToNot(HaveKey("GENERIC_SCRIPT_SECRET_KEY"))
+			})
+
+			It("always passes the command and provided env", func() {
+				script := newScriptWithEnvFilter([]string{"GENERIC_SCRIPT_ALLOWED"}, []string{"BAR", "PATH"})
+
+				Expect(script.Run()).To(Succeed())
+				Expect(cmdRunner.RunComplexCommands).To(HaveLen(1))
+				cmd := cmdRunner.RunComplexCommands[0]
+				Expect(cmd.Env).To(HaveKeyWithValue("BAR", "bar"))
+				Expect(cmd.Env).To(HaveKeyWithValue("FOO", "foo"))
+				Expect(cmd.Env).To(HaveKeyWithValue("PATH", boshenv.Path()))
+			})
+
+			It("keeps filtered variables away from scripts run by the exec runner", func() {
+				logger := boshlog.NewLogger(boshlog.LevelNone)
+
+				tmpDir, err := ioutil.TempDir("", "generic-script-env")
+				Expect(err).ToNot(HaveOccurred())
+				defer os.RemoveAll(tmpDir)
+
+				scriptPath := filepath.Join(tmpDir, "print-env")
+				Expect(ioutil.WriteFile(scriptPath, []byte("#!/bin/sh\nenv\n"), 0755)).To(Succeed())
+
+				script := boshscript.NewScript(
+					boshsys.NewOsFileSystem(logger),
+					scriptcmd.NewPidCmdRunner(boshsys.NewExecCmdRunner(logger), logger),
+					"my-tag",
+					scriptPath,
+					filepath.Join(tmpDir, "stdout.log"),
+					filepath.Join(tmpDir, "stderr.log"),
+					scriptEnv,
+					boshscript.Options{EnvDenylist: []string{"GENERIC_SCRIPT_SECRET_*"}},
+				)
+
+				Expect(script.Run()).To(Succeed())
+
+				stdout, err := ioutil.ReadFile(filepath.Join(tmpDir, "stdout.log"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(string(stdout)).To(ContainSubstring("GENERIC_SCRIPT_ALLOWED=allowed-value\n"))
+				Expect(string(stdout)).To(ContainSubstring("FOO=foo\n"))
+				Expect(string(stdout)).ToNot(ContainSubstring("GENERIC_SCRIPT_SECRET_KEY"))
+			})
+		})
+
 		It("sets the command ENV according to the provided env", func() {
 			Expect(genericScript.Run()).To(Succeed())
 			Expect(cmdRunner.RunComplexCommands).To(HaveLen(1))
@@ -161,5 +299,476 @@ var _ = Describe("GenericScript", func() {
 				Expect(stderr).To(Equal("fake-stderr"))
 			})
 		})
+
+		Context("when a timeout is set", func() {
+			newScriptWithTimeout := func(timeout time.Duration) boshscript.GenericScript {
+				return boshscript.NewScript(
+					fs,
+					cmdRunner,
+					"my-tag",
+					"/path-to-script",
+					stdoutLogPath,
+					stderrLogPath,
+					scriptEnv,
+					boshscript.Options{Timeout: timeout},
+				)
+			}
+
+			It("returns the result of the script when it finishes in time", func() {
+				process := &fakesys.FakeProcess{
+					WaitResult: boshsys.Result{Error: errors.New("fake-command-error")},
+				}
+				cmdRunner.AddProcess(fullCommand, process)
+
+				err := newScriptWithTimeout(time.Minute).Run()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("fake-command-error"))
+
+				Expect(process.TerminatedNicely).To(BeFalse())
+			})
+
+			It("terminates the script and returns an error when it does not finish in time", func() {
+				process := &fakesys.FakeProcess{
+					TerminatedNicelyCallBack: func(p *fakesys.FakeProcess) {
+						p.WaitCh <- boshsys.Result{}
+					},
+				}
+				cmdRunner.AddProcess(fullCommand, process)
+
+				err := newScriptWithTimeout(10 * time.Millisecond).Run()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Script '/path-to-script' timed out after 10ms"))
+
+				Expect(process.TerminatedNicely).To(BeTrue())
+				Expect(process.TerminateNicelyKillGracePeriod).To(Equal(10 * time.Second))
+			})
+
+			It("returns an error when the timed out script cannot be terminated", func() {
+				process := &fakesys.FakeProcess{
+					TerminatedNicelyCallBack: func(p *fakesys.FakeProcess) {},
+					TerminateNicelyErr:       errors.New("fake-terminate-error"),
+				}
+				cmdRunner.AddProcess(fullCommand, process)
+
+				err := newScriptWithTimeout(10 * time.Millisecond).Run()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Terminating script '/path-to-script' after it timed out"))
+				Expect(err.Error()).To(ContainSubstring("fake-terminate-error"))
+			})
+		})
+
+		Context("when a pid file is set", func() {
+			var (
+				pidFilePath string
+				process     *fakesys.FakeProcess
+			)
+
+			BeforeEach(func() {
+				pidFilePath = filepath.Join("base", "run", "my-tag.pid")
+
+				process = &fakesys.FakeProcess{}
+				cmdRunner.AddProcess(fullCommand, process)
+				cmdRunner.Pid = 1234
+
+				genericScript = boshscript.NewScript(
+					fs,
+					cmdRunner,
+					"my-tag",
+					"/path-to-script",
+					stdoutLogPath,
+					stderrLogPath,
+					scriptEnv,
+					boshscript.Options{PidFile: pidFilePath},
+				)
+			})
+
+			It("writes the pid of the script to the pid file while it runs", func() {
+				var pidFileContents string
+				cmdRunner.WaitCallback = func() {
+					pidFileContents, _ = fs.ReadFileString(pidFilePath)
+				}
+
+				err := genericScript.Run()
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(pidFileContents).To(Equal("1234"))
+
+				Expect(cmdRunner.RunComplexCommands).To(HaveLen(1))
+				cmd := cmdRunner.RunComplexCommands[0]
+				Expect(cmd.Env).To(HaveKeyWithValue("PATH", boshenv.Path()))
+				Expect(cmd.Env).To(HaveKeyWithValue("FOO", "foo"))
+			})
+
+			It("removes the pid file when the script completes", func() {
+				err := genericScript.Run()
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(fs.FileExists(filepath.Join("base", "run"))).To(BeTrue())
+				Expect(fs.FileExists(pidFilePath)).To(BeFalse())
+			})
+
+			It("removes the pid file when the script fails", func() {
+				process.WaitResult = boshsys.Result{Error: errors.New("fake-command-error")}
+
+				err := genericScript.Run()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("fake-command-error"))
+
+				Expect(fs.FileExists(pidFilePath)).To(BeFalse())
+			})
+
+			It("terminates the script and returns an error when the pid file cannot be written", func() {
+				process.TerminatedNicelyCallBack = func(p *fakesys.FakeProcess) {
+					p.WaitCh <- boshsys.Result{ExitStatus: 143}
+				}
+				fs.WriteFileErrors[pidFilePath] = errors.New("fake-write-error")
+
+				result, err := genericScript.RunWithResult()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Writing pid file '" + pidFilePath + "' of script '/path-to-script'"))
+				Expect(err.Error()).To(ContainSubstring("fake-write-error"))
+
+				Expect(process.TerminatedNicely).To(BeTrue())
+				Expect(result.ExitStatus).To(Equal(143))
+			})
+
+			It("writes the pid of scripts run by the exec runner", func() {
+				if runtime.GOOS == "windows" {
+					Skip("The script is a shell script")
+				}
+
+				logger := boshlog.NewLogger(boshlog.LevelNone)
+
+				tmpDir, err := ioutil.TempDir("", "generic-script-pid")
+				Expect(err).ToNot(HaveOccurred())
+				defer os.RemoveAll(tmpDir)
+
+				pidFilePath = filepath.Join(tmpDir, "run", "my-tag.pid")
+
+				// The pid file is written right after the script starts
+				scriptPath := filepath.Join(tmpDir, "print-pid")
+				scriptContents := "#!/bin/sh\nfor i in $(seq 50); do [ -s \"$PID_FILE\" ] && break; sleep 0.1; done\necho \"$$ $(cat \"$PID_FILE\")\"\n"
+				Expect(ioutil.WriteFile(scriptPath, []byte(scriptContents), 0755)).To(Succeed())
+
+				script := boshscript.NewScript(
+					boshsys.NewOsFileSystem(logger),
+					scriptcmd.NewPidCmdRunner(boshsys.NewExecCmdRunner(logger), logger),
+					"my-tag",
+					scriptPath,
+					filepath.Join(tmpDir, "stdout.log"),
+					filepath.Join(tmpDir, "stderr.log"),
+					map[string]string{"PID_FILE": pidFilePath},
+					boshscript.Options{PidFile: pidFilePath},
+				)
+
+				result, err := script.RunWithResult()
+				Expect(err).ToNot(HaveOccurred())
+
+				pids := strings.Fields(result.Stdout)
+				Expect(pids).To(HaveLen(2))
+				Expect(pids[1]).To(Equal(pids[0]))
+
+				Expect(pidFilePath).ToNot(BeAnExistingFile())
+			})
+
+			It("returns an error without running the script when the pid file directory cannot be created", func() {
+				fs.RegisterMkdirAllError(filepath.Join("base", "run"), errors.New("fake-mkdir-error"))
+
+				err := genericScript.Run()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Creating directory of pid file '" + pidFilePath + "' for script '/path-to-script'"))
+				Expect(err.Error()).To(ContainSubstring("fake-mkdir-error"))
+
+				Expect(cmdRunner.RunComplexCommands).To(BeEmpty())
+			})
+		})
+
+		Context("when combine output is set", func() {
+			BeforeEach(func() {
+				genericScript = boshscript.NewScript(
+					fs,
+					cmdRunner,
+					"my-tag",
+					"/path-to-script",
+					stdoutLogPath,
+					stderrLogPath,
+					scriptEnv,
+					boshscript.Options{CombineOutput: true},
+				)
+			})
+
+			It("writes stdout and stderr into the stdout log only", func() {
+				cmdRunner.AddCmdResult(fullCommand, fakesys.FakeCmdResult{
+					Stdout: "fake-stdout\n",
+					Stderr: "fake-stderr\n",
+				})
+
+				err := genericScript.Run()
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(cmdRunner.RunComplexCommands).To(HaveLen(1))
+				cmd := cmdRunner.RunComplexCommands[0]
+				Expect(cmd.Stdout).To(BeIdenticalTo(cmd.Stderr))
+
+				output, err := fs.ReadFileString(stdoutLogPath)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(output).To(Equal("fake-stdout\nfake-stderr\n"))
+
+				Expect(fs.FileExists(stderrLogPath)).To(BeFalse())
+			})
+		})
+
+		Context("when a log dir mode is set", func() {
+			BeforeEach(func() {
+				genericScript = boshscript.NewScript(
+					fs,
+					cmdRunner,
+					"my-tag",
+					"/path-to-script",
+					stdoutLogPath,
+					stderrLogPath,
+					scriptEnv,
+					boshscript.Options{LogDirMode: os.FileMode(0755)},
+				)
+			})
+
+			It("creates the log directories with the given mode", func() {
+				err := genericScript.Run()
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(fs.GetFileTestStat(filepath.Dir(stdoutLogPath)).FileMode).To(Equal(os.FileMode(0755)))
+				Expect(fs.GetFileTestStat(filepath.Dir(stderrLogPath)).FileMode).To(Equal(os.FileMode(0755)))
+			})
+		})
+
+		It("creates the log directories with the default mode", func() {
+			err := genericScript.Run()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fs.GetFileTestStat(filepath.Dir(stdoutLogPath)).FileMode).To(Equal(boshscript.DefaultLogDirMode))
+		})
+
+		Context("when retries are set", func() {
+			BeforeEach(func() {
+				genericScript = boshscript.NewScript(
+					fs,
+					cmdRunner,
+					"my-tag",
+					"/path-to-script",
+					stdoutLogPath,
+					stderrLogPath,
+					scriptEnv,
+					boshscript.Options{Retries: 3, RetryDelay: time.Millisecond},
+				)
+			})
+
+			It("re-runs the script until it succeeds", func() {
+				cmdRunner.AddCmdResult(fullCommand, fakesys.FakeCmdResult{ExitStatus: 1, Error: errors.New("fake-first-error")})
+				cmdRunner.AddCmdResult(fullCommand, fakesys.FakeCmdResult{ExitStatus: 1, Error: errors.New("fake-second-error")})
+				cmdRunner.AddCmdResult(fullCommand, fakesys.FakeCmdResult{ExitStatus: 0})
+
+				err := genericScript.Run()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(cmdRunner.RunComplexCommands).To(HaveLen(3))
+			})
+
+			It("does not re-run a script that succeeds", func() {
+				cmdRunner.AddCmdResult(fullCommand, fakesys.FakeCmdResult{ExitStatus: 0})
+
+				err := genericScript.Run()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(cmdRunner.RunComplexCommands).To(HaveLen(1))
+			})
+
+			It("returns the final error once retries are exhausted", func() {
+				for i := 1; i <= 3; i++ {
+					cmdRunner.AddCmdResult(fullCommand, fakesys.FakeCmdResult{ExitStatus: 1, Error: errors.New("fake-early-error")})
+				}
+				cmdRunner.AddCmdResult(fullCommand, fakesys.FakeCmdResult{ExitStatus: 1, Error: errors.New("fake-final-error")})
+
+				err := genericScript.Run()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Script '/path-to-script' failed after 4 attempts: fake-final-error"))
+				Expect(cmdRunner.RunComplexCommands).To(HaveLen(4))
+			})
+		})
+
+		Context("when run as is set", func() {
+			BeforeEach(func() {
+				if runtime.GOOS == "windows" {
+					Skip("Running scripts as another user is not supported on Windows")
+				}
+
+				genericScript = boshscript.NewScript(
+					fs,
+					cmdRunner,
+					"my-tag",
+					"/path-to-script",
+					stdoutLogPath,
+					stderrLogPath,
+					scriptEnv,
+					boshscript.Options{RunAs: "vcap"},
+				)
+			})
+
+			It("runs the script as the given user", func() {
+				err := genericScript.Run()
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(cmdRunner.RunCommands).To(Equal([][]string{{"id", "-u", "vcap"}}))
+
+				Expect(cmdRunner.RunComplexCommands).To(HaveLen(1))
+				cmd := cmdRunner.RunComplexCommands[0]
+				Expect(cmd.Name).To(Equal("chpst"))
+				Expect(cmd.Args).To(Equal([]string{"-u", "vcap", "/path-to-script"}))
+				Expect(cmd.Env).To(HaveKeyWithValue("PATH", boshenv.Path()))
+				Expect(cmd.Env).To(HaveKeyWithValue("FOO", "foo"))
+			})
+
+			It("returns an error without running the script when the user does not exist", func() {
+				cmdRunner.AddCmdResult("id -u vcap", fakesys.FakeCmdResult{
+					ExitStatus: 1,
+					Error:      errors.New("fake-no-such-user-error"),
+				})
+
+				err := genericScript.Run()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Looking up user 'vcap' to run script '/path-to-script'"))
+				Expect(err.Error()).To(ContainSubstring("fake-no-such-user-error"))
+
+				Expect(cmdRunner.RunComplexCommands).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("RunWithResult", func() {
+		It("returns the exit status and output of a successful script", func() {
+			cmdRunner.AddCmdResult(fullCommand, fakesys.FakeCmdResult{
+				Stdout: "fake-stdout",
+				Stderr: "fake-stderr",
+			})
+
+			result, err := genericScript.RunWithResult()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal(boshscript.ScriptResult{
+				ExitStatus: 0,
+				Stdout:     "fake-stdout",
+				Stderr:     "fake-stderr",
+			}))
+		})
+
+		It("returns the exit status and output of a failing script", func() {
+			cmdRunner.AddCmdResult(fullCommand, fakesys.FakeCmdResult{
+				Stdout:     "fake-stdout",
+				Stderr:     "fake-stderr",
+				ExitStatus: 2,
+				Error:      errors.New("fake-command-error"),
+			})
+
+			result, err := genericScript.RunWithResult()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("fake-command-error"))
+			Expect(result).To(Equal(boshscript.ScriptResult{
+				ExitStatus: 2,
+				Stdout:     "fake-stdout",
+				Stderr:     "fake-stderr",
+			}))
+		})
+
+		It("only returns the output of this run", func() {
+			err := fs.WriteFileString(stdoutLogPath, "previous-stdout")
+			Expect(err).ToNot(HaveOccurred())
+
+			cmdRunner.AddCmdResult(fullCommand, fakesys.FakeCmdResult{Stdout: "fake-stdout"})
+
+			result, err := genericScript.RunWithResult()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Stdout).To(Equal("fake-stdout"))
+
+			stdout, err := fs.ReadFileString(stdoutLogPath)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stdout).To(Equal("previous-stdoutfake-stdout"))
+		})
+
+		Context("when a maximum output size is set", func() {
+			BeforeEach(func() {
+				genericScript = boshscript.NewScript(
+					fs,
+					cmdRunner,
+					"my-tag",
+					"/path-to-script",
+					stdoutLogPath,
+					stderrLogPath,
+					scriptEnv,
+					boshscript.Options{MaxOutputBytes: 8},
+				)
+			})
+
+			It("returns the whole output when it is within the maximum size", func() {
+				cmdRunner.AddCmdResult(fullCommand, fakesys.FakeCmdResult{Stdout: "stdout", Stderr: "stderr"})
+
+				result, err := genericScript.RunWithResult()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.Stdout).To(Equal("stdout"))
+				Expect(result.Stderr).To(Equal("stderr"))
+			})
+
+			It("returns the last bytes of longer output while logging all of it", func() {
+				err := fs.WriteFileString(stdoutLogPath, "previous-stdout")
+				Expect(err).ToNot(HaveOccurred())
+
+				cmdRunner.AddCmdResult(fullCommand, fakesys.FakeCmdResult{
+					Stdout: "first-line\nlast-out",
+					Stderr: "first-line\nlast-err",
+				})
+
+				result, err := genericScript.RunWithResult()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.Stdout).To(Equal("last-out"))
+				Expect(result.Stderr).To(Equal("last-err"))
+
+				stdout, err := fs.ReadFileString(stdoutLogPath)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(stdout).To(Equal("previous-stdoutfirst-line\nlast-out"))
+
+				stderr, err := fs.ReadFileString(stderrLogPath)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(stderr).To(Equal("first-line\nlast-err"))
+			})
+		})
+
+		It("reports a script killed after timing out", func() {
+			process := &fakesys.FakeProcess{
+				TerminatedNicelyCallBack: func(p *fakesys.FakeProcess) {
+					p.WaitCh <- boshsys.Result{ExitStatus: 137, Error: errors.New("fake-killed-error")}
+				},
+			}
+			cmdRunner.AddProcess(fullCommand, process)
+
+			genericScript = boshscript.NewScript(
+				fs,
+				cmdRunner,
+				"my-tag",
+				"/path-to-script",
+				stdoutLogPath,
+				stderrLogPath,
+				scriptEnv,
+				boshscript.Options{Timeout: 10 * time.Millisecond},
+			)
+
+			result, err := genericScript.RunWithResult()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Script '/path-to-script' timed out after 10ms"))
+			Expect(result.ExitStatus).To(Equal(137))
+			Expect(result.TimedOut).To(BeTrue())
+		})
+
+		It("returns an unknown exit status when the script cannot be run", func() {
+			fs.MkdirAllError = errors.New("fake-mkdir-all-error")
+
+			result, err := genericScript.RunWithResult()
+			Expect(err).To(HaveOccurred())
+			Expect(result.ExitStatus).To(Equal(-1))
+		})
 	})
 })
@@ -1,6 +1,7 @@
 package script
 
 import (
+	"sort"
 	"strings"
 
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
@@ -11,6 +12,12 @@ type ParallelScript struct {
 	name       string
 	allScripts []Script
 
+	// Zero means all scripts run at the same time
+	maxConcurrency int
+
+	// Tags of the scripts that have to succeed before the script with the given tag runs
+	dependsOn map[string][]string
+
 	logTag string
 	logger boshlog.Logger
 }
@@ -20,11 +27,20 @@ type scriptResult struct {
 	Error  error
 }
 
-func NewParallelScript(name string, scripts []Script, logger boshlog.Logger) ParallelScript {
+func NewParallelScript(
+	name string,
+	scripts []Script,
+	maxConcurrency int,
+	dependsOn map[string][]string,
+	logger boshlog.Logger,
+) ParallelScript {
 	return ParallelScript{
 		name:       name,
 		allScripts: scripts,
 
+		maxConcurrency: maxConcurrency,
+		dependsOn:      dependsOn,
+
 		logTag: "ParallelScript",
 		logger: logger,
 	}
@@ -35,34 +51,96 @@ func (s ParallelScript) Path() string { return "" }
 func (s ParallelScript) Exists() bool { return true }
 
 func (s ParallelScript) Run() error {
+	err := s.checkDependencyCycles()
+	if err != nil {
+		return err
+	}
+
 	existingScripts := s.findExistingScripts(s.allScripts)
 
-	s.logger.Info(s.logTag, "Will run %d %s scripts in parallel", len(existingScripts), s.name)
+	workers := len(existingScripts)
+	if s.maxConcurrency > 0 && s.maxConcurrency < workers {
+		workers = s.maxConcurrency
+	}
 
-	resultsChan := make(chan scriptResult)
+	s.logger.Info(s.logTag, "Will run %d %s scripts in parallel, %d at a time", len(existingScripts), s.name, workers)
 
+	existingTags := map[string]bool{}
 	for _, script := range existingScripts {
-		script := script
-		go func() { resultsChan <- scriptResult{script, script.Run()} }()
+		existingTags[script.Tag()] = true
+	}
+
+	// Dependencies on jobs without the script are satisfied right away
+	waitingOn := make([]int, len(existingScripts))
+	dependents := map[string][]int{}
+
+	for i, script := range existingScripts {
+		for _, dependency := range s.dependencies(script.Tag()) {
+			if existingTags[dependency] {
+				waitingOn[i]++
+				dependents[dependency] = append(dependents[dependency], i)
+			}
+		}
 	}
 
+	var ready []int
+	for i := range existingScripts {
+		if waitingOn[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	resultsChan := make(chan scriptResult)
+
 	var failedScripts, passedScripts []string
+	failedTags := map[string]bool{}
+	running := 0
+
+	finish := func(r scriptResult) {
+		jobName := r.Script.Tag()
+
+		if r.Error == nil {
+			passedScripts = append(passedScripts, jobName)
+			s.logger.Info(s.logTag, "'%s' script has successfully executed", r.Script.Path())
+		} else {
+			failedScripts = append(failedScripts, jobName)
+			failedTags[jobName] = true
+			s.logger.Error(s.logTag, "'%s' script has failed with error: %s", r.Script.Path(), r.Error)
+		}
 
-	for i := 0; i < len(existingScripts); i++ {
-		select {
-		case r := <-resultsChan:
-			jobName := r.Script.Tag()
-
-			if r.Error == nil {
-				passedScripts = append(passedScripts, jobName)
-				s.logger.Info(s.logTag, "'%s' script has successfully executed", r.Script.Path())
-			} else {
-				failedScripts = append(failedScripts, jobName)
-				s.logger.Error(s.logTag, "'%s' script has failed with error: %s", r.Script.Path(), r.Error)
+		for _, i := range dependents[jobName] {
+			waitingOn[i]--
+			if waitingOn[i] == 0 {
+				ready = append(ready, i)
 			}
 		}
 	}
 
+	for len(passedScripts)+len(failedScripts) < len(existingScripts) {
+		for len(ready) > 0 && running < workers {
+			script := existingScripts[ready[0]]
+			ready = ready[1:]
+
+			if failed := s.failedDependency(script.Tag(), failedTags); failed != "" {
+				finish(scriptResult{script, bosherr.Errorf("Not run as '%s' script of job '%s' failed", s.name, failed)})
+				continue
+			}
+
+			running++
+
+			go func(script Script) {
+				resultsChan <- scriptResult{script, script.Run()}
+			}(script)
+		}
+
+		if running == 0 {
+			continue
+		}
+
+		finish(<-resultsChan)
+		running--
+	}
+
 	return s.summarizeErrs(passedScripts, failedScripts)
 }
 
@@ -97,6 +175,82 @@ func (s ParallelScript) findExistingScripts(all []Script) []Script {
 	return existing
 }
 
+// dependencies returns the distinct tags the script with the given tag waits for
+func (s ParallelScript) dependencies(tag string) []string {
+	var dependencies []string
+	seen := map[string]bool{}
+
+	for _, dependency := range s.dependsOn[tag] {
+		if !seen[dependency] {
+			seen[dependency] = true
+			dependencies = append(dependencies, dependency)
+		}
+	}
+
+	return dependencies
+}
+
+func (s ParallelScript) failedDependency(tag string, failedTags map[string]bool) string {
+	for _, dependency := range s.dependencies(tag) {
+		if failedTags[dependency] {
+			return dependency
+		}
+	}
+	return ""
+}
+
+// checkDependencyCycles rejects orderings that would never let all scripts run
+func (s ParallelScript) checkDependencyCycles() error {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := map[string]int{}
+
+	var visit func(tag string, path []string) error
+	visit = func(tag string, path []string) error {
+		path = append(path, tag)
+
+		switch state[tag] {
+		case visiting:
+			return bosherr.Errorf("Dependencies of %s scripts contain a cycle: %s", s.name, strings.Join(path, " -> "))
+		case visited:
+			return nil
+		}
+
+		state[tag] = visiting
+
+		for _, dependency := range s.dependencies(tag) {
+			err := visit(dependency, path)
+			if err != nil {
+				return err
+			}
+		}
+
+		state[tag] = visited
+
+		return nil
+	}
+
+	// Sorted so that the same cycle is always reported
+	var tags []string
+	for tag := range s.dependsOn {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	for _, tag := range tags {
+		err := visit(tag, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (s ParallelScript) summarizeErrs(passedScripts, failedScripts []string) error {
 	if len(failedScripts) > 0 {
 		errMsg := "Failed Jobs: " + strings.Join(failedScripts, ", ")
@@ -2,7 +2,9 @@ package script_test
 
 import (
 	"errors"
+	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	. "github.com/onsi/ginkgo"
@@ -16,16 +18,20 @@ import (
 var _ = Describe("ParallelScript", func() {
 	var (
 		scripts        []boshscript.Script
+		maxConcurrency int
+		dependsOn      map[string][]string
 		parallelScript boshscript.ParallelScript
 	)
 
 	BeforeEach(func() {
 		scripts = []boshscript.Script{}
+		maxConcurrency = 0
+		dependsOn = nil
 	})
 
 	JustBeforeEach(func() {
 		logger := boshlog.NewLogger(boshlog.LevelNone)
-		parallelScript = boshscript.NewParallelScript("run-me", scripts, logger)
+		parallelScript = boshscript.NewParallelScript("run-me", scripts, maxConcurrency, dependsOn, logger)
 
 	})
 
@@ -203,6 +209,220 @@ var _ = Describe("ParallelScript", func() {
 				close(done)
 			})
 		})
+
+		Context("when max concurrency is set", func() {
+			var (
+				running    int32
+				maxRunning int32
+			)
+
+			BeforeEach(func() {
+				maxConcurrency = 2
+				running = 0
+				maxRunning = 0
+
+				countRunning := func() error {
+					current := atomic.AddInt32(&running, 1)
+					defer atomic.AddInt32(&running, -1)
+
+					for {
+						seen := atomic.LoadInt32(&maxRunning)
+						if current <= seen || atomic.CompareAndSwapInt32(&maxRunning, seen, current) {
+							break
+						}
+					}
+
+					time.Sleep(50 * time.Millisecond)
+					return nil
+				}
+
+				for i := 0; i < 5; i++ {
+					script := &scriptfakes.FakeScript{}
+					script.TagReturns(fmt.Sprintf("fake-job-%d", i))
+					script.ExistsReturns(true)
+					script.RunStub = countRunning
+					scripts = append(scripts, script)
+				}
+			})
+
+			It("runs no more scripts at the same time than allowed", func() {
+				err := parallelScript.Run()
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(atomic.LoadInt32(&maxRunning)).To(Equal(int32(2)))
+
+				for _, script := range scripts {
+					Expect(script.(*scriptfakes.FakeScript).RunCallCount()).To(Equal(1))
+				}
+			})
+
+			It("still runs all scripts and reports every failure", func() {
+				scripts[1].(*scriptfakes.FakeScript).RunStub = func() error { return errors.New("fake-error") }
+				scripts[3].(*scriptfakes.FakeScript).RunStub = func() error { return errors.New("fake-error") }
+
+				err := parallelScript.Run()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("2 of 5 run-me scripts failed. Failed Jobs:"))
+				Expect(err.Error()).To(ContainSubstring("fake-job-1"))
+				Expect(err.Error()).To(ContainSubstring("fake-job-3"))
+			})
+		})
+
+		Context("when scripts depend on each other", func() {
+			var (
+				lock     sync.Mutex
+				started  []string
+				finished []string
+			)
+
+			addScript := func(tag string, run func() error) *scriptfakes.FakeScript {
+				script := &scriptfakes.FakeScript{}
+				script.TagReturns(tag)
+				script.ExistsReturns(true)
+				script.RunStub = func() error {
+					lock.Lock()
+					started = append(started, tag)
+					lock.Unlock()
+
+					err := run()
+
+					lock.Lock()
+					finished = append(finished, tag)
+					lock.Unlock()
+
+					return err
+				}
+				scripts = append(scripts, script)
+				return script
+			}
+
+			// Scripts and dependencies are only known once each example set them up
+			runScripts := func() error {
+				logger := boshlog.NewLogger(boshlog.LevelNone)
+				return boshscript.NewParallelScript("run-me", scripts, maxConcurrency, dependsOn, logger).Run()
+			}
+
+			succeed := func() error {
+				time.Sleep(50 * time.Millisecond)
+				return nil
+			}
+
+			BeforeEach(func() {
+				started = nil
+				finished = nil
+			})
+
+			It("runs a script only after the scripts it depends on finished", func() {
+				addScript("fake-job-3", succeed)
+				addScript("fake-job-2", succeed)
+				addScript("fake-job-1", succeed)
+				dependsOn = map[string][]string{
+					"fake-job-2": {"fake-job-1"},
+					"fake-job-3": {"fake-job-2", "fake-job-1"},
+				}
+
+				err := runScripts()
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(started).To(Equal([]string{"fake-job-1", "fake-job-2", "fake-job-3"}))
+				Expect(finished).To(Equal([]string{"fake-job-1", "fake-job-2", "fake-job-3"}))
+			})
+
+			It("runs scripts that do not depend on each other concurrently", func(done Done) {
+				waitGroup := &sync.WaitGroup{}
+				waitGroup.Add(2)
+
+				deadlockUnlessConcurrent := func() error {
+					waitGroup.Done()
+					waitGroup.Wait()
+					return nil
+				}
+
+				addScript("fake-job-1", deadlockUnlessConcurrent)
+				addScript("fake-job-2", deadlockUnlessConcurrent)
+				addScript("fake-job-3", succeed)
+				dependsOn = map[string][]string{
+					"fake-job-3": {"fake-job-1", "fake-job-2"},
+				}
+
+				err := runScripts()
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(started[2]).To(Equal("fake-job-3"))
+
+				close(done)
+			})
+
+			It("still respects max concurrency", func() {
+				maxConcurrency = 1
+
+				addScript("fake-job-1", succeed)
+				addScript("fake-job-2", succeed)
+				addScript("fake-job-3", succeed)
+				dependsOn = map[string][]string{
+					"fake-job-1": {"fake-job-3"},
+				}
+
+				err := runScripts()
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(started).To(Equal([]string{"fake-job-2", "fake-job-3", "fake-job-1"}))
+			})
+
+			It("ignores dependencies on jobs without the script", func() {
+				addScript("fake-job-1", succeed)
+				dependsOn = map[string][]string{
+					"fake-job-1": {"fake-job-without-script"},
+				}
+
+				err := runScripts()
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(started).To(Equal([]string{"fake-job-1"}))
+			})
+
+			It("does not run scripts depending on a failed script", func() {
+				addScript("fake-job-1", func() error { return errors.New("fake-error") })
+				dependent := addScript("fake-job-2", succeed)
+				addScript("fake-job-3", succeed)
+				dependsOn = map[string][]string{
+					"fake-job-2": {"fake-job-1"},
+				}
+
+				err := runScripts()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("2 of 3 run-me scripts failed. Failed Jobs: fake-job-1, fake-job-2. Successful Jobs: fake-job-3."))
+
+				Expect(dependent.RunCallCount()).To(Equal(0))
+			})
+
+			It("rejects cycles without running any script", func() {
+				script1 := addScript("fake-job-1", succeed)
+				script2 := addScript("fake-job-2", succeed)
+				dependsOn = map[string][]string{
+					"fake-job-1": {"fake-job-2"},
+					"fake-job-2": {"fake-job-1"},
+				}
+
+				err := runScripts()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Dependencies of run-me scripts contain a cycle: fake-job-1 -> fake-job-2 -> fake-job-1"))
+
+				Expect(script1.RunCallCount()).To(Equal(0))
+				Expect(script2.RunCallCount()).To(Equal(0))
+			})
+
+			It("rejects scripts depending on themselves", func() {
+				addScript("fake-job-1", succeed)
+				dependsOn = map[string][]string{
+					"fake-job-1": {"fake-job-1"},
+				}
+
+				err := runScripts()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Dependencies of run-me scripts contain a cycle: fake-job-1 -> fake-job-1"))
+			})
+		})
 	})
 
 	Describe("Cancel", func() {
@@ -7,9 +7,10 @@ import (
 //go:generate counterfeiter . JobScriptProvider
 
 type JobScriptProvider interface {
-	NewScript(jobName string, scriptName string, scriptEnv map[string]string) Script
+	NewScript(jobName string, scriptName string, scriptEnv map[string]string, opts Options) Script
 	NewDrainScript(jobName string, params boshdrain.ScriptParams) CancellableScript
-	NewParallelScript(scriptName string, scripts []Script) CancellableScript
+	NewHealthScript(jobName string) Script
+	NewParallelScript(scriptName string, scripts []Script, maxConcurrency int, dependsOn map[string][]string) CancellableScript
 }
 
 //go:generate counterfeiter . Script
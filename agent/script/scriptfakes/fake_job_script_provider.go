@@ -21,11 +21,24 @@ type FakeJobScriptProvider struct {
 	newDrainScriptReturnsOnCall map[int]struct {
 		result1 script.CancellableScript
 	}
-	NewParallelScriptStub        func(string, []script.Script) script.CancellableScript
+	NewHealthScriptStub        func(string) script.Script
+	newHealthScriptMutex       sync.RWMutex
+	newHealthScriptArgsForCall []struct {
+		arg1 string
+	}
+	newHealthScriptReturns struct {
+		result1 script.Script
+	}
+	newHealthScriptReturnsOnCall map[int]struct {
+		result1 script.Script
+	}
+	NewParallelScriptStub        func(string, []script.Script, int, map[string][]string) script.CancellableScript
 	newParallelScriptMutex       sync.RWMutex
 	newParallelScriptArgsForCall []struct {
 		arg1 string
 		arg2 []script.Script
+		arg3 int
+		arg4 map[string][]string
 	}
 	newParallelScriptReturns struct {
 		result1 script.CancellableScript
@@ -33,12 +46,13 @@ type FakeJobScriptProvider struct {
 	newParallelScriptReturnsOnCall map[int]struct {
 		result1 script.CancellableScript
 	}
-	NewScriptStub        func(string, string, map[string]string) script.Script
+	NewScriptStub        func(string, string, map[string]string, script.Options) script.Script
 	newScriptMutex       sync.RWMutex
 	newScriptArgsForCall []struct {
 		arg1 string
 		arg2 string
 		arg3 map[string]string
+		arg4 script.Options
 	}
 	newScriptReturns struct {
 		result1 script.Script
@@ -111,7 +125,67 @@ func (fake *FakeJobScriptProvider) NewDrainScriptReturnsOnCall(i int, result1 sc
 	}{result1}
 }
 
-func (fake *FakeJobScriptProvider) NewParallelScript(arg1 string, arg2 []script.Script) script.CancellableScript {
+func (fake *FakeJobScriptProvider) NewHealthScript(arg1 string) script.Script {
+	fake.newHealthScriptMutex.Lock()
+	ret, specificReturn := fake.newHealthScriptReturnsOnCall[len(fake.newHealthScriptArgsForCall)]
+	fake.newHealthScriptArgsForCall = append(fake.newHealthScriptArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	fake.recordInvocation("NewHealthScript", []interface{}{arg1})
+	fake.newHealthScriptMutex.Unlock()
+	if fake.NewHealthScriptStub != nil {
+		return fake.NewHealthScriptStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.newHealthScriptReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeJobScriptProvider) NewHealthScriptCallCount() int {
+	fake.newHealthScriptMutex.RLock()
+	defer fake.newHealthScriptMutex.RUnlock()
+	return len(fake.newHealthScriptArgsForCall)
+}
+
+func (fake *FakeJobScriptProvider) NewHealthScriptCalls(stub func(string) script.Script) {
+	fake.newHealthScriptMutex.Lock()
+	defer fake.newHealthScriptMutex.Unlock()
+	fake.NewHealthScriptStub = stub
+}
+
+func (fake *FakeJobScriptProvider) NewHealthScriptArgsForCall(i int) string {
+	fake.newHealthScriptMutex.RLock()
+	defer fake.newHealthScriptMutex.RUnlock()
+	argsForCall := fake.newHealthScriptArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeJobScriptProvider) NewHealthScriptReturns(result1 script.Script) {
+	fake.newHealthScriptMutex.Lock()
+	defer fake.newHealthScriptMutex.Unlock()
+	fake.NewHealthScriptStub = nil
+	fake.newHealthScriptReturns = struct {
+		result1 script.Script
+	}{result1}
+}
+
+func (fake *FakeJobScriptProvider) NewHealthScriptReturnsOnCall(i int, result1 script.Script) {
+	fake.newHealthScriptMutex.Lock()
+	defer fake.newHealthScriptMutex.Unlock()
+	fake.NewHealthScriptStub = nil
+	if fake.newHealthScriptReturnsOnCall == nil {
+		fake.newHealthScriptReturnsOnCall = make(map[int]struct {
+			result1 script.Script
+		})
+	}
+	fake.newHealthScriptReturnsOnCall[i] = struct {
+		result1 script.Script
+	}{result1}
+}
+
+func (fake *FakeJobScriptProvider) NewParallelScript(arg1 string, arg2 []script.Script, arg3 int, arg4 map[string][]string) script.CancellableScript {
 	var arg2Copy []script.Script
 	if arg2 != nil {
 		arg2Copy = make([]script.Script, len(arg2))
@@ -122,11 +196,13 @@ func (fake *FakeJobScriptProvider) NewParallelScript(arg1 string, arg2 []script.
 	fake.newParallelScriptArgsForCall = append(fake.newParallelScriptArgsForCall, struct {
 		arg1 string
 		arg2 []script.Script
-	}{arg1, arg2Copy})
-	fake.recordInvocation("NewParallelScript", []interface{}{arg1, arg2Copy})
+		arg3 int
+		arg4 map[string][]string
+	}{arg1, arg2Copy, arg3, arg4})
+	fake.recordInvocation("NewParallelScript", []interface{}{arg1, arg2Copy, arg3, arg4})
 	fake.newParallelScriptMutex.Unlock()
 	if fake.NewParallelScriptStub != nil {
-		return fake.NewParallelScriptStub(arg1, arg2)
+		return fake.NewParallelScriptStub(arg1, arg2, arg3, arg4)
 	}
 	if specificReturn {
 		return ret.result1
@@ -141,17 +217,17 @@ func (fake *FakeJobScriptProvider) NewParallelScriptCallCount() int {
 	return len(fake.newParallelScriptArgsForCall)
 }
 
-func (fake *FakeJobScriptProvider) NewParallelScriptCalls(stub func(string, []script.Script) script.CancellableScript) {
+func (fake *FakeJobScriptProvider) NewParallelScriptCalls(stub func(string, []script.Script, int, map[string][]string) script.CancellableScript) {
 	fake.newParallelScriptMutex.Lock()
 	defer fake.newParallelScriptMutex.Unlock()
 	fake.NewParallelScriptStub = stub
 }
 
-func (fake *FakeJobScriptProvider) NewParallelScriptArgsForCall(i int) (string, []script.Script) {
+func (fake *FakeJobScriptProvider) NewParallelScriptArgsForCall(i int) (string, []script.Script, int, map[string][]string) {
 	fake.newParallelScriptMutex.RLock()
 	defer fake.newParallelScriptMutex.RUnlock()
 	argsForCall := fake.newParallelScriptArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
 }
 
 func (fake *FakeJobScriptProvider) NewParallelScriptReturns(result1 script.CancellableScript) {
@@ -177,18 +253,19 @@ func (fake *FakeJobScriptProvider) NewParallelScriptReturnsOnCall(i int, result1
 	}{result1}
 }
 
-func (fake *FakeJobScriptProvider) NewScript(arg1 string, arg2 string, arg3 map[string]string) script.Script {
+func (fake *FakeJobScriptProvider) NewScript(arg1 string, arg2 string, arg3 map[string]string, arg4 script.Options) script.Script {
 	fake.newScriptMutex.Lock()
 	ret, specificReturn := fake.newScriptReturnsOnCall[len(fake.newScriptArgsForCall)]
 	fake.newScriptArgsForCall = append(fake.newScriptArgsForCall, struct {
 		arg1 string
 		arg2 string
 		arg3 map[string]string
-	}{arg1, arg2, arg3})
-	fake.recordInvocation("NewScript", []interface{}{arg1, arg2, arg3})
+		arg4 script.Options
+	}{arg1, arg2, arg3, arg4})
+	fake.recordInvocation("NewScript", []interface{}{arg1, arg2, arg3, arg4})
 	fake.newScriptMutex.Unlock()
 	if fake.NewScriptStub != nil {
-		return fake.NewScriptStub(arg1, arg2, arg3)
+		return fake.NewScriptStub(arg1, arg2, arg3, arg4)
 	}
 	if specificReturn {
 		return ret.result1
@@ -203,17 +280,17 @@ func (fake *FakeJobScriptProvider) NewScriptCallCount() int {
 	return len(fake.newScriptArgsForCall)
 }
 
-func (fake *FakeJobScriptProvider) NewScriptCalls(stub func(string, string, map[string]string) script.Script) {
+func (fake *FakeJobScriptProvider) NewScriptCalls(stub func(string, string, map[string]string, script.Options) script.Script) {
 	fake.newScriptMutex.Lock()
 	defer fake.newScriptMutex.Unlock()
 	fake.NewScriptStub = stub
 }
 
-func (fake *FakeJobScriptProvider) NewScriptArgsForCall(i int) (string, string, map[string]string) {
+func (fake *FakeJobScriptProvider) NewScriptArgsForCall(i int) (string, string, map[string]string, script.Options) {
 	fake.newScriptMutex.RLock()
 	defer fake.newScriptMutex.RUnlock()
 	argsForCall := fake.newScriptArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
 }
 
 func (fake *FakeJobScriptProvider) NewScriptReturns(result1 script.Script) {
@@ -244,6 +321,8 @@ func (fake *FakeJobScriptProvider) Invocations() map[string][][]interface{} {
 	defer fake.invocationsMutex.RUnlock()
 	fake.newDrainScriptMutex.RLock()
 	defer fake.newDrainScriptMutex.RUnlock()
+	fake.newHealthScriptMutex.RLock()
+	defer fake.newHealthScriptMutex.RUnlock()
 	fake.newParallelScriptMutex.RLock()
 	defer fake.newParallelScriptMutex.RUnlock()
 	fake.newScriptMutex.RLock()
@@ -1,9 +1,14 @@
 package fakes
 
-import boshtask "github.com/cloudfoundry/bosh-agent/agent/task"
+import (
+	"sync"
+
+	boshtask "github.com/cloudfoundry/bosh-agent/agent/task"
+)
 
 type FakeManager struct {
 	taskIDToTaskInfo map[string]boshtask.Info
+	taskInfosLock    sync.Mutex
 
 	AddInfoErr error
 }
@@ -13,6 +18,9 @@ func NewFakeManager() *FakeManager {
 }
 
 func (m *FakeManager) GetInfos() ([]boshtask.Info, error) {
+	m.taskInfosLock.Lock()
+	defer m.taskInfosLock.Unlock()
+
 	var taskInfos []boshtask.Info
 	for _, taskInfo := range m.taskIDToTaskInfo {
 		taskInfos = append(taskInfos, taskInfo)
@@ -21,11 +29,17 @@ func (m *FakeManager) GetInfos() ([]boshtask.Info, error) {
 }
 
 func (m *FakeManager) AddInfo(taskInfo boshtask.Info) error {
+	m.taskInfosLock.Lock()
+	defer m.taskInfosLock.Unlock()
+
 	m.taskIDToTaskInfo[taskInfo.TaskID] = taskInfo
 	return m.AddInfoErr
 }
 
 func (m *FakeManager) RemoveInfo(taskID string) error {
+	m.taskInfosLock.Lock()
+	defer m.taskInfosLock.Unlock()
+
 	delete(m.taskIDToTaskInfo, taskID)
 	return nil
 }
@@ -175,9 +175,31 @@ func (app *app) Setup(opts Options) error {
 		return bosherr.WrapError(err, "Failed constructing blobstore http client")
 	}
 
-	blobstoreDelegator := blobstore_delegator.NewBlobstoreDelegator(
-		httpblobprovider.NewHTTPBlobImpl(app.platform.GetFs(), blobstoreHTTPClient),
+	duplicateUploadMode, err := blobstore_delegator.DuplicateUploadModeFromSettings(settingsService.GetSettings().GetBlobstore())
+	if err != nil {
+		return bosherr.WrapError(err, "Getting blobstore duplicate uploads mode")
+	}
+
+	etagCacheMaxEntries, err := httpblobprovider.ETagCacheMaxEntriesFromSettings(settingsService.GetSettings().GetBlobstore())
+	if err != nil {
+		return bosherr.WrapError(err, "Getting blobstore ETag cache size")
+	}
+
+	httpBlobProvider := httpblobprovider.NewHTTPBlobImpl(app.platform.GetFs(), blobstoreHTTPClient)
+	if etagCacheMaxEntries > 0 {
+		httpBlobProvider = httpblobprovider.NewHTTPBlobImplWithETagCache(
+			app.platform.GetFs(),
+			blobstoreHTTPClient,
+			filepath.Join(app.dirProvider.DataDir(), "blob_etag_cache"),
+			etagCacheMaxEntries,
+		)
+	}
+
+	blobstoreDelegator := blobstore_delegator.NewBlobstoreDelegatorWithDuplicateUploadMode(
+		httpBlobProvider,
 		blobstore,
+		app.platform.GetFs(),
+		duplicateUploadMode,
 	)
 
 	applier, compiler := app.buildApplierAndCompiler(
@@ -211,6 +233,7 @@ func (app *app) Setup(opts Options) error {
 		app.platform,
 		sensitiveBlobManager,
 		taskService,
+		taskManager,
 		notifier,
 		applier,
 		compiler,
@@ -382,29 +405,17 @@ func (app *app) setupBlobstore(
 }
 
 func (app *app) patchBlobstoreOptions(blobstoreSettings boshsettings.Blobstore) boshsettings.Blobstore {
-	if blobstoreSettings.Type != boshblob.BlobstoreTypeLocal {
-		return blobstoreSettings
-	}
-
-	blobstorePath, ok := blobstoreSettings.Options["blobstore_path"]
-	if !ok {
-		return blobstoreSettings
-	}
-
-	pathStr, ok := blobstorePath.(string)
-	if !ok {
-		return blobstoreSettings
-	}
+	dir := app.dirProvider.BlobsDir()
 
-	if pathStr != "/var/vcap/micro_bosh/data/cache" {
+	blobstorePath, ok := blobstoreSettings.EffectiveLocalPath(dir)
+	if !ok || blobstorePath == blobstoreSettings.Options["blobstore_path"] {
 		return blobstoreSettings
 	}
 
-	dir := app.dirProvider.BlobsDir()
-	app.logger.Debug(app.logTag, fmt.Sprintf("Resetting local blobstore path to %s", dir))
+	app.logger.Debug(app.logTag, fmt.Sprintf("Resetting local blobstore path to %s", blobstorePath))
 
 	blobstoreSettings.Options = map[string]interface{}{
-		"blobstore_path": dir,
+		"blobstore_path": blobstorePath,
 	}
 
 	return blobstoreSettings
@@ -26,7 +26,11 @@ var _ = Describe("LoadConfigFromPath", func() {
 					"UsePreformattedPersistentDisk": true,
 					"BindMountPersistentDisk": true,
 					"SkipDiskSetup": true,
-					"DevicePathResolutionType": "virtio"
+					"SkipSwapCreation": true,
+					"PreserveAuthorizedKeys": true,
+					"EphemeralDiskFileSystemType": "xfs",
+					"DevicePathResolutionType": "virtio",
+					"DevicePathResolutionTimeoutInSeconds": 300
 				}
 			},
 			"Infrastructure": {
@@ -75,7 +79,12 @@ var _ = Describe("LoadConfigFromPath", func() {
 					UsePreformattedPersistentDisk: true,
 					BindMountPersistentDisk:       true,
 					SkipDiskSetup:                 true,
+					SkipSwapCreation:              true,
+					PreserveAuthorizedKeys:        true,
+					EphemeralDiskFileSystemType:   "xfs",
 					DevicePathResolutionType:      "virtio",
+
+					DevicePathResolutionTimeoutInSeconds: 300,
 				},
 			},
 			Infrastructure: boshinf.Options{
@@ -1,8 +1,10 @@
 package devicepathresolver
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
@@ -11,8 +13,14 @@ import (
 	boshsys "github.com/cloudfoundry/bosh-utils/system"
 )
 
+const defaultMappedDevicePollInterval = 100 * time.Millisecond
+
+// Matches the drive letter and optional partition number of e.g. "b1" in /dev/sdb1
+var mappedDeviceSuffixRegexp = regexp.MustCompile(`^([a-z])([0-9]*)$`)
+
 type mappedDevicePathResolver struct {
 	diskWaitTimeout time.Duration
+	pollInterval    time.Duration
 	fs              boshsys.FileSystem
 }
 
@@ -20,7 +28,17 @@ func NewMappedDevicePathResolver(
 	diskWaitTimeout time.Duration,
 	fs boshsys.FileSystem,
 ) DevicePathResolver {
-	return mappedDevicePathResolver{fs: fs, diskWaitTimeout: diskWaitTimeout}
+	return NewMappedDevicePathResolverWithPollInterval(diskWaitTimeout, defaultMappedDevicePollInterval, fs)
+}
+
+// NewMappedDevicePathResolverWithPollInterval checks for the device every
+// pollInterval until diskWaitTimeout has passed
+func NewMappedDevicePathResolverWithPollInterval(
+	diskWaitTimeout time.Duration,
+	pollInterval time.Duration,
+	fs boshsys.FileSystem,
+) DevicePathResolver {
+	return mappedDevicePathResolver{fs: fs, diskWaitTimeout: diskWaitTimeout, pollInterval: pollInterval}
 }
 
 func (dpr mappedDevicePathResolver) GetRealDevicePath(diskSettings boshsettings.DiskSettings) (string, bool, error) {
@@ -41,7 +59,7 @@ func (dpr mappedDevicePathResolver) GetRealDevicePath(diskSettings boshsettings.
 			return "", true, bosherr.Errorf("Timed out getting real device path for %s", devicePath)
 		}
 
-		time.Sleep(100 * time.Millisecond)
+		time.Sleep(dpr.pollInterval)
 
 		realPath, found, err = dpr.findPossibleDevice(devicePath)
 		if err != nil {
@@ -64,8 +82,16 @@ func (dpr mappedDevicePathResolver) findPossibleDevice(devicePath string) (strin
 			"/dev/sd",
 		}
 
+		var possiblePaths []string
 		for _, prefix := range possiblePrefixes {
-			path := prefix + pathSuffix
+			possiblePaths = append(possiblePaths, prefix+pathSuffix)
+		}
+
+		if nvmePath, ok := nvmeDevicePath(pathSuffix); ok {
+			possiblePaths = append(possiblePaths, nvmePath)
+		}
+
+		for _, path := range possiblePaths {
 			if dpr.fs.FileExists(path) {
 				return path, true, nil
 			}
@@ -90,3 +116,21 @@ func (dpr mappedDevicePathResolver) findPossibleDevice(devicePath string) (strin
 
 	return "", false, nil
 }
+
+// nvmeDevicePath maps a drive letter to the NVMe namespace of the controller
+// with the same index, e.g. "b" to /dev/nvme1n1, as NVMe based instances
+// (e.g. AWS Nitro) number controllers in the order of the requested devices
+func nvmeDevicePath(pathSuffix string) (string, bool) {
+	matches := mappedDeviceSuffixRegexp.FindStringSubmatch(pathSuffix)
+	if matches == nil {
+		return "", false
+	}
+
+	path := fmt.Sprintf("/dev/nvme%dn1", matches[1][0]-'a')
+
+	if matches[2] != "" {
+		path += "p" + matches[2]
+	}
+
+	return path, true
+}
@@ -84,6 +84,39 @@ var _ = Describe("mappedDevicePathResolver", func() {
 		})
 	})
 
+	Context("when only an NVMe device is found", func() {
+		BeforeEach(func() {
+			diskSettings = boshsettings.DiskSettings{Path: "/dev/sdb"}
+			fs.WriteFile("/dev/nvme0n1", []byte{})
+			fs.WriteFile("/dev/nvme1n1", []byte{})
+		})
+
+		It("returns the NVMe namespace of the controller matching the drive letter", func() {
+			realPath, timedOut, err := resolver.GetRealDevicePath(diskSettings)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(timedOut).To(BeFalse())
+			Expect(realPath).To(Equal("/dev/nvme1n1"))
+		})
+
+		It("maps partitions to NVMe partitions", func() {
+			fs.WriteFile("/dev/nvme1n1p2", []byte{})
+			diskSettings = boshsettings.DiskSettings{Path: "/dev/sdb2"}
+
+			realPath, timedOut, err := resolver.GetRealDevicePath(diskSettings)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(timedOut).To(BeFalse())
+			Expect(realPath).To(Equal("/dev/nvme1n1p2"))
+		})
+
+		It("prefers non NVMe devices", func() {
+			fs.WriteFile("/dev/xvdb", []byte{})
+
+			realPath, _, err := resolver.GetRealDevicePath(diskSettings)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(realPath).To(Equal("/dev/xvdb"))
+		})
+	})
+
 	Context("when no matching device is found the first time", func() {
 		Context("when the timeout has not expired", func() {
 			BeforeEach(func() {
@@ -108,6 +141,24 @@ var _ = Describe("mappedDevicePathResolver", func() {
 				Expect(timedOut).To(BeTrue())
 			})
 		})
+
+		Context("when a short timeout and poll interval are configured", func() {
+			BeforeEach(func() {
+				resolver = NewMappedDevicePathResolverWithPollInterval(50*time.Millisecond, 5*time.Millisecond, fs)
+			})
+
+			It("errs once the short timeout expired", func() {
+				startedAt := time.Now()
+
+				_, timedOut, err := resolver.GetRealDevicePath(diskSettings)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Timed out getting real device path for /dev/sda"))
+				Expect(timedOut).To(BeTrue())
+
+				Expect(time.Since(startedAt)).To(BeNumerically(">=", 50*time.Millisecond))
+				Expect(time.Since(startedAt)).To(BeNumerically("<", 500*time.Millisecond))
+			})
+		})
 	})
 
 	Context("when a path that never needs remapping is passed in", func() {
@@ -106,7 +106,7 @@ var _ = Describe("compile_package", func() {
 		})
 
 		It("compiles and stores it to the blobstore", func() {
-			_, err := agentClient.CompilePackageWithSignedURL(action.CompilePackageWithSignedURLRequest{
+			result, err := agentClient.CompilePackageWithSignedURL(action.CompilePackageWithSignedURLRequest{
 				PackageGetSignedURL: dummyPackageSignedURL,
 				UploadSignedURL:     compiledDummyPackagePutURL,
 
@@ -120,6 +120,13 @@ var _ = Describe("compile_package", func() {
 			output, err := testEnvironment.RunCommand(fmt.Sprintf("sudo stat %s/%s", testEnvironment.AssetsDir(), compiledPackagePath))
 			Expect(err).NotTo(HaveOccurred())
 			Expect(output).To(MatchRegexp("regular file"))
+
+			output, err = testEnvironment.RunCommand(fmt.Sprintf("sudo stat -c %%s %s/%s", testEnvironment.AssetsDir(), compiledPackagePath))
+			Expect(err).NotTo(HaveOccurred())
+
+			size := result["result"].(map[string]interface{})["size"]
+			Expect(size).To(BeNumerically(">", 0))
+			Expect(fmt.Sprintf("%d", size)).To(Equal(strings.TrimSpace(output)))
 		})
 
 		It("allows passing bare sha1 for legacy support", func() {
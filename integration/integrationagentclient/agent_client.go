@@ -143,9 +143,15 @@ func (c *IntegrationAgentClient) CompilePackageWithSignedURL(req action.CompileP
 		return map[string]interface{}{}, bosherr.Errorf("Unable to parse 'compile_package' response from the agent: %#v", responseValue)
 	}
 
+	size, ok := result["size"].(float64)
+	if !ok {
+		return map[string]interface{}{}, bosherr.Errorf("Unable to parse 'compile_package' response from the agent: %#v", responseValue)
+	}
+
 	return map[string]interface{}{
-		"result": map[string]string{
+		"result": map[string]interface{}{
 			"sha1": sha1,
+			"size": int64(size),
 		},
 	}, nil
 }
@@ -41,18 +41,34 @@ func (p HandlerProvider) Get(
 		return
 	}
 
-	mbusURL, err := url.Parse(p.settingsService.GetSettings().GetMbusURL())
+	settings := p.settingsService.GetSettings()
+
+	mbusURL, err := url.Parse(settings.GetMbusURL())
 	if err != nil {
 		err = bosherr.WrapError(err, "Parsing handler URL")
 		return
 	}
 
+	// Only the NATS handler connects out, the HTTPS handler listens on the primary URL
+	for i, fallback := range settings.MbusFallback {
+		fallbackURL, parseErr := url.Parse(fallback)
+		if parseErr != nil {
+			err = bosherr.WrapErrorf(parseErr, "Parsing fallback handler URL %d", i+1)
+			return
+		}
+
+		if mbusURL.Scheme != "nats" || fallbackURL.Scheme != "nats" {
+			err = bosherr.Errorf("Fallback handler URL %d with scheme %s is not supported for scheme %s", i+1, fallbackURL.Scheme, mbusURL.Scheme)
+			return
+		}
+	}
+
 	switch mbusURL.Scheme {
 	case "nats":
 		natsClient := NewTimeoutNatsClient(yagnats.NewClient(), clock.NewClock())
 		handler = NewNatsHandler(p.settingsService, natsClient, p.logger, platform, natsConnectRetryInterval, natsConnectMaxRetryInterval)
 	case "https":
-		mbusKeyPair := p.settingsService.GetSettings().Env.Bosh.Mbus.Cert
+		mbusKeyPair := settings.Env.Bosh.Mbus.Cert
 		handler = NewHTTPSHandler(mbusURL, mbusKeyPair, blobManager, p.logger, p.auditLogger)
 	default:
 		err = bosherr.Errorf("Message Bus Handler with scheme %s could not be found", mbusURL.Scheme)
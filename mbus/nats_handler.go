@@ -58,6 +58,9 @@ type natsHandler struct {
 
 	connectRetryInterval    time.Duration
 	maxConnectRetryInterval time.Duration
+
+	// Either the primary or a fallback mbus URL once connected
+	connectedURL string
 }
 
 func NewNatsHandler(
@@ -97,11 +100,21 @@ func (h *natsHandler) Run(handlerFunc boshhandler.Func) error {
 func (h *natsHandler) Start(handlerFunc boshhandler.Func) error {
 	h.RegisterAdditionalFunc(handlerFunc)
 
-	connProvider, err := h.getConnectionInfo()
-	if err != nil {
-		return bosherr.WrapError(err, "Getting connection info")
+	mbusURLs := h.settingsService.GetSettings().GetMbusURLs()
+
+	var err error
+
+	connProviders := make([]*yagnats.ConnectionInfo, len(mbusURLs))
+	for i, mbusURL := range mbusURLs {
+		connProviders[i], err = h.getConnectionInfo(mbusURL)
+		if err != nil {
+			return bosherr.WrapError(err, "Getting connection info")
+		}
 	}
 
+	// Points at the URL currently being connected to
+	connProvider := connProviders[0]
+
 	h.client.BeforeConnectCallback(func() {
 		hostSplit := strings.Split(connProvider.Addr, ":")
 		ip := hostSplit[0]
@@ -116,14 +129,28 @@ func (h *natsHandler) Start(handlerFunc boshhandler.Func) error {
 		}
 	})
 
+	// Every attempt goes through the fallback URLs in order before
+	// backing off; URLs are not logged since they carry credentials
 	natsRetryable := boshretry.NewRetryable(func() (bool, error) {
-		h.logger.Info(h.logTag, "Attempting to connect to NATS")
+		var err error
 
-		err := h.client.Connect(connProvider)
-		if err != nil {
-			return true, bosherr.WrapError(err, "Connecting to NATS")
+		for i, candidate := range connProviders {
+			if i == 0 {
+				h.logger.Info(h.logTag, "Attempting to connect to NATS")
+			} else {
+				h.logger.Info(h.logTag, "Attempting to connect to fallback NATS %d", i)
+			}
+
+			connProvider = candidate
+
+			err = h.client.Connect(connProvider)
+			if err == nil {
+				h.connectedURL = mbusURLs[i]
+				return false, nil
+			}
 		}
-		return false, nil
+
+		return true, bosherr.WrapError(err, "Connecting to NATS")
 	})
 
 	attemptRetryStrategy := boshretry.NewBackoffWithJitterRetryStrategy(
@@ -244,10 +271,10 @@ func (h *natsHandler) runUntilInterrupted() {
 	}
 }
 
-func (h *natsHandler) getConnectionInfo() (*yagnats.ConnectionInfo, error) {
+func (h *natsHandler) getConnectionInfo(mbusURL string) (*yagnats.ConnectionInfo, error) {
 	settings := h.settingsService.GetSettings()
 
-	natsURL, err := url.Parse(settings.GetMbusURL())
+	natsURL, err := url.Parse(mbusURL)
 	if err != nil {
 		return nil, bosherr.WrapError(err, "Parsing Nats URL")
 	}
@@ -291,9 +318,12 @@ func (h *natsHandler) getConnectionInfo() (*yagnats.ConnectionInfo, error) {
 func (h *natsHandler) generateCEFLog(natsMsg *yagnats.Message, severity int, statusReason string) {
 	cef := boshhandler.NewCommonEventFormat()
 
-	settings := h.settingsService.GetSettings()
+	mbusURL := h.connectedURL
+	if mbusURL == "" {
+		mbusURL = h.settingsService.GetSettings().GetMbusURL()
+	}
 
-	natsURL, err := url.Parse(settings.GetMbusURL())
+	natsURL, err := url.Parse(mbusURL)
 	if err != nil {
 		h.logger.Error(natsHandlerLogTag, err.Error())
 		return
@@ -7,19 +7,19 @@ import (
 	. "github.com/onsi/gomega"
 
 	platform "github.com/cloudfoundry/bosh-agent/platform"
-	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	fakefs "github.com/cloudfoundry/bosh-agent/platform/fakefs"
 )
 
 var _ = Describe("State", func() {
 	var (
-		fs   *fakesys.FakeFileSystem
+		fs   *fakefs.FakeFileSystem
 		path string
 		s    *platform.BootstrapState
 		err  error
 	)
 
 	BeforeEach(func() {
-		fs = fakesys.NewFakeFileSystem()
+		fs = fakefs.NewFakeFileSystem()
 		path = "/agent_state.json"
 		s, err = platform.NewBootstrapState(fs, path)
 		Expect(err).NotTo(HaveOccurred())
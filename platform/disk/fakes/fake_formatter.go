@@ -9,6 +9,14 @@ type FakeFormatter struct {
 	FormatPartitionPaths []string
 	FormatFsTypes        []boshdisk.FileSystemType
 	FormatError          error
+
+	GrowFilesystemPartitionPaths []string
+	GrowFilesystemFsTypes        []boshdisk.FileSystemType
+	GrowFilesystemError          error
+
+	CheckFilesystemPartitionPaths []string
+	CheckFilesystemRepaired       bool
+	CheckFilesystemError          error
 }
 
 func (p *FakeFormatter) Format(partitionPath string, fsType boshdisk.FileSystemType) (err error) {
@@ -20,3 +28,14 @@ func (p *FakeFormatter) Format(partitionPath string, fsType boshdisk.FileSystemT
 	p.FormatFsTypes = append(p.FormatFsTypes, fsType)
 	return
 }
+
+func (p *FakeFormatter) GrowFilesystem(partitionPath string, fsType boshdisk.FileSystemType) error {
+	p.GrowFilesystemPartitionPaths = append(p.GrowFilesystemPartitionPaths, partitionPath)
+	p.GrowFilesystemFsTypes = append(p.GrowFilesystemFsTypes, fsType)
+	return p.GrowFilesystemError
+}
+
+func (p *FakeFormatter) CheckFilesystem(partitionPath string) (bool, error) {
+	p.CheckFilesystemPartitionPaths = append(p.CheckFilesystemPartitionPaths, partitionPath)
+	return p.CheckFilesystemRepaired, p.CheckFilesystemError
+}
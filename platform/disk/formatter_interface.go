@@ -11,4 +11,9 @@ const (
 
 type Formatter interface {
 	Format(partitionPath string, fsType FileSystemType) (err error)
+	GrowFilesystem(partitionPath string, fsType FileSystemType) (err error)
+
+	// CheckFilesystem checks the filesystem on an unmounted partition and
+	// repairs what can be repaired automatically
+	CheckFilesystem(partitionPath string) (repaired bool, err error)
 }
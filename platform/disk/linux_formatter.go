@@ -62,6 +62,54 @@ func (f linuxFormatter) Format(partitionPath string, fsType FileSystemType) (err
 	return
 }
 
+// GrowFilesystem extends the filesystem on the partition to the size of the partition
+func (f linuxFormatter) GrowFilesystem(partitionPath string, fsType FileSystemType) error {
+	if fsType != FileSystemExt4 {
+		return bosherr.Errorf("Growing %s filesystems is not supported", fsType)
+	}
+
+	_, _, _, err := f.runner.RunCommand("resize2fs", "-f", partitionPath)
+	if err != nil {
+		return bosherr.WrapError(err, "Shelling out to resize2fs")
+	}
+
+	return nil
+}
+
+// CheckFilesystem runs fsck in preen mode on ext4 filesystems, which only
+// fixes problems that are safe to fix without an operator. XFS repairs itself
+// through its log on mount, so xfs_repair only looks for further corruption.
+// Partitions without a supported filesystem are left alone.
+func (f linuxFormatter) CheckFilesystem(partitionPath string) (bool, error) {
+	fsType, err := f.getPartitionFormatType(partitionPath)
+	if err != nil {
+		return false, bosherr.WrapError(err, "Checking filesystem format of partition")
+	}
+
+	switch fsType {
+	case FileSystemExt4:
+		_, _, exitStatus, err := f.runner.RunCommand("fsck", "-p", partitionPath)
+		if err == nil {
+			return false, nil
+		}
+
+		// 1 and 2 tell that errors were corrected, anything else that they were not
+		if exitStatus == 1 || exitStatus == 2 {
+			return true, nil
+		}
+
+		return false, bosherr.WrapErrorf(err, "Filesystem on %s has errors fsck could not repair automatically", partitionPath)
+
+	case FileSystemXFS:
+		_, _, _, err := f.runner.RunCommand("xfs_repair", "-n", partitionPath)
+		if err != nil {
+			return false, bosherr.WrapErrorf(err, "Filesystem on %s has errors xfs_repair could not repair automatically", partitionPath)
+		}
+	}
+
+	return false, nil
+}
+
 func (f linuxFormatter) makeFileSystemExt4(partitionPath string) error {
 	var err error
 	if f.fs.FileExists("/sys/fs/ext4/features/lazy_itable_init") {
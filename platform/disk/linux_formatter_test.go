@@ -205,4 +205,109 @@ var _ = Describe("Linux Formatter", func() {
 			Expect(err.Error()).To(Equal("Shelling out to mkfs.xfs: Sadness"))
 		})
 	})
+
+	Describe("GrowFilesystem", func() {
+		It("resizes an ext4 filesystem to fill the partition", func() {
+			fakeRunner := fakesys.NewFakeCmdRunner()
+			fakeFs := fakesys.NewFakeFileSystem()
+
+			formatter := NewLinuxFormatter(fakeRunner, fakeFs)
+			err := formatter.GrowFilesystem("/dev/xvdb2", FileSystemExt4)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeRunner.RunCommands).To(Equal([][]string{{"resize2fs", "-f", "/dev/xvdb2"}}))
+		})
+
+		It("returns an error when resize2fs fails", func() {
+			fakeRunner := fakesys.NewFakeCmdRunner()
+			fakeFs := fakesys.NewFakeFileSystem()
+			fakeRunner.AddCmdResult("resize2fs -f /dev/xvdb2", fakesys.FakeCmdResult{Error: errors.New("fake-resize2fs-error")})
+
+			formatter := NewLinuxFormatter(fakeRunner, fakeFs)
+			err := formatter.GrowFilesystem("/dev/xvdb2", FileSystemExt4)
+
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-resize2fs-error"))
+		})
+
+		It("returns an error for filesystems other than ext4", func() {
+			fakeRunner := fakesys.NewFakeCmdRunner()
+			fakeFs := fakesys.NewFakeFileSystem()
+
+			formatter := NewLinuxFormatter(fakeRunner, fakeFs)
+			err := formatter.GrowFilesystem("/dev/xvdb2", FileSystemXFS)
+
+			Expect(err).To(MatchError("Growing xfs filesystems is not supported"))
+			Expect(fakeRunner.RunCommands).To(BeEmpty())
+		})
+	})
+
+	Describe("CheckFilesystem", func() {
+		var (
+			fakeRunner *fakesys.FakeCmdRunner
+			formatter  Formatter
+		)
+
+		BeforeEach(func() {
+			fakeRunner = fakesys.NewFakeCmdRunner()
+			formatter = NewLinuxFormatter(fakeRunner, fakesys.NewFakeFileSystem())
+		})
+
+		Context("when the partition is formatted with ext4", func() {
+			BeforeEach(func() {
+				fakeRunner.AddCmdResult("blkid -p /dev/xvdc1", fakesys.FakeCmdResult{Stdout: `xxxxx TYPE="ext4" yyyy zzzz`})
+			})
+
+			It("runs fsck in preen mode on a clean filesystem", func() {
+				repaired, err := formatter.CheckFilesystem("/dev/xvdc1")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(repaired).To(BeFalse())
+				Expect(fakeRunner.RunCommands).To(ContainElement([]string{"fsck", "-p", "/dev/xvdc1"}))
+			})
+
+			It("reports errors fsck corrected", func() {
+				fakeRunner.AddCmdResult("fsck -p /dev/xvdc1", fakesys.FakeCmdResult{ExitStatus: 1, Error: errors.New("Exit code 1")})
+
+				repaired, err := formatter.CheckFilesystem("/dev/xvdc1")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(repaired).To(BeTrue())
+			})
+
+			It("returns an error when fsck cannot repair the filesystem", func() {
+				fakeRunner.AddCmdResult("fsck -p /dev/xvdc1", fakesys.FakeCmdResult{ExitStatus: 4, Error: errors.New("Exit code 4")})
+
+				_, err := formatter.CheckFilesystem("/dev/xvdc1")
+				Expect(err).To(MatchError("Filesystem on /dev/xvdc1 has errors fsck could not repair automatically: Exit code 4"))
+			})
+		})
+
+		Context("when the partition is formatted with xfs", func() {
+			BeforeEach(func() {
+				fakeRunner.AddCmdResult("blkid -p /dev/xvdc1", fakesys.FakeCmdResult{Stdout: `xxxxx TYPE="xfs" yyyy zzzz`})
+			})
+
+			It("checks the filesystem without modifying it", func() {
+				repaired, err := formatter.CheckFilesystem("/dev/xvdc1")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(repaired).To(BeFalse())
+				Expect(fakeRunner.RunCommands).To(ContainElement([]string{"xfs_repair", "-n", "/dev/xvdc1"}))
+			})
+
+			It("returns an error when the filesystem is corrupt", func() {
+				fakeRunner.AddCmdResult("xfs_repair -n /dev/xvdc1", fakesys.FakeCmdResult{ExitStatus: 1, Error: errors.New("Exit code 1")})
+
+				_, err := formatter.CheckFilesystem("/dev/xvdc1")
+				Expect(err).To(MatchError("Filesystem on /dev/xvdc1 has errors xfs_repair could not repair automatically: Exit code 1"))
+			})
+		})
+
+		It("does not check partitions without a supported filesystem", func() {
+			fakeRunner.AddCmdResult("blkid -p /dev/xvdc1", fakesys.FakeCmdResult{ExitStatus: 2, Error: errors.New("Exit code 2")})
+
+			repaired, err := formatter.CheckFilesystem("/dev/xvdc1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(repaired).To(BeFalse())
+			Expect(fakeRunner.RunCommands).To(Equal([][]string{{"blkid", "-p", "/dev/xvdc1"}}))
+		})
+	})
 })
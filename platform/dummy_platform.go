@@ -104,6 +104,19 @@ func (p dummyPlatform) GetVitalsService() (service boshvitals.Service) {
 	return p.vitalsService
 }
 
+func (p dummyPlatform) GetStatsCollector() boshstats.Collector {
+	return p.collector
+}
+
+func (p dummyPlatform) GetCPULoad() (boshstats.CPULoad, error) {
+	load, err := p.collector.GetCPULoad()
+	if err != nil {
+		return boshstats.CPULoad{}, bosherr.WrapError(err, "Getting CPU load")
+	}
+
+	return load, nil
+}
+
 func (p dummyPlatform) GetDevicePathResolver() (devicePathResolver boshdpresolv.DevicePathResolver) {
 	return p.devicePathResolver
 }
@@ -186,7 +199,7 @@ func (p dummyPlatform) GetCertManager() (certManager boshcert.Manager) {
 	return p.certManager
 }
 
-func (p dummyPlatform) SetupLogrotate(groupName, basePath, size string) (err error) {
+func (p dummyPlatform) SetupLogrotate(groupName, basePath, size string, maxLogFiles int, jobMaxLogFiles map[string]int) (err error) {
 	return
 }
 
@@ -194,6 +207,10 @@ func (p dummyPlatform) SetTimeWithNtpServers(servers []string) (err error) {
 	return
 }
 
+func (p dummyPlatform) GrowEphemeralPartition(devicePath string) (err error) {
+	return
+}
+
 func (p dummyPlatform) SetupEphemeralDiskWithPath(devicePath string, desiredSwapSizeInBytes *uint64, labelPrefix string) (err error) {
 	return
 }
@@ -202,6 +219,10 @@ func (p dummyPlatform) SetupRawEphemeralDisks(devices []boshsettings.DiskSetting
 	return
 }
 
+func (p dummyPlatform) SetupAdditionalEphemeralDisks(devicePaths []string, labelPrefix string) (err error) {
+	return
+}
+
 func (p dummyPlatform) SetupDataDir(_ boshsettings.JobDir, _ boshsettings.RunDir) error {
 	dataDir := p.dirProvider.DataDir()
 
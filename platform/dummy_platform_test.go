@@ -13,6 +13,7 @@ import (
 
 	boshdpresolv "github.com/cloudfoundry/bosh-agent/infrastructure/devicepathresolver"
 	fakedpresolv "github.com/cloudfoundry/bosh-agent/infrastructure/devicepathresolver/fakes"
+	fakefs "github.com/cloudfoundry/bosh-agent/platform/fakefs"
 	"github.com/cloudfoundry/bosh-agent/platform/fakes"
 	boshstats "github.com/cloudfoundry/bosh-agent/platform/stats"
 	fakestats "github.com/cloudfoundry/bosh-agent/platform/stats/fakes"
@@ -33,7 +34,7 @@ var _ = Describe("DummyPlatform", func() {
 	var (
 		platform           Platform
 		collector          boshstats.Collector
-		fs                 *fakesys.FakeFileSystem
+		fs                 *fakefs.FakeFileSystem
 		cmdRunner          boshsys.CmdRunner
 		dirProvider        boshdirs.Provider
 		devicePathResolver boshdpresolv.DevicePathResolver
@@ -43,7 +44,7 @@ var _ = Describe("DummyPlatform", func() {
 
 	BeforeEach(func() {
 		collector = &fakestats.FakeCollector{}
-		fs = fakesys.NewFakeFileSystem()
+		fs = fakefs.NewFakeFileSystem()
 		cmdRunner = fakesys.NewFakeCmdRunner()
 		dirProvider = boshdirs.NewProvider("/fake-dir")
 		devicePathResolver = fakedpresolv.NewFakeDevicePathResolver()
@@ -273,7 +274,7 @@ var _ = Describe("DummyPlatform", func() {
 
 			stat := fs.GetFileTestStat(filepath.Clean("/fake-dir/data/blobs"))
 
-			Expect(stat.FileType).To(Equal(fakesys.FakeFileTypeDir))
+			Expect(stat.FileType).To(Equal(fakefs.FakeFileTypeDir))
 			Expect(stat.FileMode).To(Equal(os.FileMode(0700)))
 		})
 	})
@@ -285,7 +286,7 @@ var _ = Describe("DummyPlatform", func() {
 
 			stat := fs.GetFileTestStat(filepath.Clean("/fake-dir/bosh/settings"))
 
-			Expect(stat.FileType).To(Equal(fakesys.FakeFileTypeDir))
+			Expect(stat.FileType).To(Equal(fakefs.FakeFileTypeDir))
 			Expect(stat.FileMode).To(Equal(os.FileMode(0700)))
 
 		})
@@ -0,0 +1,645 @@
+package fakefs_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-agent/platform/fakefs"
+)
+
+var _ = Describe("FakeFileSystem", func() {
+	var (
+		fs *FakeFileSystem
+	)
+
+	BeforeEach(func() {
+		fs = NewFakeFileSystem()
+	})
+
+	Describe("CopyFile", func() {
+		BeforeEach(func() {
+			err := fs.WriteFileString("/src", "original")
+			Expect(err).ToNot(HaveOccurred())
+
+			err = fs.Chmod("/src", 0640)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = fs.Chown("/src", "vcap")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("copies contents, mode, type and owner to the destination", func() {
+			err := fs.CopyFile("/src", "/dst")
+			Expect(err).ToNot(HaveOccurred())
+
+			dstStats := fs.GetFileTestStat("/dst")
+			Expect(dstStats.StringContents()).To(Equal("original"))
+			Expect(dstStats.FileMode).To(Equal(os.FileMode(0640)))
+			Expect(dstStats.FileType).To(Equal(FakeFileTypeFile))
+			Expect(dstStats.Username).To(Equal("vcap"))
+		})
+
+		It("does not change the destination when the source is written after copying", func() {
+			err := fs.CopyFile("/src", "/dst")
+			Expect(err).ToNot(HaveOccurred())
+
+			err = fs.WriteFileString("/src", "modified")
+			Expect(err).ToNot(HaveOccurred())
+
+			err = fs.Chmod("/src", 0600)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fs.ReadFileString("/dst")).To(Equal("original"))
+			Expect(fs.GetFileTestStat("/dst").FileMode).To(Equal(os.FileMode(0640)))
+		})
+
+		It("does not change the source when the destination is written after copying", func() {
+			err := fs.CopyFile("/src", "/dst")
+			Expect(err).ToNot(HaveOccurred())
+
+			err = fs.WriteFileString("/dst", "modified")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fs.ReadFileString("/src")).To(Equal("original"))
+		})
+	})
+
+	Describe("CopyDir", func() {
+		BeforeEach(func() {
+			err := fs.MkdirAll("/src/nested/deeper", 0750)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = fs.WriteFileString("/src/top.conf", "top")
+			Expect(err).ToNot(HaveOccurred())
+
+			err = fs.WriteFileString("/src/nested/deeper/leaf.conf", "leaf")
+			Expect(err).ToNot(HaveOccurred())
+
+			err = fs.Chmod("/src/nested/deeper/leaf.conf", 0600)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = fs.Symlink("/src/top.conf", "/src/nested/link")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("recreates nested files, directories and modes under the destination", func() {
+			err := fs.CopyDir("/src", "/dst")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fs.ReadFileString("/dst/top.conf")).To(Equal("top"))
+			Expect(fs.ReadFileString("/dst/nested/deeper/leaf.conf")).To(Equal("leaf"))
+			Expect(fs.GetFileTestStat("/dst/nested/deeper/leaf.conf").FileMode).To(Equal(os.FileMode(0600)))
+			Expect(fs.GetFileTestStat("/dst/nested/deeper").FileType).To(Equal(FakeFileTypeDir))
+			Expect(fs.GetFileTestStat("/dst/nested/deeper").FileMode).To(Equal(os.FileMode(0750)))
+		})
+
+		It("copies symlinks as symlinks", func() {
+			err := fs.CopyDir("/src", "/dst")
+			Expect(err).ToNot(HaveOccurred())
+
+			linkStats := fs.GetFileTestStat("/dst/nested/link")
+			Expect(linkStats.FileType).To(Equal(FakeFileTypeSymlink))
+			Expect(linkStats.SymlinkTarget).To(Equal("/src/top.conf"))
+		})
+
+		It("does not share contents between source and destination", func() {
+			err := fs.CopyDir("/src", "/dst")
+			Expect(err).ToNot(HaveOccurred())
+
+			err = fs.WriteFileString("/src/nested/deeper/leaf.conf", "modified")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fs.ReadFileString("/dst/nested/deeper/leaf.conf")).To(Equal("leaf"))
+		})
+
+		It("returns an error when the source is not a registered directory", func() {
+			err := fs.CopyDir("/missing", "/dst")
+			Expect(err).To(MatchError("/missing is not a directory"))
+
+			err = fs.CopyDir("/src/top.conf", "/dst")
+			Expect(err).To(MatchError("/src/top.conf is not a directory"))
+			Expect(fs.FileExists("/dst")).To(BeFalse())
+		})
+
+		It("returns CopyDirError when set", func() {
+			fs.CopyDirError = errors.New("fake-copy-dir-err")
+
+			err := fs.CopyDir("/src", "/dst")
+			Expect(err).To(MatchError("fake-copy-dir-err"))
+			Expect(fs.FileExists("/dst")).To(BeFalse())
+		})
+	})
+
+	Describe("Glob", func() {
+		BeforeEach(func() {
+			for _, path := range []string{
+				"/jobs/a/config.yml",
+				"/jobs/a/config.yml.bak",
+				"/jobs/b/monit.yml",
+				"/etc/nginx.conf",
+				"/etc/nginx/sites/default.conf",
+			} {
+				err := fs.WriteFileString(path, "")
+				Expect(err).ToNot(HaveOccurred())
+			}
+		})
+
+		It("returns no matches for unregistered patterns by default", func() {
+			matches, err := fs.Glob("/jobs/*/*.yml")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(matches).To(BeEmpty())
+		})
+
+		Context("when GlobUsesRealMatching is set", func() {
+			BeforeEach(func() {
+				fs.GlobUsesRealMatching = true
+			})
+
+			It("matches single segment wildcards", func() {
+				matches, err := fs.Glob("/jobs/*/*.yml")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(matches).To(Equal([]string{"/jobs/a/config.yml", "/jobs/b/monit.yml"}))
+			})
+
+			It("matches ** across any number of directories", func() {
+				matches, err := fs.Glob("/etc/**/*.conf")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(matches).To(Equal([]string{"/etc/nginx.conf", "/etc/nginx/sites/default.conf"}))
+			})
+
+			It("matches a literal path", func() {
+				matches, err := fs.Glob("/jobs/b/monit.yml")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(matches).To(Equal([]string{"/jobs/b/monit.yml"}))
+			})
+
+			It("prefers results registered with SetGlob", func() {
+				fs.SetGlob("/jobs/*/*.yml", []string{"/registered"})
+
+				matches, err := fs.Glob("/jobs/*/*.yml")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(matches).To(Equal([]string{"/registered"}))
+			})
+
+			It("returns an error for a malformed pattern", func() {
+				_, err := fs.Glob("/jobs/[")
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("RemoveAll", func() {
+		BeforeEach(func() {
+			err := fs.WriteFileString("/var/vcap/data/sys/file", "")
+			Expect(err).ToNot(HaveOccurred())
+
+			err = fs.WriteFileString("/var/vcap/data-backup/sys/file", "")
+			Expect(err).ToNot(HaveOccurred())
+
+			err = fs.WriteFileString("/var/vcap/data.tgz", "")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("removes the path and everything under it but not siblings sharing its prefix", func() {
+			err := fs.RemoveAll("/var/vcap/data")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fs.FileExists("/var/vcap/data")).To(BeFalse())
+			Expect(fs.FileExists("/var/vcap/data/sys")).To(BeFalse())
+			Expect(fs.FileExists("/var/vcap/data/sys/file")).To(BeFalse())
+
+			Expect(fs.FileExists("/var/vcap/data-backup")).To(BeTrue())
+			Expect(fs.FileExists("/var/vcap/data-backup/sys/file")).To(BeTrue())
+			Expect(fs.FileExists("/var/vcap/data.tgz")).To(BeTrue())
+		})
+
+		It("treats a trailing slash the same as the bare path", func() {
+			err := fs.RemoveAll("/var/vcap/data/")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fs.FileExists("/var/vcap/data/sys/file")).To(BeFalse())
+			Expect(fs.FileExists("/var/vcap/data-backup/sys/file")).To(BeTrue())
+		})
+
+		It("removes everything when removing the root directory", func() {
+			err := fs.RemoveAll("/")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fs.RegisteredPaths()).To(BeEmpty())
+		})
+	})
+
+	Describe("Chmod and Chown call history", func() {
+		BeforeEach(func() {
+			err := fs.WriteFileString("/home/vcap/.ssh/authorized_keys", "key")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("records the arguments of each call in order", func() {
+			err := fs.Chmod("/home/vcap/.ssh/authorized_keys", 0644)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = fs.Chown("/home/vcap/.ssh/authorized_keys", "vcap:vcap")
+			Expect(err).ToNot(HaveOccurred())
+
+			err = fs.Chmod("/home/vcap/.ssh/authorized_keys", 0600)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fs.ChmodCalls).To(Equal([]ChmodCall{
+				{Path: "/home/vcap/.ssh/authorized_keys", Perm: 0644},
+				{Path: "/home/vcap/.ssh/authorized_keys", Perm: 0600},
+			}))
+			Expect(fs.ChownCalls).To(Equal([]ChownCall{
+				{Path: "/home/vcap/.ssh/authorized_keys", Username: "vcap:vcap"},
+			}))
+		})
+
+		It("records calls that fail through ChmodErr and ChownErr", func() {
+			fs.ChmodErr = errors.New("fake-chmod-err")
+			fs.ChownErr = errors.New("fake-chown-err")
+
+			err := fs.Chmod("/home/vcap/.ssh/authorized_keys", 0600)
+			Expect(err).To(MatchError("fake-chmod-err"))
+
+			err = fs.Chown("/home/vcap/.ssh/authorized_keys", "vcap")
+			Expect(err).To(MatchError("fake-chown-err"))
+
+			Expect(fs.ChmodCalls).To(Equal([]ChmodCall{{Path: "/home/vcap/.ssh/authorized_keys", Perm: 0600}}))
+			Expect(fs.ChownCalls).To(Equal([]ChownCall{{Path: "/home/vcap/.ssh/authorized_keys", Username: "vcap"}}))
+		})
+	})
+
+	Describe("Rename", func() {
+		BeforeEach(func() {
+			err := fs.MkdirAll("/a/nested", 0755)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = fs.WriteFileString("/a/first", "first")
+			Expect(err).ToNot(HaveOccurred())
+
+			err = fs.WriteFileString("/a/nested/second", "second")
+			Expect(err).ToNot(HaveOccurred())
+
+			err = fs.Chmod("/a/nested/second", 0600)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("moves every path under a renamed directory to the new location", func() {
+			err := fs.Rename("/a", "/b")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fs.ReadFileString("/b/first")).To(Equal("first"))
+			Expect(fs.ReadFileString("/b/nested/second")).To(Equal("second"))
+			Expect(fs.GetFileTestStat("/b/nested/second").FileMode).To(Equal(os.FileMode(0600)))
+			Expect(fs.GetFileTestStat("/b").FileType).To(Equal(FakeFileTypeDir))
+
+			Expect(fs.FileExists("/a")).To(BeFalse())
+			Expect(fs.DirEntries("/")).To(Equal([]string{"/b"}))
+		})
+
+		It("merges into an existing destination directory", func() {
+			err := fs.WriteFileString("/b/existing", "existing")
+			Expect(err).ToNot(HaveOccurred())
+
+			err = fs.WriteFileString("/b/first", "overwritten")
+			Expect(err).ToNot(HaveOccurred())
+
+			err = fs.Rename("/a", "/b")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fs.DirEntries("/b")).To(Equal([]string{"/b/existing", "/b/first", "/b/nested"}))
+			Expect(fs.ReadFileString("/b/existing")).To(Equal("existing"))
+			Expect(fs.ReadFileString("/b/first")).To(Equal("first"))
+		})
+
+		It("does not move siblings sharing the old path as a prefix", func() {
+			err := fs.WriteFileString("/ab/other", "other")
+			Expect(err).ToNot(HaveOccurred())
+
+			err = fs.Rename("/a", "/b")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fs.ReadFileString("/ab/other")).To(Equal("other"))
+			Expect(fs.FileExists("/b/other")).To(BeFalse())
+		})
+	})
+
+	Describe("ReadAndFollowLink", func() {
+		BeforeEach(func() {
+			err := fs.MkdirAll("/links", 0755)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = fs.WriteFileString("/data/target", "")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("follows a chain of symlinks to the final path", func() {
+			err := fs.Symlink("/data/target", "/links/second")
+			Expect(err).ToNot(HaveOccurred())
+
+			err = fs.Symlink("/links/second", "/links/first")
+			Expect(err).ToNot(HaveOccurred())
+
+			targetPath, err := fs.ReadAndFollowLink("/links/first")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(targetPath).To(Equal("/data/target"))
+		})
+
+		It("returns an error for a self-referential symlink", func() {
+			err := fs.Symlink("/links/loop", "/links/loop")
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = fs.ReadAndFollowLink("/links/loop")
+			Expect(err).To(MatchError("too many levels of symbolic links: /links/loop"))
+		})
+
+		It("returns an error for a cycle between symlinks", func() {
+			err := fs.Symlink("/links/b", "/links/a")
+			Expect(err).ToNot(HaveOccurred())
+
+			err = fs.Symlink("/links/a", "/links/b")
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = fs.ReadAndFollowLink("/links/a")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("too many levels of symbolic links"))
+		})
+	})
+
+	Describe("RegisteredPaths", func() {
+		It("returns every known path in sorted order", func() {
+			err := fs.MkdirAll("/var/vcap/data", 0755)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = fs.WriteFileString("/etc/hosts", "")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fs.RegisteredPaths()).To(Equal([]string{
+				"/",
+				"/etc",
+				"/etc/hosts",
+				"/var",
+				"/var/vcap",
+				"/var/vcap/data",
+			}))
+		})
+
+		It("returns an empty slice when nothing is registered", func() {
+			Expect(fs.RegisteredPaths()).To(BeEmpty())
+		})
+	})
+
+	Describe("Walk", func() {
+		It("reports the registered mod time of each file", func() {
+			oldTime := time.Date(2017, time.January, 1, 0, 0, 0, 0, time.UTC)
+			newTime := time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+			err := fs.WriteFileString("/var/vcap/sys/log/old.log", "old")
+			Expect(err).ToNot(HaveOccurred())
+			fs.GetFileTestStat("/var/vcap/sys/log/old.log").ModTime = oldTime
+
+			err = fs.WriteFileString("/var/vcap/sys/log/new.log", "new")
+			Expect(err).ToNot(HaveOccurred())
+			fs.GetFileTestStat("/var/vcap/sys/log/new.log").ModTime = newTime
+
+			modTimes := map[string]time.Time{}
+			err = fs.Walk("/var/vcap/sys/log", func(path string, info os.FileInfo, err error) error {
+				if !info.IsDir() {
+					modTimes[path] = info.ModTime()
+				}
+				return err
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(modTimes).To(Equal(map[string]time.Time{
+				"/var/vcap/sys/log/old.log": oldTime,
+				"/var/vcap/sys/log/new.log": newTime,
+			}))
+		})
+
+		It("reports the name and mode of directories and files", func() {
+			err := fs.MkdirAll("/var/vcap/sys/log", 0750)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = fs.WriteFileString("/var/vcap/sys/log/file.log", "")
+			Expect(err).ToNot(HaveOccurred())
+
+			err = fs.Chmod("/var/vcap/sys/log/file.log", 0640)
+			Expect(err).ToNot(HaveOccurred())
+
+			names := map[string]string{}
+			modes := map[string]os.FileMode{}
+			err = fs.Walk("/var/vcap/sys/log", func(path string, info os.FileInfo, err error) error {
+				names[path] = info.Name()
+				modes[path] = info.Mode()
+				return err
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(names).To(Equal(map[string]string{
+				"/var/vcap/sys/log":          "log",
+				"/var/vcap/sys/log/file.log": "file.log",
+			}))
+			Expect(modes).To(Equal(map[string]os.FileMode{
+				"/var/vcap/sys/log":          0750,
+				"/var/vcap/sys/log/file.log": 0640,
+			}))
+		})
+
+		Context("when the walk func returns SkipDir", func() {
+			BeforeEach(func() {
+				for _, path := range []string{
+					"/var/vcap/data/a.log",
+					"/var/vcap/data/skipped/b.log",
+					"/var/vcap/data/skipped/nested/c.log",
+					"/var/vcap/data/skipped-not/d.log",
+					"/var/vcap/data/z.log",
+				} {
+					err := fs.WriteFileString(path, "")
+					Expect(err).ToNot(HaveOccurred())
+				}
+			})
+
+			It("skips everything below the directory", func() {
+				var visited []string
+				err := fs.Walk("/var/vcap/data", func(path string, info os.FileInfo, err error) error {
+					visited = append(visited, path)
+					if path == "/var/vcap/data/skipped" {
+						return filepath.SkipDir
+					}
+					return err
+				})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(visited).To(Equal([]string{
+					"/var/vcap/data",
+					"/var/vcap/data/a.log",
+					"/var/vcap/data/skipped",
+					"/var/vcap/data/skipped-not",
+					"/var/vcap/data/skipped-not/d.log",
+					"/var/vcap/data/z.log",
+				}))
+			})
+
+			It("skips the remaining files of the directory when returned for a file", func() {
+				var visited []string
+				err := fs.Walk("/var/vcap/data/skipped", func(path string, info os.FileInfo, err error) error {
+					visited = append(visited, path)
+					if path == "/var/vcap/data/skipped/b.log" {
+						return filepath.SkipDir
+					}
+					return err
+				})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(visited).To(Equal([]string{
+					"/var/vcap/data/skipped",
+					"/var/vcap/data/skipped/b.log",
+				}))
+			})
+
+			It("stops walking without an error when returned for the root", func() {
+				var visited []string
+				err := fs.Walk("/var/vcap/data", func(path string, info os.FileInfo, err error) error {
+					visited = append(visited, path)
+					return filepath.SkipDir
+				})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(visited).To(Equal([]string{"/var/vcap/data"}))
+			})
+		})
+
+		It("reports a zero mod time when none was registered", func() {
+			err := fs.WriteFileString("/var/vcap/sys/log/file.log", "")
+			Expect(err).ToNot(HaveOccurred())
+
+			err = fs.Walk("/var/vcap/sys/log/file.log", func(path string, info os.FileInfo, err error) error {
+				Expect(info.ModTime().IsZero()).To(BeTrue())
+				return err
+			})
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	Describe("DirEntries", func() {
+		BeforeEach(func() {
+			err := fs.MkdirAll("/var/vcap/data/sys", 0755)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = fs.MkdirAll("/var/vcap/store", 0755)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = fs.WriteFileString("/var/vcap/bosh.conf", "")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("returns only the immediate children of the directory", func() {
+			Expect(fs.DirEntries("/var/vcap")).To(Equal([]string{
+				"/var/vcap/bosh.conf",
+				"/var/vcap/data",
+				"/var/vcap/store",
+			}))
+		})
+
+		It("normalizes the directory path", func() {
+			Expect(fs.DirEntries("/var/vcap/")).To(Equal(fs.DirEntries("/var/vcap")))
+		})
+
+		It("returns the top level entries of the root directory", func() {
+			Expect(fs.DirEntries("/")).To(Equal([]string{"/var"}))
+		})
+
+		It("returns an empty slice for a directory without children", func() {
+			Expect(fs.DirEntries("/var/vcap/store")).To(BeEmpty())
+		})
+	})
+
+	Describe("FakeFile", func() {
+		Describe("Write", func() {
+			It("replaces the content when the file was not opened for appending", func() {
+				err := fs.WriteFileString("/file", "old")
+				Expect(err).ToNot(HaveOccurred())
+
+				file, err := fs.OpenFile("/file", os.O_WRONLY, 0644)
+				Expect(err).ToNot(HaveOccurred())
+
+				_, err = file.Write([]byte("new"))
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(fs.ReadFileString("/file")).To(Equal("new"))
+			})
+
+			It("appends to the existing content when the file was opened with O_APPEND", func() {
+				err := fs.WriteFileString("/var/vcap/sys/log/job/job.stdout.log", "existing\n")
+				Expect(err).ToNot(HaveOccurred())
+
+				for _, line := range []string{"first run\n", "second run\n"} {
+					file, err := fs.OpenFile("/var/vcap/sys/log/job/job.stdout.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+					Expect(err).ToNot(HaveOccurred())
+
+					_, err = file.Write([]byte(line))
+					Expect(err).ToNot(HaveOccurred())
+
+					Expect(file.Close()).To(Succeed())
+				}
+
+				Expect(fs.ReadFileString("/var/vcap/sys/log/job/job.stdout.log")).To(Equal("existing\nfirst run\nsecond run\n"))
+			})
+		})
+
+		Describe("Read", func() {
+			It("reads at most len(b) bytes per call and advances the read offset", func() {
+				err := fs.WriteFileString("/file", "0123456789")
+				Expect(err).ToNot(HaveOccurred())
+
+				file, err := fs.OpenFile("/file", 0, 0644)
+				Expect(err).ToNot(HaveOccurred())
+
+				buf := make([]byte, 3)
+
+				n, err := file.Read(buf)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(string(buf[:n])).To(Equal("012"))
+
+				n, err = file.Read(buf)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(string(buf[:n])).To(Equal("345"))
+
+				n, err = file.Read(buf)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(string(buf[:n])).To(Equal("678"))
+
+				n, err = file.Read(buf)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(string(buf[:n])).To(Equal("9"))
+
+				n, err = file.Read(buf)
+				Expect(err).To(Equal(io.EOF))
+				Expect(n).To(Equal(0))
+			})
+
+			It("reads the whole file when the buffer is large enough", func() {
+				err := fs.WriteFileString("/file", "0123456789")
+				Expect(err).ToNot(HaveOccurred())
+
+				file, err := fs.OpenFile("/file", 0, 0644)
+				Expect(err).ToNot(HaveOccurred())
+
+				buf := make([]byte, 32)
+				n, err := file.Read(buf)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(string(buf[:n])).To(Equal("0123456789"))
+
+				_, err = file.Read(buf)
+				Expect(err).To(Equal(io.EOF))
+			})
+		})
+	})
+})
@@ -0,0 +1,13 @@
+package fakefs_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestFakefs(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Fake File System Suite")
+}
@@ -7,6 +7,7 @@ import (
 	"path"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"text/template"
@@ -61,6 +62,11 @@ type LinuxOptions struct {
 	// When set to true persistent disk will be mounted as a bind-mount
 	BindMountPersistentDisk bool
 
+	// When set to true the filesystem of the persistent disk is checked, and
+	// repaired where that is safe, before mounting it; mounting fails when the
+	// filesystem is corrupt beyond automatic repair
+	CheckPersistentDiskFilesystem bool
+
 	// When set to true and no ephemeral disk is mounted, the agent will create
 	// a partition on the same device as the root partition to use as the
 	// ephemeral disk
@@ -69,10 +75,26 @@ type LinuxOptions struct {
 	// When set to true the agent will skip both root and ephemeral disk partitioning
 	SkipDiskSetup bool
 
+	// When set to true no swap partition is created and the whole ephemeral
+	// disk (or the space left on the root device) is used for data
+	SkipSwapCreation bool
+
+	// When set to true keys already in a user's authorized_keys file are kept
+	// and only keys not yet present are appended; otherwise the file is replaced
+	PreserveAuthorizedKeys bool
+
+	// Filesystem the data partition of the ephemeral disk is formatted with;
+	// possible values: ext4, xfs, "" (default is ext4)
+	EphemeralDiskFileSystemType string
+
 	// Strategy for resolving device paths;
 	// possible values: virtio, scsi, iscsi, ""
 	DevicePathResolutionType string
 
+	// Seconds the virtio strategy waits for a disk to appear under its mapped
+	// name, e.g. /dev/vdb for /dev/sdb; zero keeps the default of 30 seconds
+	DevicePathResolutionTimeoutInSeconds int
+
 	// Strategy for resolving ephemeral & persistent disk partitioners;
 	// possible values: parted, "" (default is sfdisk if disk < 2TB, parted otherwise)
 	PartitionerType string
@@ -146,6 +168,13 @@ func NewLinuxPlatform(
 
 const logTag = "linuxPlatform"
 
+// Upper bound for persistent disk read-ahead, in 512-byte sectors (32MiB)
+const maxPersistentDiskReadAhead = 65536
+
+// Unused space at the end of the ephemeral disk below which the data partition
+// is considered to fill the disk, same tolerance as used when matching partitions
+const ephemeralPartitionGrowthThresholdInMb = 100
+
 func (p linux) AssociateDisk(name string, settings boshsettings.DiskSettings) error {
 	disksDir := p.dirProvider.DisksDir()
 	err := p.fs.MkdirAll(disksDir, disksDirPermissions)
@@ -187,6 +216,19 @@ func (p linux) GetVitalsService() (service boshvitals.Service) {
 	return p.vitalsService
 }
 
+func (p linux) GetStatsCollector() boshstats.Collector {
+	return p.collector
+}
+
+func (p linux) GetCPULoad() (boshstats.CPULoad, error) {
+	load, err := p.collector.GetCPULoad()
+	if err != nil {
+		return boshstats.CPULoad{}, bosherr.WrapError(err, "Getting CPU load")
+	}
+
+	return load, nil
+}
+
 func (p linux) GetFileContentsFromCDROM(fileName string) (content []byte, err error) {
 	contents, err := p.cdutil.GetFilesContents([]string{fileName})
 	if err != nil {
@@ -402,6 +444,14 @@ func (p linux) SetupSSH(publicKeys []string, username string) error {
 
 	authKeysPath := path.Join(sshPath, "authorized_keys")
 	publicKeyString := strings.Join(publicKeys, "\n")
+
+	if p.options.PreserveAuthorizedKeys {
+		publicKeyString, err = p.appendAuthorizedKeys(authKeysPath, publicKeys)
+		if err != nil {
+			return err
+		}
+	}
+
 	err = p.fs.WriteFileString(authKeysPath, publicKeyString)
 	if err != nil {
 		return bosherr.WrapError(err, "Creating authorized_keys file")
@@ -419,6 +469,42 @@ func (p linux) SetupSSH(publicKeys []string, username string) error {
 	return nil
 }
 
+// appendAuthorizedKeys returns the existing authorized_keys content followed by
+// the keys that do not match any of its lines exactly
+func (p linux) appendAuthorizedKeys(authKeysPath string, publicKeys []string) (string, error) {
+	var existingKeys string
+
+	if p.fs.FileExists(authKeysPath) {
+		var err error
+
+		existingKeys, err = p.fs.ReadFileString(authKeysPath)
+		if err != nil {
+			return "", bosherr.WrapError(err, "Reading authorized_keys file")
+		}
+	}
+
+	present := map[string]bool{}
+	for _, line := range strings.Split(existingKeys, "\n") {
+		present[line] = true
+	}
+
+	var lines []string
+	if existingKeys != "" {
+		lines = append(lines, strings.TrimSuffix(existingKeys, "\n"))
+	}
+
+	for _, publicKey := range publicKeys {
+		if present[publicKey] {
+			continue
+		}
+
+		present[publicKey] = true
+		lines = append(lines, publicKey)
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
 func (p linux) SetUserPassword(user, encryptedPwd string) (err error) {
 	if encryptedPwd == "" {
 		encryptedPwd = "*"
@@ -517,16 +603,22 @@ func (p linux) SetupHostname(hostname string) error {
 	return nil
 }
 
-func (p linux) SetupLogrotate(groupName, basePath, size string) (err error) {
+func (p linux) SetupLogrotate(groupName, basePath, size string, maxLogFiles int, jobMaxLogFiles map[string]int) (err error) {
+	entries, err := p.logrotateEntries(basePath, maxLogFiles, jobMaxLogFiles)
+	if err != nil {
+		err = bosherr.WrapError(err, "Finding log directories to rotate")
+		return
+	}
+
 	buffer := bytes.NewBuffer([]byte{})
 	t := template.Must(template.New("logrotate-d-config").Parse(etcLogrotateDTemplate))
 
 	type logrotateArgs struct {
-		BasePath string
-		Size     string
+		Entries []logrotateEntry
+		Size    string
 	}
 
-	err = t.Execute(buffer, logrotateArgs{basePath, size})
+	err = t.Execute(buffer, logrotateArgs{entries, size})
 	if err != nil {
 		err = bosherr.WrapError(err, "Generating logrotate config")
 		return
@@ -543,18 +635,91 @@ func (p linux) SetupLogrotate(groupName, basePath, size string) (err error) {
 	return
 }
 
+type logrotateEntry struct {
+	Paths  string
+	Rotate int
+}
+
+// logrotateEntries covers all logs in a single entry unless jobs keep a different
+// number of rotated files. Since logrotate rejects logs matched by more than one
+// entry, the remaining log directories are then listed one by one; directories
+// created afterwards are picked up on the next apply.
+func (p linux) logrotateEntries(basePath string, maxLogFiles int, jobMaxLogFiles map[string]int) ([]logrotateEntry, error) {
+	logDir := path.Join(basePath, "data", "sys", "log")
+
+	if len(jobMaxLogFiles) == 0 {
+		return []logrotateEntry{
+			{Paths: logrotatePaths(logDir, path.Join(logDir, "*"), path.Join(logDir, "*", "*")), Rotate: maxLogFiles},
+		}, nil
+	}
+
+	matches, err := p.fs.Glob(path.Join(logDir, "*"))
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Globbing %s", logDir)
+	}
+
+	sort.Strings(matches)
+
+	sharedDirs := []string{logDir}
+
+	for _, match := range matches {
+		if _, found := jobMaxLogFiles[path.Base(match)]; found {
+			continue
+		}
+
+		fileInfo, err := p.fs.Stat(match)
+		if err != nil {
+			return nil, bosherr.WrapErrorf(err, "Checking if %s is a directory", match)
+		}
+
+		if fileInfo.IsDir() {
+			sharedDirs = append(sharedDirs, match, path.Join(match, "*"))
+		}
+	}
+
+	entries := []logrotateEntry{{Paths: logrotatePaths(sharedDirs...), Rotate: maxLogFiles}}
+
+	jobNames := []string{}
+	for jobName := range jobMaxLogFiles {
+		jobNames = append(jobNames, jobName)
+	}
+
+	sort.Strings(jobNames)
+
+	for _, jobName := range jobNames {
+		jobLogDir := path.Join(logDir, jobName)
+
+		entries = append(entries, logrotateEntry{
+			Paths:  logrotatePaths(jobLogDir, path.Join(jobLogDir, "*")),
+			Rotate: jobMaxLogFiles[jobName],
+		})
+	}
+
+	return entries, nil
+}
+
+func logrotatePaths(dirs ...string) string {
+	var paths []string
+
+	for _, dir := range dirs {
+		paths = append(paths, path.Join(dir, "*.log"), path.Join(dir, ".*.log"))
+	}
+
+	return strings.Join(paths, " ")
+}
+
 // Logrotate config file - /etc/logrotate.d/<group-name>
 // Stemcell stage logrotate_config configures logrotate to run every hour
 const etcLogrotateDTemplate = `# Generated by bosh-agent
-
-{{ .BasePath }}/data/sys/log/*.log {{ .BasePath }}/data/sys/log/.*.log {{ .BasePath }}/data/sys/log/*/*.log {{ .BasePath }}/data/sys/log/*/.*.log {{ .BasePath }}/data/sys/log/*/*/*.log {{ .BasePath }}/data/sys/log/*/*/.*.log {
+{{ range .Entries }}
+{{ .Paths }} {
   missingok
-  rotate 7
+  rotate {{ .Rotate }}
   compress
   copytruncate
-  size={{ .Size }}
+  size={{ $.Size }}
 }
-`
+{{ end }}`
 
 func (p linux) SetTimeWithNtpServers(servers []string) (err error) {
 	serversFilePath := path.Join(p.dirProvider.BaseDir(), "/bosh/etc/ntpserver")
@@ -601,6 +766,16 @@ func (p linux) SetupEphemeralDiskWithPath(realPath string, desiredSwapSizeInByte
 		return nil
 	}
 
+	dataFileSystemType, err := p.ephemeralDataFileSystemType()
+	if err != nil {
+		return err
+	}
+
+	if p.options.SkipSwapCreation {
+		noSwapSizeInBytes := uint64(0)
+		desiredSwapSizeInBytes = &noSwapSizeInBytes
+	}
+
 	var swapPartitionPath, dataPartitionPath string
 
 	// Agent can only setup ephemeral data directory either on ephemeral device
@@ -648,10 +823,10 @@ func (p linux) SetupEphemeralDiskWithPath(realPath string, desiredSwapSizeInByte
 		return err
 	}
 
-	p.logger.Info(logTag, "Formatting `%s' (canonical path: %s) as ext4", dataPartitionPath, canonicalDataPartitionPath)
-	err = p.diskManager.GetFormatter().Format(canonicalDataPartitionPath, boshdisk.FileSystemExt4)
+	p.logger.Info(logTag, "Formatting `%s' (canonical path: %s) as %s", dataPartitionPath, canonicalDataPartitionPath, dataFileSystemType)
+	err = p.diskManager.GetFormatter().Format(canonicalDataPartitionPath, dataFileSystemType)
 	if err != nil {
-		return bosherr.WrapError(err, "Formatting data partition with ext4")
+		return bosherr.WrapErrorf(err, "Formatting data partition with %s", dataFileSystemType)
 	}
 
 	p.logger.Info(logTag, "Mounting `%s' (canonical path: %s) at `%s'", dataPartitionPath, canonicalDataPartitionPath, mountPoint)
@@ -663,6 +838,19 @@ func (p linux) SetupEphemeralDiskWithPath(realPath string, desiredSwapSizeInByte
 	return nil
 }
 
+func (p linux) ephemeralDataFileSystemType() (boshdisk.FileSystemType, error) {
+	fileSystemType := boshdisk.FileSystemType(p.options.EphemeralDiskFileSystemType)
+
+	switch fileSystemType {
+	case boshdisk.FileSystemExt4, boshdisk.FileSystemXFS:
+		return fileSystemType, nil
+	case boshdisk.FileSystemDefault:
+		return boshdisk.FileSystemExt4, nil
+	default:
+		return "", bosherr.Errorf(`The ephemeral disk filesystem type "%s" is not supported`, fileSystemType)
+	}
+}
+
 func (p linux) SetupRawEphemeralDisks(devices []boshsettings.DiskSettings) (err error) {
 	if p.options.SkipDiskSetup {
 		return nil
@@ -720,6 +908,69 @@ func (p linux) SetupRawEphemeralDisks(devices []boshsettings.DiskSettings) (err
 	return nil
 }
 
+// SetupAdditionalEphemeralDisks gives each disk a single data partition
+// mounted below the data dir, e.g. /var/vcap/data/ephemeral_disks/1 for the
+// second ephemeral disk; swap only lives on the first ephemeral disk
+func (p linux) SetupAdditionalEphemeralDisks(realPaths []string, labelPrefix string) error {
+	if p.options.SkipDiskSetup || len(realPaths) == 0 {
+		return nil
+	}
+
+	p.logger.Info(logTag, "Setting up additional ephemeral disks")
+
+	dataFileSystemType, err := p.ephemeralDataFileSystemType()
+	if err != nil {
+		return err
+	}
+
+	partitioner := p.diskManager.GetEphemeralDevicePartitioner()
+	noSwapSizeInBytes := uint64(0)
+
+	for i, realPath := range realPaths {
+		diskNumber := i + 1
+
+		if realPath == "" {
+			return bosherr.Errorf("Additional ephemeral disk %d not found", diskNumber)
+		}
+
+		mountPoint := path.Join(p.dirProvider.DataDir(), "ephemeral_disks", strconv.Itoa(diskNumber))
+
+		err = p.fs.MkdirAll(mountPoint, ephemeralDiskPermissions)
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Creating mount point of additional ephemeral disk %d", diskNumber)
+		}
+
+		diskSizeInBytes, err := partitioner.GetDeviceSizeInBytes(realPath)
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Getting device size of additional ephemeral disk %d", diskNumber)
+		}
+
+		_, dataPartitionPath, err := p.partitionDisk(diskSizeInBytes, &noSwapSizeInBytes, realPath, 1, partitioner, labelPrefix)
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Partitioning additional ephemeral disk '%s'", realPath)
+		}
+
+		canonicalDataPartitionPath, err := resolveCanonicalLink(p.cmdRunner, dataPartitionPath)
+		if err != nil {
+			return err
+		}
+
+		p.logger.Info(logTag, "Formatting `%s' (canonical path: %s) as %s", dataPartitionPath, canonicalDataPartitionPath, dataFileSystemType)
+		err = p.diskManager.GetFormatter().Format(canonicalDataPartitionPath, dataFileSystemType)
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Formatting additional ephemeral disk %d with %s", diskNumber, dataFileSystemType)
+		}
+
+		p.logger.Info(logTag, "Mounting `%s' (canonical path: %s) at `%s'", dataPartitionPath, canonicalDataPartitionPath, mountPoint)
+		err = p.diskManager.GetMounter().Mount(canonicalDataPartitionPath, mountPoint)
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Mounting additional ephemeral disk %d", diskNumber)
+		}
+	}
+
+	return nil
+}
+
 func (p linux) SetupDataDir(jobConfig boshsettings.JobDir, runConfig boshsettings.RunDir) error {
 	dataDir := p.dirProvider.DataDir()
 
@@ -1115,6 +1366,12 @@ func (p linux) changeTmpDirPermissions(path string) error {
 func (p linux) MountPersistentDisk(diskSetting boshsettings.DiskSettings, mountPoint string) error {
 	p.logger.Debug(logTag, "Mounting persistent disk %+v at %s", diskSetting, mountPoint)
 
+	if readAhead := diskSetting.ReadAhead; readAhead != nil {
+		if *readAhead < 0 || *readAhead > maxPersistentDiskReadAhead {
+			return bosherr.Errorf("Invalid persistent disk read-ahead %d, must be between 0 and %d sectors", *readAhead, maxPersistentDiskReadAhead)
+		}
+	}
+
 	realPath, _, err := p.devicePathResolver.GetRealDevicePath(diskSetting)
 	if err != nil {
 		return bosherr.WrapError(err, "Getting real device path")
@@ -1130,7 +1387,7 @@ func (p linux) MountPersistentDisk(diskSetting boshsettings.DiskSettings, mountP
 	if isMountPoint {
 		if partitionPath == devicePath {
 			p.logger.Info(logTag, "device: %s is already mounted on %s, skipping mounting", devicePath, mountPoint)
-			return nil
+			return p.setPersistentDiskReadAhead(devicePath, diskSetting.ReadAhead)
 		}
 
 		mountPoint = p.dirProvider.StoreMigrationDir()
@@ -1173,12 +1430,28 @@ func (p linux) MountPersistentDisk(diskSetting boshsettings.DiskSettings, mountP
 		realPath = partitionPath
 	}
 
+	if p.options.CheckPersistentDiskFilesystem {
+		repaired, err := p.diskManager.GetFormatter().CheckFilesystem(realPath)
+		if err != nil {
+			return bosherr.WrapError(err, "Checking persistent disk filesystem")
+		}
+
+		if repaired {
+			p.logger.Warn(logTag, "Repaired filesystem errors on %s before mounting it", realPath)
+		}
+	}
+
 	err = p.diskManager.GetMounter().Mount(realPath, mountPoint, diskSetting.MountOptions...)
 
 	if err != nil {
 		return bosherr.WrapError(err, "Mounting partition")
 	}
 
+	err = p.setPersistentDiskReadAhead(realPath, diskSetting.ReadAhead)
+	if err != nil {
+		return err
+	}
+
 	managedSettingsPath := filepath.Join(p.dirProvider.BoshDir(), "managed_disk_settings.json")
 
 	err = p.fs.WriteFileString(managedSettingsPath, diskSetting.ID)
@@ -1190,6 +1463,28 @@ func (p linux) MountPersistentDisk(diskSetting boshsettings.DiskSettings, mountP
 	return nil
 }
 
+func (p linux) setPersistentDiskReadAhead(devicePath string, readAhead *int) error {
+	if readAhead == nil {
+		return nil
+	}
+
+	stdout, _, _, err := p.cmdRunner.RunCommand("blockdev", "--getra", devicePath)
+	if err == nil {
+		current, err := strconv.Atoi(strings.TrimSpace(stdout))
+		if err == nil && current == *readAhead {
+			p.logger.Debug(logTag, "Read-ahead of %s is already %d sectors", devicePath, current)
+			return nil
+		}
+	}
+
+	_, _, _, err = p.cmdRunner.RunCommand("blockdev", "--setra", strconv.Itoa(*readAhead), devicePath)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Setting read-ahead of %s", devicePath)
+	}
+
+	return nil
+}
+
 func (p linux) UnmountPersistentDisk(diskSettings boshsettings.DiskSettings) (bool, error) {
 	p.logger.Debug(logTag, "Unmounting persistent disk %+v", diskSettings)
 
@@ -1470,6 +1765,63 @@ func (p linux) createEphemeralPartitionsOnRootDevice(desiredSwapSizeInBytes *uin
 	return swapPartitionPath, dataPartitionPath, nil
 }
 
+// GrowEphemeralPartition extends the data partition of the ephemeral disk and
+// its ext4 filesystem when the IaaS resized the disk after it was partitioned
+func (p linux) GrowEphemeralPartition(devicePath string) error {
+	if p.options.SkipDiskSetup {
+		return nil
+	}
+
+	partitions, deviceSizeInBytes, err := p.diskManager.GetEphemeralDevicePartitioner().GetPartitions(devicePath)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Getting existing partitions of `%s'", devicePath)
+	}
+
+	if len(partitions) == 0 {
+		return bosherr.Errorf("No partitions found on ephemeral disk `%s'", devicePath)
+	}
+
+	// The data partition is always created after the optional swap partition
+	dataPartition := partitions[len(partitions)-1]
+	if dataPartition.Type != boshdisk.PartitionTypeLinux {
+		return bosherr.Errorf("Last partition of ephemeral disk `%s' is not a linux partition", devicePath)
+	}
+
+	if deviceSizeInBytes <= dataPartition.EndInBytes+boshdisk.ConvertFromMbToBytes(ephemeralPartitionGrowthThresholdInMb) {
+		p.logger.Info(logTag, "Data partition already fills ephemeral disk `%s', skipping", devicePath)
+		return nil
+	}
+
+	if !p.cmdRunner.CommandExists("growpart") {
+		return bosherr.Error("The program 'growpart' is not installed, ephemeral partition cannot be grown")
+	}
+
+	p.logger.Info(logTag, "Growing data partition %d of ephemeral disk `%s' to %dB", dataPartition.Index, devicePath, deviceSizeInBytes)
+	stdout, _, _, err := p.cmdRunner.RunCommand("growpart", devicePath, strconv.Itoa(dataPartition.Index))
+	if err != nil {
+		if !strings.Contains(stdout, "NOCHANGE") {
+			return bosherr.WrapError(err, "growpart")
+		}
+	}
+
+	canonicalDataPartitionPath, err := resolveCanonicalLink(p.cmdRunner, p.partitionPath(devicePath, dataPartition.Index))
+	if err != nil {
+		return err
+	}
+
+	dataFileSystemType, err := p.ephemeralDataFileSystemType()
+	if err != nil {
+		return err
+	}
+
+	err = p.diskManager.GetFormatter().GrowFilesystem(canonicalDataPartitionPath, dataFileSystemType)
+	if err != nil {
+		return bosherr.WrapError(err, "Growing data partition filesystem")
+	}
+
+	return nil
+}
+
 func (p linux) partitionEphemeralDisk(realPath string, desiredSwapSizeInBytes *uint64, labelPrefix string) (string, string, error) {
 	p.logger.Info(logTag, "Creating swap & ephemeral partitions on ephemeral disk...")
 	p.logger.Debug(logTag, "Getting device size of `%s'", realPath)
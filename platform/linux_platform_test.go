@@ -1,3 +1,4 @@
+//go:build !windows
 // +build !windows
 
 package platform_test
@@ -21,6 +22,7 @@ import (
 	"github.com/cloudfoundry/bosh-agent/platform/cert/certfakes"
 	"github.com/cloudfoundry/bosh-agent/platform/disk/diskfakes"
 	fakedisk "github.com/cloudfoundry/bosh-agent/platform/disk/fakes"
+	fakefs "github.com/cloudfoundry/bosh-agent/platform/fakefs"
 	fakeplat "github.com/cloudfoundry/bosh-agent/platform/fakes"
 	fakenet "github.com/cloudfoundry/bosh-agent/platform/net/fakes"
 	fakestats "github.com/cloudfoundry/bosh-agent/platform/stats/fakes"
@@ -29,6 +31,7 @@ import (
 	fakeuuidgen "github.com/cloudfoundry/bosh-utils/uuid/fakes"
 
 	boshdisk "github.com/cloudfoundry/bosh-agent/platform/disk"
+	boshstats "github.com/cloudfoundry/bosh-agent/platform/stats"
 	boshvitals "github.com/cloudfoundry/bosh-agent/platform/vitals"
 	boshsettings "github.com/cloudfoundry/bosh-agent/settings"
 	boshdirs "github.com/cloudfoundry/bosh-agent/settings/directories"
@@ -39,7 +42,7 @@ import (
 var _ = Describe("LinuxPlatform", func() {
 	var (
 		collector                  *fakestats.FakeCollector
-		fs                         *fakesys.FakeFileSystem
+		fs                         *fakefs.FakeFileSystem
 		cmdRunner                  *fakesys.FakeCmdRunner
 		diskManager                *diskfakes.FakeManager
 		dirProvider                boshdirs.Provider
@@ -74,7 +77,7 @@ var _ = Describe("LinuxPlatform", func() {
 		logger = boshlog.NewLogger(boshlog.LevelNone)
 
 		collector = &fakestats.FakeCollector{}
-		fs = fakesys.NewFakeFileSystem()
+		fs = fakefs.NewFakeFileSystem()
 		cmdRunner = fakesys.NewFakeCmdRunner()
 		dirProvider = boshdirs.NewProvider("/fake-dir")
 		cdutil = fakecdrom.NewFakeCDUtil()
@@ -174,7 +177,7 @@ var _ = Describe("LinuxPlatform", func() {
 			Expect(err).NotTo(HaveOccurred())
 
 			basePathStat := fs.GetFileTestStat("/some/path/to/home1")
-			Expect(basePathStat.FileType).To(Equal(fakesys.FakeFileTypeDir))
+			Expect(basePathStat.FileType).To(Equal(fakefs.FakeFileTypeDir))
 			Expect(basePathStat.FileMode).To(Equal(os.FileMode(0755)))
 
 			Expect(len(cmdRunner.RunCommands)).To(Equal(2))
@@ -601,14 +604,14 @@ bosh_foobar:...`
 			Expect("vcap").To(Equal(fs.HomeDirUsername))
 
 			Expect(sshDirStat).NotTo(BeNil())
-			Expect(sshDirStat.FileType).To(Equal(fakesys.FakeFileTypeDir))
+			Expect(sshDirStat.FileType).To(Equal(fakefs.FakeFileTypeDir))
 			Expect(os.FileMode(0700)).To(Equal(sshDirStat.FileMode))
 			Expect("vcap").To(Equal(sshDirStat.Username))
 
 			authKeysStat := fs.GetFileTestStat(path.Join(sshDirPath, "authorized_keys"))
 
 			Expect(authKeysStat).NotTo(BeNil())
-			Expect(fakesys.FakeFileTypeFile).To(Equal(authKeysStat.FileType))
+			Expect(fakefs.FakeFileTypeFile).To(Equal(authKeysStat.FileType))
 			Expect(os.FileMode(0600)).To(Equal(authKeysStat.FileMode))
 			Expect("vcap").To(Equal(authKeysStat.Username))
 			Expect("some public key").To(Equal(authKeysStat.StringContents()))
@@ -625,19 +628,87 @@ bosh_foobar:...`
 			Expect("vcap").To(Equal(fs.HomeDirUsername))
 
 			Expect(sshDirStat).NotTo(BeNil())
-			Expect(sshDirStat.FileType).To(Equal(fakesys.FakeFileTypeDir))
+			Expect(sshDirStat.FileType).To(Equal(fakefs.FakeFileTypeDir))
 			Expect(os.FileMode(0700)).To(Equal(sshDirStat.FileMode))
 			Expect("vcap").To(Equal(sshDirStat.Username))
 
 			authKeysStat := fs.GetFileTestStat(path.Join(sshDirPath, "authorized_keys"))
 
 			Expect(authKeysStat).NotTo(BeNil())
-			Expect(fakesys.FakeFileTypeFile).To(Equal(authKeysStat.FileType))
+			Expect(fakefs.FakeFileTypeFile).To(Equal(authKeysStat.FileType))
 			Expect(os.FileMode(0600)).To(Equal(authKeysStat.FileMode))
 			Expect("vcap").To(Equal(authKeysStat.Username))
 			Expect("some public key\nsome other public key").To(Equal(authKeysStat.StringContents()))
 		})
 
+		It("replaces keys already in authorized_keys", func() {
+			fs.HomeDirHomePath = "/some/home/dir"
+			fs.WriteFileString("/some/home/dir/.ssh/authorized_keys", "out-of-band key\n")
+
+			err := platform.SetupSSH([]string{"some public key"}, "vcap")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fs.ReadFileString("/some/home/dir/.ssh/authorized_keys")).To(Equal("some public key"))
+		})
+
+		Context("when authorized keys are preserved", func() {
+			BeforeEach(func() {
+				options.PreserveAuthorizedKeys = true
+				fs.HomeDirHomePath = "/some/home/dir"
+			})
+
+			It("writes the keys when there is no authorized_keys file yet", func() {
+				err := platform.SetupSSH([]string{"some public key", "some other public key"}, "vcap")
+				Expect(err).NotTo(HaveOccurred())
+
+				authKeysStat := fs.GetFileTestStat("/some/home/dir/.ssh/authorized_keys")
+				Expect(authKeysStat).NotTo(BeNil())
+				Expect(os.FileMode(0600)).To(Equal(authKeysStat.FileMode))
+				Expect("vcap").To(Equal(authKeysStat.Username))
+				Expect(authKeysStat.StringContents()).To(Equal("some public key\nsome other public key"))
+			})
+
+			It("writes the keys when authorized_keys is empty", func() {
+				fs.WriteFileString("/some/home/dir/.ssh/authorized_keys", "")
+
+				err := platform.SetupSSH([]string{"some public key"}, "vcap")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fs.ReadFileString("/some/home/dir/.ssh/authorized_keys")).To(Equal("some public key"))
+			})
+
+			It("appends keys that are not present yet after the existing ones", func() {
+				fs.WriteFileString("/some/home/dir/.ssh/authorized_keys", "out-of-band key\n")
+
+				err := platform.SetupSSH([]string{"some public key"}, "vcap")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fs.ReadFileString("/some/home/dir/.ssh/authorized_keys")).To(Equal("out-of-band key\nsome public key"))
+			})
+
+			It("does not duplicate keys that are already present", func() {
+				fs.WriteFileString("/some/home/dir/.ssh/authorized_keys", "out-of-band key\nsome public key")
+
+				err := platform.SetupSSH([]string{"some public key", "some other public key", "some other public key"}, "vcap")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fs.ReadFileString("/some/home/dir/.ssh/authorized_keys")).To(Equal("out-of-band key\nsome public key\nsome other public key"))
+
+				err = platform.SetupSSH([]string{"some public key"}, "vcap")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fs.ReadFileString("/some/home/dir/.ssh/authorized_keys")).To(Equal("out-of-band key\nsome public key\nsome other public key"))
+			})
+
+			It("returns an error when authorized_keys cannot be read", func() {
+				fs.WriteFileString("/some/home/dir/.ssh/authorized_keys", "out-of-band key")
+				fs.RegisterReadFileError("/some/home/dir/.ssh/authorized_keys", errors.New("fake-read-error"))
+
+				err := platform.SetupSSH([]string{"some public key"}, "vcap")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Reading authorized_keys file"))
+			})
+		})
 	})
 
 	Describe("SetUserPassword", func() {
@@ -756,7 +827,7 @@ fake-base-path/data/sys/log/*.log fake-base-path/data/sys/log/.*.log fake-base-p
 `
 
 		It("sets up logrotate", func() {
-			platform.SetupLogrotate("fake-group-name", "fake-base-path", "fake-size")
+			platform.SetupLogrotate("fake-group-name", "fake-base-path", "fake-size", 7, map[string]int{})
 
 			logrotateFileContent, err := fs.ReadFileString("/etc/logrotate.d/fake-group-name")
 			Expect(err).NotTo(HaveOccurred())
@@ -765,6 +836,64 @@ fake-base-path/data/sys/log/*.log fake-base-path/data/sys/log/.*.log fake-base-p
 			Expect(len(cmdRunner.RunCommands)).To(Equal(1))
 			Expect(cmdRunner.RunCommands[0]).To(Equal([]string{"/var/vcap/bosh/bin/setup-logrotate.sh"}))
 		})
+
+		It("keeps the configured number of rotated log files", func() {
+			err := platform.SetupLogrotate("fake-group-name", "fake-base-path", "fake-size", 3, map[string]int{})
+			Expect(err).NotTo(HaveOccurred())
+
+			logrotateFileContent, err := fs.ReadFileString("/etc/logrotate.d/fake-group-name")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(logrotateFileContent).To(ContainSubstring("  rotate 3\n"))
+			Expect(logrotateFileContent).NotTo(ContainSubstring("rotate 7"))
+		})
+
+		Context("when jobs keep a different number of rotated log files", func() {
+			BeforeEach(func() {
+				fs.GlobUsesRealMatching = true
+
+				err := fs.MkdirAll("/fake-base-path/data/sys/log/verbose-job", 0755)
+				Expect(err).NotTo(HaveOccurred())
+				err = fs.MkdirAll("/fake-base-path/data/sys/log/quiet-job", 0755)
+				Expect(err).NotTo(HaveOccurred())
+				err = fs.WriteFileString("/fake-base-path/data/sys/log/agent.log", "")
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("rotates the logs of those jobs in their own entries and lists the other log directories", func() {
+				err := platform.SetupLogrotate("fake-group-name", "/fake-base-path", "fake-size", 7, map[string]int{"verbose-job": 2})
+				Expect(err).NotTo(HaveOccurred())
+
+				logrotateFileContent, err := fs.ReadFileString("/etc/logrotate.d/fake-group-name")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(logrotateFileContent).To(Equal(`# Generated by bosh-agent
+
+/fake-base-path/data/sys/log/*.log /fake-base-path/data/sys/log/.*.log /fake-base-path/data/sys/log/quiet-job/*.log /fake-base-path/data/sys/log/quiet-job/.*.log /fake-base-path/data/sys/log/quiet-job/*/*.log /fake-base-path/data/sys/log/quiet-job/*/.*.log {
+  missingok
+  rotate 7
+  compress
+  copytruncate
+  size=fake-size
+}
+
+/fake-base-path/data/sys/log/verbose-job/*.log /fake-base-path/data/sys/log/verbose-job/.*.log /fake-base-path/data/sys/log/verbose-job/*/*.log /fake-base-path/data/sys/log/verbose-job/*/.*.log {
+  missingok
+  rotate 2
+  compress
+  copytruncate
+  size=fake-size
+}
+`))
+			})
+
+			It("returns an error when the log directories cannot be listed", func() {
+				fs.GlobErr = errors.New("fake-glob-error")
+
+				err := platform.SetupLogrotate("fake-group-name", "/fake-base-path", "fake-size", 7, map[string]int{"verbose-job": 2})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-glob-error"))
+				Expect(fs.FileExists("/etc/logrotate.d/fake-group-name")).To(BeFalse())
+			})
+		})
 	})
 
 	Describe("SetTimeWithNtpServers", func() {
@@ -773,7 +902,7 @@ fake-base-path/data/sys/log/*.log fake-base-path/data/sys/log/.*.log fake-base-p
 
 			ntpConfig := fs.GetFileTestStat("/fake-dir/bosh/etc/ntpserver")
 			Expect(ntpConfig.StringContents()).To(Equal("0.north-america.pool.ntp.org 1.north-america.pool.ntp.org"))
-			Expect(ntpConfig.FileType).To(Equal(fakesys.FakeFileTypeFile))
+			Expect(ntpConfig.FileType).To(Equal(fakefs.FakeFileTypeFile))
 
 			Expect(len(cmdRunner.RunCommands)).To(Equal(1))
 			Expect(cmdRunner.RunCommands[0]).To(Equal([]string{"sync-time"}))
@@ -805,7 +934,7 @@ fake-base-path/data/sys/log/*.log fake-base-path/data/sys/log/.*.log fake-base-p
 				Expect(err).NotTo(HaveOccurred())
 
 				dataDir := fs.GetFileTestStat("/fake-dir/data")
-				Expect(dataDir.FileType).To(Equal(fakesys.FakeFileTypeDir))
+				Expect(dataDir.FileType).To(Equal(fakefs.FakeFileTypeDir))
 				Expect(dataDir.FileMode).To(Equal(os.FileMode(0750)))
 			})
 
@@ -935,6 +1064,76 @@ fake-base-path/data/sys/log/*.log fake-base-path/data/sys/log/.*.log fake-base-p
 						partition = mounter.SwapOnArgsForCall(0)
 						Expect(partition).To(Equal(partitionPath(devicePath, 1)))
 					})
+
+					Context("when swap creation is skipped", func() {
+						BeforeEach(func() {
+							options.SkipSwapCreation = true
+						})
+
+						It("creates a single data partition spanning the whole disk", func() {
+							collector.MemStats.Total = uint64(1024 * 1024)
+							partitioner.GetDeviceSizeInBytesSizes[devicePath] = uint64(4 * 1024 * 1024)
+							err := act()
+							Expect(err).NotTo(HaveOccurred())
+
+							Expect(partitioner.PartitionPartitions).To(Equal([]boshdisk.Partition{
+								{NamePrefix: expectedLabelPrefix, SizeInBytes: uint64(4 * 1024 * 1024), Type: boshdisk.PartitionTypeLinux},
+							}))
+						})
+
+						It("formats and mounts the data partition without turning on swap", func() {
+							collector.MemStats.Total = uint64(1024 * 1024)
+							partitioner.GetDeviceSizeInBytesSizes[devicePath] = uint64(4 * 1024 * 1024)
+							err := act()
+							Expect(err).NotTo(HaveOccurred())
+
+							Expect(formatter.FormatPartitionPaths).To(Equal([]string{partitionPath(devicePath, 1)}))
+							Expect(formatter.FormatFsTypes).To(Equal([]boshdisk.FileSystemType{boshdisk.FileSystemExt4}))
+
+							Expect(mounter.MountCallCount()).To(Equal(1))
+							partition, mntPoint, _ := mounter.MountArgsForCall(0)
+							Expect(partition).To(Equal(partitionPath(devicePath, 1)))
+							Expect(mntPoint).To(Equal("/fake-dir/data"))
+
+							Expect(mounter.SwapOnCallCount()).To(Equal(0))
+						})
+					})
+
+					Context("when the data partition filesystem is xfs", func() {
+						BeforeEach(func() {
+							options.EphemeralDiskFileSystemType = "xfs"
+						})
+
+						It("formats the data partition as xfs and swap as swap", func() {
+							collector.MemStats.Total = uint64(1024 * 1024)
+							partitioner.GetDeviceSizeInBytesSizes[devicePath] = uint64(1024 * 1024)
+							err := act()
+							Expect(err).NotTo(HaveOccurred())
+
+							Expect(formatter.FormatPartitionPaths).To(Equal([]string{partitionPath(devicePath, 1), partitionPath(devicePath, 2)}))
+							Expect(formatter.FormatFsTypes).To(Equal([]boshdisk.FileSystemType{boshdisk.FileSystemSwap, boshdisk.FileSystemXFS}))
+
+							Expect(mounter.MountCallCount()).To(Equal(1))
+						})
+					})
+
+					Context("when the data partition filesystem is not supported", func() {
+						BeforeEach(func() {
+							options.EphemeralDiskFileSystemType = "btrfs"
+						})
+
+						It("returns an error without touching the disk", func() {
+							collector.MemStats.Total = uint64(1024 * 1024)
+							partitioner.GetDeviceSizeInBytesSizes[devicePath] = uint64(1024 * 1024)
+							err := act()
+							Expect(err).To(HaveOccurred())
+							Expect(err.Error()).To(Equal(`The ephemeral disk filesystem type "btrfs" is not supported`))
+
+							Expect(partitioner.PartitionCalled).To(BeFalse())
+							Expect(formatter.FormatPartitionPaths).To(BeEmpty())
+							Expect(mounter.MountCallCount()).To(Equal(0))
+						})
+					})
 				})
 
 				It("creates swap the size of the memory and the rest for data when disk is bigger than twice the memory", func() {
@@ -1509,7 +1708,7 @@ fake-base-path/data/sys/log/*.log fake-base-path/data/sys/log/.*.log fake-base-p
 				Expect(err).ToNot(HaveOccurred())
 
 				dataDir := fs.GetFileTestStat("/fake-dir/data")
-				Expect(dataDir.FileType).To(Equal(fakesys.FakeFileTypeDir))
+				Expect(dataDir.FileType).To(Equal(fakefs.FakeFileTypeDir))
 				Expect(dataDir.FileMode).To(Equal(os.FileMode(0750)))
 
 				Expect(partitioner.PartitionCalled).To(BeFalse())
@@ -1519,6 +1718,96 @@ fake-base-path/data/sys/log/*.log fake-base-path/data/sys/log/.*.log fake-base-p
 		})
 	})
 
+	Describe("GrowEphemeralPartition", func() {
+		BeforeEach(func() {
+			cmdRunner.CommandExistsValue = true
+			cmdRunner.AddCmdResult("readlink -f /dev/xvdb2", fakesys.FakeCmdResult{Stdout: "/dev/xvdb2\n"})
+
+			partitioner.GetPartitionsPartitions = []boshdisk.ExistingPartition{
+				{Index: 1, StartInBytes: 1048576, EndInBytes: 1073741823, SizeInBytes: 1072693248, Type: boshdisk.PartitionTypeSwap},
+				{Index: 2, StartInBytes: 1073741824, EndInBytes: 10737401855, SizeInBytes: 9663660032, Type: boshdisk.PartitionTypeLinux},
+			}
+		})
+
+		Context("when the device has grown", func() {
+			BeforeEach(func() {
+				partitioner.GetPartitionsSizes = map[string]uint64{"/dev/xvdb": 21474836480}
+			})
+
+			It("grows the data partition and its ext4 filesystem", func() {
+				err := platform.GrowEphemeralPartition("/dev/xvdb")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(cmdRunner.RunCommands).To(ContainElement([]string{"growpart", "/dev/xvdb", "2"}))
+				Expect(formatter.GrowFilesystemPartitionPaths).To(Equal([]string{"/dev/xvdb2"}))
+				Expect(formatter.GrowFilesystemFsTypes).To(Equal([]boshdisk.FileSystemType{boshdisk.FileSystemExt4}))
+			})
+
+			It("grows the filesystem when growpart reports the partition cannot be grown further", func() {
+				cmdRunner.AddCmdResult("growpart /dev/xvdb 2", fakesys.FakeCmdResult{
+					Stdout: "NOCHANGE: partition 2 is size 20971487. it cannot be grown\n",
+					Error:  errors.New("fake-growpart-error"),
+				})
+
+				err := platform.GrowEphemeralPartition("/dev/xvdb")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(formatter.GrowFilesystemPartitionPaths).To(Equal([]string{"/dev/xvdb2"}))
+			})
+
+			It("returns an error when growpart fails", func() {
+				cmdRunner.AddCmdResult("growpart /dev/xvdb 2", fakesys.FakeCmdResult{Error: errors.New("fake-growpart-error")})
+
+				err := platform.GrowEphemeralPartition("/dev/xvdb")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-growpart-error"))
+				Expect(formatter.GrowFilesystemPartitionPaths).To(BeEmpty())
+			})
+
+			It("returns an error when growing the filesystem fails", func() {
+				formatter.GrowFilesystemError = errors.New("fake-resize-error")
+
+				err := platform.GrowEphemeralPartition("/dev/xvdb")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-resize-error"))
+			})
+
+			It("returns an error when growpart is not installed", func() {
+				cmdRunner.CommandExistsValue = false
+
+				err := platform.GrowEphemeralPartition("/dev/xvdb")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("'growpart' is not installed"))
+			})
+		})
+
+		It("does nothing when the data partition already fills the device", func() {
+			partitioner.GetPartitionsSizes = map[string]uint64{"/dev/xvdb": 10737418240}
+
+			err := platform.GrowEphemeralPartition("/dev/xvdb")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(cmdRunner.RunCommands).To(BeEmpty())
+			Expect(formatter.GrowFilesystemPartitionPaths).To(BeEmpty())
+		})
+
+		It("returns an error when the last partition is not a linux partition", func() {
+			partitioner.GetPartitionsPartitions = partitioner.GetPartitionsPartitions[:1]
+			partitioner.GetPartitionsSizes = map[string]uint64{"/dev/xvdb": 21474836480}
+
+			err := platform.GrowEphemeralPartition("/dev/xvdb")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("is not a linux partition"))
+		})
+
+		It("returns an error when the partitions cannot be read", func() {
+			partitioner.GetPartitionsErr = errors.New("fake-get-partitions-error")
+
+			err := platform.GrowEphemeralPartition("/dev/xvdb")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-get-partitions-error"))
+		})
+	})
+
 	Describe("SetupRawEphemeralDisks", func() {
 		It("labels the raw ephemeral paths for unpartitioned disks", func() {
 			result := fakesys.FakeCmdResult{
@@ -1681,6 +1970,73 @@ Number  Start   End     Size    File system  Name             Flags
 		})
 	})
 
+	Describe("SetupAdditionalEphemeralDisks", func() {
+		BeforeEach(func() {
+			partitioner.GetDeviceSizeInBytesSizes["/dev/xvdc"] = uint64(1024 * 1024)
+			partitioner.GetDeviceSizeInBytesSizes["/dev/xvdd"] = uint64(2048 * 1024)
+
+			cmdRunner.AddCmdResult("readlink -f /dev/xvdc1", fakesys.FakeCmdResult{Stdout: "/dev/xvdc1\n"})
+			cmdRunner.AddCmdResult("readlink -f /dev/xvdd1", fakesys.FakeCmdResult{Stdout: "/dev/xvdd1\n"})
+		})
+
+		It("partitions, formats and mounts each disk below the data dir", func() {
+			err := platform.SetupAdditionalEphemeralDisks([]string{"/dev/xvdc", "/dev/xvdd"}, "fake-agent-id")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(partitioner.PartitionDevicePath).To(Equal("/dev/xvdd"))
+			Expect(partitioner.PartitionPartitions).To(Equal([]boshdisk.Partition{
+				{NamePrefix: "bosh-partition-fake-agent-id", SizeInBytes: 2048 * 1024, Type: boshdisk.PartitionTypeLinux},
+			}))
+
+			Expect(formatter.FormatPartitionPaths).To(Equal([]string{"/dev/xvdc1", "/dev/xvdd1"}))
+			Expect(formatter.FormatFsTypes).To(Equal([]boshdisk.FileSystemType{boshdisk.FileSystemExt4, boshdisk.FileSystemExt4}))
+
+			Expect(mounter.MountCallCount()).To(Equal(2))
+			partition, mountPoint, _ := mounter.MountArgsForCall(0)
+			Expect(partition).To(Equal("/dev/xvdc1"))
+			Expect(mountPoint).To(Equal("/fake-dir/data/ephemeral_disks/1"))
+			partition, mountPoint, _ = mounter.MountArgsForCall(1)
+			Expect(partition).To(Equal("/dev/xvdd1"))
+			Expect(mountPoint).To(Equal("/fake-dir/data/ephemeral_disks/2"))
+
+			Expect(fs.FileExists("/fake-dir/data/ephemeral_disks/1")).To(BeTrue())
+			Expect(fs.FileExists("/fake-dir/data/ephemeral_disks/2")).To(BeTrue())
+		})
+
+		It("returns an error when a disk was not found", func() {
+			err := platform.SetupAdditionalEphemeralDisks([]string{"/dev/xvdc", ""}, "fake-agent-id")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Additional ephemeral disk 2 not found"))
+
+			Expect(mounter.MountCallCount()).To(Equal(1))
+		})
+
+		It("returns an error when partitioning fails", func() {
+			partitioner.PartitionErr = errors.New("fake-partition-error")
+
+			err := platform.SetupAdditionalEphemeralDisks([]string{"/dev/xvdc", "/dev/xvdd"}, "fake-agent-id")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-partition-error"))
+
+			Expect(formatter.FormatCalled).To(BeFalse())
+			Expect(mounter.MountCallCount()).To(Equal(0))
+		})
+
+		Context("when SkipDiskSetup is true", func() {
+			BeforeEach(func() {
+				options.SkipDiskSetup = true
+			})
+
+			It("does nothing", func() {
+				err := platform.SetupAdditionalEphemeralDisks([]string{"/dev/xvdc", "/dev/xvdd"}, "fake-agent-id")
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(partitioner.PartitionCalled).To(BeFalse())
+				Expect(mounter.MountCallCount()).To(Equal(0))
+			})
+		})
+	})
+
 	Describe("SetupDataDir", func() {
 		It("creates jobs directory in data directory", func() {
 			err := platform.SetupDataDir(boshsettings.JobDir{}, boshsettings.RunDir{})
@@ -1688,7 +2044,7 @@ Number  Start   End     Size    File system  Name             Flags
 
 			sysLogStats := fs.GetFileTestStat("/fake-dir/data/jobs")
 			Expect(sysLogStats).ToNot(BeNil())
-			Expect(sysLogStats.FileType).To(Equal(fakesys.FakeFileTypeDir))
+			Expect(sysLogStats.FileType).To(Equal(fakefs.FakeFileTypeDir))
 			Expect(sysLogStats.FileMode).To(Equal(os.FileMode(0750)))
 			Expect(cmdRunner.RunCommands[2]).To(Equal([]string{"chown", "root:vcap", "/fake-dir/data/jobs"}))
 		})
@@ -1699,7 +2055,7 @@ Number  Start   End     Size    File system  Name             Flags
 
 			sysLogStats := fs.GetFileTestStat("/fake-dir/data/sensitive_blobs")
 			Expect(sysLogStats).ToNot(BeNil())
-			Expect(sysLogStats.FileType).To(Equal(fakesys.FakeFileTypeDir))
+			Expect(sysLogStats.FileType).To(Equal(fakefs.FakeFileTypeDir))
 			Expect(sysLogStats.FileMode).To(Equal(os.FileMode(0700)))
 			Expect(cmdRunner.RunCommands[3]).To(Equal([]string{"chown", "root:vcap", "/fake-dir/data/sensitive_blobs"}))
 		})
@@ -1710,7 +2066,7 @@ Number  Start   End     Size    File system  Name             Flags
 
 			sysLogStats := fs.GetFileTestStat("/fake-dir/data/packages")
 			Expect(sysLogStats).ToNot(BeNil())
-			Expect(sysLogStats.FileType).To(Equal(fakesys.FakeFileTypeDir))
+			Expect(sysLogStats.FileType).To(Equal(fakefs.FakeFileTypeDir))
 			Expect(sysLogStats.FileMode).To(Equal(os.FileMode(0755)))
 			Expect(cmdRunner.RunCommands[4]).To(Equal([]string{"chown", "root:vcap", "/fake-dir/data/packages"}))
 		})
@@ -1770,7 +2126,7 @@ Number  Start   End     Size    File system  Name             Flags
 
 				sysLogStats := fs.GetFileTestStat("/fake-dir/data/sys/log")
 				Expect(sysLogStats).ToNot(BeNil())
-				Expect(sysLogStats.FileType).To(Equal(fakesys.FakeFileTypeDir))
+				Expect(sysLogStats.FileType).To(Equal(fakefs.FakeFileTypeDir))
 				Expect(sysLogStats.FileMode).To(Equal(os.FileMode(0750)))
 				Expect(cmdRunner.RunCommands[0]).To(Equal([]string{"chown", "root:vcap", "/fake-dir/data/sys"}))
 				Expect(cmdRunner.RunCommands[1]).To(Equal([]string{"chown", "root:vcap", "/fake-dir/data/sys/log"}))
@@ -1782,7 +2138,7 @@ Number  Start   End     Size    File system  Name             Flags
 
 				sysStats := fs.GetFileTestStat("/fake-dir/sys")
 				Expect(sysStats).ToNot(BeNil())
-				Expect(sysStats.FileType).To(Equal(fakesys.FakeFileTypeSymlink))
+				Expect(sysStats.FileType).To(Equal(fakefs.FakeFileTypeSymlink))
 				Expect(sysStats.SymlinkTarget).To(Equal("/fake-dir/data/sys"))
 			})
 
@@ -1812,7 +2168,7 @@ Number  Start   End     Size    File system  Name             Flags
 
 				sysRunStats := fs.GetFileTestStat("/fake-dir/bosh/canrestart")
 				Expect(sysRunStats).ToNot(BeNil())
-				Expect(sysRunStats.FileType).To(Equal(fakesys.FakeFileTypeDir))
+				Expect(sysRunStats.FileType).To(Equal(fakefs.FakeFileTypeDir))
 				Expect(sysRunStats.FileMode).To(Equal(os.FileMode(0740)))
 				Expect(cmdRunner.RunCommands).To(HaveLen(1))
 				Expect(cmdRunner.RunCommands[0]).To(Equal([]string{"chown", "root:vcap", "/fake-dir/bosh/canrestart"}))
@@ -1866,7 +2222,7 @@ Number  Start   End     Size    File system  Name             Flags
 
 				sysLogStats := fs.GetFileTestStat("/fake-dir/data/sys/log")
 				Expect(sysLogStats).ToNot(BeNil())
-				Expect(sysLogStats.FileType).To(Equal(fakesys.FakeFileTypeDir))
+				Expect(sysLogStats.FileType).To(Equal(fakefs.FakeFileTypeDir))
 				Expect(sysLogStats.FileMode).To(Equal(os.FileMode(0750)))
 				Expect(cmdRunner.RunCommands[0]).To(Equal([]string{"chown", "root:vcap", "/fake-dir/data/sys"}))
 				Expect(cmdRunner.RunCommands[1]).To(Equal([]string{"chown", "root:vcap", "/fake-dir/data/sys/log"}))
@@ -1878,7 +2234,7 @@ Number  Start   End     Size    File system  Name             Flags
 
 				sysStats := fs.GetFileTestStat("/fake-dir/sys")
 				Expect(sysStats).ToNot(BeNil())
-				Expect(sysStats.FileType).To(Equal(fakesys.FakeFileTypeSymlink))
+				Expect(sysStats.FileType).To(Equal(fakefs.FakeFileTypeSymlink))
 				Expect(sysStats.SymlinkTarget).To(Equal("/fake-dir/data/sys"))
 			})
 
@@ -1888,7 +2244,7 @@ Number  Start   End     Size    File system  Name             Flags
 
 				sysRunStats := fs.GetFileTestStat("/fake-dir/data/sys/run")
 				Expect(sysRunStats).ToNot(BeNil())
-				Expect(sysRunStats.FileType).To(Equal(fakesys.FakeFileTypeDir))
+				Expect(sysRunStats.FileType).To(Equal(fakefs.FakeFileTypeDir))
 				Expect(sysRunStats.FileMode).To(Equal(os.FileMode(0750)))
 				Expect(cmdRunner.RunCommands[5]).To(Equal([]string{"chown", "root:vcap", "/fake-dir/data/sys/run"}))
 			})
@@ -2004,7 +2360,7 @@ Number  Start   End     Size    File system  Name             Flags
 
 			fileStats := fs.GetFileTestStat("/fake-dir/data/tmp")
 			Expect(fileStats).NotTo(BeNil())
-			Expect(fileStats.FileType).To(Equal(fakesys.FakeFileType(fakesys.FakeFileTypeDir)))
+			Expect(fileStats.FileType).To(Equal(fakefs.FakeFileType(fakefs.FakeFileTypeDir)))
 			Expect(fileStats.FileMode).To(Equal(os.FileMode(0755)))
 		})
 
@@ -2394,7 +2750,7 @@ Number  Start   End     Size    File system  Name             Flags
 				err := act()
 				Expect(err).NotTo(HaveOccurred())
 				testFileStat := fs.GetFileTestStat("/fake-dir/data/root_log")
-				Expect(testFileStat.FileType).To(Equal(fakesys.FakeFileTypeDir))
+				Expect(testFileStat.FileType).To(Equal(fakefs.FakeFileTypeDir))
 				Expect(cmdRunner.RunCommands[0]).To(Equal([]string{"chmod", "0771", "/fake-dir/data/root_log"}))
 			})
 
@@ -2549,7 +2905,7 @@ sam:fakeanotheruser`)
 			err := act()
 			Expect(err).NotTo(HaveOccurred())
 			testFileStat := fs.GetFileTestStat("/fake-dir/data/blobs")
-			Expect(testFileStat.FileType).To(Equal(fakesys.FakeFileTypeDir))
+			Expect(testFileStat.FileType).To(Equal(fakefs.FakeFileTypeDir))
 			Expect(testFileStat.FileMode).To(Equal(os.FileMode(0700)))
 		})
 
@@ -2635,7 +2991,7 @@ sam:fakeanotheruser`)
 					It("mounts the store migration directory", func() {
 						err := platform.MountPersistentDisk(diskSettings, mntPoint)
 						Expect(err).ToNot(HaveOccurred())
-						Expect(fs.GetFileTestStat("/fake-dir/store_migration_target").FileType).To(Equal(fakesys.FakeFileTypeDir))
+						Expect(fs.GetFileTestStat("/fake-dir/store_migration_target").FileType).To(Equal(fakefs.FakeFileTypeDir))
 
 						Expect(mounter.MountCallCount()).To(Equal(1))
 						partition, mntPt, options := mounter.MountArgsForCall(0)
@@ -2665,7 +3021,7 @@ sam:fakeanotheruser`)
 					Expect(err).ToNot(HaveOccurred())
 
 					mountPoint := fs.GetFileTestStat("/mnt/point")
-					Expect(mountPoint.FileType).To(Equal(fakesys.FakeFileTypeDir))
+					Expect(mountPoint.FileType).To(Equal(fakefs.FakeFileTypeDir))
 					Expect(mountPoint.FileMode).To(Equal(os.FileMode(0700)))
 				})
 
@@ -2742,6 +3098,107 @@ sam:fakeanotheruser`)
 						Expect(diskManager.GetPersistentDevicePartitionerArgsForCall(0)).To(Equal("cool-partitioner"))
 					})
 				})
+
+				Context("when a read-ahead is configured", func() {
+					BeforeEach(func() {
+						readAhead := 4096
+						diskSettings.ReadAhead = &readAhead
+					})
+
+					It("sets the read-ahead of the mounted partition", func() {
+						err := platform.MountPersistentDisk(diskSettings, mntPoint)
+						Expect(err).ToNot(HaveOccurred())
+
+						Expect(mounter.MountCallCount()).To(Equal(1))
+						Expect(cmdRunner.RunCommands).To(ContainElement(
+							[]string{"blockdev", "--setra", "4096", "/dev/mapper/fake-real-device-path-part1"},
+						))
+					})
+
+					It("does not set the read-ahead when it already has the configured value", func() {
+						cmdRunner.AddCmdResult("blockdev --getra /dev/mapper/fake-real-device-path-part1", fakesys.FakeCmdResult{Stdout: "4096\n"})
+
+						err := platform.MountPersistentDisk(diskSettings, mntPoint)
+						Expect(err).ToNot(HaveOccurred())
+
+						Expect(cmdRunner.RunCommands).ToNot(ContainElement(
+							[]string{"blockdev", "--setra", "4096", "/dev/mapper/fake-real-device-path-part1"},
+						))
+					})
+
+					It("sets the read-ahead when the disk is already mounted", func() {
+						mounter.IsMountPointReturns("/dev/mapper/fake-real-device-path-part1", true, nil)
+
+						err := platform.MountPersistentDisk(diskSettings, mntPoint)
+						Expect(err).ToNot(HaveOccurred())
+
+						Expect(mounter.MountCallCount()).To(Equal(0))
+						Expect(cmdRunner.RunCommands).To(ContainElement(
+							[]string{"blockdev", "--setra", "4096", "/dev/mapper/fake-real-device-path-part1"},
+						))
+					})
+
+					It("returns an error when setting the read-ahead fails", func() {
+						cmdRunner.AddCmdResult("blockdev --setra 4096 /dev/mapper/fake-real-device-path-part1", fakesys.FakeCmdResult{Error: errors.New("fake-blockdev-err")})
+
+						err := platform.MountPersistentDisk(diskSettings, mntPoint)
+						Expect(err).To(HaveOccurred())
+						Expect(err.Error()).To(ContainSubstring("Setting read-ahead of /dev/mapper/fake-real-device-path-part1"))
+						Expect(err.Error()).To(ContainSubstring("fake-blockdev-err"))
+					})
+
+					It("rejects an invalid read-ahead before mounting", func() {
+						readAhead := -1
+						diskSettings.ReadAhead = &readAhead
+
+						err := platform.MountPersistentDisk(diskSettings, mntPoint)
+						Expect(err).To(HaveOccurred())
+						Expect(err.Error()).To(Equal("Invalid persistent disk read-ahead -1, must be between 0 and 65536 sectors"))
+
+						Expect(mounter.MountCallCount()).To(Equal(0))
+						Expect(cmdRunner.RunCommands).To(BeEmpty())
+					})
+				})
+
+				Context("when the persistent disk filesystem is checked before mounting", func() {
+					BeforeEach(func() {
+						options.CheckPersistentDiskFilesystem = true
+					})
+
+					It("checks the partition before mounting a clean filesystem", func() {
+						mounter.MountStub = func(string, string, ...string) error {
+							Expect(formatter.CheckFilesystemPartitionPaths).To(Equal([]string{"/dev/mapper/fake-real-device-path-part1"}))
+							return nil
+						}
+
+						err := platform.MountPersistentDisk(diskSettings, mntPoint)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(mounter.MountCallCount()).To(Equal(1))
+					})
+
+					It("mounts a filesystem that got repaired", func() {
+						formatter.CheckFilesystemRepaired = true
+
+						err := platform.MountPersistentDisk(diskSettings, mntPoint)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(mounter.MountCallCount()).To(Equal(1))
+					})
+
+					It("does not mount a filesystem that cannot be repaired", func() {
+						formatter.CheckFilesystemError = errors.New("fake-fsck-err")
+
+						err := platform.MountPersistentDisk(diskSettings, mntPoint)
+						Expect(err).To(HaveOccurred())
+						Expect(err.Error()).To(Equal("Checking persistent disk filesystem: fake-fsck-err"))
+						Expect(mounter.MountCallCount()).To(Equal(0))
+					})
+				})
+
+				It("does not check the filesystem by default", func() {
+					err := platform.MountPersistentDisk(diskSettings, mntPoint)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(formatter.CheckFilesystemPartitionPaths).To(BeEmpty())
+				})
 			})
 		})
 
@@ -2775,7 +3232,7 @@ sam:fakeanotheruser`)
 					It("mounts the store migration directory", func() {
 						err := platform.MountPersistentDisk(diskSettings, mntPoint)
 						Expect(err).ToNot(HaveOccurred())
-						Expect(fs.GetFileTestStat("/fake-dir/store_migration_target").FileType).To(Equal(fakesys.FakeFileTypeDir))
+						Expect(fs.GetFileTestStat("/fake-dir/store_migration_target").FileType).To(Equal(fakefs.FakeFileTypeDir))
 
 						Expect(mounter.MountCallCount()).To(Equal(1))
 						partition, mntPt, options := mounter.MountArgsForCall(0)
@@ -2805,7 +3262,7 @@ sam:fakeanotheruser`)
 					Expect(err).ToNot(HaveOccurred())
 
 					mountPoint := fs.GetFileTestStat("/mnt/point")
-					Expect(mountPoint.FileType).To(Equal(fakesys.FakeFileTypeDir))
+					Expect(mountPoint.FileType).To(Equal(fakefs.FakeFileTypeDir))
 					Expect(mountPoint.FileMode).To(Equal(os.FileMode(0700)))
 				})
 
@@ -2869,7 +3326,7 @@ sam:fakeanotheruser`)
 					Expect(err).ToNot(HaveOccurred())
 
 					mountPoint := fs.GetFileTestStat("/mnt/point")
-					Expect(mountPoint.FileType).To(Equal(fakesys.FakeFileTypeDir))
+					Expect(mountPoint.FileType).To(Equal(fakefs.FakeFileTypeDir))
 					Expect(mountPoint.FileMode).To(Equal(os.FileMode(0700)))
 				})
 
@@ -2982,7 +3439,7 @@ sam:fakeanotheruser`)
 					Expect(err).ToNot(HaveOccurred())
 
 					mountPoint := fs.GetFileTestStat("/mnt/point")
-					Expect(mountPoint.FileType).To(Equal(fakesys.FakeFileTypeDir))
+					Expect(mountPoint.FileType).To(Equal(fakefs.FakeFileTypeDir))
 					Expect(mountPoint.FileMode).To(Equal(os.FileMode(0700)))
 				})
 
@@ -3707,6 +4164,24 @@ unit: sectors
 		})
 	})
 
+	Describe("GetCPULoad", func() {
+		It("returns the load reported by the stats collector", func() {
+			collector.CPULoad = boshstats.CPULoad{One: 0.5, Five: 1.25, Fifteen: 2}
+
+			load, err := platform.GetCPULoad()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(load).To(Equal(boshstats.CPULoad{One: 0.5, Five: 1.25, Fifteen: 2}))
+		})
+
+		It("returns an error when the stats collector fails", func() {
+			collector.CPULoadErr = errors.New("fake-cpu-load-error")
+
+			_, err := platform.GetCPULoad()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Getting CPU load: fake-cpu-load-error"))
+		})
+	})
+
 	Describe("GetHostPublicKey", func() {
 		It("gets host public key if file exists", func() {
 			fs.WriteFileString("/etc/ssh/ssh_host_rsa_key.pub", "public-key")
@@ -3904,7 +4379,7 @@ unit: sectors
 			basePathStat := fs.GetFileTestStat(recordsJSONFile.Name())
 
 			Expect(basePathStat).ToNot(BeNil())
-			Expect(basePathStat.FileType).To(Equal(fakesys.FakeFileTypeFile))
+			Expect(basePathStat.FileType).To(Equal(fakefs.FakeFileTypeFile))
 			Expect(basePathStat.FileMode).To(Equal(os.FileMode(0640)))
 			Expect(basePathStat.Username).To(Equal("root"))
 			Expect(basePathStat.Groupname).To(Equal("vcap"))
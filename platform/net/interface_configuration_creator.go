@@ -171,12 +171,19 @@ func (creator interfaceConfigurationCreator) createMultipleInterfaceConfiguratio
 	}
 
 	// create interface configuration for networks that do not have a MAC or have an alias
+	nameResolver := NewInterfaceNameResolver(networks, interfacesByMAC)
+
 	for _, networkSettings = range networks {
 		if networkSettings.Mac != "" || networkSettings.Alias == "" {
 			continue
 		}
 
-		staticConfigs, dhcpConfigs, err = creator.createInterfaceConfiguration(staticConfigs, dhcpConfigs, networkSettings.Alias, networkSettings)
+		ifaceName, err := nameResolver.Resolve(networkSettings.Alias)
+		if err != nil {
+			return nil, nil, bosherr.WrapError(err, "Resolving interface name for alias")
+		}
+
+		staticConfigs, dhcpConfigs, err = creator.createInterfaceConfiguration(staticConfigs, dhcpConfigs, ifaceName, networkSettings)
 		if err != nil {
 			return nil, nil, bosherr.WrapError(err, "Creating interface configuration using alias")
 		}
@@ -357,6 +357,28 @@ var _ = Describe("InterfaceConfigurationCreator", func() {
 				})
 			})
 
+			Context("when the aliased interface has a different kernel name", func() {
+				BeforeEach(func() {
+					staticNetwork.Alias = "eth0"
+					staticNetworkWithoutMAC.Alias = "eth0:1"
+					staticNetworkWithoutMAC.IP = "1.2.3.5"
+					networks["foo"] = staticNetwork
+					networks["baz"] = staticNetworkWithoutMAC
+					interfacesByMAC[staticNetwork.Mac] = "ens3"
+				})
+
+				It("names virtual interfaces after the kernel interface matching the alias MAC address", func() {
+					staticInterfaceConfigurations, _, err := interfaceConfigurationCreator.CreateInterfaceConfigurations(networks, interfacesByMAC)
+					Expect(err).ToNot(HaveOccurred())
+
+					names := []string{}
+					for _, config := range staticInterfaceConfigurations {
+						names = append(names, config.Name)
+					}
+					Expect(names).To(ConsistOf("ens3", "ens3:1"))
+				})
+			})
+
 			Context("when static network has postup routes, dhcp network has no postup routes", func() {
 				BeforeEach(func() {
 					staticNetwork.Routes = []boshsettings.Route{
@@ -0,0 +1,60 @@
+package net
+
+import (
+	"strings"
+
+	boshsettings "github.com/cloudfoundry/bosh-agent/settings"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+)
+
+// InterfaceNameResolver maps logical interface names used as network aliases
+// (e.g. eth0, eth0:1) to kernel interface names (e.g. ens3, ens3:1).
+// A logical name is bound to a MAC address by a network that has both an alias and a MAC;
+// the kernel interface with that MAC address is then used in place of the logical name.
+type InterfaceNameResolver struct {
+	macsByLogicalName map[string]string
+	interfacesByMAC   map[string]string
+}
+
+func NewInterfaceNameResolver(networks boshsettings.Networks, interfacesByMAC map[string]string) InterfaceNameResolver {
+	macsByLogicalName := map[string]string{}
+
+	for _, network := range networks {
+		if network.Alias == "" || network.Mac == "" {
+			continue
+		}
+
+		logicalName, _ := splitVirtualInterfaceName(network.Alias)
+		macsByLogicalName[logicalName] = network.Mac
+	}
+
+	return InterfaceNameResolver{
+		macsByLogicalName: macsByLogicalName,
+		interfacesByMAC:   interfacesByMAC,
+	}
+}
+
+// Resolve returns logicalName unchanged when no MAC address is bound to it
+func (r InterfaceNameResolver) Resolve(logicalName string) (string, error) {
+	baseName, virtualSuffix := splitVirtualInterfaceName(logicalName)
+
+	mac, found := r.macsByLogicalName[baseName]
+	if !found {
+		return logicalName, nil
+	}
+
+	ifaceName, found := r.interfacesByMAC[mac]
+	if !found {
+		return "", bosherr.Errorf("No device found for interface '%s' with MAC address '%s'", logicalName, mac)
+	}
+
+	return ifaceName + virtualSuffix, nil
+}
+
+// splitVirtualInterfaceName splits eth0:1 into eth0 and :1
+func splitVirtualInterfaceName(name string) (string, string) {
+	if i := strings.Index(name, ":"); i != -1 {
+		return name[:i], name[i:]
+	}
+	return name, ""
+}
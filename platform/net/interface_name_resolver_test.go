@@ -0,0 +1,63 @@
+package net_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-agent/platform/net"
+
+	boshsettings "github.com/cloudfoundry/bosh-agent/settings"
+)
+
+var _ = Describe("InterfaceNameResolver", func() {
+	var (
+		networks        boshsettings.Networks
+		interfacesByMAC map[string]string
+		resolver        InterfaceNameResolver
+	)
+
+	BeforeEach(func() {
+		networks = boshsettings.Networks{
+			"default": boshsettings.Network{Alias: "eth0", Mac: "aa:bb:cc:dd:ee:ff"},
+			"virtual": boshsettings.Network{Alias: "eth0:1"},
+		}
+		interfacesByMAC = map[string]string{
+			"aa:bb:cc:dd:ee:ff": "ens3",
+			"11:22:33:44:55:66": "enp0s8",
+		}
+	})
+
+	JustBeforeEach(func() {
+		resolver = NewInterfaceNameResolver(networks, interfacesByMAC)
+	})
+
+	It("maps a logical name to the kernel interface with the matching MAC address", func() {
+		ifaceName, err := resolver.Resolve("eth0")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ifaceName).To(Equal("ens3"))
+	})
+
+	It("keeps the virtual interface suffix of a logical name", func() {
+		ifaceName, err := resolver.Resolve("eth0:1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ifaceName).To(Equal("ens3:1"))
+	})
+
+	It("returns logical names without a bound MAC address unchanged", func() {
+		ifaceName, err := resolver.Resolve("eth1:2")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ifaceName).To(Equal("eth1:2"))
+	})
+
+	Context("when no interface has the MAC address bound to the logical name", func() {
+		BeforeEach(func() {
+			delete(interfacesByMAC, "aa:bb:cc:dd:ee:ff")
+		})
+
+		It("returns an error", func() {
+			_, err := resolver.Resolve("eth0:1")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("No device found for interface 'eth0:1' with MAC address 'aa:bb:cc:dd:ee:ff'"))
+		})
+	})
+})
@@ -24,6 +24,13 @@ import (
 
 const UbuntuNetManagerLogTag = "UbuntuNetManager"
 
+const (
+	restartNetworkingScriptPath = "/var/vcap/bosh/bin/restart_networking"
+
+	// Only present when systemd is the running init system
+	systemdRuntimeDir = "/run/systemd/system"
+)
+
 type UbuntuNetManager struct {
 	cmdRunner                     boshsys.CmdRunner
 	fs                            boshsys.FileSystem
@@ -309,7 +316,19 @@ func (net UbuntuNetManager) ifaceAddresses(staticConfigs []StaticInterfaceConfig
 }
 
 func (net UbuntuNetManager) restartNetworking() error {
-	_, _, _, err := net.cmdRunner.RunCommand("/var/vcap/bosh/bin/restart_networking")
+	var err error
+
+	// Stemcells ship a script knowing how to restart networking on their OS;
+	// otherwise restart whatever applies the generated configuration
+	switch {
+	case net.fs.FileExists(restartNetworkingScriptPath):
+		_, _, _, err = net.cmdRunner.RunCommand(restartNetworkingScriptPath)
+	case net.fs.FileExists(systemdRuntimeDir):
+		_, _, _, err = net.cmdRunner.RunCommand("systemctl", "restart", "systemd-networkd")
+	default:
+		_, _, _, err = net.cmdRunner.RunCommand("/etc/init.d/networking", "restart")
+	}
+
 	if err != nil {
 		return err
 	}
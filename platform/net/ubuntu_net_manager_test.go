@@ -256,6 +256,7 @@ var _ = Describe("ubuntuNetManager", func() {
 			interfaceAddrsProvider.GetInterfaceAddresses = []boship.InterfaceAddress{
 				boship.NewSimpleInterfaceAddress("ethstatic", "1.2.3.4"),
 			}
+			fs.WriteFileString("/var/vcap/bosh/bin/restart_networking", "")
 			fs.WriteFileString("/etc/resolv.conf", `
 nameserver 8.8.8.8
 nameserver 9.9.9.9
@@ -1055,6 +1056,54 @@ prepend domain-name-servers 8.8.8.8, 9.9.9.9;
 			Expect(fs.ReadFileString("/etc/dhcp/dhclient.conf")).ToNot(Equal(initialDhcpConfig))
 		})
 
+		Context("when the stemcell does not provide a restart_networking script", func() {
+			BeforeEach(func() {
+				stubInterfaces(map[string]boshsettings.Network{
+					"ethdhcp":   dhcpNetwork,
+					"ethstatic": staticNetwork,
+				})
+
+				err := fs.RemoveAll("/var/vcap/bosh/bin/restart_networking")
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("restarts systemd-networkd when systemd is the init system", func() {
+				err := fs.MkdirAll("/run/systemd/system", 0755)
+				Expect(err).ToNot(HaveOccurred())
+
+				err = netManager.SetupNetworking(boshsettings.Networks{"dhcp-network": dhcpNetwork, "static-network": staticNetwork}, nil)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(len(cmdRunner.RunCommands)).To(Equal(5))
+				Expect(cmdRunner.RunCommands[3]).To(Equal([]string{"systemctl", "restart", "systemd-networkd"}))
+				Expect(cmdRunner.RunCommands).ToNot(ContainElement([]string{"/etc/init.d/networking", "restart"}))
+			})
+
+			It("restarts networking through its init script otherwise", func() {
+				err := netManager.SetupNetworking(boshsettings.Networks{"dhcp-network": dhcpNetwork, "static-network": staticNetwork}, nil)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(len(cmdRunner.RunCommands)).To(Equal(5))
+				Expect(cmdRunner.RunCommands[3]).To(Equal([]string{"/etc/init.d/networking", "restart"}))
+				Expect(cmdRunner.RunCommands).ToNot(ContainElement([]string{"systemctl", "restart", "systemd-networkd"}))
+			})
+
+			It("does not restart networking when the configuration did not change", func() {
+				err := fs.MkdirAll("/run/systemd/system", 0755)
+				Expect(err).ToNot(HaveOccurred())
+
+				err = netManager.SetupNetworking(boshsettings.Networks{"dhcp-network": dhcpNetwork, "static-network": staticNetwork}, nil)
+				Expect(err).ToNot(HaveOccurred())
+
+				cmdRunner.ClearCommandHistory()
+
+				err = netManager.SetupNetworking(boshsettings.Networks{"dhcp-network": dhcpNetwork, "static-network": staticNetwork}, nil)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(cmdRunner.RunCommands).To(Equal([][]string{{"resolvconf", "-u"}}))
+			})
+		})
+
 		It("broadcasts MAC addresses for all interfaces", func() {
 			stubInterfaces(map[string]boshsettings.Network{
 				"ethdhcp":   dhcpNetwork,
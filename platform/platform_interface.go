@@ -6,6 +6,7 @@ import (
 	"log"
 
 	boshdpresolv "github.com/cloudfoundry/bosh-agent/infrastructure/devicepathresolver"
+	boshstats "github.com/cloudfoundry/bosh-agent/platform/stats"
 	boshvitals "github.com/cloudfoundry/bosh-agent/platform/vitals"
 	boshsettings "github.com/cloudfoundry/bosh-agent/settings"
 	boshdir "github.com/cloudfoundry/bosh-agent/settings/directories"
@@ -34,6 +35,8 @@ type Platform interface {
 	GetCopier() boshcmd.Copier
 	GetDirProvider() boshdir.Provider
 	GetVitalsService() boshvitals.Service
+	GetStatsCollector() boshstats.Collector
+	GetCPULoad() (boshstats.CPULoad, error)
 	GetAuditLogger() AuditLogger
 	GetDevicePathResolver() (devicePathResolver boshdpresolv.DevicePathResolver)
 	GetAgentSettingsPath(tmpfs bool) string
@@ -52,10 +55,12 @@ type Platform interface {
 	SetupIPv6(boshsettings.IPv6) error
 	SetupHostname(hostname string) (err error)
 	SetupNetworking(networks boshsettings.Networks) (err error)
-	SetupLogrotate(groupName, basePath, size string) (err error)
+	SetupLogrotate(groupName, basePath, size string, maxLogFiles int, jobMaxLogFiles map[string]int) (err error)
 	SetTimeWithNtpServers(servers []string) (err error)
 	SetupEphemeralDiskWithPath(devicePath string, desiredSwapSizeInBytes *uint64, labelPrefix string) (err error)
+	GrowEphemeralPartition(devicePath string) (err error)
 	SetupRawEphemeralDisks(devices []boshsettings.DiskSettings) (err error)
+	SetupAdditionalEphemeralDisks(devicePaths []string, labelPrefix string) (err error)
 	SetupDataDir(boshsettings.JobDir, boshsettings.RunDir) (err error)
 	SetupSharedMemory() (err error)
 	SetupTmpDir() (err error)
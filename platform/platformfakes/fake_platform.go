@@ -7,6 +7,7 @@ import (
 	"github.com/cloudfoundry/bosh-agent/infrastructure/devicepathresolver"
 	"github.com/cloudfoundry/bosh-agent/platform"
 	"github.com/cloudfoundry/bosh-agent/platform/cert"
+	"github.com/cloudfoundry/bosh-agent/platform/stats"
 	"github.com/cloudfoundry/bosh-agent/platform/vitals"
 	"github.com/cloudfoundry/bosh-agent/settings"
 	"github.com/cloudfoundry/bosh-agent/settings/directories"
@@ -94,6 +95,18 @@ type FakePlatform struct {
 	getAuditLoggerReturnsOnCall map[int]struct {
 		result1 platform.AuditLogger
 	}
+	GetCPULoadStub        func() (stats.CPULoad, error)
+	getCPULoadMutex       sync.RWMutex
+	getCPULoadArgsForCall []struct {
+	}
+	getCPULoadReturns struct {
+		result1 stats.CPULoad
+		result2 error
+	}
+	getCPULoadReturnsOnCall map[int]struct {
+		result1 stats.CPULoad
+		result2 error
+	}
 	GetCertManagerStub        func() cert.Manager
 	getCertManagerMutex       sync.RWMutex
 	getCertManagerArgsForCall []struct {
@@ -263,6 +276,16 @@ type FakePlatform struct {
 	getRunnerReturnsOnCall map[int]struct {
 		result1 system.CmdRunner
 	}
+	GetStatsCollectorStub        func() stats.Collector
+	getStatsCollectorMutex       sync.RWMutex
+	getStatsCollectorArgsForCall []struct {
+	}
+	getStatsCollectorReturns struct {
+		result1 stats.Collector
+	}
+	getStatsCollectorReturnsOnCall map[int]struct {
+		result1 stats.Collector
+	}
 	GetVitalsServiceStub        func() vitals.Service
 	getVitalsServiceMutex       sync.RWMutex
 	getVitalsServiceArgsForCall []struct {
@@ -273,6 +296,17 @@ type FakePlatform struct {
 	getVitalsServiceReturnsOnCall map[int]struct {
 		result1 vitals.Service
 	}
+	GrowEphemeralPartitionStub        func(string) error
+	growEphemeralPartitionMutex       sync.RWMutex
+	growEphemeralPartitionArgsForCall []struct {
+		arg1 string
+	}
+	growEphemeralPartitionReturns struct {
+		result1 error
+	}
+	growEphemeralPartitionReturnsOnCall map[int]struct {
+		result1 error
+	}
 	IsMountPointStub        func(string) (string, bool, error)
 	isMountPointMutex       sync.RWMutex
 	isMountPointArgsForCall []struct {
@@ -405,6 +439,18 @@ type FakePlatform struct {
 	setUserPasswordReturnsOnCall map[int]struct {
 		result1 error
 	}
+	SetupAdditionalEphemeralDisksStub        func([]string, string) error
+	setupAdditionalEphemeralDisksMutex       sync.RWMutex
+	setupAdditionalEphemeralDisksArgsForCall []struct {
+		arg1 []string
+		arg2 string
+	}
+	setupAdditionalEphemeralDisksReturns struct {
+		result1 error
+	}
+	setupAdditionalEphemeralDisksReturnsOnCall map[int]struct {
+		result1 error
+	}
 	SetupBlobsDirStub        func() error
 	setupBlobsDirMutex       sync.RWMutex
 	setupBlobsDirArgsForCall []struct {
@@ -512,12 +558,14 @@ type FakePlatform struct {
 	setupLoggingAndAuditingReturnsOnCall map[int]struct {
 		result1 error
 	}
-	SetupLogrotateStub        func(string, string, string) error
+	SetupLogrotateStub        func(string, string, string, int, map[string]int) error
 	setupLogrotateMutex       sync.RWMutex
 	setupLogrotateArgsForCall []struct {
 		arg1 string
 		arg2 string
 		arg3 string
+		arg4 int
+		arg5 map[string]int
 	}
 	setupLogrotateReturns struct {
 		result1 error
@@ -1078,6 +1126,61 @@ func (fake *FakePlatform) GetAuditLoggerReturnsOnCall(i int, result1 platform.Au
 	}{result1}
 }
 
+func (fake *FakePlatform) GetCPULoad() (stats.CPULoad, error) {
+	fake.getCPULoadMutex.Lock()
+	ret, specificReturn := fake.getCPULoadReturnsOnCall[len(fake.getCPULoadArgsForCall)]
+	fake.getCPULoadArgsForCall = append(fake.getCPULoadArgsForCall, struct {
+	}{})
+	fake.recordInvocation("GetCPULoad", []interface{}{})
+	fake.getCPULoadMutex.Unlock()
+	if fake.GetCPULoadStub != nil {
+		return fake.GetCPULoadStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.getCPULoadReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakePlatform) GetCPULoadCallCount() int {
+	fake.getCPULoadMutex.RLock()
+	defer fake.getCPULoadMutex.RUnlock()
+	return len(fake.getCPULoadArgsForCall)
+}
+
+func (fake *FakePlatform) GetCPULoadCalls(stub func() (stats.CPULoad, error)) {
+	fake.getCPULoadMutex.Lock()
+	defer fake.getCPULoadMutex.Unlock()
+	fake.GetCPULoadStub = stub
+}
+
+func (fake *FakePlatform) GetCPULoadReturns(result1 stats.CPULoad, result2 error) {
+	fake.getCPULoadMutex.Lock()
+	defer fake.getCPULoadMutex.Unlock()
+	fake.GetCPULoadStub = nil
+	fake.getCPULoadReturns = struct {
+		result1 stats.CPULoad
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakePlatform) GetCPULoadReturnsOnCall(i int, result1 stats.CPULoad, result2 error) {
+	fake.getCPULoadMutex.Lock()
+	defer fake.getCPULoadMutex.Unlock()
+	fake.GetCPULoadStub = nil
+	if fake.getCPULoadReturnsOnCall == nil {
+		fake.getCPULoadReturnsOnCall = make(map[int]struct {
+			result1 stats.CPULoad
+			result2 error
+		})
+	}
+	fake.getCPULoadReturnsOnCall[i] = struct {
+		result1 stats.CPULoad
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakePlatform) GetCertManager() cert.Manager {
 	fake.getCertManagerMutex.Lock()
 	ret, specificReturn := fake.getCertManagerReturnsOnCall[len(fake.getCertManagerArgsForCall)]
@@ -1917,6 +2020,58 @@ func (fake *FakePlatform) GetRunnerReturnsOnCall(i int, result1 system.CmdRunner
 	}{result1}
 }
 
+func (fake *FakePlatform) GetStatsCollector() stats.Collector {
+	fake.getStatsCollectorMutex.Lock()
+	ret, specificReturn := fake.getStatsCollectorReturnsOnCall[len(fake.getStatsCollectorArgsForCall)]
+	fake.getStatsCollectorArgsForCall = append(fake.getStatsCollectorArgsForCall, struct {
+	}{})
+	fake.recordInvocation("GetStatsCollector", []interface{}{})
+	fake.getStatsCollectorMutex.Unlock()
+	if fake.GetStatsCollectorStub != nil {
+		return fake.GetStatsCollectorStub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.getStatsCollectorReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakePlatform) GetStatsCollectorCallCount() int {
+	fake.getStatsCollectorMutex.RLock()
+	defer fake.getStatsCollectorMutex.RUnlock()
+	return len(fake.getStatsCollectorArgsForCall)
+}
+
+func (fake *FakePlatform) GetStatsCollectorCalls(stub func() stats.Collector) {
+	fake.getStatsCollectorMutex.Lock()
+	defer fake.getStatsCollectorMutex.Unlock()
+	fake.GetStatsCollectorStub = stub
+}
+
+func (fake *FakePlatform) GetStatsCollectorReturns(result1 stats.Collector) {
+	fake.getStatsCollectorMutex.Lock()
+	defer fake.getStatsCollectorMutex.Unlock()
+	fake.GetStatsCollectorStub = nil
+	fake.getStatsCollectorReturns = struct {
+		result1 stats.Collector
+	}{result1}
+}
+
+func (fake *FakePlatform) GetStatsCollectorReturnsOnCall(i int, result1 stats.Collector) {
+	fake.getStatsCollectorMutex.Lock()
+	defer fake.getStatsCollectorMutex.Unlock()
+	fake.GetStatsCollectorStub = nil
+	if fake.getStatsCollectorReturnsOnCall == nil {
+		fake.getStatsCollectorReturnsOnCall = make(map[int]struct {
+			result1 stats.Collector
+		})
+	}
+	fake.getStatsCollectorReturnsOnCall[i] = struct {
+		result1 stats.Collector
+	}{result1}
+}
+
 func (fake *FakePlatform) GetVitalsService() vitals.Service {
 	fake.getVitalsServiceMutex.Lock()
 	ret, specificReturn := fake.getVitalsServiceReturnsOnCall[len(fake.getVitalsServiceArgsForCall)]
@@ -1969,6 +2124,66 @@ func (fake *FakePlatform) GetVitalsServiceReturnsOnCall(i int, result1 vitals.Se
 	}{result1}
 }
 
+func (fake *FakePlatform) GrowEphemeralPartition(arg1 string) error {
+	fake.growEphemeralPartitionMutex.Lock()
+	ret, specificReturn := fake.growEphemeralPartitionReturnsOnCall[len(fake.growEphemeralPartitionArgsForCall)]
+	fake.growEphemeralPartitionArgsForCall = append(fake.growEphemeralPartitionArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	fake.recordInvocation("GrowEphemeralPartition", []interface{}{arg1})
+	fake.growEphemeralPartitionMutex.Unlock()
+	if fake.GrowEphemeralPartitionStub != nil {
+		return fake.GrowEphemeralPartitionStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.growEphemeralPartitionReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakePlatform) GrowEphemeralPartitionCallCount() int {
+	fake.growEphemeralPartitionMutex.RLock()
+	defer fake.growEphemeralPartitionMutex.RUnlock()
+	return len(fake.growEphemeralPartitionArgsForCall)
+}
+
+func (fake *FakePlatform) GrowEphemeralPartitionCalls(stub func(string) error) {
+	fake.growEphemeralPartitionMutex.Lock()
+	defer fake.growEphemeralPartitionMutex.Unlock()
+	fake.GrowEphemeralPartitionStub = stub
+}
+
+func (fake *FakePlatform) GrowEphemeralPartitionArgsForCall(i int) string {
+	fake.growEphemeralPartitionMutex.RLock()
+	defer fake.growEphemeralPartitionMutex.RUnlock()
+	argsForCall := fake.growEphemeralPartitionArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakePlatform) GrowEphemeralPartitionReturns(result1 error) {
+	fake.growEphemeralPartitionMutex.Lock()
+	defer fake.growEphemeralPartitionMutex.Unlock()
+	fake.GrowEphemeralPartitionStub = nil
+	fake.growEphemeralPartitionReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakePlatform) GrowEphemeralPartitionReturnsOnCall(i int, result1 error) {
+	fake.growEphemeralPartitionMutex.Lock()
+	defer fake.growEphemeralPartitionMutex.Unlock()
+	fake.GrowEphemeralPartitionStub = nil
+	if fake.growEphemeralPartitionReturnsOnCall == nil {
+		fake.growEphemeralPartitionReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.growEphemeralPartitionReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakePlatform) IsMountPoint(arg1 string) (string, bool, error) {
 	fake.isMountPointMutex.Lock()
 	ret, specificReturn := fake.isMountPointReturnsOnCall[len(fake.isMountPointArgsForCall)]
@@ -2642,6 +2857,72 @@ func (fake *FakePlatform) SetUserPasswordReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakePlatform) SetupAdditionalEphemeralDisks(arg1 []string, arg2 string) error {
+	var arg1Copy []string
+	if arg1 != nil {
+		arg1Copy = make([]string, len(arg1))
+		copy(arg1Copy, arg1)
+	}
+	fake.setupAdditionalEphemeralDisksMutex.Lock()
+	ret, specificReturn := fake.setupAdditionalEphemeralDisksReturnsOnCall[len(fake.setupAdditionalEphemeralDisksArgsForCall)]
+	fake.setupAdditionalEphemeralDisksArgsForCall = append(fake.setupAdditionalEphemeralDisksArgsForCall, struct {
+		arg1 []string
+		arg2 string
+	}{arg1Copy, arg2})
+	fake.recordInvocation("SetupAdditionalEphemeralDisks", []interface{}{arg1Copy, arg2})
+	fake.setupAdditionalEphemeralDisksMutex.Unlock()
+	if fake.SetupAdditionalEphemeralDisksStub != nil {
+		return fake.SetupAdditionalEphemeralDisksStub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.setupAdditionalEphemeralDisksReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakePlatform) SetupAdditionalEphemeralDisksCallCount() int {
+	fake.setupAdditionalEphemeralDisksMutex.RLock()
+	defer fake.setupAdditionalEphemeralDisksMutex.RUnlock()
+	return len(fake.setupAdditionalEphemeralDisksArgsForCall)
+}
+
+func (fake *FakePlatform) SetupAdditionalEphemeralDisksCalls(stub func([]string, string) error) {
+	fake.setupAdditionalEphemeralDisksMutex.Lock()
+	defer fake.setupAdditionalEphemeralDisksMutex.Unlock()
+	fake.SetupAdditionalEphemeralDisksStub = stub
+}
+
+func (fake *FakePlatform) SetupAdditionalEphemeralDisksArgsForCall(i int) ([]string, string) {
+	fake.setupAdditionalEphemeralDisksMutex.RLock()
+	defer fake.setupAdditionalEphemeralDisksMutex.RUnlock()
+	argsForCall := fake.setupAdditionalEphemeralDisksArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakePlatform) SetupAdditionalEphemeralDisksReturns(result1 error) {
+	fake.setupAdditionalEphemeralDisksMutex.Lock()
+	defer fake.setupAdditionalEphemeralDisksMutex.Unlock()
+	fake.SetupAdditionalEphemeralDisksStub = nil
+	fake.setupAdditionalEphemeralDisksReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakePlatform) SetupAdditionalEphemeralDisksReturnsOnCall(i int, result1 error) {
+	fake.setupAdditionalEphemeralDisksMutex.Lock()
+	defer fake.setupAdditionalEphemeralDisksMutex.Unlock()
+	fake.SetupAdditionalEphemeralDisksStub = nil
+	if fake.setupAdditionalEphemeralDisksReturnsOnCall == nil {
+		fake.setupAdditionalEphemeralDisksReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.setupAdditionalEphemeralDisksReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakePlatform) SetupBlobsDir() error {
 	fake.setupBlobsDirMutex.Lock()
 	ret, specificReturn := fake.setupBlobsDirReturnsOnCall[len(fake.setupBlobsDirArgsForCall)]
@@ -3197,18 +3478,20 @@ func (fake *FakePlatform) SetupLoggingAndAuditingReturnsOnCall(i int, result1 er
 	}{result1}
 }
 
-func (fake *FakePlatform) SetupLogrotate(arg1 string, arg2 string, arg3 string) error {
+func (fake *FakePlatform) SetupLogrotate(arg1 string, arg2 string, arg3 string, arg4 int, arg5 map[string]int) error {
 	fake.setupLogrotateMutex.Lock()
 	ret, specificReturn := fake.setupLogrotateReturnsOnCall[len(fake.setupLogrotateArgsForCall)]
 	fake.setupLogrotateArgsForCall = append(fake.setupLogrotateArgsForCall, struct {
 		arg1 string
 		arg2 string
 		arg3 string
-	}{arg1, arg2, arg3})
-	fake.recordInvocation("SetupLogrotate", []interface{}{arg1, arg2, arg3})
+		arg4 int
+		arg5 map[string]int
+	}{arg1, arg2, arg3, arg4, arg5})
+	fake.recordInvocation("SetupLogrotate", []interface{}{arg1, arg2, arg3, arg4, arg5})
 	fake.setupLogrotateMutex.Unlock()
 	if fake.SetupLogrotateStub != nil {
-		return fake.SetupLogrotateStub(arg1, arg2, arg3)
+		return fake.SetupLogrotateStub(arg1, arg2, arg3, arg4, arg5)
 	}
 	if specificReturn {
 		return ret.result1
@@ -3223,17 +3506,17 @@ func (fake *FakePlatform) SetupLogrotateCallCount() int {
 	return len(fake.setupLogrotateArgsForCall)
 }
 
-func (fake *FakePlatform) SetupLogrotateCalls(stub func(string, string, string) error) {
+func (fake *FakePlatform) SetupLogrotateCalls(stub func(string, string, string, int, map[string]int) error) {
 	fake.setupLogrotateMutex.Lock()
 	defer fake.setupLogrotateMutex.Unlock()
 	fake.SetupLogrotateStub = stub
 }
 
-func (fake *FakePlatform) SetupLogrotateArgsForCall(i int) (string, string, string) {
+func (fake *FakePlatform) SetupLogrotateArgsForCall(i int) (string, string, string, int, map[string]int) {
 	fake.setupLogrotateMutex.RLock()
 	defer fake.setupLogrotateMutex.RUnlock()
 	argsForCall := fake.setupLogrotateArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
 }
 
 func (fake *FakePlatform) SetupLogrotateReturns(result1 error) {
@@ -3962,6 +4245,8 @@ func (fake *FakePlatform) Invocations() map[string][][]interface{} {
 	defer fake.getAgentSettingsPathMutex.RUnlock()
 	fake.getAuditLoggerMutex.RLock()
 	defer fake.getAuditLoggerMutex.RUnlock()
+	fake.getCPULoadMutex.RLock()
+	defer fake.getCPULoadMutex.RUnlock()
 	fake.getCertManagerMutex.RLock()
 	defer fake.getCertManagerMutex.RUnlock()
 	fake.getCompressorMutex.RLock()
@@ -3992,8 +4277,12 @@ func (fake *FakePlatform) Invocations() map[string][][]interface{} {
 	defer fake.getPersistentDiskSettingsPathMutex.RUnlock()
 	fake.getRunnerMutex.RLock()
 	defer fake.getRunnerMutex.RUnlock()
+	fake.getStatsCollectorMutex.RLock()
+	defer fake.getStatsCollectorMutex.RUnlock()
 	fake.getVitalsServiceMutex.RLock()
 	defer fake.getVitalsServiceMutex.RUnlock()
+	fake.growEphemeralPartitionMutex.RLock()
+	defer fake.growEphemeralPartitionMutex.RUnlock()
 	fake.isMountPointMutex.RLock()
 	defer fake.isMountPointMutex.RUnlock()
 	fake.isPersistentDiskMountableMutex.RLock()
@@ -4016,6 +4305,8 @@ func (fake *FakePlatform) Invocations() map[string][][]interface{} {
 	defer fake.setTimeWithNtpServersMutex.RUnlock()
 	fake.setUserPasswordMutex.RLock()
 	defer fake.setUserPasswordMutex.RUnlock()
+	fake.setupAdditionalEphemeralDisksMutex.RLock()
+	defer fake.setupAdditionalEphemeralDisksMutex.RUnlock()
 	fake.setupBlobsDirMutex.RLock()
 	defer fake.setupBlobsDirMutex.RUnlock()
 	fake.setupBoshSettingsDiskMutex.RLock()
@@ -111,7 +111,12 @@ func NewProvider(logger boshlog.Logger, dirProvider boshdirs.Provider, statsColl
 	case "virtio":
 		udev := boshudev.NewConcreteUdevDevice(runner, logger)
 		idDevicePathResolver := devicepathresolver.NewIDDevicePathResolver(500*time.Millisecond, udev, fs)
-		mappedDevicePathResolver := devicepathresolver.NewMappedDevicePathResolver(30000*time.Millisecond, fs)
+		mappedDeviceWaitTimeout := 30000 * time.Millisecond
+		if options.Linux.DevicePathResolutionTimeoutInSeconds > 0 {
+			mappedDeviceWaitTimeout = time.Duration(options.Linux.DevicePathResolutionTimeoutInSeconds) * time.Second
+		}
+
+		mappedDevicePathResolver := devicepathresolver.NewMappedDevicePathResolver(mappedDeviceWaitTimeout, fs)
 		devicePathResolver = devicepathresolver.NewVirtioDevicePathResolver(idDevicePathResolver, mappedDevicePathResolver, logger)
 	case "scsi":
 		scsiIDPathResolver := devicepathresolver.NewSCSIIDDevicePathResolver(50000*time.Millisecond, fs, logger)
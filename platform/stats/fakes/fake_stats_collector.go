@@ -10,8 +10,9 @@ import (
 type FakeCollector struct {
 	StartCollectingCPUStats boshstats.CPUStats
 
-	CPULoad  boshstats.CPULoad
-	cpuStats boshstats.CPUStats
+	CPULoad    boshstats.CPULoad
+	CPULoadErr error
+	cpuStats   boshstats.CPUStats
 
 	MemStats    boshstats.Usage
 	MemStatsErr error
@@ -28,6 +29,7 @@ func (c *FakeCollector) StartCollecting(collectionInterval time.Duration, latest
 
 func (c *FakeCollector) GetCPULoad() (load boshstats.CPULoad, err error) {
 	load = c.CPULoad
+	err = c.CPULoadErr
 	return
 }
 
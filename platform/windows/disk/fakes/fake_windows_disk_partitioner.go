@@ -22,6 +22,20 @@ type FakeWindowsDiskPartitioner struct {
 		result1 string
 		result2 error
 	}
+	CreatePartitionStub        func(string, uint64) (string, error)
+	createPartitionMutex       sync.RWMutex
+	createPartitionArgsForCall []struct {
+		arg1 string
+		arg2 uint64
+	}
+	createPartitionReturns struct {
+		result1 string
+		result2 error
+	}
+	createPartitionReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
 	GetCountOnDiskStub        func(string) (string, error)
 	getCountOnDiskMutex       sync.RWMutex
 	getCountOnDiskArgsForCall []struct {
@@ -59,6 +73,19 @@ type FakeWindowsDiskPartitioner struct {
 	initializeDiskReturnsOnCall map[int]struct {
 		result1 error
 	}
+	IsInitializedStub        func(string) (bool, error)
+	isInitializedMutex       sync.RWMutex
+	isInitializedArgsForCall []struct {
+		arg1 string
+	}
+	isInitializedReturns struct {
+		result1 bool
+		result2 error
+	}
+	isInitializedReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
 	PartitionDiskStub        func(string) (string, error)
 	partitionDiskMutex       sync.RWMutex
 	partitionDiskArgsForCall []struct {
@@ -140,6 +167,70 @@ func (fake *FakeWindowsDiskPartitioner) AssignDriveLetterReturnsOnCall(i int, re
 	}{result1, result2}
 }
 
+func (fake *FakeWindowsDiskPartitioner) CreatePartition(arg1 string, arg2 uint64) (string, error) {
+	fake.createPartitionMutex.Lock()
+	ret, specificReturn := fake.createPartitionReturnsOnCall[len(fake.createPartitionArgsForCall)]
+	fake.createPartitionArgsForCall = append(fake.createPartitionArgsForCall, struct {
+		arg1 string
+		arg2 uint64
+	}{arg1, arg2})
+	fake.recordInvocation("CreatePartition", []interface{}{arg1, arg2})
+	fake.createPartitionMutex.Unlock()
+	if fake.CreatePartitionStub != nil {
+		return fake.CreatePartitionStub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.createPartitionReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeWindowsDiskPartitioner) CreatePartitionCallCount() int {
+	fake.createPartitionMutex.RLock()
+	defer fake.createPartitionMutex.RUnlock()
+	return len(fake.createPartitionArgsForCall)
+}
+
+func (fake *FakeWindowsDiskPartitioner) CreatePartitionCalls(stub func(string, uint64) (string, error)) {
+	fake.createPartitionMutex.Lock()
+	defer fake.createPartitionMutex.Unlock()
+	fake.CreatePartitionStub = stub
+}
+
+func (fake *FakeWindowsDiskPartitioner) CreatePartitionArgsForCall(i int) (string, uint64) {
+	fake.createPartitionMutex.RLock()
+	defer fake.createPartitionMutex.RUnlock()
+	argsForCall := fake.createPartitionArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeWindowsDiskPartitioner) CreatePartitionReturns(result1 string, result2 error) {
+	fake.createPartitionMutex.Lock()
+	defer fake.createPartitionMutex.Unlock()
+	fake.CreatePartitionStub = nil
+	fake.createPartitionReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeWindowsDiskPartitioner) CreatePartitionReturnsOnCall(i int, result1 string, result2 error) {
+	fake.createPartitionMutex.Lock()
+	defer fake.createPartitionMutex.Unlock()
+	fake.CreatePartitionStub = nil
+	if fake.createPartitionReturnsOnCall == nil {
+		fake.createPartitionReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.createPartitionReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeWindowsDiskPartitioner) GetCountOnDisk(arg1 string) (string, error) {
 	fake.getCountOnDiskMutex.Lock()
 	ret, specificReturn := fake.getCountOnDiskReturnsOnCall[len(fake.getCountOnDiskArgsForCall)]
@@ -326,6 +417,69 @@ func (fake *FakeWindowsDiskPartitioner) InitializeDiskReturnsOnCall(i int, resul
 	}{result1}
 }
 
+func (fake *FakeWindowsDiskPartitioner) IsInitialized(arg1 string) (bool, error) {
+	fake.isInitializedMutex.Lock()
+	ret, specificReturn := fake.isInitializedReturnsOnCall[len(fake.isInitializedArgsForCall)]
+	fake.isInitializedArgsForCall = append(fake.isInitializedArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	fake.recordInvocation("IsInitialized", []interface{}{arg1})
+	fake.isInitializedMutex.Unlock()
+	if fake.IsInitializedStub != nil {
+		return fake.IsInitializedStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.isInitializedReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeWindowsDiskPartitioner) IsInitializedCallCount() int {
+	fake.isInitializedMutex.RLock()
+	defer fake.isInitializedMutex.RUnlock()
+	return len(fake.isInitializedArgsForCall)
+}
+
+func (fake *FakeWindowsDiskPartitioner) IsInitializedCalls(stub func(string) (bool, error)) {
+	fake.isInitializedMutex.Lock()
+	defer fake.isInitializedMutex.Unlock()
+	fake.IsInitializedStub = stub
+}
+
+func (fake *FakeWindowsDiskPartitioner) IsInitializedArgsForCall(i int) string {
+	fake.isInitializedMutex.RLock()
+	defer fake.isInitializedMutex.RUnlock()
+	argsForCall := fake.isInitializedArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeWindowsDiskPartitioner) IsInitializedReturns(result1 bool, result2 error) {
+	fake.isInitializedMutex.Lock()
+	defer fake.isInitializedMutex.Unlock()
+	fake.IsInitializedStub = nil
+	fake.isInitializedReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeWindowsDiskPartitioner) IsInitializedReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.isInitializedMutex.Lock()
+	defer fake.isInitializedMutex.Unlock()
+	fake.IsInitializedStub = nil
+	if fake.isInitializedReturnsOnCall == nil {
+		fake.isInitializedReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.isInitializedReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeWindowsDiskPartitioner) PartitionDisk(arg1 string) (string, error) {
 	fake.partitionDiskMutex.Lock()
 	ret, specificReturn := fake.partitionDiskReturnsOnCall[len(fake.partitionDiskArgsForCall)]
@@ -394,12 +548,16 @@ func (fake *FakeWindowsDiskPartitioner) Invocations() map[string][][]interface{}
 	defer fake.invocationsMutex.RUnlock()
 	fake.assignDriveLetterMutex.RLock()
 	defer fake.assignDriveLetterMutex.RUnlock()
+	fake.createPartitionMutex.RLock()
+	defer fake.createPartitionMutex.RUnlock()
 	fake.getCountOnDiskMutex.RLock()
 	defer fake.getCountOnDiskMutex.RUnlock()
 	fake.getFreeSpaceOnDiskMutex.RLock()
 	defer fake.getFreeSpaceOnDiskMutex.RUnlock()
 	fake.initializeDiskMutex.RLock()
 	defer fake.initializeDiskMutex.RUnlock()
+	fake.isInitializedMutex.RLock()
+	defer fake.isInitializedMutex.RUnlock()
 	fake.partitionDiskMutex.RLock()
 	defer fake.partitionDiskMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
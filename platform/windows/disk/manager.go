@@ -23,8 +23,10 @@ type WindowsDiskLinker interface {
 type WindowsDiskPartitioner interface {
 	GetCountOnDisk(diskNumber string) (string, error)
 	GetFreeSpaceOnDisk(diskNumber string) (int, error)
+	IsInitialized(diskNumber string) (bool, error)
 	InitializeDisk(diskNumber string) error
 	PartitionDisk(diskNumber string) (string, error)
+	CreatePartition(diskNumber string, sizeInBytes uint64) (string, error)
 	AssignDriveLetter(diskNumber, partitionNumber string) (string, error)
 }
 
@@ -10,6 +10,12 @@ import (
 	boshsys "github.com/cloudfoundry/bosh-utils/system"
 )
 
+// Reported by New-Partition when the requested size exceeds the largest free extent
+const notEnoughCapacityError = "Not enough available capacity"
+
+// Localized PowerShell may group digits, e.g. 5,368,709,120 or 5.368.709.120
+var thousandsSeparatorReplacer = strings.NewReplacer(",", "", ".", "", "'", "", " ", "", "\u00a0", "", "\u202f", "")
+
 type Partitioner struct {
 	Runner boshsys.CmdRunner
 }
@@ -51,7 +57,7 @@ func (p *Partitioner) GetFreeSpaceOnDisk(diskNumber string) (int, error) {
 	}
 
 	stdoutTrimmed := strings.TrimSpace(stdout)
-	freeSpace, err := strconv.Atoi(stdoutTrimmed)
+	freeSpace, err := strconv.Atoi(thousandsSeparatorReplacer.Replace(stdoutTrimmed))
 
 	if err != nil {
 		return 0, fmt.Errorf(
@@ -63,6 +69,36 @@ func (p *Partitioner) GetFreeSpaceOnDisk(diskNumber string) (int, error) {
 	return freeSpace, nil
 }
 
+// IsInitialized reports whether the disk already has a partition table, so
+// that it can be checked before InitializeDisk clobbers an existing one
+func (p *Partitioner) IsInitialized(diskNumber string) (bool, error) {
+	getPartitionStyleCommand := fmt.Sprintf(
+		"Get-Disk -Number %s | Select -ExpandProperty PartitionStyle",
+		diskNumber,
+	)
+	getPartitionStyleCommandArgs := strings.Split(getPartitionStyleCommand, " ")
+
+	stdout, _, _, err := p.Runner.RunCommand(
+		getPartitionStyleCommandArgs[0],
+		getPartitionStyleCommandArgs[1:]...,
+	)
+
+	if err != nil {
+		return false, fmt.Errorf("failed to get partition style of disk %s: %s", diskNumber, err)
+	}
+
+	partitionStyle := strings.TrimSpace(stdout)
+
+	switch partitionStyle {
+	case "GPT", "MBR":
+		return true, nil
+	case "RAW":
+		return false, nil
+	default:
+		return false, fmt.Errorf("unknown partition style \"%s\" of disk %s", partitionStyle, diskNumber)
+	}
+}
+
 func (p *Partitioner) InitializeDisk(diskNumber string) error {
 	_, _, _, err := p.Runner.RunCommand("Initialize-Disk", "-Number", diskNumber, "-PartitionStyle", "GPT")
 	if err != nil {
@@ -90,6 +126,36 @@ func (p *Partitioner) PartitionDisk(diskNumber string) (string, error) {
 	return strings.TrimSpace(stdout), nil
 }
 
+// CreatePartition creates a partition of the given size, unlike PartitionDisk
+// which uses all of the remaining space on the disk
+func (p *Partitioner) CreatePartition(diskNumber string, sizeInBytes uint64) (string, error) {
+	stdout, stderr, _, err := p.Runner.RunCommand(
+		"New-Partition",
+		"-DiskNumber",
+		diskNumber,
+		"-Size",
+		strconv.FormatUint(sizeInBytes, 10),
+		"|",
+		"Select",
+		"-ExpandProperty",
+		"PartitionNumber",
+	)
+	if err != nil {
+		if strings.Contains(stderr, notEnoughCapacityError) || strings.Contains(err.Error(), notEnoughCapacityError) {
+			return "", fmt.Errorf(
+				"failed to create partition of %d bytes on disk %s: not enough free space on disk: %s",
+				sizeInBytes,
+				diskNumber,
+				err,
+			)
+		}
+
+		return "", fmt.Errorf("failed to create partition of %d bytes on disk %s: %s", sizeInBytes, diskNumber, err)
+	}
+
+	return strings.TrimSpace(stdout), nil
+}
+
 func (p *Partitioner) AssignDriveLetter(diskNumber, partitionNumber string) (string, error) {
 	_, _, _, err := p.Runner.RunCommand(
 		"Add-PartitionAccessPath",
@@ -128,5 +194,15 @@ func (p *Partitioner) AssignDriveLetter(diskNumber, partitionNumber string) (str
 		)
 	}
 
-	return strings.TrimSpace(stdout), nil
+	// DriveLetter is the NUL character when the partition has no drive letter
+	driveLetter := strings.Trim(stdout, " \t\r\n\x00")
+	if driveLetter == "" {
+		return "", fmt.Errorf(
+			"no drive letter was assigned to partition %s on disk %s",
+			partitionNumber,
+			diskNumber,
+		)
+	}
+
+	return driveLetter, nil
 }
@@ -55,6 +55,19 @@ At line:1 char:1
 
 		})
 
+		It("returns the free space on disk when the output groups digits with locale separators", func() {
+			for _, formattedFreeSpace := range []string{"5,368,709,120", "5.368.709.120", "5 368 709 120", "5\u00a0368\u00a0709\u00a0120", "5'368'709'120"} {
+				cmdRunner.AddCmdResult(
+					partitionFreeSpaceCommand(diskNumber),
+					fakes.FakeCmdResult{Stdout: formattedFreeSpace + "\r\n"},
+				)
+
+				freeSpace, err := partitioner.GetFreeSpaceOnDisk(diskNumber)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(freeSpace).To(Equal(5368709120))
+			}
+		})
+
 		It("when the command fails returns a wrapped error", func() {
 			cmdRunnerError := errors.New("It went wrong")
 			cmdRunner.AddCmdResult(
@@ -124,6 +137,66 @@ At line:1 char:1
 		})
 	})
 
+	Describe("IsInitialized", func() {
+		It("returns false when the disk has no partition style", func() {
+			cmdRunner.AddCmdResult(
+				partitionStyleCommand(diskNumber),
+				fakes.FakeCmdResult{Stdout: "RAW\r\n"},
+			)
+
+			initialized, err := partitioner.IsInitialized(diskNumber)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(initialized).To(BeFalse())
+		})
+
+		It("returns true when the disk has a GPT partition style", func() {
+			cmdRunner.AddCmdResult(
+				partitionStyleCommand(diskNumber),
+				fakes.FakeCmdResult{Stdout: "GPT\r\n"},
+			)
+
+			initialized, err := partitioner.IsInitialized(diskNumber)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(initialized).To(BeTrue())
+		})
+
+		It("returns true when the disk has an MBR partition style", func() {
+			cmdRunner.AddCmdResult(
+				partitionStyleCommand(diskNumber),
+				fakes.FakeCmdResult{Stdout: "MBR\r\n"},
+			)
+
+			initialized, err := partitioner.IsInitialized(diskNumber)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(initialized).To(BeTrue())
+		})
+
+		It("when the command fails returns a wrapped error", func() {
+			cmdRunnerError := errors.New("It went wrong")
+			cmdRunner.AddCmdResult(
+				partitionStyleCommand(diskNumber),
+				fakes.FakeCmdResult{ExitStatus: -1, Error: cmdRunnerError},
+			)
+
+			_, err := partitioner.IsInitialized(diskNumber)
+			Expect(err).To(MatchError(fmt.Sprintf(
+				"failed to get partition style of disk %s: %s",
+				diskNumber,
+				cmdRunnerError.Error(),
+			)))
+		})
+
+		It("returns an error when the partition style is not recognized", func() {
+			cmdRunner.AddCmdResult(
+				partitionStyleCommand(diskNumber),
+				fakes.FakeCmdResult{Stdout: "Unknown\r\n"},
+			)
+
+			_, err := partitioner.IsInitialized(diskNumber)
+			Expect(err).To(MatchError(fmt.Sprintf(`unknown partition style "Unknown" of disk %s`, diskNumber)))
+		})
+	})
+
 	Describe("InitializeDisk", func() {
 		It("makes the request to initialize the given disk", func() {
 			expectedCommand := initializeDiskCommand(diskNumber)
@@ -175,6 +248,63 @@ At line:1 char:1
 		})
 	})
 
+	Describe("CreatePartition", func() {
+		var sizeInBytes uint64
+
+		BeforeEach(func() {
+			sizeInBytes = 1073741824
+		})
+
+		It("makes the request to create a partition of the given size and returns the generated partition number", func() {
+			expectedCommand := createPartitionCommand(diskNumber, sizeInBytes)
+			expectedPartitionNumber := "3"
+			cmdRunner.AddCmdResult(expectedCommand, fakes.FakeCmdResult{Stdout: fmt.Sprintf(`%s
+`, expectedPartitionNumber)})
+
+			partitionNumber, err := partitioner.CreatePartition(diskNumber, sizeInBytes)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(partitionNumber).To(Equal(expectedPartitionNumber))
+			Expect(cmdRunner.RunCommands).To(Equal([][]string{strings.Split(expectedCommand, " ")}))
+		})
+
+		It("returns a descriptive error when there is not enough free space on the disk", func() {
+			cmdRunnerError := errors.New("Failed to partition")
+			cmdRunner.AddCmdResult(
+				createPartitionCommand(diskNumber, sizeInBytes),
+				fakes.FakeCmdResult{
+					Stderr: `New-Partition : Not enough available capacity
+At line:1 char:1
++ New-Partition -DiskNumber 1 -Size 1073741824
+`,
+					Error: cmdRunnerError,
+				},
+			)
+
+			partitionNumber, err := partitioner.CreatePartition(diskNumber, sizeInBytes)
+			Expect(partitionNumber).To(BeEmpty())
+			Expect(err).To(MatchError(fmt.Sprintf(
+				"failed to create partition of %d bytes on disk %s: not enough free space on disk: %s",
+				sizeInBytes,
+				diskNumber,
+				cmdRunnerError,
+			)))
+		})
+
+		It("returns a wrapped error with no partition number when the command fails", func() {
+			cmdRunnerError := errors.New("Failed to partition")
+			cmdRunner.AddCmdResult(
+				createPartitionCommand(diskNumber, sizeInBytes),
+				fakes.FakeCmdResult{Error: cmdRunnerError},
+			)
+
+			partitionNumber, err := partitioner.CreatePartition(diskNumber, sizeInBytes)
+			Expect(partitionNumber).To(BeEmpty())
+			Expect(err).To(MatchError(
+				fmt.Sprintf("failed to create partition of %d bytes on disk %s: %s", sizeInBytes, diskNumber, cmdRunnerError),
+			))
+		})
+	})
+
 	Describe("AssignDriveLetter", func() {
 		var partitionNumber string
 
@@ -233,6 +363,22 @@ At line:1 char:1
 			)))
 			Expect(driveLetter).To(Equal(""))
 		})
+
+		It("returns an error when the partition has no drive letter", func() {
+			cmdRunner.AddCmdResult(addPartitionAccessPathCommand(diskNumber, partitionNumber), fakes.FakeCmdResult{})
+			cmdRunner.AddCmdResult(
+				getDriveLetterCommand(diskNumber, partitionNumber),
+				fakes.FakeCmdResult{Stdout: "\x00\r\n"},
+			)
+
+			driveLetter, err := partitioner.AssignDriveLetter(diskNumber, partitionNumber)
+			Expect(err).To(MatchError(fmt.Sprintf(
+				"no drive letter was assigned to partition %s on disk %s",
+				partitionNumber,
+				diskNumber,
+			)))
+			Expect(driveLetter).To(Equal(""))
+		})
 	})
 })
 
@@ -244,6 +390,10 @@ func partitionFreeSpaceCommand(diskNumber string) string {
 	return fmt.Sprintf("Get-Disk %s | Select -ExpandProperty LargestFreeExtent", diskNumber)
 }
 
+func partitionStyleCommand(diskNumber string) string {
+	return fmt.Sprintf("Get-Disk -Number %s | Select -ExpandProperty PartitionStyle", diskNumber)
+}
+
 func initializeDiskCommand(diskNumber string) string {
 	return fmt.Sprintf("Initialize-Disk -Number %s -PartitionStyle GPT", diskNumber)
 }
@@ -255,6 +405,14 @@ func partitionDiskCommand(diskNumber string) string {
 	)
 }
 
+func createPartitionCommand(diskNumber string, sizeInBytes uint64) string {
+	return fmt.Sprintf(
+		"New-Partition -DiskNumber %s -Size %d | Select -ExpandProperty PartitionNumber",
+		diskNumber,
+		sizeInBytes,
+	)
+}
+
 func addPartitionAccessPathCommand(diskNumber, partitionNumber string) string {
 	return fmt.Sprintf(
 		"Add-PartitionAccessPath -DiskNumber %s -PartitionNumber %s -AssignDriveLetter",
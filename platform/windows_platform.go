@@ -127,6 +127,19 @@ func (p WindowsPlatform) GetVitalsService() (service boshvitals.Service) {
 	return p.vitalsService
 }
 
+func (p WindowsPlatform) GetStatsCollector() boshstats.Collector {
+	return p.collector
+}
+
+func (p WindowsPlatform) GetCPULoad() (boshstats.CPULoad, error) {
+	load, err := p.collector.GetCPULoad()
+	if err != nil {
+		return boshstats.CPULoad{}, bosherr.WrapError(err, "Getting CPU load")
+	}
+
+	return load, nil
+}
+
 func (p WindowsPlatform) GetDevicePathResolver() (devicePathResolver boshdpresolv.DevicePathResolver) {
 	return p.devicePathResolver
 }
@@ -262,6 +275,10 @@ func (p WindowsPlatform) SetupRootDisk(ephemeralDiskPath string) (err error) {
 	return
 }
 
+func (p WindowsPlatform) GrowEphemeralPartition(devicePath string) (err error) {
+	return
+}
+
 func (p WindowsPlatform) SetupCanRestartDir() error {
 	return nil
 }
@@ -393,7 +410,7 @@ func (p WindowsPlatform) GetCertManager() (certManager boshcert.Manager) {
 	return p.certManager
 }
 
-func (p WindowsPlatform) SetupLogrotate(groupName, basePath, size string) (err error) {
+func (p WindowsPlatform) SetupLogrotate(groupName, basePath, size string, maxLogFiles int, jobMaxLogFiles map[string]int) (err error) {
 	return
 }
 
@@ -533,6 +550,10 @@ func (p WindowsPlatform) SetupRawEphemeralDisks(devices []boshsettings.DiskSetti
 	return
 }
 
+func (p WindowsPlatform) SetupAdditionalEphemeralDisks(devicePaths []string, labelPrefix string) (err error) {
+	return
+}
+
 func (p WindowsPlatform) SetupDataDir(_ boshsettings.JobDir, _ boshsettings.RunDir) error {
 	dataDir := p.dirProvider.DataDir()
 	sysDataDir := filepath.Join(dataDir, "sys")
@@ -1,3 +1,4 @@
+//go:build windows
 // +build windows
 
 package platform_test
@@ -16,6 +17,7 @@ import (
 
 	"github.com/google/uuid"
 
+	fakefs "github.com/cloudfoundry/bosh-agent/platform/fakefs"
 	"github.com/cloudfoundry/bosh-agent/platform/windows/powershell"
 
 	"golang.org/x/sys/windows"
@@ -52,7 +54,6 @@ const ERROR_LOGON_FAILURE = syscall.Errno(0x52E)
 // Use LogonUser to check if the provided password is correct.
 //
 // https://msdn.microsoft.com/en-us/library/windows/desktop/aa378184(v=vs.85).aspx
-//
 func ValidUserPassword(username, password string) error {
 	const LOGON32_LOGON_NETWORK = 3
 	const LOGON32_PROVIDER_DEFAULT = 0
@@ -90,7 +91,7 @@ func ValidUserPassword(username, password string) error {
 var _ = Describe("WindowsPlatform", func() {
 	var (
 		collector                  *fakestats.FakeCollector
-		fs                         *fakesys.FakeFileSystem
+		fs                         *fakefs.FakeFileSystem
 		cmdRunner                  *fakesys.FakeCmdRunner
 		dirProvider                boshdirs.Provider
 		netManager                 *fakenet.FakeManager
@@ -116,7 +117,7 @@ var _ = Describe("WindowsPlatform", func() {
 		logger = boshlog.NewWriterLogger(boshlog.LevelDebug, logBuffer)
 
 		collector = &fakestats.FakeCollector{}
-		fs = fakesys.NewFakeFileSystem()
+		fs = fakefs.NewFakeFileSystem()
 		cmdRunner = fakesys.NewFakeCmdRunner()
 		dirProvider = boshdirs.NewProvider("/fake-dir")
 		netManager = &fakenet.FakeManager{}
@@ -181,7 +182,7 @@ var _ = Describe("WindowsPlatform", func() {
 
 			fileStats := fs.GetFileTestStat("/fake-dir/data/tmp")
 			Expect(fileStats).NotTo(BeNil())
-			Expect(fileStats.FileType).To(Equal(fakesys.FakeFileType(fakesys.FakeFileTypeDir)))
+			Expect(fileStats.FileType).To(Equal(fakefs.FakeFileType(fakefs.FakeFileTypeDir)))
 		})
 
 		It("returns error if creating new temp dir errs", func() {
@@ -217,7 +218,7 @@ var _ = Describe("WindowsPlatform", func() {
 
 			fileStats := fs.GetFileTestStat("/fake-dir/data/blobs")
 			Expect(fileStats).NotTo(BeNil())
-			Expect(fileStats.FileType).To(Equal(fakesys.FakeFileType(fakesys.FakeFileTypeDir)))
+			Expect(fileStats.FileType).To(Equal(fakefs.FakeFileType(fakefs.FakeFileTypeDir)))
 		})
 
 		It("returns error if creating new temp dir errs", func() {
@@ -236,11 +237,11 @@ var _ = Describe("WindowsPlatform", func() {
 
 			fileStats := fs.GetFileTestStat("/fake-dir/data/sys/log")
 			Expect(fileStats).NotTo(BeNil())
-			Expect(fileStats.FileType).To(Equal(fakesys.FakeFileType(fakesys.FakeFileTypeDir)))
+			Expect(fileStats.FileType).To(Equal(fakefs.FakeFileType(fakefs.FakeFileTypeDir)))
 
 			fileStats = fs.GetFileTestStat("/fake-dir/sys")
 			Expect(fileStats).NotTo(BeNil())
-			Expect(fileStats.FileType).To(Equal(fakesys.FakeFileType(fakesys.FakeFileTypeSymlink)))
+			Expect(fileStats.FileType).To(Equal(fakefs.FakeFileType(fakefs.FakeFileTypeSymlink)))
 		})
 
 		It("returns error if creating new temp dir errs", func() {
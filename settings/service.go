@@ -83,21 +83,22 @@ func (s *settingsService) LoadSettings() error {
 	if fetchErr != nil {
 		s.logger.Error(settingsServiceLogTag, "Failed loading settings via fetcher: %v", fetchErr)
 
-		opts := boshsys.ReadOpts{Quiet: true}
-		existingSettingsJSON, readError := s.fs.ReadFileWithOpts(s.getSettingsPath(), opts)
-		if readError != nil {
-			s.logger.Error(settingsServiceLogTag, "Failed reading settings from file %s", readError.Error())
-			return bosherr.WrapError(fetchErr, "Invoking settings fetcher")
-		}
-
-		s.logger.Debug(settingsServiceLogTag, "Successfully read settings from file")
-
-		cachedSettings := Settings{}
+		settingsPath := s.getSettingsPath()
 
-		err := json.Unmarshal(existingSettingsJSON, &cachedSettings)
+		cachedSettings, err := s.readSettingsFile(settingsPath)
 		if err != nil {
-			s.logger.Error(settingsServiceLogTag, "Failed unmarshalling settings from file %s", err.Error())
-			return bosherr.WrapError(fetchErr, "Invoking settings fetcher")
+			s.logger.Error(settingsServiceLogTag, "Failed reading settings from file %s", err.Error())
+
+			// The settings file may have been corrupted, e.g. by a crash while it was written
+			cachedSettings, err = s.readSettingsFile(settingsBackupPath(settingsPath))
+			if err != nil {
+				s.logger.Error(settingsServiceLogTag, "Failed reading settings from backup file %s", err.Error())
+				return bosherr.WrapError(fetchErr, "Invoking settings fetcher")
+			}
+
+			s.logger.Info(settingsServiceLogTag, "Recovered settings from backup file")
+		} else {
+			s.logger.Debug(settingsServiceLogTag, "Successfully read settings from file")
 		}
 
 		s.settingsMutex.Lock()
@@ -123,7 +124,7 @@ func (s *settingsService) LoadSettings() error {
 		return bosherr.WrapError(err, "Marshalling settings json")
 	}
 
-	err = s.fs.WriteFileQuietly(s.getSettingsPath(), newSettingsJSON)
+	err = s.writeSettingsFile(s.getSettingsPath(), newSettingsJSON)
 	if err != nil {
 		return bosherr.WrapError(err, "Writing setting json")
 	}
@@ -131,6 +132,52 @@ func (s *settingsService) LoadSettings() error {
 	return nil
 }
 
+// writeSettingsFile replaces the settings file atomically by renaming a fully
+// written temporary file over it. The previous settings are kept in a backup
+// file which is read when the settings file cannot be.
+func (s *settingsService) writeSettingsFile(settingsPath string, settingsJSON []byte) error {
+	tmpPath := settingsPath + ".tmp"
+
+	err := s.fs.WriteFileQuietly(tmpPath, settingsJSON)
+	if err != nil {
+		return bosherr.WrapError(err, "Writing temporary settings file")
+	}
+
+	if s.fs.FileExists(settingsPath) {
+		// A corrupted settings file must not replace a good backup
+		if _, err := s.readSettingsFile(settingsPath); err == nil {
+			err = s.fs.CopyFile(settingsPath, settingsBackupPath(settingsPath))
+			if err != nil {
+				return bosherr.WrapError(err, "Backing up settings file")
+			}
+		}
+	}
+
+	err = s.fs.Rename(tmpPath, settingsPath)
+	if err != nil {
+		return bosherr.WrapError(err, "Renaming temporary settings file")
+	}
+
+	return nil
+}
+
+func (s *settingsService) readSettingsFile(settingsPath string) (Settings, error) {
+	var settings Settings
+
+	opts := boshsys.ReadOpts{Quiet: true}
+	settingsJSON, err := s.fs.ReadFileWithOpts(settingsPath, opts)
+	if err != nil {
+		return settings, err
+	}
+
+	err = json.Unmarshal(settingsJSON, &settings)
+	if err != nil {
+		return settings, bosherr.WrapErrorf(err, "Unmarshalling settings from file %s", settingsPath)
+	}
+
+	return settings, nil
+}
+
 func (s *settingsService) GetAllPersistentDiskSettings() (map[string]DiskSettings, error) {
 	s.persistentDiskSettingsMutex.Lock()
 	defer s.persistentDiskSettingsMutex.Unlock()
@@ -234,11 +281,19 @@ func (s *settingsService) GetSettings() Settings {
 }
 
 func (s *settingsService) InvalidateSettings() error {
-	err := s.fs.RemoveAll(s.getSettingsPath())
+	settingsPath := s.getSettingsPath()
+
+	err := s.fs.RemoveAll(settingsPath)
 	if err != nil {
 		return bosherr.WrapError(err, "Removing settings file")
 	}
 
+	// Otherwise the previous settings would be recovered from the backup
+	err = s.fs.RemoveAll(settingsBackupPath(settingsPath))
+	if err != nil {
+		return bosherr.WrapError(err, "Removing settings backup file")
+	}
+
 	return nil
 }
 
@@ -300,6 +355,10 @@ func (s *settingsService) getSettingsPath() string {
 	return s.platform.GetAgentSettingsPath(s.settings.Env.Bosh.Agent.Settings.TmpFS)
 }
 
+func settingsBackupPath(settingsPath string) string {
+	return settingsPath + ".bak"
+}
+
 func (s *settingsService) getPersistentDiskSettingsPath() string {
 	s.settingsMutex.Lock()
 	defer s.settingsMutex.Unlock()
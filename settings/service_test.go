@@ -129,6 +129,55 @@ var _ = Describe("settingsService", func() {
 					Expect(err).To(HaveOccurred())
 					Expect(err.Error()).To(ContainSubstring("fs-write-file-error"))
 				})
+
+				It("writes the settings to a temporary file which is renamed over the settings file", func() {
+					err := service.LoadSettings()
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(fs.RenameOldPaths).To(Equal([]string{"/setting/path.json.tmp"}))
+					Expect(fs.RenameNewPaths).To(Equal([]string{"/setting/path.json"}))
+					Expect(fs.FileExists("/setting/path.json.tmp")).To(BeFalse())
+				})
+
+				It("leaves the settings file alone when the temporary file cannot be renamed", func() {
+					err := fs.WriteFileString("/setting/path.json", `{"agent_id":"some-old-agent-id"}`)
+					Expect(err).NotTo(HaveOccurred())
+					fs.RenameError = errors.New("fs-rename-error")
+
+					err = service.LoadSettings()
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("fs-rename-error"))
+
+					contents, err := fs.ReadFileString("/setting/path.json")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(contents).To(Equal(`{"agent_id":"some-old-agent-id"}`))
+				})
+
+				It("keeps the previous settings in a backup file", func() {
+					err := fs.WriteFileString("/setting/path.json", `{"agent_id":"some-old-agent-id"}`)
+					Expect(err).NotTo(HaveOccurred())
+
+					err = service.LoadSettings()
+					Expect(err).NotTo(HaveOccurred())
+
+					contents, err := fs.ReadFileString("/setting/path.json.bak")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(contents).To(Equal(`{"agent_id":"some-old-agent-id"}`))
+				})
+
+				It("does not replace the backup file with corrupted previous settings", func() {
+					err := fs.WriteFileString("/setting/path.json", `{"agent_id":`)
+					Expect(err).NotTo(HaveOccurred())
+					err = fs.WriteFileString("/setting/path.json.bak", `{"agent_id":"some-old-agent-id"}`)
+					Expect(err).NotTo(HaveOccurred())
+
+					err = service.LoadSettings()
+					Expect(err).NotTo(HaveOccurred())
+
+					contents, err := fs.ReadFileString("/setting/path.json.bak")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(contents).To(Equal(`{"agent_id":"some-old-agent-id"}`))
+				})
 			})
 		})
 
@@ -212,6 +261,28 @@ var _ = Describe("settingsService", func() {
 				})
 			})
 
+			Context("when a corrupted settings file and a backup file exist", func() {
+				It("returns settings from the backup file", func() {
+					fs.WriteFile("/setting/path.json", []byte(`{"agent_id":`))
+					fs.WriteFile("/setting/path.json.bak", []byte(`{"agent_id":"some-agent-id"}`))
+
+					err := service.LoadSettings()
+					Expect(err).ToNot(HaveOccurred())
+					Expect(service.GetSettings().AgentID).To(Equal("some-agent-id"))
+				})
+
+				It("returns any error from the fetcher when the backup file is corrupted too", func() {
+					fs.WriteFile("/setting/path.json", []byte(`{"agent_id":`))
+					fs.WriteFile("/setting/path.json.bak", []byte(`$%^&*(`))
+
+					err := service.LoadSettings()
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("fake-fetch-error"))
+
+					Expect(service.GetSettings()).To(Equal(Settings{}))
+				})
+			})
+
 			Context("when no settings file exists", func() {
 				It("returns any error from the fetcher", func() {
 					err := service.LoadSettings()
@@ -220,6 +291,16 @@ var _ = Describe("settingsService", func() {
 
 					Expect(service.GetSettings()).To(Equal(Settings{}))
 				})
+
+				It("ignores a temporary settings file left behind by an interrupted write", func() {
+					fs.WriteFile("/setting/path.json.tmp", []byte(`{"agent_id":"some-agent-id"}`))
+
+					err := service.LoadSettings()
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("fake-fetch-error"))
+
+					Expect(service.GetSettings()).To(Equal(Settings{}))
+				})
 			})
 		})
 	})
@@ -580,6 +661,19 @@ var _ = Describe("settingsService", func() {
 			Expect(fs.FileExists("/setting/path.json")).To(BeFalse())
 		})
 
+		It("removes the settings backup file", func() {
+			fakeSettingsSource.SettingsValue = Settings{}
+			fakeSettingsSource.SettingsErr = nil
+			service, fs := buildService()
+
+			fs.WriteFile("/setting/path.json.bak", []byte(`{}`))
+
+			err := service.InvalidateSettings()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fs.FileExists("/setting/path.json.bak")).To(BeFalse())
+		})
+
 		It("returns err if removing settings file errored", func() {
 			fakeSettingsSource.SettingsValue = Settings{}
 			fakeSettingsSource.SettingsErr = nil
@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"net"
 	"strconv"
+	"time"
 
 	"github.com/cloudfoundry/bosh-agent/platform/disk"
+	boshblob "github.com/cloudfoundry/bosh-utils/blobstore"
 )
 
 type DiskAssociations []DiskAssociation
@@ -24,6 +26,8 @@ const (
 	EphemeralUserPrefix = "bosh_"
 )
 
+const DefaultDrainLockTimeout = 30 * time.Minute
+
 type Settings struct {
 	AgentID   string    `json:"agent_id"`
 	Blobstore Blobstore `json:"blobstore"`
@@ -33,6 +37,9 @@ type Settings struct {
 	NTP       []string  `json:"ntp"`
 	Mbus      string    `json:"mbus"`
 	VM        VM        `json:"vm"`
+
+	// Tried in order when the primary mbus cannot be connected to
+	MbusFallback []string `json:"mbus_fallback"`
 }
 
 type UpdateSettings struct {
@@ -50,6 +57,32 @@ type Blobstore struct {
 	Options map[string]interface{} `json:"options"`
 }
 
+// Path used by micro BOSH which is moved to the agent's blobs directory
+const legacyLocalBlobstorePath = "/var/vcap/micro_bosh/data/cache"
+
+// Blobs directory of agents using the default /var/vcap base directory
+const defaultBlobsDir = "/var/vcap/data/blobs"
+
+// EffectiveLocalPath returns the blobstore_path option of a local blobstore,
+// with the legacy micro BOSH cache path rewritten to blobsDir. It returns
+// false for other blobstore types and when the path is not set.
+func (b Blobstore) EffectiveLocalPath(blobsDir string) (string, bool) {
+	if b.Type != boshblob.BlobstoreTypeLocal {
+		return "", false
+	}
+
+	path, ok := b.Options["blobstore_path"].(string)
+	if !ok {
+		return "", false
+	}
+
+	if path == legacyLocalBlobstorePath {
+		return blobsDir, true
+	}
+
+	return path, true
+}
+
 type Disks struct {
 	// e.g "/dev/sda", "1"
 	System string `json:"system"`
@@ -61,6 +94,10 @@ type Disks struct {
 	//     {"lun" => "0", "host_device_id" => "{host-device-id}"}
 	Ephemeral interface{} `json:"ephemeral"`
 
+	// Several ephemeral disks given as paths or volume IDs, e.g. ["/dev/sdb", "/dev/sdc"];
+	// takes precedence over Ephemeral when not empty
+	EphemeralDisks []string `json:"ephemeral_disks"`
+
 	// Older CPIs returned disk settings as strings
 	// e.g {"disk-3845-43758-7243-38754" => "/dev/sdc"}
 	//     {"disk-3845-43758-7243-38754" => "3"}
@@ -87,6 +124,9 @@ type DiskSettings struct {
 	FileSystemType disk.FileSystemType
 	MountOptions   []string
 
+	// Read-ahead in 512-byte sectors; nil leaves the device default untouched
+	ReadAhead *int
+
 	Partitioner string
 }
 
@@ -149,6 +189,21 @@ func (s Settings) EphemeralDiskSettings() DiskSettings {
 	return diskSettings
 }
 
+// EphemeralDisksSettings returns at least one element, the single Ephemeral
+// disk is used when EphemeralDisks is empty
+func (s Settings) EphemeralDisksSettings() []DiskSettings {
+	if len(s.Disks.EphemeralDisks) == 0 {
+		return []DiskSettings{s.EphemeralDiskSettings()}
+	}
+
+	var disksSettings []DiskSettings
+	for _, disk := range s.Disks.EphemeralDisks {
+		disksSettings = append(disksSettings, DiskSettings{Path: disk, VolumeID: disk})
+	}
+
+	return disksSettings
+}
+
 func (s Settings) RawEphemeralDiskSettings() (devices []DiskSettings) {
 	return s.Disks.RawEphemeral
 }
@@ -161,6 +216,11 @@ func (s Settings) GetMbusURL() string {
 	return s.Mbus
 }
 
+// GetMbusURLs returns the primary mbus URL followed by the fallback URLs
+func (s Settings) GetMbusURLs() []string {
+	return append([]string{s.GetMbusURL()}, s.MbusFallback...)
+}
+
 func (s Settings) GetBlobstore() Blobstore {
 	if len(s.Env.Bosh.Blobstores) > 0 {
 		return s.Env.Bosh.Blobstores[0]
@@ -168,6 +228,12 @@ func (s Settings) GetBlobstore() Blobstore {
 	return s.Blobstore
 }
 
+// EffectiveBlobstorePath returns where blobs of a local blobstore are stored
+// by an agent using the default base directory, see Blobstore.EffectiveLocalPath
+func (s Settings) EffectiveBlobstorePath() (string, bool) {
+	return s.GetBlobstore().EffectiveLocalPath(defaultBlobsDir)
+}
+
 func (s Settings) GetNtpServers() []string {
 	if len(s.Env.Bosh.NTP) > 0 {
 		return s.Env.Bosh.NTP
@@ -221,6 +287,7 @@ func (s Settings) populatePersistentDiskSettings(diskID string, settingsInfo int
 	diskSettings.FileSystemType = s.Env.PersistentDiskFS
 	diskSettings.MountOptions = s.Env.PersistentDiskMountOptions
 	diskSettings.Partitioner = s.Env.PersistentDiskPartitioner
+	diskSettings.ReadAhead = s.Env.PersistentDiskReadAhead
 
 	return diskSettings
 }
@@ -230,6 +297,7 @@ type Env struct {
 	PersistentDiskFS           disk.FileSystemType `json:"persistent_disk_fs"`
 	PersistentDiskMountOptions []string            `json:"persistent_disk_mount_options"`
 	PersistentDiskPartitioner  string              `json:"persistent_disk_partitioner"`
+	PersistentDiskReadAhead    *int                `json:"persistent_disk_read_ahead"`
 }
 
 func (e Env) GetPassword() string {
@@ -269,6 +337,43 @@ func (e Env) GetParallel() *int {
 	return &result
 }
 
+func (e Env) GetApplyParallel() *int {
+	result := 1
+	if e.Bosh.ApplyParallel != nil && *e.Bosh.ApplyParallel > 0 {
+		result = *e.Bosh.ApplyParallel
+	}
+	return &result
+}
+
+func (e Env) GetApplyJobsParallel() *int {
+	result := 1
+	if e.Bosh.ApplyJobsParallel != nil && *e.Bosh.ApplyJobsParallel > 0 {
+		result = *e.Bosh.ApplyJobsParallel
+	}
+	return &result
+}
+
+func (e Env) GetDrainLockTimeout() time.Duration {
+	if e.Bosh.Drain.LockTimeout > 0 {
+		return time.Duration(e.Bosh.Drain.LockTimeout) * time.Second
+	}
+	return DefaultDrainLockTimeout
+}
+
+func (e Env) IsDrainScriptRequired(jobName string) bool {
+	if e.Bosh.Drain.RequireScript {
+		return true
+	}
+
+	for _, requiredJob := range e.Bosh.Drain.RequireScriptJobs {
+		if requiredJob == jobName {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (e Env) IsNATSMutualTLSEnabled() bool {
 	return len(e.Bosh.Mbus.Cert.Certificate) > 0 && len(e.Bosh.Mbus.Cert.PrivateKey) > 0
 }
@@ -288,6 +393,18 @@ type BoshEnv struct {
 	Blobstores            []Blobstore `json:"blobstores"`
 	NTP                   []string    `json:"ntp"`
 	Parallel              *int        `json:"parallel"`
+	Logs                  Logs        `json:"logs"`
+	Drain                 Drain       `json:"drain"`
+	DigestAlgorithm       string      `json:"digest_algorithm"`
+	EnableDropCaches      bool        `json:"enable_drop_caches"`
+
+	// Number of packages unpacked concurrently during apply;
+	// packages are unpacked sequentially when not set
+	ApplyParallel *int `json:"apply_parallel"`
+
+	// Number of jobs whose rendered templates are downloaded concurrently during
+	// apply; templates are downloaded sequentially when not set
+	ApplyJobsParallel *int `json:"apply_jobs_parallel"`
 }
 
 type AgentEnv struct {
@@ -298,6 +415,35 @@ type AgentSettings struct {
 	TmpFS bool `json:"tmpfs"`
 }
 
+type Logs struct {
+	// Maximum size in bytes of a fetched logs tarball; unlimited when 0
+	MaxTarballSize uint64 `json:"max_tarball_size"`
+
+	// Symlinked log files and directories are collected unless set to true
+	SkipSymlinks bool `json:"skip_symlinks"`
+}
+
+type Drain struct {
+	// When set to true concurrent drains are serialized through a lock file
+	// and the drain scripts of the instance's jobs are run one at a time
+	Serialize bool `json:"serialize"`
+
+	// Seconds after which a drain lock held by a process that is no longer
+	// running is considered stale
+	LockTimeout int `json:"lock_timeout"`
+
+	// When set to true every job must have a drain script
+	RequireScript bool `json:"require_script"`
+
+	// Jobs that must have a drain script even when RequireScript is not set
+	RequireScriptJobs []string `json:"require_script_jobs"`
+
+	// Seconds dynamic drain scripts are polled at most and at least every,
+	// unbounded when not set
+	MinPollInterval int `json:"min_poll_interval"`
+	MaxPollInterval int `json:"max_poll_interval"`
+}
+
 type MBus struct {
 	Cert CertKeyPair `json:"cert"`
 	URLs []string    `json:"urls"`
@@ -2,6 +2,7 @@ package settings_test
 
 import (
 	"encoding/json"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
@@ -373,6 +374,16 @@ var _ = Describe("Settings", func() {
 					}))
 				})
 
+				It("gets the persistent disk read-ahead from env", func() {
+					settingsJSON := `{"env": {"persistent_disk_read_ahead": 4096}}`
+
+					err := json.Unmarshal([]byte(settingsJSON), &settings)
+					Expect(err).NotTo(HaveOccurred())
+					diskSettings := settings.PersistentDiskSettingsFromHint("fake-disk-id", diskHint)
+					Expect(diskSettings.ReadAhead).ToNot(BeNil())
+					Expect(*diskSettings.ReadAhead).To(Equal(4096))
+				})
+
 				It("does not crash if env does not have a filesystem type or a persistent_disk_mount_options", func() {
 					settingsJSON := `{"env": {"bosh": {"password": "secret"}}}`
 
@@ -999,6 +1010,117 @@ var _ = Describe("Settings", func() {
 			})
 		})
 
+		Context("when apply_parallel is not specified in the json", func() {
+			It("applies packages sequentially", func() {
+				var env Env
+				err := json.Unmarshal([]byte(`{"bosh": {}}`), &env)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(*env.GetApplyParallel()).To(Equal(1))
+			})
+		})
+
+		Context("when apply_parallel is specified in the json", func() {
+			It("uses the configured value", func() {
+				var env Env
+				err := json.Unmarshal([]byte(`{"bosh": {"apply_parallel": 4}}`), &env)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(*env.GetApplyParallel()).To(Equal(4))
+			})
+		})
+
+		Context("when apply_jobs_parallel is not specified in the json", func() {
+			It("downloads job templates sequentially", func() {
+				var env Env
+				err := json.Unmarshal([]byte(`{"bosh": {"apply_parallel": 4}}`), &env)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(*env.GetApplyJobsParallel()).To(Equal(1))
+			})
+		})
+
+		Context("when apply_jobs_parallel is specified in the json", func() {
+			It("uses the configured value", func() {
+				var env Env
+				err := json.Unmarshal([]byte(`{"bosh": {"apply_jobs_parallel": 3}}`), &env)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(*env.GetApplyJobsParallel()).To(Equal(3))
+			})
+		})
+
+		It("can set the maximum logs tarball size", func() {
+			env := Env{}
+			err := json.Unmarshal([]byte(`{"bosh": {} }`), &env)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env.Bosh.Logs).To(Equal(Logs{}))
+
+			env = Env{}
+			err = json.Unmarshal([]byte(`{"bosh": {"logs": {"max_tarball_size": 1048576} } }`), &env)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env.Bosh.Logs).To(Equal(Logs{MaxTarballSize: 1048576}))
+		})
+
+		It("can skip symlinks when fetching logs", func() {
+			env := Env{}
+			err := json.Unmarshal([]byte(`{"bosh": {"logs": {"skip_symlinks": true} } }`), &env)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env.Bosh.Logs).To(Equal(Logs{SkipSymlinks: true}))
+		})
+
+		It("can set the digest algorithm", func() {
+			env := Env{}
+			err := json.Unmarshal([]byte(`{"bosh": {"digest_algorithm": "sha256"} }`), &env)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env.Bosh.DigestAlgorithm).To(Equal("sha256"))
+		})
+
+		It("can enable dropping caches", func() {
+			env := Env{}
+			err := json.Unmarshal([]byte(`{"bosh": {"enable_drop_caches": true} }`), &env)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env.Bosh.EnableDropCaches).To(BeTrue())
+		})
+
+		Context("#GetDrainLockTimeout", func() {
+			It("defaults to 30 minutes", func() {
+				env := Env{}
+				Expect(env.GetDrainLockTimeout()).To(Equal(30 * time.Minute))
+			})
+
+			It("returns the configured lock timeout", func() {
+				env := Env{}
+				err := json.Unmarshal([]byte(`{"bosh": {"drain": {"serialize": true, "lock_timeout": 120} } }`), &env)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(env.Bosh.Drain.Serialize).To(BeTrue())
+				Expect(env.GetDrainLockTimeout()).To(Equal(2 * time.Minute))
+			})
+		})
+
+		Context("#IsDrainScriptRequired", func() {
+			It("does not require drain scripts by default", func() {
+				env := Env{}
+				Expect(env.IsDrainScriptRequired("foo")).To(BeFalse())
+			})
+
+			It("requires drain scripts for every job when set globally", func() {
+				env := Env{}
+				err := json.Unmarshal([]byte(`{"bosh": {"drain": {"require_script": true} } }`), &env)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(env.IsDrainScriptRequired("foo")).To(BeTrue())
+				Expect(env.IsDrainScriptRequired("bar")).To(BeTrue())
+			})
+
+			It("requires drain scripts only for the listed jobs", func() {
+				env := Env{}
+				err := json.Unmarshal([]byte(`{"bosh": {"drain": {"require_script_jobs": ["foo"]} } }`), &env)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(env.IsDrainScriptRequired("foo")).To(BeTrue())
+				Expect(env.IsDrainScriptRequired("bar")).To(BeFalse())
+			})
+		})
+
 		Context("#GetBlobstore", func() {
 			blobstoreLocal := Blobstore{
 				Type: "local",
@@ -1217,6 +1339,124 @@ var _ = Describe("Settings", func() {
 		})
 	})
 
+	Describe("#EphemeralDisksSettings", func() {
+		It("returns the settings of every ephemeral disk", func() {
+			settings = Settings{
+				Disks: Disks{
+					Ephemeral:      "/dev/sdz",
+					EphemeralDisks: []string{"/dev/sdb", "/dev/sdc"},
+				},
+			}
+
+			Expect(settings.EphemeralDisksSettings()).To(Equal([]DiskSettings{
+				{Path: "/dev/sdb", VolumeID: "/dev/sdb"},
+				{Path: "/dev/sdc", VolumeID: "/dev/sdc"},
+			}))
+		})
+
+		It("treats the single ephemeral disk as a one element list", func() {
+			settings = Settings{
+				Disks: Disks{
+					Ephemeral: map[string]interface{}{"path": "/dev/sdb"},
+				},
+			}
+
+			Expect(settings.EphemeralDisksSettings()).To(Equal([]DiskSettings{{Path: "/dev/sdb"}}))
+		})
+
+		It("returns empty settings when there is no ephemeral disk", func() {
+			settings = Settings{}
+
+			Expect(settings.EphemeralDisksSettings()).To(Equal([]DiskSettings{{}}))
+		})
+	})
+
+	Describe("#EffectiveBlobstorePath", func() {
+		It("rewrites the legacy micro BOSH path of a local blobstore to the blobs dir", func() {
+			settings = Settings{
+				Blobstore: Blobstore{
+					Type:    "local",
+					Options: map[string]interface{}{"blobstore_path": "/var/vcap/micro_bosh/data/cache"},
+				},
+			}
+
+			path, ok := settings.EffectiveBlobstorePath()
+			Expect(ok).To(BeTrue())
+			Expect(path).To(Equal("/var/vcap/data/blobs"))
+		})
+
+		It("returns other paths of a local blobstore as they are", func() {
+			settings = Settings{
+				Blobstore: Blobstore{
+					Type:    "local",
+					Options: map[string]interface{}{"blobstore_path": "/var/vcap/data"},
+				},
+			}
+
+			path, ok := settings.EffectiveBlobstorePath()
+			Expect(ok).To(BeTrue())
+			Expect(path).To(Equal("/var/vcap/data"))
+		})
+
+		It("uses the blobstore from env when present", func() {
+			settings = Settings{
+				Blobstore: Blobstore{Type: "dav"},
+				Env: Env{
+					Bosh: BoshEnv{
+						Blobstores: []Blobstore{{
+							Type:    "local",
+							Options: map[string]interface{}{"blobstore_path": "/var/vcap/micro_bosh/data/cache"},
+						}},
+					},
+				},
+			}
+
+			path, ok := settings.EffectiveBlobstorePath()
+			Expect(ok).To(BeTrue())
+			Expect(path).To(Equal("/var/vcap/data/blobs"))
+		})
+
+		It("returns false when the local blobstore has no path", func() {
+			settings = Settings{Blobstore: Blobstore{Type: "local"}}
+
+			_, ok := settings.EffectiveBlobstorePath()
+			Expect(ok).To(BeFalse())
+		})
+
+		It("returns false for other blobstore types", func() {
+			settings = Settings{
+				Blobstore: Blobstore{
+					Type:    "dav",
+					Options: map[string]interface{}{"blobstore_path": "/var/vcap/micro_bosh/data/cache"},
+				},
+			}
+
+			_, ok := settings.EffectiveBlobstorePath()
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Describe("#GetMbusURLs", func() {
+		It("returns the primary mbus URL followed by the fallbacks", func() {
+			settings = Settings{
+				Mbus:         "nats://top-level:123",
+				MbusFallback: []string{"nats://fallback-1:123", "nats://fallback-2:123"},
+			}
+
+			Expect(settings.GetMbusURLs()).To(Equal([]string{
+				"nats://top-level:123",
+				"nats://fallback-1:123",
+				"nats://fallback-2:123",
+			}))
+		})
+
+		It("returns only the primary mbus URL without fallbacks", func() {
+			settings = Settings{Mbus: "nats://top-level:123"}
+
+			Expect(settings.GetMbusURLs()).To(Equal([]string{"nats://top-level:123"}))
+		})
+	})
+
 	Describe("HasInterfaceAlias", func() {
 		Context("when networks is empty", func() {
 			It("returns found=false", func() {